@@ -0,0 +1,95 @@
+package http_agent
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"net/http"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *routeHandler) handleAPIKeyRoute(prefixPath string) {
+	// (optional). add prefix api version
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/scopes", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionAPIKeyManage), h.APIKeyScopeList)
+	api.GET("/list", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionAPIKeyManage), h.APIKeyList)
+	api.GET("/detail/:id", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionAPIKeyManage), h.APIKeyDetail)
+	api.POST("/create", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionAPIKeyManage), h.APIKeyCreate)
+	api.PUT("/update/:id", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionAPIKeyManage), h.APIKeyUpdate)
+	api.POST("/rotate/:id", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionAPIKeyManage), h.APIKeyRotate)
+	api.POST("/revoke/:id", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionAPIKeyManage), h.APIKeyRevoke)
+}
+
+func (r *routeHandler) APIKeyScopeList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.GetAPIKeyScopeList(ctx, c.MustGet("token_data").(domain.JWTClaimAgent))
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) APIKeyList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+	options := map[string]interface{}{
+		"query": c.Request.URL.Query(),
+	}
+
+	response := r.Usecase.GetAPIKeyList(ctx, claim, options)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) APIKeyDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.GetAPIKeyDetail(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), c.Param("id"))
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) APIKeyCreate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.APIKeyRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := r.Usecase.CreateAPIKey(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) APIKeyUpdate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.APIKeyRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := r.Usecase.UpdateAPIKey(ctx, claim, c.Param("id"), payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) APIKeyRotate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.RotateAPIKey(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), c.Param("id"))
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) APIKeyRevoke(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.RevokeAPIKey(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), c.Param("id"))
+	c.JSON(response.Status, response)
+}
@@ -2,6 +2,7 @@ package http_agent
 
 import (
 	"app/domain"
+	"app/helpers"
 	"net/http"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
@@ -15,8 +16,21 @@ func (h *routeHandler) handleAuthRoute(prefixPath string) {
 	api.POST("/login", h.Login)
 	api.POST("/request-password-reset", h.RequestPasswordReset)
 	api.POST("/password-reset", h.ResetPassword)
+	api.POST("/refresh-token", h.RefreshToken)
+	api.POST("/unlock", h.UnlockAccount)
+	api.POST("/sso/authorize", h.SSOAuthorize)
+	api.POST("/sso/callback", h.SSOCallback)
+	api.POST("/login/two-factor/setup", h.SetupTwoFactorLogin)
+	api.POST("/login/two-factor/verify", h.VerifyTwoFactorLogin)
 
 	api.GET("/me", h.Middleware.AuthAgent(), h.GetMe)
+	api.GET("/permissions", h.Middleware.AuthAgent(), h.MyPermissions)
+	api.POST("/logout", h.Middleware.AuthAgent(), h.Logout)
+	api.POST("/logout-all", h.Middleware.AuthAgent(), h.LogoutAll)
+	api.POST("/two-factor/setup", h.Middleware.AuthAgent(), h.SetupTwoFactor)
+	api.POST("/two-factor/enable", h.Middleware.AuthAgent(), h.EnableTwoFactor)
+	api.POST("/two-factor/disable", h.Middleware.AuthAgent(), h.DisableTwoFactor)
+	api.POST("/two-factor/recovery-codes", h.Middleware.AuthAgent(), h.RegenerateRecoveryCodes)
 }
 
 func (r *routeHandler) Login(c *gin.Context) {
@@ -28,6 +42,8 @@ func (r *routeHandler) Login(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
 		return
 	}
+	payload.IPAddress = c.ClientIP()
+	payload.UserAgent = c.Request.UserAgent()
 
 	response := r.Usecase.Login(ctx, payload)
 	c.JSON(response.Status, response)
@@ -69,3 +85,95 @@ func (r *routeHandler) ResetPassword(c *gin.Context) {
 	response := r.Usecase.PasswordReset(ctx, payload)
 	c.JSON(response.Status, response)
 }
+
+func (r *routeHandler) RefreshToken(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.RefreshTokenRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.RefreshToken(ctx, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := r.Usecase.Logout(ctx, claim)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) LogoutAll(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := r.Usecase.LogoutAll(ctx, claim)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) SSOAuthorize(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.SSOAuthorizeRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+	payload.Binding = helpers.NewOIDCBinding()
+
+	response := r.Usecase.SSOAuthorize(ctx, payload)
+	if response.Status == http.StatusOK {
+		// the callback is only accepted from the browser holding this cookie
+		c.SetSameSite(http.SameSiteNoneMode)
+		c.SetCookie(helpers.OIDCBindingCookie, payload.Binding, int(helpers.OIDCStateTTL.Seconds()), "/", "", true, true)
+	}
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) SSOCallback(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.SSOCallbackRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+	payload.Binding, _ = c.Cookie(helpers.OIDCBindingCookie)
+
+	response := r.Usecase.SSOCallback(ctx, payload)
+	c.SetSameSite(http.SameSiteNoneMode)
+	c.SetCookie(helpers.OIDCBindingCookie, "", -1, "/", "", true, true)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) MyPermissions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := r.Usecase.GetMyPermissions(ctx, claim)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) UnlockAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.UnlockAccountRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.UnlockAccount(ctx, payload)
+	c.JSON(response.Status, response)
+}
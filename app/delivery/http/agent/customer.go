@@ -2,6 +2,7 @@ package http_agent
 
 import (
 	"app/domain"
+	"app/domain/model"
 	"net/http"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
@@ -14,9 +15,11 @@ func (r *routeHandler) handleCustomerRoute(prefix string) {
 
 	api.GET("/list", r.Middleware.AuthAgent(), r.CustomerList)
 	api.GET("/detail/:id", r.Middleware.AuthAgent(), r.CustomerDetail)
-	api.POST("/create", r.Middleware.AuthAgent(), r.Middleware.Role("admin"), r.CustomerCreate)
-	api.PUT("/update/:id", r.Middleware.AuthAgent(), r.Middleware.Role("admin"), r.CustomerUpdate)
-	api.DELETE("/delete/:id", r.Middleware.AuthAgent(), r.Middleware.Role("admin"), r.CustomerDelete)
+	api.POST("/create", r.Middleware.AuthAgent(), r.Middleware.Permission(model.PermissionCustomerManage), r.CustomerCreate)
+	api.PUT("/update/:id", r.Middleware.AuthAgent(), r.Middleware.Permission(model.PermissionCustomerManage), r.CustomerUpdate)
+	api.DELETE("/delete/:id", r.Middleware.AuthAgent(), r.Middleware.Permission(model.PermissionCustomerManage), r.CustomerDelete)
+	api.GET("/balance-history/:id", r.Middleware.AuthAgent(), r.Middleware.Permission(model.PermissionBillingView), r.CustomerBalanceHistoryList)
+	api.GET("/balance-statement/:id", r.Middleware.AuthAgent(), r.Middleware.Permission(model.PermissionBillingView), r.CustomerBalanceStatement)
 }
 
 func (r *routeHandler) CustomerList(c *gin.Context) {
@@ -88,3 +91,27 @@ func (r *routeHandler) CustomerDelete(c *gin.Context) {
 	response := r.Usecase.DeleteCustomer(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), options)
 	c.JSON(response.Status, response)
 }
+
+func (r *routeHandler) CustomerBalanceHistoryList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	options := map[string]interface{}{
+		"id":    c.Param("id"),
+		"query": c.Request.URL.Query(),
+	}
+
+	response := r.Usecase.GetCustomerBalanceHistoryList(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), options)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) CustomerBalanceStatement(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	options := map[string]interface{}{
+		"id":    c.Param("id"),
+		"query": c.Request.URL.Query(),
+	}
+
+	response := r.Usecase.GetCustomerBalanceStatement(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), options)
+	c.JSON(response.Status, response)
+}
@@ -13,6 +13,7 @@ func (h *routeHandler) handleDashboardRoute(prefixPath string) {
 	api.GET("", h.Middleware.AuthAgent(), h.Dashboard)
 	api.GET("/total-ticket", h.Middleware.AuthAgent(), h.TotalTicket)
 	api.GET("/total-ticket-now", h.Middleware.AuthAgent(), h.TotalTicketNow)
+	api.GET("/workload", h.Middleware.AuthAgent(), h.AgentWorkload)
 }
 
 func (r *routeHandler) TotalTicket(c *gin.Context) {
@@ -41,3 +42,12 @@ func (r *routeHandler) Dashboard(c *gin.Context) {
 	response := r.Usecase.GetDataDashboard(ctx, claim, c.Request.URL.Query())
 	c.AbortWithStatusJSON(response.Status, response)
 }
+
+func (r *routeHandler) AgentWorkload(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := r.Usecase.GetAgentWorkload(ctx, claim)
+	c.AbortWithStatusJSON(response.Status, response)
+}
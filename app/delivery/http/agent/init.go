@@ -32,5 +32,9 @@ func NewAgentRouteHandler(route *gin.RouterGroup, middleware middleware.Middlewa
 	handler.handleUserRoute("/user")
 	handler.handleConfigRoute("/config")
 	handler.handleTicketCategoryRoute("/ticket-category")
+	handler.handleRoleRoute("/role")
 	handler.handleNotificationRoute("/notification")
+	handler.handleServerResourceRoute("/server-resource")
+	handler.handleSecurityEventRoute("/security-event")
+	handler.handleAPIKeyRoute("/api-key")
 }
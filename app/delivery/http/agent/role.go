@@ -0,0 +1,87 @@
+package http_agent
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"net/http"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *routeHandler) handleRoleRoute(prefixPath string) {
+	// (optional). add prefix api version
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/permissions", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionRoleManage), h.PermissionList)
+	api.GET("/list", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionRoleManage), h.RoleList)
+	api.GET("/detail/:id", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionRoleManage), h.RoleDetail)
+	api.POST("/create", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionRoleManage), h.RoleCreate)
+	api.PUT("/update/:id", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionRoleManage), h.RoleUpdate)
+	api.DELETE("/delete/:id", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionRoleManage), h.RoleDelete)
+}
+
+func (r *routeHandler) PermissionList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := r.Usecase.GetPermissionList(ctx, claim)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) RoleList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+	query := c.Request.URL.Query()
+
+	response := r.Usecase.GetRoleList(ctx, claim, query)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) RoleDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.GetRoleDetail(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), c.Param("id"))
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) RoleCreate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.RoleRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := r.Usecase.CreateRole(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) RoleUpdate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.RoleRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := r.Usecase.UpdateRole(ctx, claim, c.Param("id"), payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) RoleDelete(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.DeleteRole(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), c.Param("id"))
+	c.JSON(response.Status, response)
+}
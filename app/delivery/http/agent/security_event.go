@@ -0,0 +1,27 @@
+package http_agent
+
+import (
+	"app/domain"
+	"app/domain/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *routeHandler) handleSecurityEventRoute(prefixPath string) {
+	// (optional). add prefix api version
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/list", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionSecurityView), h.SecurityEventList)
+}
+
+func (h *routeHandler) SecurityEventList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+	options := map[string]interface{}{
+		"query": c.Request.URL.Query(),
+	}
+
+	response := h.Usecase.GetSecurityEventList(ctx, claim, options)
+	c.JSON(response.Status, response)
+}
@@ -0,0 +1,57 @@
+package http_agent
+
+import (
+	"app/domain"
+	"net/http"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *routeHandler) handleServerResourceRoute(prefixPath string) {
+	// (optional). add prefix api version
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/list", h.Middleware.AuthAgent(), h.ServerResourceList)
+	api.GET("/detail/:id", h.Middleware.AuthAgent(), h.ServerResourceDetail)
+	api.POST("/take-task/:id", h.Middleware.AuthAgent(), h.TakeProvisioningTask)
+	api.POST("/complete-task/:id", h.Middleware.AuthAgent(), h.CompleteProvisioningTask)
+}
+
+func (r *routeHandler) ServerResourceList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+	query := c.Request.URL.Query()
+
+	response := r.Usecase.GetServerResourceList(ctx, claim, query)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) ServerResourceDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.GetServerResourceDetail(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), c.Param("id"))
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) TakeProvisioningTask(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.TakeProvisioningTask(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), c.Param("id"))
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) CompleteProvisioningTask(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.CompleteProvisioningTaskRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.CompleteProvisioningTask(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), c.Param("id"), payload)
+	c.JSON(response.Status, response)
+}
@@ -2,6 +2,7 @@ package http_agent
 
 import (
 	"app/domain"
+	"app/domain/model"
 	"net/http"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
@@ -13,9 +14,13 @@ func (h *routeHandler) handleSettingRoute(prefixPath string) {
 	api := h.Route.Group(prefixPath)
 
 	api.POST("/change-password", h.Middleware.AuthAgent(), h.ChangePassword)
-	api.POST("/change-domain", h.Middleware.AuthAgent(), h.Middleware.Role("admin"), h.ChangeDomain)
+	api.POST("/change-domain", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionSettingManage), h.ChangeDomain)
 	api.POST("/update-profile", h.Middleware.AuthAgent(), h.UpdateProfile)
-	api.POST("/change-color", h.Middleware.AuthAgent(), h.Middleware.Role("admin"), h.ChangeColor)
+	api.POST("/change-color", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionSettingManage), h.ChangeColor)
+	api.POST("/change-overage-policy", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionSettingManage), h.ChangeOveragePolicy)
+	api.POST("/change-passwordless-login", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionSettingManage), h.ChangePasswordlessLogin)
+	api.POST("/change-auto-assign-ticket", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionSettingManage), h.ChangeAutoAssignTicket)
+	api.POST("/change-agent-capacity", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionSettingManage), h.ChangeAgentCapacity)
 	api.POST("/upload-profile-picture", h.Middleware.AuthAgent(), h.UploadAgentProfilePicture)
 }
 
@@ -83,6 +88,70 @@ func (h *routeHandler) ChangeColor(c *gin.Context) {
 	c.JSON(response.Status, response)
 }
 
+func (h *routeHandler) ChangeOveragePolicy(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.ChangeOveragePolicyRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := h.Usecase.ChangeOveragePolicy(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) ChangePasswordlessLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.ChangePasswordlessLoginRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := h.Usecase.ChangePasswordlessLogin(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) ChangeAutoAssignTicket(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.ChangeAutoAssignTicketRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := h.Usecase.ChangeAutoAssignTicket(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) ChangeAgentCapacity(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.ChangeAgentCapacityRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := h.Usecase.ChangeAgentCapacity(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
+
 func (r *routeHandler) UploadAgentProfilePicture(c *gin.Context) {
 	ctx := c.Request.Context()
 
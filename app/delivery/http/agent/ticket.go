@@ -2,6 +2,8 @@ package http_agent
 
 import (
 	"app/domain"
+	"app/domain/model"
+	"io"
 	"net/http"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
@@ -15,8 +17,8 @@ func (h *routeHandler) handleTicketRoute(prefixPath string) {
 	api.GET("/list", h.Middleware.AuthAgent(), h.TicketList)
 	api.GET("/mine/list", h.Middleware.AuthAgent(), h.MyTicketList)
 	api.GET("/detail/:id", h.Middleware.AuthAgent(), h.TicketDetail)
-	api.POST("/close", h.Middleware.AuthAgent(), h.TicketClose)
-	api.POST("/reopen", h.Middleware.AuthAgent(), h.TicketReopen)
+	api.POST("/close", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionTicketClose), h.TicketClose)
+	api.POST("/reopen", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionTicketClose), h.TicketReopen)
 	// api.POST("/logging/start", h.Middleware.AuthAgent(), h.TicketLogStart)
 	// api.POST("/logging/stop", h.Middleware.AuthAgent(), h.TicketLogStop)
 	api.POST("/logging/pause", h.Middleware.AuthAgent(), h.TicketLogPause)
@@ -26,9 +28,11 @@ func (h *routeHandler) handleTicketRoute(prefixPath string) {
 	api.GET("/comments/detail/:idComment", h.Middleware.AuthAgent(), h.TicketCommentDetail)
 	api.PUT("/time-track/update/:idTicket", h.Middleware.AuthAgent(), h.TimeTrack)
 	api.GET("/export-csv", h.Middleware.AuthAgent(), h.ExportTicketsToCSV)
-	api.POST("/assign-me/:ticket_id", h.Middleware.AuthAgent(), h.AssignMe)
+	api.POST("/assign-me/:ticket_id", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionTicketAssign), h.AssignMe)
 	api.GET("/total-ticket/:id", h.Middleware.AuthAgent(), h.TotalTicketCustomer)
 	api.GET("/total-ticket-day/:id", h.Middleware.AuthAgent(), h.TotalTicketCustomerDays)
+	api.POST("/presence/:id", h.Middleware.AuthAgent(), h.TicketPresenceUpdate)
+	api.GET("/presence/stream/:id", h.Middleware.AuthAgent(), h.TicketPresenceStream)
 }
 
 func (r *routeHandler) TicketList(c *gin.Context) {
@@ -239,3 +243,41 @@ func (r *routeHandler) TotalTicketCustomerDays(c *gin.Context) {
 	response := r.Usecase.GetDataCustomerTicket(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), options)
 	c.JSON(response.Status, response)
 }
+
+func (h *routeHandler) TicketPresenceUpdate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.TicketPresenceRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	response := h.Usecase.UpdateTicketPresence(ctx, claim, c.Param("id"), payload)
+	c.JSON(response.Status, response)
+}
+
+// TicketPresenceStream server sent events of the ticket presence
+func (h *routeHandler) TicketPresenceStream(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimAgent)
+
+	updates, errResponse := h.Usecase.SubscribeTicketPresence(ctx, claim, c.Param("id"))
+	if errResponse != nil {
+		c.AbortWithStatusJSON(errResponse.Status, errResponse)
+		return
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		presence, ok := <-updates
+		if !ok {
+			return false
+		}
+		c.SSEvent("presence", presence)
+		return true
+	})
+}
@@ -2,6 +2,7 @@ package http_agent
 
 import (
 	"app/domain"
+	"app/domain/model"
 	"net/http"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
@@ -14,9 +15,9 @@ func (h *routeHandler) handleTicketCategoryRoute(prefixPath string) {
 
 	api.GET("/list", h.Middleware.AuthAgent(), h.TicketCategoryList)
 	api.GET("/detail/:id", h.Middleware.AuthAgent(), h.TicketCategoryDetail)
-	api.POST("/create", h.Middleware.AuthAgent(), h.Middleware.Role("admin"), h.TicketCategoryCreate)
-	api.PUT("/update/:id", h.Middleware.AuthAgent(), h.Middleware.Role("admin"), h.TicketCategoryUpdate)
-	api.DELETE("/delete/:id", h.Middleware.AuthAgent(), h.Middleware.Role("admin"), h.TicketCategoryDelete)
+	api.POST("/create", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionCategoryManage), h.TicketCategoryCreate)
+	api.PUT("/update/:id", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionCategoryManage), h.TicketCategoryUpdate)
+	api.DELETE("/delete/:id", h.Middleware.AuthAgent(), h.Middleware.Permission(model.PermissionCategoryManage), h.TicketCategoryDelete)
 }
 
 func (r *routeHandler) TicketCategoryList(c *gin.Context) {
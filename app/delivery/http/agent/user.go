@@ -2,6 +2,7 @@ package http_agent
 
 import (
 	"app/domain"
+	"app/domain/model"
 	"net/http"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
@@ -12,11 +13,12 @@ func (r *routeHandler) handleUserRoute(prefix string) {
 	// (optional). add prefix api version
 	api := r.Route.Group(prefix)
 
-	api.GET("/list", r.Middleware.AuthAgent(), r.Middleware.Role("admin"), r.UserList)
-	api.GET("/detail/:id", r.Middleware.AuthAgent(), r.Middleware.Role("admin"), r.UserDetail)
-	api.POST("/create", r.Middleware.AuthAgent(), r.Middleware.Role("admin"), r.UserCreate)
-	api.PUT("/update/:id", r.Middleware.AuthAgent(), r.Middleware.Role("admin"), r.UserUpdate)
-	api.DELETE("/delete/:id", r.Middleware.AuthAgent(), r.Middleware.Role("admin"), r.UserDelete)
+	api.GET("/list", r.Middleware.AuthAgent(), r.Middleware.Permission(model.PermissionUserManage), r.UserList)
+	api.GET("/detail/:id", r.Middleware.AuthAgent(), r.Middleware.Permission(model.PermissionUserManage), r.UserDetail)
+	api.POST("/create", r.Middleware.AuthAgent(), r.Middleware.Permission(model.PermissionUserManage), r.UserCreate)
+	api.PUT("/update/:id", r.Middleware.AuthAgent(), r.Middleware.Permission(model.PermissionUserManage), r.UserUpdate)
+	api.DELETE("/delete/:id", r.Middleware.AuthAgent(), r.Middleware.Permission(model.PermissionUserManage), r.UserDelete)
+	api.POST("/category/bulk", r.Middleware.AuthAgent(), r.Middleware.Permission(model.PermissionUserManage), r.UserBulkCategory)
 }
 
 func (r *routeHandler) UserList(c *gin.Context) {
@@ -88,3 +90,17 @@ func (r *routeHandler) UserDelete(c *gin.Context) {
 	response := r.Usecase.DeleteAgent(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), options)
 	c.JSON(response.Status, response)
 }
+
+func (r *routeHandler) UserBulkCategory(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.BulkAgentCategoryRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.BulkUpdateAgentCategory(ctx, c.MustGet("token_data").(domain.JWTClaimAgent), payload)
+	c.JSON(response.Status, response)
+}
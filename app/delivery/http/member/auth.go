@@ -2,6 +2,7 @@ package http_member
 
 import (
 	"app/domain"
+	"app/helpers"
 	"net/http"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
@@ -18,8 +19,19 @@ func (h *routeHandler) handleAuthRoute(prefixPath string) {
 	api.POST("/request-password-reset", h.RequestPasswordReset)
 	api.POST("/password-reset", h.ResetPassword)
 	api.POST("/register-b2b", h.CreateCustomer)
+	api.POST("/refresh-token", h.RefreshToken)
+	api.POST("/unlock", h.UnlockAccount)
+	api.POST("/magic-link/request", h.RequestMagicLink)
+	api.POST("/magic-link/login", h.MagicLinkLogin)
+	api.GET("/invitation", h.InvitationDetail)
+	api.POST("/invitation/accept", h.InvitationAccept)
+	api.POST("/sso/authorize", h.SSOAuthorize)
+	api.POST("/sso/callback", h.SSOCallback)
 
 	api.GET("/me", h.Middleware.AuthCustomer(), h.GetMe)
+	api.GET("/permissions", h.Middleware.AuthCustomer(), h.MyPermissions)
+	api.POST("/logout", h.Middleware.AuthCustomer(), h.Logout)
+	api.POST("/logout-all", h.Middleware.AuthCustomer(), h.LogoutAll)
 }
 
 func (r *routeHandler) Login(c *gin.Context) {
@@ -31,6 +43,8 @@ func (r *routeHandler) Login(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
 		return
 	}
+	payload.IPAddress = c.ClientIP()
+	payload.UserAgent = c.Request.UserAgent()
 
 	response := r.Usecase.Login(ctx, payload)
 	c.JSON(response.Status, response)
@@ -114,3 +128,148 @@ func (r *routeHandler) CreateCustomer(c *gin.Context) {
 	response := r.Usecase.RegisterB2B(ctx, payload)
 	c.JSON(response.Status, response)
 }
+
+func (r *routeHandler) RefreshToken(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.RefreshTokenRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.RefreshToken(ctx, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimUser)
+
+	response := r.Usecase.Logout(ctx, claim)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) LogoutAll(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimUser)
+
+	response := r.Usecase.LogoutAll(ctx, claim)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) SSOAuthorize(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.SSOAuthorizeRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+	payload.Binding = helpers.NewOIDCBinding()
+
+	response := r.Usecase.SSOAuthorize(ctx, payload)
+	if response.Status == http.StatusOK {
+		// the callback is only accepted from the browser holding this cookie
+		c.SetSameSite(http.SameSiteNoneMode)
+		c.SetCookie(helpers.OIDCBindingCookie, payload.Binding, int(helpers.OIDCStateTTL.Seconds()), "/", "", true, true)
+	}
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) SSOCallback(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.SSOCallbackRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+	payload.Binding, _ = c.Cookie(helpers.OIDCBindingCookie)
+
+	response := r.Usecase.SSOCallback(ctx, payload)
+	c.SetSameSite(http.SameSiteNoneMode)
+	c.SetCookie(helpers.OIDCBindingCookie, "", -1, "/", "", true, true)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) MyPermissions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimUser)
+
+	response := r.Usecase.GetMyPermissions(ctx, claim)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) UnlockAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.UnlockAccountRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.UnlockAccount(ctx, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) RequestMagicLink(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.MagicLinkRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+	payload.IPAddress = c.ClientIP()
+	payload.UserAgent = c.Request.UserAgent()
+
+	response := r.Usecase.RequestMagicLink(ctx, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) MagicLinkLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.MagicLinkLoginRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+	payload.IPAddress = c.ClientIP()
+	payload.UserAgent = c.Request.UserAgent()
+
+	response := r.Usecase.MagicLinkLogin(ctx, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) InvitationDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.GetInvitationDetail(ctx, c.Query("token"))
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) InvitationAccept(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.AcceptInvitationRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.AcceptInvitation(ctx, payload)
+	c.JSON(response.Status, response)
+}
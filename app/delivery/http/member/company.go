@@ -1,6 +1,9 @@
 package http_member
 
 import (
+	"app/domain"
+	"app/domain/model"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,6 +12,8 @@ func (h *routeHandler) handleCompanyRoute(prefixPath string) {
 	api := h.Route.Group(prefixPath)
 
 	api.GET("/detail-by-domain/:domain", h.CompanyDetailByDomain)
+	api.GET("/wallet", h.Middleware.AuthCustomer(), h.Middleware.Permission(model.PermissionBillingView), h.CompanyWallet)
+	api.GET("/wallet/history", h.Middleware.AuthCustomer(), h.Middleware.Permission(model.PermissionBillingView), h.CompanyWalletHistoryList)
 }
 
 func (r *routeHandler) CompanyDetailByDomain(c *gin.Context) {
@@ -21,3 +26,17 @@ func (r *routeHandler) CompanyDetailByDomain(c *gin.Context) {
 	response := r.Usecase.GetCompanyDetailByDomain(ctx, options)
 	c.JSON(response.Status, response)
 }
+
+func (r *routeHandler) CompanyWallet(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.GetCompanyWallet(ctx, c.MustGet("token_data").(domain.JWTClaimUser))
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) CompanyWalletHistoryList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.GetCompanyWalletHistoryList(ctx, c.MustGet("token_data").(domain.JWTClaimUser), c.Request.URL.Query())
+	c.JSON(response.Status, response)
+}
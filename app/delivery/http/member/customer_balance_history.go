@@ -0,0 +1,35 @@
+package http_member
+
+import (
+	"app/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *routeHandler) handleBalanceHistoryRoute(prefixPath string) {
+	// (optional). add prefix api version
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/list", h.Middleware.AuthCustomer(), h.BalanceHistoryList)
+	api.GET("/statement", h.Middleware.AuthCustomer(), h.BalanceStatement)
+}
+
+func (h *routeHandler) BalanceHistoryList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimUser)
+	query := c.Request.URL.Query()
+
+	response := h.Usecase.GetBalanceHistoryList(ctx, claim, query)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) BalanceStatement(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimUser)
+	query := c.Request.URL.Query()
+
+	response := h.Usecase.GetBalanceStatement(ctx, claim, query)
+	c.JSON(response.Status, response)
+}
@@ -12,6 +12,8 @@ func (h *routeHandler) handleCustomerSubscriptionRoute(prefixPath string) {
 
 	api.GET("/list", h.Middleware.AuthCustomer(), h.CustomerSubscriptionList)
 	api.GET("/detail/:id", h.Middleware.AuthCustomer(), h.CustomerSubscriptionDetail)
+	api.POST("/auto-renew/:id", h.Middleware.AuthCustomer(), h.EnableAutoRenewal)
+	api.POST("/cancel-renewal/:id", h.Middleware.AuthCustomer(), h.CancelRenewal)
 }
 
 func (h *routeHandler) CustomerSubscriptionList(c *gin.Context) {
@@ -32,3 +34,21 @@ func (h *routeHandler) CustomerSubscriptionDetail(c *gin.Context) {
 	response := h.Usecase.GetCustomerSubscriptionDetail(ctx, c.MustGet("token_data").(domain.JWTClaimUser), customerSubscriptionID)
 	c.JSON(response.Status, response)
 }
+
+func (h *routeHandler) EnableAutoRenewal(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	customerSubscriptionID := c.Param("id")
+
+	response := h.Usecase.EnableAutoRenewal(ctx, c.MustGet("token_data").(domain.JWTClaimUser), customerSubscriptionID)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) CancelRenewal(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	customerSubscriptionID := c.Param("id")
+
+	response := h.Usecase.CancelRenewal(ctx, c.MustGet("token_data").(domain.JWTClaimUser), customerSubscriptionID)
+	c.JSON(response.Status, response)
+}
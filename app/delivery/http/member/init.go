@@ -28,6 +28,7 @@ func NewCustomerHandler(route *gin.RouterGroup, middleware middleware.Middleware
 	handler.handleOrderRoute("/order")
 	handler.handleHourPackageRoute("/package/hour")
 	handler.handleCustomerSubscriptionRoute("/customer-subscription")
+	handler.handleBalanceHistoryRoute("/balance-history")
 	handler.handleUserRoute("/user")
 	handler.handleProjectRoute("/project")
 	handler.handleCompanyRoute("/company")
@@ -36,5 +37,8 @@ func NewCustomerHandler(route *gin.RouterGroup, middleware middleware.Middleware
 	handler.handleServerPackageRoute("/package/server")
 	handler.handleConfigRoute("/config")
 	handler.handleNotificationRoute("/notification")
+	handler.handleServerResourceRoute("/my-server")
+	handler.handleIntegrationRoute("/integration")
+	handler.handlePublicTicketRoute("/public/ticket")
 
 }
@@ -0,0 +1,19 @@
+package http_member
+
+import (
+	"app/domain/model"
+)
+
+// handleIntegrationRoute server to server ticket access with a company api key,
+// the requests act as the customer linked to the key
+func (h *routeHandler) handleIntegrationRoute(prefixPath string) {
+	// (optional). add prefix api version
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/ticket/list", h.Middleware.AuthAPIKey(model.APIKeyScopeTicketsRead), h.TicketList)
+	api.GET("/ticket/detail/:id", h.Middleware.AuthAPIKey(model.APIKeyScopeTicketsRead), h.TicketDetail)
+	api.POST("/ticket/create", h.Middleware.AuthAPIKey(model.APIKeyScopeTicketsWrite), h.TicketCreate)
+	api.POST("/ticket/comments/add", h.Middleware.AuthAPIKey(model.APIKeyScopeTicketsWrite), h.TicketCommentCreate)
+	api.GET("/ticket/comments/list/:idTicket", h.Middleware.AuthAPIKey(model.APIKeyScopeTicketsRead), h.TicketCommentList)
+	api.GET("/ticket/comments/detail/:idComment", h.Middleware.AuthAPIKey(model.APIKeyScopeTicketsRead), h.TicketCommentDetail)
+}
@@ -16,8 +16,10 @@ func (h *routeHandler) handleOrderRoute(prefixPath string) {
 	api.GET("/detail/:id", h.Middleware.AuthCustomer(), h.OrderDetail)
 	api.POST("/hour/create", h.Middleware.AuthCustomer(), h.OrderHourCreate)
 	api.POST("/server/create", h.Middleware.AuthCustomer(), h.OrderServerCreate)
+	api.POST("/hour/change", h.Middleware.AuthCustomer(), h.OrderChangePackage)
 	api.POST("/confirm", h.Middleware.AuthCustomer(), h.ConfirmOrder)
 	api.POST("/upload-attachment", h.Middleware.AuthCustomer(), h.UploadAttachmentOrder)
+	api.GET("/document/:id/:type", h.Middleware.AuthCustomer(), h.OrderDocument)
 }
 
 func (h *routeHandler) OrderList(c *gin.Context) {
@@ -55,6 +57,22 @@ func (h *routeHandler) OrderHourCreate(c *gin.Context) {
 	c.JSON(response.Status, response)
 }
 
+func (h *routeHandler) OrderChangePackage(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.ChangePackageOrderRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimUser)
+
+	response := h.Usecase.CreateChangePackageOrder(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
+
 func (h *routeHandler) OrderServerCreate(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -98,3 +116,12 @@ func (r *routeHandler) UploadAttachmentOrder(c *gin.Context) {
 	response := r.Usecase.UploadAttachmentOrder(ctx, claim, payload, c.Request)
 	c.AbortWithStatusJSON(response.Status, response)
 }
+
+func (h *routeHandler) OrderDocument(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimUser)
+
+	response := h.Usecase.GetOrderDocument(ctx, claim, c.Param("id"), c.Param("type"))
+	c.JSON(response.Status, response)
+}
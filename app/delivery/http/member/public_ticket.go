@@ -0,0 +1,73 @@
+package http_member
+
+import (
+	"app/domain"
+	"net/http"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/gin-gonic/gin"
+)
+
+// handlePublicTicketRoute ticket submission for visitors without an account
+func (h *routeHandler) handlePublicTicketRoute(prefixPath string) {
+	// (optional). add prefix api version
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/form", h.PublicTicketForm)
+	api.POST("/submit", h.PublicTicketSubmit)
+	api.GET("/track", h.PublicTicketDetail)
+	api.GET("/track/comments", h.PublicTicketCommentList)
+	api.POST("/track/comments/add", h.PublicTicketCommentCreate)
+}
+
+func (r *routeHandler) PublicTicketForm(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.GetPublicTicketForm(ctx, c.Request.URL.Query())
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) PublicTicketSubmit(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.PublicTicketRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+	payload.IPAddress = c.ClientIP()
+
+	response := r.Usecase.SubmitPublicTicket(ctx, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) PublicTicketDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.GetPublicTicketDetail(ctx, c.Query("token"))
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) PublicTicketCommentList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query := c.Request.URL.Query()
+
+	response := r.Usecase.GetPublicTicketCommentList(ctx, query.Get("token"), query)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) PublicTicketCommentCreate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.PublicTicketCommentRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.CreatePublicTicketComment(ctx, payload)
+	c.JSON(response.Status, response)
+}
@@ -0,0 +1,32 @@
+package http_member
+
+import (
+	"app/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *routeHandler) handleServerResourceRoute(prefixPath string) {
+	// (optional). add prefix api version
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/list", h.Middleware.AuthCustomer(), h.MyServerList)
+	api.GET("/detail/:id", h.Middleware.AuthCustomer(), h.MyServerDetail)
+}
+
+func (h *routeHandler) MyServerList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimUser)
+	query := c.Request.URL.Query()
+
+	response := h.Usecase.GetMyServerList(ctx, claim, query)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) MyServerDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := h.Usecase.GetMyServerDetail(ctx, c.MustGet("token_data").(domain.JWTClaimUser), c.Param("id"))
+	c.JSON(response.Status, response)
+}
@@ -13,6 +13,7 @@ func (h *routeHandler) handleSettingRoute(prefixPath string) {
 	api := h.Route.Group(prefixPath)
 
 	api.POST("/change-password", h.Middleware.AuthCustomer(), h.ChangePassword)
+	api.POST("/change-currency", h.Middleware.AuthCustomer(), h.ChangeCurrency)
 }
 
 func (h *routeHandler) ChangePassword(c *gin.Context) {
@@ -30,3 +31,19 @@ func (h *routeHandler) ChangePassword(c *gin.Context) {
 	response := h.Usecase.ChangePassword(ctx, claim, payload)
 	c.JSON(response.Status, response)
 }
+
+func (h *routeHandler) ChangeCurrency(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.ChangeCurrencyRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimUser)
+
+	response := h.Usecase.ChangeCurrency(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
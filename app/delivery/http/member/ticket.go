@@ -2,6 +2,7 @@ package http_member
 
 import (
 	"app/domain"
+	"app/domain/model"
 	"net/http"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
@@ -15,12 +16,12 @@ func (h *routeHandler) handleTicketRoute(prefixPath string) {
 	api.GET("/list", h.Middleware.AuthCustomer(), h.TicketList)
 	api.GET("/detail/:id", h.Middleware.AuthCustomer(), h.TicketDetail)
 	api.POST("/create", h.Middleware.AuthCustomer(), h.TicketCreate)
-	api.POST("/close", h.Middleware.AuthCustomer(), h.TicketClose)
+	api.POST("/close", h.Middleware.AuthCustomer(), h.Middleware.Permission(model.PermissionTicketClose), h.TicketClose)
 	api.POST("/close-by-email", h.TicketCloseByEmail)
 	api.POST("/comments/add", h.Middleware.AuthCustomer(), h.TicketCommentCreate)
 	api.GET("/comments/list/:idTicket", h.Middleware.AuthCustomer(), h.TicketCommentList)
 	api.GET("/comments/detail/:idComment", h.Middleware.AuthCustomer(), h.TicketCommentDetail)
-	api.POST("/reopen", h.Middleware.AuthCustomer(), h.TicketReopen)
+	api.POST("/reopen", h.Middleware.AuthCustomer(), h.Middleware.Permission(model.PermissionTicketClose), h.TicketReopen)
 	api.POST("/cancel", h.Middleware.AuthCustomer(), h.CancelTicket)
 }
 
@@ -2,6 +2,7 @@ package http_member
 
 import (
 	"app/domain"
+	"app/domain/model"
 	"net/http"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
@@ -12,11 +13,16 @@ func (r *routeHandler) handleUserRoute(prefix string) {
 	// (optional). add prefix api version
 	api := r.Route.Group(prefix)
 
-	api.GET("/list", r.Middleware.AuthCustomer(), r.Middleware.Role("admin"), r.UserList)
-	api.GET("/detail/:id", r.Middleware.AuthCustomer(), r.Middleware.Role("admin"), r.UserDetail)
-	api.POST("/create", r.Middleware.AuthCustomer(), r.Middleware.Role("admin"), r.UserCreate)
-	api.PUT("/update/:id", r.Middleware.AuthCustomer(), r.Middleware.Role("admin"), r.UserUpdate)
-	api.DELETE("/delete/:id", r.Middleware.AuthCustomer(), r.Middleware.Role("admin"), r.UserDelete)
+	api.GET("/list", r.Middleware.AuthCustomer(), r.Middleware.Permission(model.PermissionCustomerManage), r.UserList)
+	api.GET("/detail/:id", r.Middleware.AuthCustomer(), r.Middleware.Permission(model.PermissionCustomerManage), r.UserDetail)
+	api.POST("/create", r.Middleware.AuthCustomer(), r.Middleware.Permission(model.PermissionCustomerManage), r.UserCreate)
+	api.PUT("/update/:id", r.Middleware.AuthCustomer(), r.Middleware.Permission(model.PermissionCustomerManage), r.UserUpdate)
+	api.DELETE("/delete/:id", r.Middleware.AuthCustomer(), r.Middleware.Permission(model.PermissionCustomerManage), r.UserDelete)
+	api.PATCH("/wallet-cap/:id", r.Middleware.AuthCustomer(), r.Middleware.Permission(model.PermissionCustomerManage), r.UserWalletCap)
+	api.GET("/invitation/list", r.Middleware.AuthCustomer(), r.Middleware.Permission(model.PermissionCustomerManage), r.InvitationList)
+	api.POST("/invitation/bulk", r.Middleware.AuthCustomer(), r.Middleware.Permission(model.PermissionCustomerManage), r.InvitationBulk)
+	api.POST("/invitation/resend/:id", r.Middleware.AuthCustomer(), r.Middleware.Permission(model.PermissionCustomerManage), r.InvitationResend)
+	api.POST("/invitation/revoke/:id", r.Middleware.AuthCustomer(), r.Middleware.Permission(model.PermissionCustomerManage), r.InvitationRevoke)
 }
 
 func (r *routeHandler) UserList(c *gin.Context) {
@@ -88,3 +94,54 @@ func (r *routeHandler) UserDelete(c *gin.Context) {
 	response := r.Usecase.DeleteUser(ctx, c.MustGet("token_data").(domain.JWTClaimUser), options)
 	c.JSON(response.Status, response)
 }
+
+func (r *routeHandler) UserWalletCap(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.UpdateWalletCapRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	options := map[string]interface{}{
+		"id":      c.Param("id"),
+		"payload": payload,
+	}
+
+	response := r.Usecase.UpdateUserWalletCap(ctx, c.MustGet("token_data").(domain.JWTClaimUser), options)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) InvitationList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	options := map[string]interface{}{
+		"query": c.Request.URL.Query(),
+	}
+
+	response := r.Usecase.GetInvitationList(ctx, c.MustGet("token_data").(domain.JWTClaimUser), options)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) InvitationBulk(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.BulkInviteUser(ctx, c.MustGet("token_data").(domain.JWTClaimUser), c.Request)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) InvitationResend(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.ResendInvitation(ctx, c.MustGet("token_data").(domain.JWTClaimUser), c.Param("id"))
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) InvitationRevoke(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := r.Usecase.RevokeInvitation(ctx, c.MustGet("token_data").(domain.JWTClaimUser), c.Param("id"))
+	c.JSON(response.Status, response)
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthAPIKey server to server access, the request gets the claims of the customer linked to the key
+func (m *appMiddleware) AuthAPIKey(scopes ...model.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-API-Key")
+		if key == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(http.StatusUnauthorized, "Unauthorized: Header X-API-Key is required"))
+			return
+		}
+
+		prefix, ok := helpers.ParseAPIKeyPrefix(key)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(http.StatusUnauthorized, "Unauthorized: API key not valid"))
+			return
+		}
+
+		apiKey, err := m.mongo.FetchOneAPIKey(c, map[string]interface{}{
+			"prefix": prefix,
+		})
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Error(http.StatusInternalServerError, err.Error()))
+			return
+		}
+		if apiKey == nil || !helpers.CompareAPIKey(key, apiKey.KeyHash) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(http.StatusUnauthorized, "Unauthorized: API key not valid"))
+			return
+		}
+		if apiKey.RevokedAt != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(http.StatusUnauthorized, "Unauthorized: API key has been revoked"))
+			return
+		}
+
+		for _, scope := range scopes {
+			if !apiKey.HasScope(scope) {
+				c.AbortWithStatusJSON(http.StatusForbidden, response.Error(http.StatusForbidden, "Forbidden: API key is missing the "+string(scope)+" scope"))
+				return
+			}
+		}
+
+		// rate limit per key
+		limit := apiKey.RateLimit
+		if limit <= 0 {
+			limit = helpers.GetAPIKeyRateLimit()
+		}
+		allowed, remaining, reset := helpers.NewRateLimiter(m.redisRepo).Allow(c, "apikey:"+apiKey.ID.Hex(), limit, time.Minute)
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(reset.Seconds())+1))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, response.Error(http.StatusTooManyRequests, "rate limit exceeded, try again later"))
+			return
+		}
+
+		//check customer
+		customer, err := m.mongo.FetchOneCustomer(c, map[string]interface{}{
+			"id": apiKey.Customer.ID,
+		})
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Error(http.StatusInternalServerError, err.Error()))
+			return
+		}
+		if customer == nil || customer.Company.ID != apiKey.Company.ID {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(http.StatusUnauthorized, "Unauthorized: API key customer not found"))
+			return
+		}
+
+		//check company
+		company, err := m.mongo.FetchOneCompany(c, map[string]interface{}{
+			"id": apiKey.Company.ID,
+		})
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Error(http.StatusInternalServerError, err.Error()))
+			return
+		}
+		if company == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(http.StatusUnauthorized, "Unauthorized: Company not found"))
+			return
+		}
+
+		// last used, written at most once a minute
+		now := time.Now()
+		if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > time.Minute || apiKey.LastUsedIP != c.ClientIP() {
+			apiKey.LastUsedAt = &now
+			apiKey.LastUsedIP = c.ClientIP()
+			go m.mongo.UpdateAPIKeyUsage(context.Background(), apiKey)
+		}
+
+		// the same claims a customer login gets, the usecases do not know about keys
+		claims := domain.JWTClaimUser{
+			UserID:    customer.ID.Hex(),
+			CompanyID: apiKey.Company.ID,
+			Role:      string(customer.Role),
+			User: model.UserNested{
+				ID:    customer.ID.Hex(),
+				Name:  customer.Name,
+				Email: customer.Email,
+			},
+			Company: model.CompanyNested{
+				ID:       company.ID.Hex(),
+				Name:     company.Name,
+				Image:    company.Logo.URL,
+				Type:     company.Type,
+				Code:     company.Code,
+				LogoUrl:  company.Logo.URL,
+				Settings: company.Settings,
+			},
+		}
+
+		c.Set("token_data", claims)
+		c.Set("api_key", *apiKey)
+		c.Next()
+	}
+}
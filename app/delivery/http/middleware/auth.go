@@ -68,6 +68,23 @@ func (m *appMiddleware) AuthCustomer() gin.HandlerFunc {
 			return
 		}
 
+		// check session is not revoked
+		active, err := m._checkAuthSession(c, model.SessionCustomer, claims.SessionID)
+		if err != nil {
+			c.AbortWithStatusJSON(
+				http.StatusInternalServerError,
+				response.Error(http.StatusInternalServerError, err.Error()),
+			)
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				response.Error(http.StatusUnauthorized, "Unauthorized: Session has ended"),
+			)
+			return
+		}
+
 		//check customer
 		customer, err := m.mongo.FetchOneCustomer(c, map[string]interface{}{
 			"id": claims.UserID,
@@ -91,6 +108,17 @@ func (m *appMiddleware) AuthCustomer() gin.HandlerFunc {
 			Name:  customer.Name,
 			Email: customer.Email,
 		}
+		// the role may have changed since the token was signed
+		claims.Role = string(customer.Role)
+
+		// generated or expired passwords have to be replaced before anything else
+		if customer.MustChangePassword && claims.Impersonator == nil && !_passwordChangeAllowed(c) {
+			c.AbortWithStatusJSON(
+				http.StatusForbidden,
+				response.Error(http.StatusForbidden, "password change required"),
+			)
+			return
+		}
 
 		//check company
 		company, err := m.mongo.FetchOneCompany(c, map[string]interface{}{
@@ -146,6 +174,12 @@ func (m *appMiddleware) AuthCustomer() gin.HandlerFunc {
 		// }
 
 		c.Set("token_data", *claims)
+
+		// everything done while impersonating lands in the audit log
+		if claims.Impersonator != nil {
+			m._impersonate(c, model.SessionCustomer, claims.CompanyID, claims.SessionID, claims.User, *claims.Impersonator)
+			return
+		}
 		c.Next()
 	}
 }
@@ -206,6 +240,23 @@ func (m *appMiddleware) AuthAgent() gin.HandlerFunc {
 			return
 		}
 
+		// check session is not revoked
+		active, err := m._checkAuthSession(c, model.SessionAgent, claims.SessionID)
+		if err != nil {
+			c.AbortWithStatusJSON(
+				http.StatusInternalServerError,
+				response.Error(http.StatusInternalServerError, err.Error()),
+			)
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				response.Error(http.StatusUnauthorized, "Unauthorized: Session has ended"),
+			)
+			return
+		}
+
 		//check agent
 		agent, err := m.mongo.FetchOneAgent(c, map[string]interface{}{
 			"id": claims.UserID,
@@ -229,6 +280,17 @@ func (m *appMiddleware) AuthAgent() gin.HandlerFunc {
 			Name:  agent.Name,
 			Email: agent.Email,
 		}
+		// the role may have changed since the token was signed
+		claims.Role = string(agent.Role)
+
+		// generated or expired passwords have to be replaced before anything else
+		if agent.MustChangePassword && claims.Impersonator == nil && !_passwordChangeAllowed(c) {
+			c.AbortWithStatusJSON(
+				http.StatusForbidden,
+				response.Error(http.StatusForbidden, "password change required"),
+			)
+			return
+		}
 
 		//check company
 		company, err := m.mongo.FetchOneCompany(c, map[string]interface{}{
@@ -259,6 +321,12 @@ func (m *appMiddleware) AuthAgent() gin.HandlerFunc {
 		}
 
 		c.Set("token_data", *claims)
+
+		// everything done while impersonating lands in the audit log
+		if claims.Impersonator != nil {
+			m._impersonate(c, model.SessionAgent, claims.CompanyID, claims.SessionID, claims.User, *claims.Impersonator)
+			return
+		}
 		c.Next()
 	}
 }
@@ -318,6 +386,23 @@ func (m *appMiddleware) AuthSuperadmin() gin.HandlerFunc {
 			return
 		}
 
+		// check session is not revoked
+		active, err := m._checkAuthSession(c, model.SessionSuperadmin, claims.SessionID)
+		if err != nil {
+			c.AbortWithStatusJSON(
+				http.StatusInternalServerError,
+				response.Error(http.StatusInternalServerError, err.Error()),
+			)
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				response.Error(http.StatusUnauthorized, "Unauthorized: Session has ended"),
+			)
+			return
+		}
+
 		//check admin
 		admin, err := m.mongo.FetchOneSuperadmin(c, map[string]interface{}{
 			"id": claims.UserID,
@@ -347,6 +432,50 @@ func (m *appMiddleware) AuthSuperadmin() gin.HandlerFunc {
 	}
 }
 
+// _passwordChangeAllowed routes still open while a password change is pending
+func _passwordChangeAllowed(c *gin.Context) bool {
+	for _, path := range []string{"/setting/change-password", "/auth/me", "/auth/permissions", "/auth/logout", "/auth/logout-all"} {
+		if strings.HasSuffix(c.FullPath(), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// _checkAuthSession tokens without an active session are rejected, the state is cached in redis when enabled
+func (m *appMiddleware) _checkAuthSession(c *gin.Context, userType model.AuthSessionUserType, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+
+	key := m.cache.keyPrefix + helpers.AuthSessionCacheKey(sessionID)
+	if m.cache.enabled {
+		if state, err := m.cache.store.Get(c, key).Result(); err == nil {
+			return state == "active", nil
+		}
+	}
+
+	session, err := m.mongo.FetchOneAuthSession(c, map[string]interface{}{
+		"id":       sessionID,
+		"userType": string(userType),
+	})
+	if err != nil {
+		return false, err
+	}
+	active := session != nil && session.IsActive()
+
+	if m.cache.enabled {
+		state := "revoked"
+		if active {
+			state = "active"
+		}
+		m.cache.store.Set(c, key, state, m.cache.storeTTL)
+	}
+
+	return active, nil
+}
+
+// Role allow the request by role code, prefer Permission so custom roles are honoured
 func (m *appMiddleware) Role(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenData := c.MustGet("token_data")
@@ -373,3 +502,47 @@ func (m *appMiddleware) Role(allowedRoles ...string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// Permission allow the request when the role of the user grants every given permission
+func (m *appMiddleware) Permission(permissions ...model.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var scope model.RoleScope
+		var companyID, code string
+
+		switch claim := c.MustGet("token_data").(type) {
+		case domain.JWTClaimAgent:
+			scope, companyID, code = model.RoleScopeAgent, claim.CompanyID, claim.Role
+		case domain.JWTClaimUser:
+			scope, companyID, code = model.RoleScopeCustomer, claim.CompanyID, claim.Role
+		default:
+			response := response.Error(http.StatusForbidden, "Forbidden: You don't have permission to access this resource")
+			c.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		role := helpers.DefaultRole(scope, code)
+		if role == nil {
+			var err error
+			role, err = m.mongo.FetchOneRole(c, map[string]interface{}{
+				"companyID": companyID,
+				"scope":     string(scope),
+				"code":      code,
+			})
+			if err != nil {
+				c.AbortWithStatusJSON(
+					http.StatusInternalServerError,
+					response.Error(http.StatusInternalServerError, err.Error()),
+				)
+				return
+			}
+		}
+
+		if role == nil || !role.HasPermission(permissions...) {
+			response := response.Error(http.StatusForbidden, "Forbidden: You don't have permission to access this resource")
+			c.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"bytes"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// impersonationWriteGroups route groups an impersonating superadmin may change, writes anywhere else
+// (credentials, api keys, two factor, sessions, roles, billing...) stay with the owner of the account
+var impersonationWriteGroups = []string{"ticket", "timelogs", "attachment", "notification", "ticket-category", "project"}
+
+// impersonationHiddenGroups route groups not even read while impersonating
+var impersonationHiddenGroups = []string{"api-key"}
+
+// _impersonationAllowed reads are allowed outside the hidden groups, writes only in the allowed groups
+func _impersonationAllowed(c *gin.Context) bool {
+	// full path is /<portal>/<group>/...
+	parts := strings.SplitN(strings.TrimPrefix(c.FullPath(), "/"), "/", 3)
+	if len(parts) < 2 || slices.Contains(impersonationHiddenGroups, parts[1]) {
+		return false
+	}
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return slices.Contains(impersonationWriteGroups, parts[1])
+}
+
+// _impersonate run the request for the impersonating superadmin and keep it in the audit log
+func (m *appMiddleware) _impersonate(c *gin.Context, userType model.AuthSessionUserType, companyID, sessionID string, user, impersonator model.UserNested) {
+	if !_impersonationAllowed(c) {
+		c.AbortWithStatusJSON(
+			http.StatusForbidden,
+			response.Error(http.StatusForbidden, "this action is not allowed while impersonating"),
+		)
+		return
+	}
+
+	// read the body and put it back for the handler
+	var body []byte
+	if c.Request.Body != nil && strings.HasPrefix(c.ContentType(), "application/json") {
+		body, _ = io.ReadAll(c.Request.Body)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	c.Next()
+
+	event := helpers.NewSecurityEvent(model.SecurityImpersonationAction, userType, companyID, user, c.ClientIP(), c.Request.UserAgent(), "")
+	event.Impersonator = &impersonator
+	event.Request = &model.SecurityEventRequest{
+		SessionID: sessionID,
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Status:    c.Writer.Status(),
+		Body:      helpers.RedactRequestBody(body),
+	}
+	if err := m.mongo.CreateSecurityEvent(c, event); err != nil {
+		logrus.Error("CreateSecurityEvent:", err)
+	}
+}
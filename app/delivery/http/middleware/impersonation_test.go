@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestImpersonationAllowed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		method string
+		route  string
+		path   string
+		want   bool
+	}{
+		{http.MethodGet, "/agent/ticket/list", "/agent/ticket/list", true},
+		{http.MethodPost, "/agent/ticket/comments/add", "/agent/ticket/comments/add", true},
+		{http.MethodGet, "/customer/order/list", "/customer/order/list", true},
+		{http.MethodPost, "/customer/order/create", "/customer/order/create", false},
+		{http.MethodGet, "/agent/api-key/list", "/agent/api-key/list", false},
+		{http.MethodPost, "/agent/api-key/create", "/agent/api-key/create", false},
+		{http.MethodPost, "/agent/auth/two-factor/disable", "/agent/auth/two-factor/disable", false},
+		{http.MethodPost, "/agent/auth/logout-all", "/agent/auth/logout-all", false},
+		{http.MethodPost, "/agent/setting/change-password", "/agent/setting/change-password", false},
+		{http.MethodPut, "/agent/role/update/:id", "/agent/role/update/1", false},
+		{http.MethodPut, "/agent/user/update/:id", "/agent/user/update/1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			var got bool
+			engine := gin.New()
+			engine.Handle(tt.method, tt.route, func(c *gin.Context) {
+				got = _impersonationAllowed(c)
+			})
+			engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+			if got != tt.want {
+				t.Fatalf("_impersonationAllowed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
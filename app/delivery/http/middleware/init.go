@@ -2,6 +2,8 @@ package middleware
 
 import (
 	mongorepo "app/app/repository/mongo"
+	redisrepo "app/app/repository/redis"
+	"app/domain/model"
 	"app/helpers"
 	"io"
 	"os"
@@ -20,6 +22,7 @@ type appMiddleware struct {
 	xenditWebhookToken  string
 	cache               CacheConfig
 	mongo               mongorepo.MongoDBRepo
+	redisRepo           redisrepo.RedisRepo
 }
 
 type CacheConfig struct {
@@ -28,9 +31,10 @@ type CacheConfig struct {
 	store       *redis.Client
 	storeTTL    time.Duration
 	cachePrefix string
+	keyPrefix   string
 }
 
-func NewMiddleware(redis *redis.Client, mongo mongorepo.MongoDBRepo) Middleware {
+func NewMiddleware(redis *redis.Client, mongo mongorepo.MongoDBRepo, redisRepo redisrepo.RedisRepo) Middleware {
 	ttl, _ := time.ParseDuration(os.Getenv("REDIS_TTL"))
 	// default ttl redis
 	if ttl == 0 {
@@ -42,6 +46,7 @@ func NewMiddleware(redis *redis.Client, mongo mongorepo.MongoDBRepo) Middleware
 
 	return &appMiddleware{
 		mongo:               mongo,
+		redisRepo:           redisRepo,
 		secretKeyCustomer:   helpers.GetJWTSecretKeyCustomer(),
 		secretKeyAgent:      helpers.GetJWTSecretKeyAgent(),
 		secretKeySuperuser:  helpers.GetJWTSecretKeySuperuser(),
@@ -51,6 +56,7 @@ func NewMiddleware(redis *redis.Client, mongo mongorepo.MongoDBRepo) Middleware
 			enabled:     useRedis,
 			store:       redis,
 			cachePrefix: redisKeyPrefix + "gin:",
+			keyPrefix:   redisKeyPrefix,
 			storeTTL:    ttl,
 			headerKeys: []string{
 				"User-Agent",
@@ -67,7 +73,9 @@ type Middleware interface {
 	AuthCustomer() gin.HandlerFunc
 	AuthAgent() gin.HandlerFunc
 	AuthSuperadmin() gin.HandlerFunc
+	AuthAPIKey(scopes ...model.APIKeyScope) gin.HandlerFunc
 	Role(allowedRoles ...string) gin.HandlerFunc
+	Permission(permissions ...model.Permission) gin.HandlerFunc
 	Logger(writer io.Writer) gin.HandlerFunc
 	Recovery() gin.HandlerFunc
 	Cache(expiry ...time.Duration) gin.HandlerFunc
@@ -17,6 +17,8 @@ func (h *routeHandler) handleAgentRoute(prefixPath string) {
 	api.PATCH("/update/:id", h.Middleware.AuthSuperadmin(), h.AgentUpdate)
 	api.DELETE("/delete/:id", h.Middleware.AuthSuperadmin(), h.AgentDelete)
 	api.PATCH("/reset-password/:id", h.Middleware.AuthSuperadmin(), h.AgentResetPassword)
+	api.PATCH("/reset-two-factor/:id", h.Middleware.AuthSuperadmin(), h.AgentResetTwoFactor)
+	api.PATCH("/unlock/:id", h.Middleware.AuthSuperadmin(), h.AgentUnlock)
 }
 
 func (h *routeHandler) AgentList(c *gin.Context) {
@@ -92,3 +94,23 @@ func (h *routeHandler) AgentResetPassword(c *gin.Context) {
 	response := h.Usecase.ResetPasswordAgent(ctx, claim, userId)
 	c.JSON(response.Status, response)
 }
+
+func (h *routeHandler) AgentResetTwoFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+	userId := c.Param("id")
+
+	response := h.Usecase.ResetAgentTwoFactor(ctx, claim, userId)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) AgentUnlock(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+	userId := c.Param("id")
+
+	response := h.Usecase.UnlockAgent(ctx, claim, userId)
+	c.JSON(response.Status, response)
+}
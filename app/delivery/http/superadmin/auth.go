@@ -13,8 +13,17 @@ func (h *routeHandler) handleAuthRoute(prefixPath string) {
 	api := h.Route.Group(prefixPath)
 
 	api.POST("/login", h.Login)
+	api.POST("/refresh-token", h.RefreshToken)
+	api.POST("/login/two-factor/setup", h.SetupTwoFactorLogin)
+	api.POST("/login/two-factor/verify", h.VerifyTwoFactorLogin)
 
 	api.GET("/me", h.Middleware.AuthSuperadmin(), h.GetMe)
+	api.POST("/logout", h.Middleware.AuthSuperadmin(), h.Logout)
+	api.POST("/logout-all", h.Middleware.AuthSuperadmin(), h.LogoutAll)
+	api.POST("/two-factor/setup", h.Middleware.AuthSuperadmin(), h.SetupTwoFactor)
+	api.POST("/two-factor/enable", h.Middleware.AuthSuperadmin(), h.EnableTwoFactor)
+	api.POST("/two-factor/disable", h.Middleware.AuthSuperadmin(), h.DisableTwoFactor)
+	api.POST("/two-factor/recovery-codes", h.Middleware.AuthSuperadmin(), h.RegenerateRecoveryCodes)
 }
 
 func (r *routeHandler) Login(c *gin.Context) {
@@ -26,6 +35,8 @@ func (r *routeHandler) Login(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
 		return
 	}
+	payload.IPAddress = c.ClientIP()
+	payload.UserAgent = c.Request.UserAgent()
 
 	response := r.Usecase.Login(ctx, payload)
 	c.JSON(response.Status, response)
@@ -37,3 +48,35 @@ func (r *routeHandler) GetMe(c *gin.Context) {
 	response := r.Usecase.GetMe(ctx, c.MustGet("token_data").(domain.JWTClaimSuperadmin))
 	c.JSON(response.Status, response)
 }
+
+func (r *routeHandler) RefreshToken(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.RefreshTokenRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.RefreshToken(ctx, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	response := r.Usecase.Logout(ctx, claim)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) LogoutAll(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	response := r.Usecase.LogoutAll(ctx, claim)
+	c.JSON(response.Status, response)
+}
@@ -18,6 +18,9 @@ func (h *routeHandler) handleCompanyRoute(prefixPath string) {
 	api.POST("/create", h.Middleware.AuthSuperadmin(), h.CompanyCreate)
 	api.PUT("/update/:id", h.Middleware.AuthSuperadmin(), h.CompanyUpdate)
 	api.DELETE("/delete/:id", h.Middleware.AuthSuperadmin(), h.CompanyDelete)
+	api.PATCH("/wallet/:id", h.Middleware.AuthSuperadmin(), h.CompanyWalletUpdate)
+	api.PATCH("/two-factor/:id", h.Middleware.AuthSuperadmin(), h.CompanyTwoFactorUpdate)
+	api.PUT("/sso/:id", h.Middleware.AuthSuperadmin(), h.CompanySSOUpdate)
 }
 
 func (r *routeHandler) CompanyList(c *gin.Context) {
@@ -101,3 +104,60 @@ func (r *routeHandler) UploadCompanyLogo(c *gin.Context) {
 	response := r.Usecase.UploadCompanyLogo(ctx, claim, payload, c.Request)
 	c.AbortWithStatusJSON(response.Status, response)
 }
+
+func (r *routeHandler) CompanyWalletUpdate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.UpdateCompanyWalletRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	options := map[string]interface{}{
+		"id":      c.Param("id"),
+		"payload": payload,
+	}
+
+	response := r.Usecase.UpdateCompanyWallet(ctx, c.MustGet("token_data").(domain.JWTClaimSuperadmin), options)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) CompanyTwoFactorUpdate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.CompanyTwoFactorRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	options := map[string]interface{}{
+		"id":      c.Param("id"),
+		"payload": payload,
+	}
+
+	response := r.Usecase.UpdateCompanyTwoFactor(ctx, c.MustGet("token_data").(domain.JWTClaimSuperadmin), options)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) CompanySSOUpdate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.UpdateCompanySSORequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	options := map[string]interface{}{
+		"id":      c.Param("id"),
+		"payload": payload,
+	}
+
+	response := r.Usecase.UpdateCompanySSO(ctx, c.MustGet("token_data").(domain.JWTClaimSuperadmin), options)
+	c.JSON(response.Status, response)
+}
@@ -0,0 +1,32 @@
+package http_superadmin
+
+import (
+	"app/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *routeHandler) handleCreditNoteRoute(prefixPath string) {
+	// (optional). add prefix api version
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/list", h.Middleware.AuthSuperadmin(), h.CreditNoteList)
+	api.GET("/detail/:id", h.Middleware.AuthSuperadmin(), h.CreditNoteDetail)
+}
+
+func (h *routeHandler) CreditNoteList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+	query := c.Request.URL.Query()
+
+	response := h.Usecase.GetCreditNoteList(ctx, claim, query)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) CreditNoteDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := h.Usecase.GetCreditNoteDetail(ctx, c.Param("id"))
+	c.JSON(response.Status, response)
+}
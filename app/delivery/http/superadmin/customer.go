@@ -16,7 +16,9 @@ func (h *routeHandler) handleCustomerRoute(prefixPath string) {
 	api.PATCH("/update/:id", h.Middleware.AuthSuperadmin(), h.CustomerUpdate)
 	api.DELETE("/delete/:id", h.Middleware.AuthSuperadmin(), h.CustomerDelete)
 	api.PATCH("/reset-password/:id", h.Middleware.AuthSuperadmin(), h.CustomerResetPassword)
+	api.PATCH("/unlock/:id", h.Middleware.AuthSuperadmin(), h.CustomerUnlock)
 	api.POST("/import", h.Middleware.AuthSuperadmin(), h.CustomerImport)
+	api.GET("/balance-reconciliation/:id", h.Middleware.AuthSuperadmin(), h.CustomerBalanceReconciliation)
 }
 
 func (h *routeHandler) CustomerList(c *gin.Context) {
@@ -102,3 +104,22 @@ func (h *routeHandler) CustomerImport(c *gin.Context) {
 	response := h.Usecase.ImportCustomer(ctx, claim, c.Request)
 	c.JSON(response.Status, response)
 }
+
+func (h *routeHandler) CustomerBalanceReconciliation(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	response := h.Usecase.GetCustomerBalanceReconciliation(ctx, claim, c.Param("id"))
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) CustomerUnlock(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+	userId := c.Param("id")
+
+	response := h.Usecase.UnlockCustomer(ctx, claim, userId)
+	c.JSON(response.Status, response)
+}
@@ -0,0 +1,51 @@
+package http_superadmin
+
+import (
+	"app/domain"
+	"net/http"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *routeHandler) handleExchangeRateRoute(prefixPath string) {
+	// (optional). add prefix api version
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/list", h.Middleware.AuthSuperadmin(), h.ExchangeRateList)
+	api.GET("/latest/:currency", h.Middleware.AuthSuperadmin(), h.LatestExchangeRate)
+	api.POST("/create", h.Middleware.AuthSuperadmin(), h.CreateExchangeRate)
+}
+
+func (h *routeHandler) ExchangeRateList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+	query := c.Request.URL.Query()
+
+	response := h.Usecase.GetExchangeRateList(ctx, claim, query)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) LatestExchangeRate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := h.Usecase.GetLatestExchangeRate(ctx, c.Param("currency"))
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) CreateExchangeRate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.ExchangeRateRequest{}
+	err := c.Bind(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	response := h.Usecase.CreateExchangeRate(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
@@ -16,6 +16,7 @@ func (h *routeHandler) handleHourPackageRoute(prefixPath string) {
 	api.PUT("/update/:id", h.Update)
 	api.PATCH("/update-status/:id", h.UpdateStatus)
 	api.DELETE("/delete/:id", h.Delete)
+	api.POST("/grant-trial", h.GrantTrial)
 }
 
 func (h *routeHandler) List(c *gin.Context) {
@@ -98,3 +99,19 @@ func (r *routeHandler) Delete(c *gin.Context) {
 	response := r.Usecase.DeleteHourPackage(ctx, claim, productId)
 	c.JSON(response.Status, response)
 }
+
+func (r *routeHandler) GrantTrial(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.GrantTrialRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	response := r.Usecase.GrantTrial(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
@@ -0,0 +1,72 @@
+package http_superadmin
+
+import (
+	"app/domain"
+	"net/http"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *routeHandler) handleImpersonationRoute(prefixPath string) {
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/list", h.Middleware.AuthSuperadmin(), h.ImpersonationList)
+	api.POST("/agent/:id", h.Middleware.AuthSuperadmin(), h.ImpersonateAgent)
+	api.POST("/customer/:id", h.Middleware.AuthSuperadmin(), h.ImpersonateCustomer)
+	api.POST("/end/:id", h.Middleware.AuthSuperadmin(), h.EndImpersonation)
+}
+
+func (h *routeHandler) ImpersonationList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	response := h.Usecase.GetImpersonationList(ctx, claim)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) ImpersonateAgent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.ImpersonateRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+	payload.IPAddress = c.ClientIP()
+	payload.UserAgent = c.Request.UserAgent()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	response := h.Usecase.ImpersonateAgent(ctx, claim, c.Param("id"), payload)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) ImpersonateCustomer(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.ImpersonateRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+	payload.IPAddress = c.ClientIP()
+	payload.UserAgent = c.Request.UserAgent()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	response := h.Usecase.ImpersonateCustomer(ctx, claim, c.Param("id"), payload)
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) EndImpersonation(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	response := h.Usecase.EndImpersonation(ctx, claim, c.Param("id"))
+	c.JSON(response.Status, response)
+}
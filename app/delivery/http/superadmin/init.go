@@ -25,6 +25,8 @@ func NewSuperadminRouteHandler(route *gin.RouterGroup, middleware middleware.Mid
 	handler.handleTicketRoute("/ticket")
 	handler.handleTicketCommentRoute("/ticket-comment")
 	handler.handleOrderRoute("/order")
+	handler.handleCreditNoteRoute("/credit-note")
+	handler.handleExchangeRateRoute("/exchange-rate")
 	handler.handleCustomerRoute("/customer")
 	handler.handleHourPackageRoute("/package/hour")
 	handler.handleAgentRoute("/agent")
@@ -32,4 +34,6 @@ func NewSuperadminRouteHandler(route *gin.RouterGroup, middleware middleware.Mid
 	handler.handleCompanyProductRoute("/company-product")
 	handler.handleConfigRoute("/config")
 	handler.handleServerPackageRoute("/package/server")
+	handler.handleSecurityEventRoute("/security-event")
+	handler.handleImpersonationRoute("/impersonate")
 }
@@ -16,6 +16,8 @@ func (h *routeHandler) handleOrderRoute(prefixPath string) {
 	api.GET("/detail/:id", h.Middleware.AuthSuperadmin(), h.OrderDetail)
 	api.PATCH("/update-manual-payment/:id", h.Middleware.AuthSuperadmin(), h.UpdateManualPayment)
 	api.POST("/upload-attachment", h.Middleware.AuthSuperadmin(), h.UploadAttachmentOrder)
+	api.GET("/document/:id/:type", h.Middleware.AuthSuperadmin(), h.OrderDocument)
+	api.POST("/refund/:id", h.Middleware.AuthSuperadmin(), h.RefundOrder)
 }
 
 func (h *routeHandler) OrderList(c *gin.Context) {
@@ -65,3 +67,26 @@ func (r *routeHandler) UploadAttachmentOrder(c *gin.Context) {
 	response := r.Usecase.UploadAttachmentOrder(ctx, claim, payload, c.Request)
 	c.AbortWithStatusJSON(response.Status, response)
 }
+
+func (h *routeHandler) OrderDocument(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	response := h.Usecase.GetOrderDocument(ctx, c.Param("id"), c.Param("type"))
+	c.JSON(response.Status, response)
+}
+
+func (h *routeHandler) RefundOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+	payload := domain.RefundOrderRequest{}
+
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := h.Usecase.RefundOrder(ctx, claim, c.Param("id"), payload)
+	c.JSON(response.Status, response)
+}
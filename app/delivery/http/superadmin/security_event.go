@@ -0,0 +1,26 @@
+package http_superadmin
+
+import (
+	"app/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *routeHandler) handleSecurityEventRoute(prefixPath string) {
+	// (optional). add prefix api version
+	api := h.Route.Group(prefixPath)
+
+	api.GET("/list", h.Middleware.AuthSuperadmin(), h.SecurityEventList)
+}
+
+func (h *routeHandler) SecurityEventList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+	options := map[string]interface{}{
+		"query": c.Request.URL.Query(),
+	}
+
+	response := h.Usecase.GetSecurityEventList(ctx, claim, options)
+	c.JSON(response.Status, response)
+}
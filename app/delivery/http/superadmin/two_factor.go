@@ -0,0 +1,96 @@
+package http_superadmin
+
+import (
+	"app/domain"
+	"net/http"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (r *routeHandler) SetupTwoFactorLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.TwoFactorChallengeRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.SetupTwoFactorLogin(ctx, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) VerifyTwoFactorLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload := domain.TwoFactorLoginRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+	payload.IPAddress = c.ClientIP()
+	payload.UserAgent = c.Request.UserAgent()
+
+	response := r.Usecase.VerifyTwoFactorLogin(ctx, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) SetupTwoFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	response := r.Usecase.SetupTwoFactor(ctx, claim)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) EnableTwoFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	payload := domain.TwoFactorCodeRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.EnableTwoFactor(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) DisableTwoFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	payload := domain.DisableTwoFactorRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.DisableTwoFactor(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
+
+func (r *routeHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claim := c.MustGet("token_data").(domain.JWTClaimSuperadmin)
+
+	payload := domain.TwoFactorCodeRequest{}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(http.StatusBadRequest, "invalid json data"))
+		return
+	}
+
+	response := r.Usecase.RegenerateRecoveryCodes(ctx, claim, payload)
+	c.JSON(response.Status, response)
+}
@@ -46,6 +46,34 @@ func generateQueryFilterAgent(options map[string]interface{}, withOptions bool)
 		query["passwordResetToken"] = passwordResetToken
 	}
 
+	if role, ok := options["role"].(string); ok {
+		query["role"] = role
+	}
+
+	if lockoutToken, ok := options["lockoutToken"].(string); ok {
+		query["lockout.tokenHash"] = lockoutToken
+	}
+
+	if ssoIssuer, ok := options["ssoIssuer"].(string); ok {
+		query["sso.issuer"] = ssoIssuer
+	}
+
+	if ssoSubject, ok := options["ssoSubject"].(string); ok {
+		query["sso.subject"] = ssoSubject
+	}
+
+	// agents created before multi category only have the primary category
+	if categoryIDs, ok := options["categoryIDs"].([]string); ok {
+		query["$or"] = []bson.M{
+			{"categories.id": bson.M{"$in": categoryIDs}},
+			{"category.id": bson.M{"$in": categoryIDs}},
+		}
+	}
+
+	if language, ok := options["language"].(string); ok {
+		query["languages"] = language
+	}
+
 	return query, mongoOptions
 }
 
@@ -0,0 +1,124 @@
+package mongorepo
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	moptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func generateQueryFilterAPIKey(options map[string]interface{}, withOptions bool) (query bson.M, mongoOptions *moptions.FindOptions) {
+	// common filter and find options
+	query = helpers.CommonFilter(options)
+	if withOptions {
+		mongoOptions = helpers.CommonMongoFindOptions(options)
+	}
+
+	if companyID, ok := options["companyID"].(string); ok {
+		query["company.id"] = companyID
+	}
+
+	if customerID, ok := options["customerID"].(string); ok {
+		query["customer.id"] = customerID
+	}
+
+	if q, ok := options["q"].(string); ok {
+		regex := bson.M{
+			"$regex": primitive.Regex{
+				Pattern: q,
+				Options: "i",
+			},
+		}
+		query["name"] = regex
+	}
+
+	if prefix, ok := options["prefix"].(string); ok {
+		query["prefix"] = prefix
+	}
+
+	if active, ok := options["active"].(bool); ok {
+		if active {
+			query["revokedAt"] = nil
+		} else {
+			query["revokedAt"] = bson.M{"$ne": nil}
+		}
+	}
+
+	return query, mongoOptions
+}
+
+func (r *mongoDBRepo) FetchAPIKeyList(ctx context.Context, options map[string]interface{}) (cur *mongo.Cursor, err error) {
+	query, findOptions := generateQueryFilterAPIKey(options, true)
+
+	cur, err = r.Conn.Collection(r.APIKeyCollection).Find(ctx, query, findOptions)
+	if err != nil {
+		logrus.Error("FetchAPIKeyList Find:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CountAPIKey(ctx context.Context, options map[string]interface{}) (total int64) {
+	query, _ := generateQueryFilterAPIKey(options, false)
+
+	total, err := r.Conn.Collection(r.APIKeyCollection).CountDocuments(ctx, query)
+	if err != nil {
+		logrus.Error("CountAPIKey CountDocuments:", err)
+		return 0
+	}
+	return
+}
+
+func (r *mongoDBRepo) FetchOneAPIKey(ctx context.Context, options map[string]interface{}) (row *model.APIKey, err error) {
+	query, _ := generateQueryFilterAPIKey(options, false)
+
+	err = r.Conn.Collection(r.APIKeyCollection).FindOne(ctx, query).Decode(&row)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = nil
+			return
+		}
+
+		logrus.Error("FetchOneAPIKey FindOne:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CreateAPIKey(ctx context.Context, row *model.APIKey) (err error) {
+	_, err = r.Conn.Collection(r.APIKeyCollection).InsertOne(ctx, row)
+	if err != nil {
+		logrus.Error("CreateAPIKey InsertOne:", err)
+		return
+	}
+	return
+}
+
+func (r *mongoDBRepo) UpdateOneAPIKey(ctx context.Context, row *model.APIKey) (err error) {
+	_, err = r.Conn.Collection(r.APIKeyCollection).UpdateOne(ctx, bson.M{"_id": row.ID}, bson.M{"$set": row})
+	if err != nil {
+		logrus.Error("UpdateOneAPIKey UpdateOne:", err)
+		return
+	}
+	return
+}
+
+// UpdateAPIKeyUsage only touch the usage fields, it runs on every request of the key
+func (r *mongoDBRepo) UpdateAPIKeyUsage(ctx context.Context, row *model.APIKey) (err error) {
+	_, err = r.Conn.Collection(r.APIKeyCollection).UpdateOne(ctx, bson.M{"_id": row.ID}, bson.M{"$set": bson.M{
+		"lastUsedAt": row.LastUsedAt,
+		"lastUsedIP": row.LastUsedIP,
+	}})
+	if err != nil {
+		logrus.Error("UpdateAPIKeyUsage UpdateOne:", err)
+		return
+	}
+	return
+}
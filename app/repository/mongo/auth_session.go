@@ -0,0 +1,110 @@
+package mongorepo
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	moptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func generateQueryFilterAuthSession(options map[string]interface{}, withOptions bool) (query bson.M, mongoOptions *moptions.FindOptions) {
+	// common filter and find options
+	query = helpers.CommonFilter(options)
+	if withOptions {
+		mongoOptions = helpers.CommonMongoFindOptions(options)
+	}
+
+	// filter
+	if userType, ok := options["userType"].(string); ok {
+		query["userType"] = userType
+	}
+	if userID, ok := options["userID"].(string); ok {
+		query["user.id"] = userID
+	}
+	if impersonated, ok := options["impersonated"].(bool); ok && impersonated {
+		query["impersonator"] = bson.M{"$ne": nil}
+	}
+	if impersonatorID, ok := options["impersonatorID"].(string); ok {
+		query["impersonator.id"] = impersonatorID
+	}
+	if active, ok := options["active"].(bool); ok && active {
+		query["revokedAt"] = nil
+		query["expiredAt"] = bson.M{"$gt": time.Now()}
+	}
+
+	return query, mongoOptions
+}
+
+func (r *mongoDBRepo) FetchAuthSessionList(ctx context.Context, options map[string]interface{}) (cur *mongo.Cursor, err error) {
+	query, findOptions := generateQueryFilterAuthSession(options, true)
+
+	cur, err = r.Conn.Collection(r.AuthSessionCollection).Find(ctx, query, findOptions)
+	if err != nil {
+		logrus.Error("FetchAuthSessionList Find:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) FetchOneAuthSession(ctx context.Context, options map[string]interface{}) (row *model.AuthSession, err error) {
+	query, _ := generateQueryFilterAuthSession(options, false)
+
+	err = r.Conn.Collection(r.AuthSessionCollection).FindOne(ctx, query).Decode(&row)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = nil
+			return
+		}
+
+		logrus.Error("FetchOneAuthSession FindOne:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CreateAuthSession(ctx context.Context, row *model.AuthSession) (err error) {
+	_, err = r.Conn.Collection(r.AuthSessionCollection).InsertOne(ctx, row)
+	if err != nil {
+		logrus.Error("CreateAuthSession InsertOne:", err)
+		return
+	}
+	return
+}
+
+func (r *mongoDBRepo) UpdateOneAuthSession(ctx context.Context, row *model.AuthSession) (err error) {
+	_, err = r.Conn.Collection(r.AuthSessionCollection).UpdateOne(ctx, bson.M{"_id": row.ID}, bson.M{"$set": row})
+	if err != nil {
+		logrus.Error("UpdateOneAuthSession UpdateOne:", err)
+		return
+	}
+	return
+}
+
+// RotateAuthSessionToken swap the refresh token only while the presented one is still current,
+// matched is false when it was already rotated or the session ended
+func (r *mongoDBRepo) RotateAuthSessionToken(ctx context.Context, id primitive.ObjectID, presentedHash, refreshTokenHash string, now time.Time) (matched bool, err error) {
+	res, err := r.Conn.Collection(r.AuthSessionCollection).UpdateOne(ctx, bson.M{
+		"_id":              id,
+		"refreshTokenHash": presentedHash,
+		"revokedAt":        nil,
+		"expiredAt":        bson.M{"$gt": now},
+	}, bson.M{"$set": bson.M{
+		"previousTokenHash": presentedHash,
+		"refreshTokenHash":  refreshTokenHash,
+		"refreshedAt":       now,
+		"updatedAt":         now,
+	}})
+	if err != nil {
+		logrus.Error("RotateAuthSessionToken UpdateOne:", err)
+		return
+	}
+	return res.MatchedCount > 0, nil
+}
@@ -136,7 +136,7 @@ func (r *mongoDBRepo) UpdateCompany(ctx context.Context, company *model.Company)
 
 func (r *mongoDBRepo) UpdatePartialCompany(ctx context.Context, options map[string]interface{}, field map[string]interface{}) (err error) {
 	query, _ := generateQueryFilterCompany(options, false)
-	_, err = r.Conn.Collection(r.CompanyCollection).UpdateOne(context.TODO(), query, bson.M{
+	_, err = r.Conn.Collection(r.CompanyCollection).UpdateOne(ctx, query, bson.M{
 		"$set": field,
 	})
 
@@ -149,7 +149,7 @@ func (r *mongoDBRepo) UpdatePartialCompany(ctx context.Context, options map[stri
 
 func (r *mongoDBRepo) IncrementOneCompany(ctx context.Context, id string, payload map[string]int64) (err error) {
 	obj, _ := primitive.ObjectIDFromHex(id)
-	_, err = r.Conn.Collection(r.CompanyCollection).UpdateOne(context.Background(), map[string]any{
+	_, err = r.Conn.Collection(r.CompanyCollection).UpdateOne(ctx, map[string]any{
 		"_id": obj,
 	}, bson.M{
 		"$inc": payload,
@@ -169,3 +169,18 @@ func (r *mongoDBRepo) DeleteCompany(ctx context.Context, company *model.Company)
 	}
 	return
 }
+
+// GrantCompanyTrial set the trial only when the company never had one, granted is false otherwise
+func (r *mongoDBRepo) GrantCompanyTrial(ctx context.Context, id primitive.ObjectID, grant *model.TrialGrant) (granted bool, err error) {
+	res, err := r.Conn.Collection(r.CompanyCollection).UpdateOne(ctx, bson.M{
+		"_id":   id,
+		"trial": nil,
+	}, bson.M{
+		"$set": bson.M{"trial": grant},
+	})
+	if err != nil {
+		logrus.Error("GrantCompanyTrial UpdateOne:", err)
+		return
+	}
+	return res.MatchedCount > 0, nil
+}
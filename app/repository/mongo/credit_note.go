@@ -0,0 +1,105 @@
+package mongorepo
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	moptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func generateQueryFilterCreditNote(options map[string]interface{}, withOptions bool) (query bson.M, mongoOptions *moptions.FindOptions) {
+	// common filter and find options
+	query = helpers.CommonFilter(options)
+	if withOptions {
+		mongoOptions = helpers.CommonMongoFindOptions(options)
+	}
+
+	// filter
+	if orderID, ok := options["orderID"].(string); ok {
+		query["order.id"] = orderID
+	}
+
+	if state, ok := options["state"].(model.RefundState); ok {
+		query["state"] = state
+	}
+
+	customQuery := helpers.CustomCommonFilter(options)
+	for key, value := range customQuery {
+		query[key] = value
+	}
+
+	return query, mongoOptions
+}
+
+func (r *mongoDBRepo) FetchCreditNoteList(ctx context.Context, options map[string]interface{}) (cur *mongo.Cursor, err error) {
+	query, findOptions := generateQueryFilterCreditNote(options, true)
+
+	cur, err = r.Conn.Collection(r.CreditNoteCollection).Find(ctx, query, findOptions)
+	if err != nil {
+		logrus.Error("FetchCreditNoteList Find:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CountCreditNote(ctx context.Context, options map[string]interface{}) (total int64) {
+	query, _ := generateQueryFilterCreditNote(options, false)
+
+	total, err := r.Conn.Collection(r.CreditNoteCollection).CountDocuments(ctx, query)
+	if err != nil {
+		logrus.Error("CountCreditNote", err)
+		return 0
+	}
+	return
+}
+
+func (r *mongoDBRepo) FetchOneCreditNote(ctx context.Context, options map[string]interface{}) (row *model.CreditNote, err error) {
+	query, _ := generateQueryFilterCreditNote(options, false)
+
+	err = r.Conn.Collection(r.CreditNoteCollection).FindOne(ctx, query).Decode(&row)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = nil
+			return
+		}
+
+		logrus.Error("FetchOneCreditNote FindOne:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CreateCreditNote(ctx context.Context, row *model.CreditNote) (err error) {
+	_, err = r.Conn.Collection(r.CreditNoteCollection).InsertOne(ctx, row)
+	if err != nil {
+		logrus.Error("CreateCreditNote InsertOne:", err)
+		return
+	}
+	return
+}
+
+func (r *mongoDBRepo) UpdateOneCreditNote(ctx context.Context, row *model.CreditNote) (err error) {
+	_, err = r.Conn.Collection(r.CreditNoteCollection).UpdateOne(ctx, bson.M{"_id": row.ID}, bson.M{"$set": row})
+	if err != nil {
+		logrus.Error("UpdateOneCreditNote UpdateOne:", err)
+		return
+	}
+	return
+}
+
+// UpdatePartialCreditNote matched is false when no credit note fits the options, used to move the state only once
+func (r *mongoDBRepo) UpdatePartialCreditNote(ctx context.Context, options, field map[string]interface{}) (matched bool, err error) {
+	query, _ := generateQueryFilterCreditNote(options, false)
+	res, err := r.Conn.Collection(r.CreditNoteCollection).UpdateOne(ctx, query, bson.M{"$set": field})
+	if err != nil {
+		logrus.Error("UpdatePartialCreditNote UpdateOne:", err)
+		return
+	}
+	return res.MatchedCount > 0, nil
+}
@@ -2,11 +2,136 @@ package mongorepo
 
 import (
 	"app/domain/model"
+	"app/helpers"
 	"context"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	moptions "go.mongodb.org/mongo-driver/mongo/options"
 )
 
+func generateQueryFilterCustomerBalanceHistory(options map[string]interface{}, withOptions bool) (query bson.M, mongoOptions *moptions.FindOptions) {
+	// common filter and find options
+	query = helpers.CommonFilter(options)
+	if withOptions {
+		mongoOptions = helpers.CommonMongoFindOptions(options)
+	}
+
+	// filter
+	if customerID, ok := options["customerID"].(string); ok {
+		query["customer.id"] = customerID
+	}
+
+	if companyID, ok := options["companyID"].(string); ok {
+		query["companyID"] = companyID
+	}
+
+	// entries recorded before the wallet field existed belong to the customer
+	if wallet, ok := options["wallet"].(model.WalletType); ok {
+		if wallet == model.WalletCompany {
+			query["wallet"] = wallet
+		} else {
+			query["wallet"] = bson.M{"$ne": model.WalletCompany}
+		}
+	}
+
+	if referenceType, ok := options["referenceType"].(model.ReferenceType); ok {
+		query["reference.type"] = referenceType
+	}
+
+	if referenceID, ok := options["referenceID"].(string); ok {
+		query["reference.unique_id"] = referenceID
+	}
+
+	// created at
+	createdAt := bson.M{}
+	if createdFrom, ok := options["createdFrom"].(time.Time); ok {
+		createdAt["$gte"] = createdFrom
+	}
+	if createdBefore, ok := options["createdBefore"].(time.Time); ok {
+		createdAt["$lt"] = createdBefore
+	}
+	if len(createdAt) > 0 {
+		query["createdAt"] = createdAt
+	}
+
+	return query, mongoOptions
+}
+
+func (r *mongoDBRepo) FetchCustomerBalanceHistoryList(ctx context.Context, options map[string]interface{}) (cur *mongo.Cursor, err error) {
+	query, findOptions := generateQueryFilterCustomerBalanceHistory(options, true)
+
+	cur, err = r.Conn.Collection(r.CustomerBalanceHistoryCollection).Find(ctx, query, findOptions)
+	if err != nil {
+		logrus.Error("FetchCustomerBalanceHistoryList Find:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CountCustomerBalanceHistory(ctx context.Context, options map[string]interface{}) (total int64) {
+	query, _ := generateQueryFilterCustomerBalanceHistory(options, false)
+
+	total, err := r.Conn.Collection(r.CustomerBalanceHistoryCollection).CountDocuments(ctx, query)
+	if err != nil {
+		logrus.Error("CountCustomerBalanceHistory", err)
+		return 0
+	}
+	return
+}
+
+func (r *mongoDBRepo) FetchOneCustomerBalanceHistory(ctx context.Context, options map[string]interface{}) (row *model.CustomerBalanceHistory, err error) {
+	query, _ := generateQueryFilterCustomerBalanceHistory(options, false)
+
+	err = r.Conn.Collection(r.CustomerBalanceHistoryCollection).FindOne(ctx, query, helpers.CommonMongoFindOneOptions(options)).Decode(&row)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = nil
+			return
+		}
+
+		logrus.Error("FetchOneCustomerBalanceHistory FindOne:", err)
+		return
+	}
+
+	return
+}
+
+// SumCustomerBalanceHistory return total in and out of the matched ledger entries
+func (r *mongoDBRepo) SumCustomerBalanceHistory(ctx context.Context, options map[string]interface{}) (in int64, out int64, err error) {
+	query, _ := generateQueryFilterCustomerBalanceHistory(options, false)
+
+	cur, err := r.Conn.Collection(r.CustomerBalanceHistoryCollection).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: query}},
+		{{Key: "$group", Value: bson.M{
+			"_id": nil,
+			"in":  bson.M{"$sum": "$in"},
+			"out": bson.M{"$sum": "$out"},
+		}}},
+	})
+	if err != nil {
+		logrus.Error("SumCustomerBalanceHistory Aggregate:", err)
+		return
+	}
+	defer cur.Close(ctx)
+
+	result := struct {
+		In  int64 `bson:"in"`
+		Out int64 `bson:"out"`
+	}{}
+	if cur.Next(ctx) {
+		if err = cur.Decode(&result); err != nil {
+			logrus.Error("SumCustomerBalanceHistory Decode:", err)
+			return
+		}
+	}
+
+	return result.In, result.Out, nil
+}
+
 func (r *mongoDBRepo) CreateCustomerBalanceHistory(ctx context.Context, row *model.CustomerBalanceHistory) (err error) {
 	_, err = r.Conn.Collection(r.CustomerBalanceHistoryCollection).InsertOne(ctx, row)
 	if err != nil {
@@ -0,0 +1,132 @@
+package mongorepo
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	moptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func generateQueryFilterCustomerInvitation(options map[string]interface{}, withOptions bool) (query bson.M, mongoOptions *moptions.FindOptions) {
+	// common filter and find options
+	query = helpers.CommonFilter(options)
+	if withOptions {
+		mongoOptions = helpers.CommonMongoFindOptions(options)
+	}
+
+	if companyID, ok := options["companyID"].(string); ok {
+		query["company.id"] = companyID
+	}
+
+	if email, ok := options["email"].(string); ok {
+		query["email"] = email
+	}
+
+	if tokenHash, ok := options["tokenHash"].(string); ok {
+		query["tokenHash"] = tokenHash
+	}
+
+	// expired is a pending invitation past its expiry
+	if status, ok := options["status"].(string); ok {
+		switch model.InvitationStatus(status) {
+		case model.InvitationExpired:
+			query["status"] = model.InvitationPending
+			query["expiredAt"] = bson.M{"$lte": time.Now()}
+		case model.InvitationPending:
+			query["status"] = model.InvitationPending
+			query["expiredAt"] = bson.M{"$gt": time.Now()}
+		default:
+			query["status"] = status
+		}
+	}
+
+	if q, ok := options["q"].(string); ok {
+		regex := bson.M{
+			"$regex": primitive.Regex{
+				Pattern: q,
+				Options: "i",
+			},
+		}
+		query["$or"] = []bson.M{
+			{"name": regex},
+			{"email": regex},
+		}
+	}
+
+	return query, mongoOptions
+}
+
+func (r *mongoDBRepo) FetchCustomerInvitationList(ctx context.Context, options map[string]interface{}) (cur *mongo.Cursor, err error) {
+	query, findOptions := generateQueryFilterCustomerInvitation(options, true)
+
+	cur, err = r.Conn.Collection(r.CustomerInvitationCollection).Find(ctx, query, findOptions)
+	if err != nil {
+		logrus.Error("FetchCustomerInvitationList Find:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CountCustomerInvitation(ctx context.Context, options map[string]interface{}) (total int64) {
+	query, _ := generateQueryFilterCustomerInvitation(options, false)
+
+	total, err := r.Conn.Collection(r.CustomerInvitationCollection).CountDocuments(ctx, query)
+	if err != nil {
+		logrus.Error("CountCustomerInvitation CountDocuments:", err)
+		return 0
+	}
+	return
+}
+
+func (r *mongoDBRepo) FetchOneCustomerInvitation(ctx context.Context, options map[string]interface{}) (row *model.CustomerInvitation, err error) {
+	query, _ := generateQueryFilterCustomerInvitation(options, false)
+
+	err = r.Conn.Collection(r.CustomerInvitationCollection).FindOne(ctx, query).Decode(&row)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = nil
+			return
+		}
+
+		logrus.Error("FetchOneCustomerInvitation FindOne:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CreateCustomerInvitation(ctx context.Context, row *model.CustomerInvitation) (err error) {
+	_, err = r.Conn.Collection(r.CustomerInvitationCollection).InsertOne(ctx, row)
+	if err != nil {
+		logrus.Error("CreateCustomerInvitation InsertOne:", err)
+		return
+	}
+	return
+}
+
+func (r *mongoDBRepo) UpdateOneCustomerInvitation(ctx context.Context, row *model.CustomerInvitation) (err error) {
+	_, err = r.Conn.Collection(r.CustomerInvitationCollection).UpdateOne(ctx, bson.M{"_id": row.ID}, bson.M{"$set": row})
+	if err != nil {
+		logrus.Error("UpdateOneCustomerInvitation UpdateOne:", err)
+		return
+	}
+	return
+}
+
+// UpdatePartialCustomerInvitation matched is false when no invitation fits the options, used to move the status only once
+func (r *mongoDBRepo) UpdatePartialCustomerInvitation(ctx context.Context, options, field map[string]interface{}) (matched bool, err error) {
+	query, _ := generateQueryFilterCustomerInvitation(options, false)
+	res, err := r.Conn.Collection(r.CustomerInvitationCollection).UpdateOne(ctx, query, bson.M{"$set": field})
+	if err != nil {
+		logrus.Error("UpdatePartialCustomerInvitation UpdateOne:", err)
+		return
+	}
+	return res.MatchedCount > 0, nil
+}
@@ -4,6 +4,7 @@ import (
 	"app/domain/model"
 	"app/helpers"
 	"context"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
@@ -24,6 +25,18 @@ func generateQueryFilterCustomerSubscription(options map[string]interface{}, wit
 		query["customer.id"] = customerID
 	}
 
+	if orderID, ok := options["orderID"].(string); ok {
+		query["order.id"] = orderID
+	}
+
+	// the order created the subscription or extended it
+	if anyOrderID, ok := options["anyOrderID"].(string); ok {
+		query["$or"] = []bson.M{
+			{"order.id": anyOrderID},
+			{"extendedBy.id": anyOrderID},
+		}
+	}
+
 	if serverPackageId, ok := options["serverPackageId"].(string); ok {
 		query["serverPackage.id"] = serverPackageId
 	}
@@ -44,6 +57,30 @@ func generateQueryFilterCustomerSubscription(options map[string]interface{}, wit
 		query["status"] = status
 	}
 
+	if isCompanyWallet, ok := options["isCompanyWallet"].(bool); ok {
+		if isCompanyWallet {
+			query["isCompanyWallet"] = true
+		} else {
+			query["isCompanyWallet"] = bson.M{"$ne": true}
+		}
+	}
+
+	if autoRenew, ok := options["autoRenew"].(bool); ok {
+		query["autoRenew"] = autoRenew
+	}
+
+	if hasRenewal, ok := options["hasRenewal"].(bool); ok {
+		if hasRenewal {
+			query["renewal"] = bson.M{"$ne": nil}
+		} else {
+			query["renewal"] = nil
+		}
+	}
+
+	if expiredBefore, ok := options["expiredBefore"].(time.Time); ok {
+		query["expiredAt"] = bson.M{"$lte": expiredBefore}
+	}
+
 	customQuery := helpers.CustomCommonFilter(options)
 	for key, value := range customQuery {
 		query[key] = value
@@ -114,7 +151,7 @@ func (r *mongoDBRepo) UpdateOneCustomerSubscription(ctx context.Context, custome
 func (r *mongoDBRepo) UpdateManyPartialCustomerSubscription(ctx context.Context, ids []primitive.ObjectID, field map[string]interface{}) (err error) {
 	filter := bson.M{"_id": bson.M{"$in": ids}}
 
-	_, err = r.Conn.Collection(r.CustomerSubscriptionCollection).UpdateMany(context.TODO(), filter, bson.M{
+	_, err = r.Conn.Collection(r.CustomerSubscriptionCollection).UpdateMany(ctx, filter, bson.M{
 		"$set": field,
 	})
 	if err != nil {
@@ -126,7 +163,7 @@ func (r *mongoDBRepo) UpdateManyPartialCustomerSubscription(ctx context.Context,
 
 func (r *mongoDBRepo) UpdatePartialCustomerSubscription(ctx context.Context, options, field map[string]interface{}) (err error) {
 	query, _ := generateQueryFilterCustomerSubscription(options, false)
-	_, err = r.Conn.Collection(r.CustomerSubscriptionCollection).UpdateOne(context.Background(), query, bson.M{
+	_, err = r.Conn.Collection(r.CustomerSubscriptionCollection).UpdateOne(ctx, query, bson.M{
 		"$set": field,
 	})
 	if err != nil {
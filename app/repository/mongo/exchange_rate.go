@@ -0,0 +1,85 @@
+package mongorepo
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	moptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func generateQueryFilterExchangeRate(options map[string]interface{}, withOptions bool) (query bson.M, mongoOptions *moptions.FindOptions) {
+	// common filter and find options
+	query = helpers.CommonFilter(options)
+	if withOptions {
+		mongoOptions = helpers.CommonMongoFindOptions(options)
+	}
+
+	// filter
+	if currency, ok := options["currency"].(string); ok {
+		query["currency"] = currency
+	}
+	if effective, ok := options["effective"].(bool); ok && effective {
+		query["effectiveAt"] = bson.M{"$lte": time.Now()}
+	}
+
+	return query, mongoOptions
+}
+
+func (r *mongoDBRepo) FetchExchangeRateList(ctx context.Context, options map[string]interface{}) (cur *mongo.Cursor, err error) {
+	query, findOptions := generateQueryFilterExchangeRate(options, true)
+
+	cur, err = r.Conn.Collection(r.ExchangeRateCollection).Find(ctx, query, findOptions)
+	if err != nil {
+		logrus.Error("FetchExchangeRateList Find:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CountExchangeRate(ctx context.Context, options map[string]interface{}) (total int64) {
+	query, _ := generateQueryFilterExchangeRate(options, false)
+
+	total, err := r.Conn.Collection(r.ExchangeRateCollection).CountDocuments(ctx, query)
+	if err != nil {
+		logrus.Error("CountExchangeRate", err)
+		return 0
+	}
+	return
+}
+
+// FetchLatestExchangeRate the rate in effect now for the currency
+func (r *mongoDBRepo) FetchLatestExchangeRate(ctx context.Context, currency string) (row *model.ExchangeRate, err error) {
+	query, _ := generateQueryFilterExchangeRate(map[string]interface{}{
+		"currency":  currency,
+		"effective": true,
+	}, false)
+
+	findOptions := moptions.FindOne().SetSort(bson.D{{Key: "effectiveAt", Value: -1}, {Key: "createdAt", Value: -1}})
+	err = r.Conn.Collection(r.ExchangeRateCollection).FindOne(ctx, query, findOptions).Decode(&row)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = nil
+			return
+		}
+
+		logrus.Error("FetchLatestExchangeRate FindOne:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CreateExchangeRate(ctx context.Context, row *model.ExchangeRate) (err error) {
+	_, err = r.Conn.Collection(r.ExchangeRateCollection).InsertOne(ctx, row)
+	if err != nil {
+		logrus.Error("CreateExchangeRate InsertOne:", err)
+		return
+	}
+	return
+}
@@ -31,6 +31,19 @@ func generateQueryFilterHourPackage(options map[string]interface{}, withOptions
 		query["status"] = status
 	}
 
+	// packages created before the type are regular
+	if packageType, ok := options["type"].(string); ok {
+		if packageType == string(model.HourPackageTypeTrial) {
+			query["type"] = packageType
+		} else {
+			query["type"] = bson.M{"$ne": string(model.HourPackageTypeTrial)}
+		}
+	}
+
+	if autoGrant, ok := options["autoGrant"].(bool); ok {
+		query["trial.autoGrant"] = autoGrant
+	}
+
 	if q, ok := options["q"].(string); ok {
 		regex := bson.M{
 			"$regex": primitive.Regex{
@@ -3,6 +3,7 @@ package mongorepo
 import (
 	"app/domain/model"
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -30,6 +31,15 @@ type mongoDBRepo struct {
 	TicketCategoryCollection         string
 	ServerPackageCollection          string
 	NotificationCollection           string
+	CreditNoteCollection             string
+	ServerResourceCollection         string
+	ExchangeRateCollection           string
+	AuthSessionCollection            string
+	RoleCollection                   string
+	SecurityEventCollection          string
+	APIKeyCollection                 string
+	CustomerInvitationCollection     string
+	SequenceCollection               string
 }
 
 func NewMongodbRepo(Conn *mongo.Database) MongoDBRepo {
@@ -55,10 +65,57 @@ func NewMongodbRepo(Conn *mongo.Database) MongoDBRepo {
 		TicketCategoryCollection:         "ticket_categories",
 		ServerPackageCollection:          "server_packages",
 		NotificationCollection:           "notification",
+		CreditNoteCollection:             "credit_notes",
+		ServerResourceCollection:         "server_resources",
+		ExchangeRateCollection:           "exchange_rates",
+		AuthSessionCollection:            "auth_sessions",
+		RoleCollection:                   "roles",
+		SecurityEventCollection:          "security_events",
+		APIKeyCollection:                 "api_keys",
+		CustomerInvitationCollection:     "customer_invitations",
+		SequenceCollection:               "sequences",
 	}
 }
 
 type MongoDBRepo interface {
+	// Transaction
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error)
+
+	// Auth Session
+	FetchAuthSessionList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
+	FetchOneAuthSession(ctx context.Context, options map[string]interface{}) (*model.AuthSession, error)
+	CreateAuthSession(ctx context.Context, row *model.AuthSession) (err error)
+	UpdateOneAuthSession(ctx context.Context, row *model.AuthSession) (err error)
+	RotateAuthSessionToken(ctx context.Context, id primitive.ObjectID, presentedHash, refreshTokenHash string, now time.Time) (matched bool, err error)
+
+	// Role
+	FetchRoleList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
+	CountRole(ctx context.Context, options map[string]interface{}) (total int64)
+	FetchOneRole(ctx context.Context, options map[string]interface{}) (*model.Role, error)
+	CreateRole(ctx context.Context, row *model.Role) (err error)
+	UpdateOneRole(ctx context.Context, row *model.Role) (err error)
+
+	// Security Event
+	FetchSecurityEventList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
+	CountSecurityEvent(ctx context.Context, options map[string]interface{}) (total int64)
+	CreateSecurityEvent(ctx context.Context, row *model.SecurityEvent) (err error)
+
+	// API Key
+	FetchAPIKeyList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
+	CountAPIKey(ctx context.Context, options map[string]interface{}) (total int64)
+	FetchOneAPIKey(ctx context.Context, options map[string]interface{}) (*model.APIKey, error)
+	CreateAPIKey(ctx context.Context, row *model.APIKey) (err error)
+	UpdateOneAPIKey(ctx context.Context, row *model.APIKey) (err error)
+	UpdateAPIKeyUsage(ctx context.Context, row *model.APIKey) (err error)
+
+	// Customer Invitation
+	FetchCustomerInvitationList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
+	CountCustomerInvitation(ctx context.Context, options map[string]interface{}) (total int64)
+	FetchOneCustomerInvitation(ctx context.Context, options map[string]interface{}) (*model.CustomerInvitation, error)
+	CreateCustomerInvitation(ctx context.Context, row *model.CustomerInvitation) (err error)
+	UpdateOneCustomerInvitation(ctx context.Context, row *model.CustomerInvitation) (err error)
+	UpdatePartialCustomerInvitation(ctx context.Context, options, field map[string]interface{}) (matched bool, err error)
+
 	// Customer
 	FetchCustomerList(ctx context.Context, options map[string]interface{}) (cur *mongo.Cursor, err error)
 	CountCustomer(ctx context.Context, options map[string]interface{}) (total int64)
@@ -69,6 +126,9 @@ type MongoDBRepo interface {
 	UpdatePartialCustomer(ctx context.Context, options, field map[string]interface{}) (err error)
 	UpdateManyPartialCustomer(ctx context.Context, ids []primitive.ObjectID, field map[string]interface{}) (err error)
 	IncrementOneCustomer(ctx context.Context, id string, payload map[string]int64) (err error)
+	IncrementCustomerWalletCap(ctx context.Context, id string, duration int64) (walletCap model.WalletCap, err error)
+	GrantCustomerTrial(ctx context.Context, id primitive.ObjectID, grant *model.TrialGrant) (granted bool, err error)
+	ConsumeCustomerMagicLink(ctx context.Context, tokenHash string, now time.Time) (*model.Customer, error)
 
 	// Agent
 	FetchOneAgent(ctx context.Context, options map[string]interface{}) (*model.Agent, error)
@@ -91,6 +151,7 @@ type MongoDBRepo interface {
 	UpdateCompany(ctx context.Context, company *model.Company) (err error)
 	UpdatePartialCompany(ctx context.Context, options map[string]interface{}, field map[string]interface{}) (err error)
 	IncrementOneCompany(ctx context.Context, id string, payload map[string]int64) (err error)
+	GrantCompanyTrial(ctx context.Context, id primitive.ObjectID, grant *model.TrialGrant) (granted bool, err error)
 	DeleteCompany(ctx context.Context, company *model.Company) (err error)
 
 	// // CompanyProduct
@@ -109,7 +170,9 @@ type MongoDBRepo interface {
 	FetchOneTicket(ctx context.Context, options map[string]interface{}) (*model.Ticket, error)
 	UpdateTicket(ctx context.Context, ticket *model.Ticket) (err error)
 	CountTicket(ctx context.Context, options map[string]interface{}) int64
+	CountTicketByAgent(ctx context.Context, options map[string]interface{}, agentIDs []string) (map[string]int64, error)
 	UpdateTicketPartial(ctx context.Context, ids primitive.ObjectID, field map[string]interface{}) error
+	PauseTicketTimer(ctx context.Context, ticket *model.Ticket, pause model.PauseHistory, duration int) (matched bool, err error)
 
 	// Ticket Comment
 	CreateTicketComment(ctx context.Context, ticket *model.TicketComment) (err error)
@@ -145,6 +208,7 @@ type MongoDBRepo interface {
 	// Superadmin
 	FetchSuperadminList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
 	FetchOneSuperadmin(ctx context.Context, options map[string]interface{}) (*model.Superadmin, error)
+	UpdateOneSuperadmin(ctx context.Context, options, payload map[string]interface{}) (err error)
 
 	//hour package
 	FetchHourPackageList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
@@ -161,8 +225,30 @@ type MongoDBRepo interface {
 	FetchOneOrder(ctx context.Context, options map[string]interface{}) (row *model.Order, err error)
 	CreateOrder(ctx context.Context, order *model.Order) (err error)
 	UpdateOneOrder(ctx context.Context, order *model.Order) (err error)
+	UpdatePartialOrder(ctx context.Context, options, field map[string]interface{}) (matched bool, err error)
+
+	// Sequence
+	NextSequence(ctx context.Context, name string, start int64) (value int64, err error)
+
+	// Credit Note
+	FetchCreditNoteList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
+	CountCreditNote(ctx context.Context, options map[string]interface{}) int64
+	FetchOneCreditNote(ctx context.Context, options map[string]interface{}) (*model.CreditNote, error)
+	CreateCreditNote(ctx context.Context, row *model.CreditNote) (err error)
+	UpdateOneCreditNote(ctx context.Context, row *model.CreditNote) (err error)
+	UpdatePartialCreditNote(ctx context.Context, options, field map[string]interface{}) (matched bool, err error)
+
+	// Exchange Rate
+	FetchExchangeRateList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
+	CountExchangeRate(ctx context.Context, options map[string]interface{}) int64
+	FetchLatestExchangeRate(ctx context.Context, currency string) (*model.ExchangeRate, error)
+	CreateExchangeRate(ctx context.Context, row *model.ExchangeRate) (err error)
 
 	// Customer Balance History
+	FetchCustomerBalanceHistoryList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
+	CountCustomerBalanceHistory(ctx context.Context, options map[string]interface{}) int64
+	FetchOneCustomerBalanceHistory(ctx context.Context, options map[string]interface{}) (*model.CustomerBalanceHistory, error)
+	SumCustomerBalanceHistory(ctx context.Context, options map[string]interface{}) (in int64, out int64, err error)
 	CreateCustomerBalanceHistory(ctx context.Context, row *model.CustomerBalanceHistory) (err error)
 
 	//Customer Subscription
@@ -197,6 +283,14 @@ type MongoDBRepo interface {
 	DeleteServerPackage(ctx context.Context, ServerPackages *model.ServerPackage) (err error)
 	UpdatePartialServerPackage(ctx context.Context, options map[string]interface{}, field map[string]interface{}) (err error)
 
+	// Server Resource
+	FetchServerResourceList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
+	CountServerResource(ctx context.Context, options map[string]interface{}) int64
+	FetchOneServerResource(ctx context.Context, options map[string]interface{}) (*model.ServerResource, error)
+	CreateServerResource(ctx context.Context, row *model.ServerResource) (err error)
+	UpdateOneServerResource(ctx context.Context, row *model.ServerResource) (err error)
+	UpdatePartialServerResource(ctx context.Context, options, field map[string]interface{}) (matched bool, err error)
+
 	// Notification
 	CountNotification(ctx context.Context, options map[string]interface{}) int64
 	CreateNotification(ctx context.Context, row *model.Notification) (err error)
@@ -44,6 +44,58 @@ func generateQueryFilterOrder(options map[string]interface{}, withOptions bool)
 		query["type"] = types
 	}
 
+	// expired at
+	expiredAt := bson.M{}
+	if expiredBefore, ok := options["expiredBefore"].(time.Time); ok {
+		expiredAt["$lt"] = expiredBefore
+	}
+	if expiredAfter, ok := options["expiredAfter"].(time.Time); ok {
+		expiredAt["$gte"] = expiredAfter
+	}
+	if len(expiredAt) > 0 {
+		query["expiredAt"] = expiredAt
+	}
+
+	if paymentReminderSent, ok := options["paymentReminderSent"].(bool); ok {
+		if paymentReminderSent {
+			query["reminder.paymentSentAt"] = bson.M{"$ne": nil}
+		} else {
+			query["reminder.paymentSentAt"] = nil
+		}
+	}
+
+	if waitingApprovalSent, ok := options["waitingApprovalSent"].(bool); ok {
+		if waitingApprovalSent {
+			query["reminder.waitingApprovalSentAt"] = bson.M{"$ne": nil}
+		} else {
+			query["reminder.waitingApprovalSentAt"] = nil
+		}
+	}
+
+	if trial, ok := options["trial"].(bool); ok {
+		if trial {
+			query["trial"] = bson.M{"$ne": nil}
+		} else {
+			query["trial"] = nil
+		}
+	}
+
+	if trialConverted, ok := options["trialConverted"].(bool); ok {
+		if trialConverted {
+			query["trial.convertedAt"] = bson.M{"$ne": nil}
+		} else {
+			query["trial.convertedAt"] = nil
+		}
+	}
+
+	if hasDocument, ok := options["hasDocument"].(bool); ok {
+		if hasDocument {
+			query["document.invoiceNumber"] = bson.M{"$nin": []interface{}{nil, ""}}
+		} else {
+			query["document.invoiceNumber"] = bson.M{"$in": []interface{}{nil, ""}}
+		}
+	}
+
 	if q, ok := options["q"].(string); ok {
 		regex := bson.M{
 			"$regex": primitive.Regex{
@@ -136,3 +188,14 @@ func (r *mongoDBRepo) UpdateOneOrder(ctx context.Context, order *model.Order) (e
 	}
 	return
 }
+
+// UpdatePartialOrder matched is false when no order fits the options anymore, ex: the status moved on
+func (r *mongoDBRepo) UpdatePartialOrder(ctx context.Context, options, field map[string]interface{}) (matched bool, err error) {
+	query, _ := generateQueryFilterOrder(options, false)
+	res, err := r.Conn.Collection(r.OrderCollection).UpdateOne(ctx, query, bson.M{"$set": field})
+	if err != nil {
+		logrus.Error("UpdatePartialOrder UpdateOne:", err)
+		return
+	}
+	return res.MatchedCount > 0, nil
+}
@@ -0,0 +1,92 @@
+package mongorepo
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	moptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func generateQueryFilterRole(options map[string]interface{}, withOptions bool) (query bson.M, mongoOptions *moptions.FindOptions) {
+	// common filter and find options
+	query = helpers.CommonFilter(options)
+	if withOptions {
+		mongoOptions = helpers.CommonMongoFindOptions(options)
+	}
+
+	if companyID, ok := options["companyID"].(string); ok {
+		query["company.id"] = companyID
+	}
+
+	if scope, ok := options["scope"].(string); ok {
+		query["scope"] = scope
+	}
+
+	if code, ok := options["code"].(string); ok {
+		query["code"] = code
+	}
+
+	return query, mongoOptions
+}
+
+func (r *mongoDBRepo) FetchRoleList(ctx context.Context, options map[string]interface{}) (cur *mongo.Cursor, err error) {
+	query, findOptions := generateQueryFilterRole(options, true)
+
+	cur, err = r.Conn.Collection(r.RoleCollection).Find(ctx, query, findOptions)
+	if err != nil {
+		logrus.Error("FetchRoleList Find:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CountRole(ctx context.Context, options map[string]interface{}) (total int64) {
+	query, _ := generateQueryFilterRole(options, false)
+
+	total, err := r.Conn.Collection(r.RoleCollection).CountDocuments(ctx, query)
+	if err != nil {
+		logrus.Error("CountRole CountDocuments:", err)
+		return 0
+	}
+	return
+}
+
+func (r *mongoDBRepo) FetchOneRole(ctx context.Context, options map[string]interface{}) (row *model.Role, err error) {
+	query, _ := generateQueryFilterRole(options, false)
+
+	err = r.Conn.Collection(r.RoleCollection).FindOne(ctx, query).Decode(&row)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = nil
+			return
+		}
+
+		logrus.Error("FetchOneRole FindOne:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CreateRole(ctx context.Context, row *model.Role) (err error) {
+	_, err = r.Conn.Collection(r.RoleCollection).InsertOne(ctx, row)
+	if err != nil {
+		logrus.Error("CreateRole InsertOne:", err)
+		return
+	}
+	return
+}
+
+func (r *mongoDBRepo) UpdateOneRole(ctx context.Context, row *model.Role) (err error) {
+	_, err = r.Conn.Collection(r.RoleCollection).UpdateOne(ctx, bson.M{"_id": row.ID}, bson.M{"$set": row})
+	if err != nil {
+		logrus.Error("UpdateOneRole UpdateOne:", err)
+		return
+	}
+	return
+}
@@ -0,0 +1,105 @@
+package mongorepo
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	moptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func generateQueryFilterSecurityEvent(options map[string]interface{}, withOptions bool) (query bson.M, mongoOptions *moptions.FindOptions) {
+	// common filter and find options
+	query = helpers.CommonFilter(options)
+	if withOptions {
+		mongoOptions = helpers.CommonMongoFindOptions(options)
+	}
+
+	if companyID, ok := options["companyID"].(string); ok {
+		query["companyID"] = companyID
+	}
+
+	if userType, ok := options["userType"].(string); ok {
+		query["userType"] = userType
+	}
+
+	if userID, ok := options["userID"].(string); ok {
+		query["user.id"] = userID
+	}
+
+	if eventType, ok := options["type"].(string); ok {
+		query["type"] = eventType
+	}
+
+	if ipAddress, ok := options["ipAddress"].(string); ok {
+		query["ipAddress"] = ipAddress
+	}
+
+	if impersonatorID, ok := options["impersonatorID"].(string); ok {
+		query["impersonator.id"] = impersonatorID
+	}
+
+	if sessionID, ok := options["sessionID"].(string); ok {
+		query["request.sessionID"] = sessionID
+	}
+
+	if q, ok := options["q"].(string); ok {
+		query["user.email"] = bson.M{
+			"$regex": primitive.Regex{
+				Pattern: q,
+				Options: "i",
+			},
+		}
+	}
+
+	// created at
+	createdAt := bson.M{}
+	if createdFrom, ok := options["createdFrom"].(time.Time); ok {
+		createdAt["$gte"] = createdFrom
+	}
+	if createdBefore, ok := options["createdBefore"].(time.Time); ok {
+		createdAt["$lt"] = createdBefore
+	}
+	if len(createdAt) > 0 {
+		query["createdAt"] = createdAt
+	}
+
+	return query, mongoOptions
+}
+
+func (r *mongoDBRepo) FetchSecurityEventList(ctx context.Context, options map[string]interface{}) (cur *mongo.Cursor, err error) {
+	query, findOptions := generateQueryFilterSecurityEvent(options, true)
+
+	cur, err = r.Conn.Collection(r.SecurityEventCollection).Find(ctx, query, findOptions)
+	if err != nil {
+		logrus.Error("FetchSecurityEventList Find:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CountSecurityEvent(ctx context.Context, options map[string]interface{}) (total int64) {
+	query, _ := generateQueryFilterSecurityEvent(options, false)
+
+	total, err := r.Conn.Collection(r.SecurityEventCollection).CountDocuments(ctx, query)
+	if err != nil {
+		logrus.Error("CountSecurityEvent CountDocuments:", err)
+		return 0
+	}
+	return
+}
+
+func (r *mongoDBRepo) CreateSecurityEvent(ctx context.Context, row *model.SecurityEvent) (err error) {
+	_, err = r.Conn.Collection(r.SecurityEventCollection).InsertOne(ctx, row)
+	if err != nil {
+		logrus.Error("CreateSecurityEvent InsertOne:", err)
+		return
+	}
+	return
+}
@@ -0,0 +1,39 @@
+package mongorepo
+
+import (
+	"app/domain/model"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	moptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NextSequence increase the named sequence and return the new value, a missing sequence continues from start
+func (r *mongoDBRepo) NextSequence(ctx context.Context, name string, start int64) (value int64, err error) {
+	collection := r.Conn.Collection(r.SequenceCollection)
+	opts := moptions.FindOneAndUpdate().SetReturnDocument(moptions.After)
+
+	row := model.Sequence{}
+	err = collection.FindOneAndUpdate(ctx, bson.M{"_id": name}, bson.M{
+		"$inc": bson.M{"value": 1},
+	}, opts).Decode(&row)
+	if err == mongo.ErrNoDocuments {
+		// first use, a concurrent writer may have created it already
+		if _, err = collection.InsertOne(ctx, model.Sequence{ID: name, Value: start}); err != nil && !mongo.IsDuplicateKeyError(err) {
+			logrus.Error("NextSequence InsertOne:", err)
+			return
+		}
+
+		err = collection.FindOneAndUpdate(ctx, bson.M{"_id": name}, bson.M{
+			"$inc": bson.M{"value": 1},
+		}, opts).Decode(&row)
+	}
+	if err != nil {
+		logrus.Error("NextSequence FindOneAndUpdate:", err)
+		return
+	}
+
+	return row.Value, nil
+}
@@ -0,0 +1,136 @@
+package mongorepo
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	moptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func generateQueryFilterServerResource(options map[string]interface{}, withOptions bool) (query bson.M, mongoOptions *moptions.FindOptions) {
+	// common filter and find options
+	query = helpers.CommonFilter(options)
+	if withOptions {
+		mongoOptions = helpers.CommonMongoFindOptions(options)
+	}
+
+	// filter
+	if subscriptionID, ok := options["subscriptionID"].(string); ok {
+		query["subscriptionId"] = subscriptionID
+	}
+
+	if status, ok := options["status"].(string); ok {
+		query["status"] = status
+	}
+
+	if taskStatus, ok := options["taskStatus"].(string); ok {
+		query["task.status"] = taskStatus
+	}
+
+	if taskAgentID, ok := options["taskAgentID"].(string); ok {
+		query["task.agent.id"] = taskAgentID
+	}
+
+	if taskAssigned, ok := options["taskAssigned"].(bool); ok {
+		if taskAssigned {
+			query["task.agent"] = bson.M{"$ne": nil}
+		} else {
+			query["task.agent"] = nil
+		}
+	}
+
+	if q, ok := options["q"].(string); ok {
+		regex := bson.M{
+			"$regex": primitive.Regex{
+				Pattern: q,
+				Options: "i",
+			},
+		}
+		query["$or"] = []bson.M{
+			{"customer.name": regex},
+			{"hostname": regex},
+			{"serverPackage.name": regex},
+		}
+	}
+
+	customQuery := helpers.CustomCommonFilter(options)
+	for key, value := range customQuery {
+		query[key] = value
+	}
+
+	return query, mongoOptions
+}
+
+func (r *mongoDBRepo) FetchServerResourceList(ctx context.Context, options map[string]interface{}) (cur *mongo.Cursor, err error) {
+	query, findOptions := generateQueryFilterServerResource(options, true)
+
+	cur, err = r.Conn.Collection(r.ServerResourceCollection).Find(ctx, query, findOptions)
+	if err != nil {
+		logrus.Error("FetchServerResourceList Find:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CountServerResource(ctx context.Context, options map[string]interface{}) (total int64) {
+	query, _ := generateQueryFilterServerResource(options, false)
+
+	total, err := r.Conn.Collection(r.ServerResourceCollection).CountDocuments(ctx, query)
+	if err != nil {
+		logrus.Error("CountServerResource", err)
+		return 0
+	}
+	return
+}
+
+func (r *mongoDBRepo) FetchOneServerResource(ctx context.Context, options map[string]interface{}) (row *model.ServerResource, err error) {
+	query, _ := generateQueryFilterServerResource(options, false)
+
+	err = r.Conn.Collection(r.ServerResourceCollection).FindOne(ctx, query).Decode(&row)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = nil
+			return
+		}
+
+		logrus.Error("FetchOneServerResource FindOne:", err)
+		return
+	}
+
+	return
+}
+
+func (r *mongoDBRepo) CreateServerResource(ctx context.Context, row *model.ServerResource) (err error) {
+	_, err = r.Conn.Collection(r.ServerResourceCollection).InsertOne(ctx, row)
+	if err != nil {
+		logrus.Error("CreateServerResource InsertOne:", err)
+		return
+	}
+	return
+}
+
+func (r *mongoDBRepo) UpdateOneServerResource(ctx context.Context, row *model.ServerResource) (err error) {
+	_, err = r.Conn.Collection(r.ServerResourceCollection).UpdateOne(ctx, bson.M{"_id": row.ID}, bson.M{"$set": row})
+	if err != nil {
+		logrus.Error("UpdateOneServerResource UpdateOne:", err)
+		return
+	}
+	return
+}
+
+// UpdatePartialServerResource matched is false when no server fits the options anymore, ex: the task was taken
+func (r *mongoDBRepo) UpdatePartialServerResource(ctx context.Context, options, field map[string]interface{}) (matched bool, err error) {
+	query, _ := generateQueryFilterServerResource(options, false)
+	res, err := r.Conn.Collection(r.ServerResourceCollection).UpdateOne(ctx, query, bson.M{"$set": field})
+	if err != nil {
+		logrus.Error("UpdatePartialServerResource UpdateOne:", err)
+		return
+	}
+	return res.MatchedCount > 0, nil
+}
@@ -55,3 +55,15 @@ func (r *mongoDBRepo) FetchOneSuperadmin(ctx context.Context, options map[string
 
 	return
 }
+
+func (r *mongoDBRepo) UpdateOneSuperadmin(ctx context.Context, options, payload map[string]interface{}) (err error) {
+	query, _ := generateQueryFilterSuperadmin(options, false)
+	_, err = r.Conn.Collection(r.SuperadminCollection).UpdateOne(ctx, query, bson.M{
+		"$set": payload,
+	})
+	if err != nil {
+		logrus.Error("UpdateOneSuperadmin UpdateOne:", err)
+		return
+	}
+	return
+}
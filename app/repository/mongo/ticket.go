@@ -24,6 +24,14 @@ func generateQueryFilterTicket(options map[string]interface{}, withOptions bool)
 		query[key] = value
 	}
 
+	if logStatus, ok := options["logStatus"].(string); ok {
+		query["logTime.status"] = logStatus
+	}
+
+	if trackToken, ok := options["trackToken"].(string); ok {
+		query["trackToken"] = trackToken
+	}
+
 	return query, mongoOptions
 }
 
@@ -51,6 +59,42 @@ func (r *mongoDBRepo) CountTicket(ctx context.Context, options map[string]interf
 	return
 }
 
+// CountTicketByAgent count the matched tickets of each agent in one aggregation
+func (r *mongoDBRepo) CountTicketByAgent(ctx context.Context, options map[string]interface{}, agentIDs []string) (totals map[string]int64, err error) {
+	query, _ := generateQueryFilterTicket(options, false)
+	query["agents.id"] = bson.M{"$in": agentIDs}
+
+	cur, err := r.Conn.Collection(r.TicketCollection).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: query}},
+		{{Key: "$unwind", Value: "$agents"}},
+		{{Key: "$match", Value: bson.M{"agents.id": bson.M{"$in": agentIDs}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$agents.id",
+			"total": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		logrus.Error("CountTicketByAgent Aggregate:", err)
+		return
+	}
+	defer cur.Close(ctx)
+
+	totals = make(map[string]int64)
+	for cur.Next(ctx) {
+		row := struct {
+			ID    string `bson:"_id"`
+			Total int64  `bson:"total"`
+		}{}
+		if err = cur.Decode(&row); err != nil {
+			logrus.Error("CountTicketByAgent Decode:", err)
+			return nil, err
+		}
+		totals[row.ID] = row.Total
+	}
+
+	return totals, cur.Err()
+}
+
 func (r *mongoDBRepo) FetchOneTicket(ctx context.Context, options map[string]interface{}) (row *model.Ticket, err error) {
 	query, _ := generateQueryFilterTicket(options, false)
 
@@ -96,3 +140,27 @@ func (r *mongoDBRepo) UpdateTicketPartial(ctx context.Context, id primitive.Obje
 	}
 	return nil
 }
+
+// PauseTicketTimer pause the running segment the ticket was read with, matched is false when the timer
+// was paused, resumed or stopped meanwhile
+func (r *mongoDBRepo) PauseTicketTimer(ctx context.Context, ticket *model.Ticket, pause model.PauseHistory, duration int) (matched bool, err error) {
+	res, err := r.Conn.Collection(r.TicketCollection).UpdateOne(ctx, bson.M{
+		"_id":            ticket.ID,
+		"logTime.status": model.Running,
+		"$expr": bson.M{
+			"$eq": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$logTime.pauseHistory", bson.A{}}}}, len(ticket.LogTime.PauseHistory)},
+		},
+	}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"logTime.status":            model.Paused,
+			"logTime.durationInSeconds": bson.M{"$add": bson.A{"$logTime.durationInSeconds", duration}},
+			"logTime.pauseHistory":      bson.M{"$concatArrays": bson.A{bson.M{"$ifNull": bson.A{"$logTime.pauseHistory", bson.A{}}}, bson.A{pause}}},
+			"updatedAt":                 pause.PausedAt,
+		}}},
+	})
+	if err != nil {
+		logrus.Error("PauseTicketTimer UpdateOne:", err)
+		return
+	}
+	return res.MatchedCount > 0, nil
+}
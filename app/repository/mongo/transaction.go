@@ -0,0 +1,27 @@
+package mongorepo
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// WithTransaction run fn inside a mongo transaction, every repo call inside fn must use the given ctx
+func (r *mongoDBRepo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	session, err := r.Conn.Client().StartSession()
+	if err != nil {
+		logrus.Error("WithTransaction StartSession:", err)
+		return
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	if err != nil {
+		logrus.Error("WithTransaction:", err)
+		return
+	}
+	return
+}
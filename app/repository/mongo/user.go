@@ -4,6 +4,7 @@ import (
 	"app/domain/model"
 	"app/helpers"
 	"context"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
@@ -75,6 +76,22 @@ func generateQueryFilterCustomer(options map[string]interface{}, withOptions boo
 		query["passwordResetToken"] = passwordResetToken
 	}
 
+	if role, ok := options["role"].(string); ok {
+		query["role"] = role
+	}
+
+	if lockoutToken, ok := options["lockoutToken"].(string); ok {
+		query["lockout.tokenHash"] = lockoutToken
+	}
+
+	if ssoIssuer, ok := options["ssoIssuer"].(string); ok {
+		query["sso.issuer"] = ssoIssuer
+	}
+
+	if ssoSubject, ok := options["ssoSubject"].(string); ok {
+		query["sso.subject"] = ssoSubject
+	}
+
 	return query, mongoOptions
 }
 
@@ -142,7 +159,7 @@ func (r *mongoDBRepo) UpdateOneCustomer(ctx context.Context, options, payload ma
 
 func (r *mongoDBRepo) UpdatePartialCustomer(ctx context.Context, options, field map[string]interface{}) (err error) {
 	query, _ := generateQueryFilterCustomer(options, false)
-	_, err = r.Conn.Collection(r.CustomerCollection).UpdateOne(context.Background(), query, bson.M{
+	_, err = r.Conn.Collection(r.CustomerCollection).UpdateOne(ctx, query, bson.M{
 		"$set": field,
 	})
 	if err != nil {
@@ -155,7 +172,7 @@ func (r *mongoDBRepo) UpdatePartialCustomer(ctx context.Context, options, field
 func (r *mongoDBRepo) UpdateManyPartialCustomer(ctx context.Context, ids []primitive.ObjectID, field map[string]interface{}) (err error) {
 	filter := bson.M{"_id": bson.M{"$in": ids}}
 
-	_, err = r.Conn.Collection(r.CustomerCollection).UpdateMany(context.TODO(), filter, bson.M{
+	_, err = r.Conn.Collection(r.CustomerCollection).UpdateMany(ctx, filter, bson.M{
 		"$set": field,
 	})
 	if err != nil {
@@ -180,7 +197,7 @@ func (r *mongoDBRepo) CreateManyCustomer(ctx context.Context, rows []*model.Cust
 
 func (r *mongoDBRepo) IncrementOneCustomer(ctx context.Context, id string, payload map[string]int64) (err error) {
 	obj, _ := primitive.ObjectIDFromHex(id)
-	_, err = r.Conn.Collection(r.CustomerCollection).UpdateOne(context.Background(), map[string]any{
+	_, err = r.Conn.Collection(r.CustomerCollection).UpdateOne(ctx, map[string]any{
 		"_id": obj,
 	}, bson.M{
 		"$inc": payload,
@@ -191,3 +208,58 @@ func (r *mongoDBRepo) IncrementOneCustomer(ctx context.Context, id string, paylo
 	}
 	return
 }
+
+// ConsumeCustomerMagicLink clear the sign in link of the token and return the customer as it was before,
+// nil when no link of the token is still valid so a link only signs in once
+func (r *mongoDBRepo) ConsumeCustomerMagicLink(ctx context.Context, tokenHash string, now time.Time) (row *model.Customer, err error) {
+	err = r.Conn.Collection(r.CustomerCollection).FindOneAndUpdate(ctx, bson.M{
+		"magicLink.tokenHash": tokenHash,
+		"magicLink.expiredAt": bson.M{"$gt": now},
+		"deletedAt":           nil,
+	}, bson.M{
+		"$set": bson.M{"magicLink": nil},
+	}).Decode(&row)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = nil
+			return
+		}
+
+		logrus.Error("ConsumeCustomerMagicLink FindOneAndUpdate:", err)
+		return
+	}
+
+	return
+}
+
+// GrantCustomerTrial set the trial only when the customer never had one, granted is false otherwise
+func (r *mongoDBRepo) GrantCustomerTrial(ctx context.Context, id primitive.ObjectID, grant *model.TrialGrant) (granted bool, err error) {
+	res, err := r.Conn.Collection(r.CustomerCollection).UpdateOne(ctx, bson.M{
+		"_id":   id,
+		"trial": nil,
+	}, bson.M{
+		"$set": bson.M{"trial": grant},
+	})
+	if err != nil {
+		logrus.Error("GrantCustomerTrial UpdateOne:", err)
+		return
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// IncrementCustomerWalletCap add the drawn time to the member wallet cap and return the cap after it,
+// the time is always added so a draw past the cap is still charged
+func (r *mongoDBRepo) IncrementCustomerWalletCap(ctx context.Context, id string, duration int64) (walletCap model.WalletCap, err error) {
+	obj, _ := primitive.ObjectIDFromHex(id)
+	row := model.Customer{}
+	err = r.Conn.Collection(r.CustomerCollection).FindOneAndUpdate(ctx, bson.M{
+		"_id": obj,
+	}, bson.M{
+		"$inc": bson.M{"walletCap.used": duration},
+	}, moptions.FindOneAndUpdate().SetReturnDocument(moptions.After)).Decode(&row)
+	if err != nil {
+		logrus.Error("IncrementCustomerWalletCap FindOneAndUpdate:", err)
+		return
+	}
+	return row.WalletCap, nil
+}
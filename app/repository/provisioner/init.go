@@ -0,0 +1,24 @@
+package provisionerrepo
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"context"
+	"os"
+)
+
+// NewProvisionerRepo select the provider from the PROVISIONER env, local by default
+func NewProvisionerRepo() ProvisionerRepo {
+	switch os.Getenv("PROVISIONER") {
+	default:
+		return NewLocalProvisioner()
+	}
+}
+
+type ProvisionerRepo interface {
+	Name() string
+	Provision(ctx context.Context, resource model.ServerResource) (domain.ProvisionResult, error)
+	Suspend(ctx context.Context, resource model.ServerResource) error
+	Resume(ctx context.Context, resource model.ServerResource) error
+	Terminate(ctx context.Context, resource model.ServerResource) error
+}
@@ -0,0 +1,40 @@
+package provisionerrepo
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// localProvisioner does nothing on the infrastructure, the agent set the server up by hand and fill the details on the task
+type localProvisioner struct{}
+
+func NewLocalProvisioner() ProvisionerRepo {
+	return &localProvisioner{}
+}
+
+func (p *localProvisioner) Name() string {
+	return "local"
+}
+
+func (p *localProvisioner) Provision(ctx context.Context, resource model.ServerResource) (domain.ProvisionResult, error) {
+	logrus.Info("Local provision server ", resource.ID.Hex())
+	return domain.ProvisionResult{}, nil
+}
+
+func (p *localProvisioner) Suspend(ctx context.Context, resource model.ServerResource) error {
+	logrus.Info("Local suspend server ", resource.ID.Hex())
+	return nil
+}
+
+func (p *localProvisioner) Resume(ctx context.Context, resource model.ServerResource) error {
+	logrus.Info("Local resume server ", resource.ID.Hex())
+	return nil
+}
+
+func (p *localProvisioner) Terminate(ctx context.Context, resource model.ServerResource) error {
+	logrus.Info("Local terminate server ", resource.ID.Hex())
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,7 +18,9 @@ func (r *redisRepo) GetTTL() time.Duration {
 
 func (r *redisRepo) Get(ctx context.Context, key string) (value []byte, err error) {
 	if value, err = r.Conn.Get(ctx, r.Prefix+key).Bytes(); err != nil {
-		logrus.Error("Redis Get:", err)
+		if err != redis.Nil {
+			logrus.Error("Redis Get:", err)
+		}
 		return
 	}
 
@@ -36,3 +39,29 @@ func (r *redisRepo) Set(ctx context.Context, key string, value []byte, expiratio
 
 	return
 }
+
+func (r *redisRepo) Del(ctx context.Context, keys ...string) (err error) {
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, r.Prefix+key)
+	}
+	if err = r.Conn.Del(ctx, prefixed...).Err(); err != nil {
+		logrus.Error("Redis Del:", err)
+		return
+	}
+
+	return
+}
+
+// Incr increase the counter, the expiration is set when the counter is created
+func (r *redisRepo) Incr(ctx context.Context, key string, expiration time.Duration) (value int64, err error) {
+	pipe := r.Conn.TxPipeline()
+	incr := pipe.Incr(ctx, r.Prefix+key)
+	pipe.ExpireNX(ctx, r.Prefix+key, expiration)
+	if _, err = pipe.Exec(ctx); err != nil {
+		logrus.Error("Redis Incr:", err)
+		return
+	}
+
+	return incr.Val(), nil
+}
@@ -39,4 +39,12 @@ type RedisRepo interface {
 	GetTTL() time.Duration
 	Get(ctx context.Context, key string) (value []byte, err error)
 	Set(ctx context.Context, key string, value []byte, expiration *time.Duration) (err error)
+	Del(ctx context.Context, keys ...string) (err error)
+	Incr(ctx context.Context, key string, expiration time.Duration) (value int64, err error)
+	ZAdd(ctx context.Context, key string, member string, score float64, expiration time.Duration) (err error)
+	ZRem(ctx context.Context, key string, members ...string) (err error)
+	ZRangeByScore(ctx context.Context, key string, min, max string) (members []string, err error)
+	ZRemRangeByScore(ctx context.Context, key string, min, max string) (err error)
+	Publish(ctx context.Context, channel string, message []byte) (err error)
+	Subscribe(ctx context.Context, channel string) (messages <-chan []byte, unsubscribe func() error)
 }
@@ -0,0 +1,59 @@
+package redisrepo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+func (r *redisRepo) Publish(ctx context.Context, channel string, message []byte) (err error) {
+	if err = r.Conn.Publish(ctx, r.Prefix+channel, message).Err(); err != nil {
+		logrus.Error("Redis Publish:", err)
+		return
+	}
+
+	return
+}
+
+// Subscribe the messages channel is closed after unsubscribe is called or ctx is done,
+// the pubsub is only closed by the reading goroutine so it is never closed twice
+func (r *redisRepo) Subscribe(ctx context.Context, channel string) (messages <-chan []byte, unsubscribe func() error) {
+	pubsub := r.Conn.Subscribe(ctx, r.Prefix+channel)
+	in := pubsub.Channel()
+
+	done := make(chan struct{})
+	var once sync.Once
+	unsubscribe = func() error {
+		once.Do(func() { close(done) })
+		return nil
+	}
+
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case msg, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	return out, unsubscribe
+}
@@ -0,0 +1,53 @@
+package redisrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// ZAdd add or update the member score, the expiration of the whole set is refreshed
+func (r *redisRepo) ZAdd(ctx context.Context, key string, member string, score float64, expiration time.Duration) (err error) {
+	pipe := r.Conn.TxPipeline()
+	pipe.ZAdd(ctx, r.Prefix+key, redis.Z{Score: score, Member: member})
+	pipe.Expire(ctx, r.Prefix+key, expiration)
+	if _, err = pipe.Exec(ctx); err != nil {
+		logrus.Error("Redis ZAdd:", err)
+		return
+	}
+
+	return
+}
+
+func (r *redisRepo) ZRem(ctx context.Context, key string, members ...string) (err error) {
+	values := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		values = append(values, member)
+	}
+	if err = r.Conn.ZRem(ctx, r.Prefix+key, values...).Err(); err != nil {
+		logrus.Error("Redis ZRem:", err)
+		return
+	}
+
+	return
+}
+
+func (r *redisRepo) ZRangeByScore(ctx context.Context, key string, min, max string) (members []string, err error) {
+	if members, err = r.Conn.ZRangeByScore(ctx, r.Prefix+key, &redis.ZRangeBy{Min: min, Max: max}).Result(); err != nil {
+		logrus.Error("Redis ZRangeByScore:", err)
+		return
+	}
+
+	return
+}
+
+func (r *redisRepo) ZRemRangeByScore(ctx context.Context, key string, min, max string) (err error) {
+	if err = r.Conn.ZRemRangeByScore(ctx, r.Prefix+key, min, max).Err(); err != nil {
+		logrus.Error("Redis ZRemRangeByScore:", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,57 @@
+package xenditrepo
+
+import (
+	"app/domain"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+func (r *xenditRepo) ExpireInvoice(ctx context.Context, invoiceID string) (result response.Base, err error) {
+	expireURL := r.baseURL.ResolveReference(&url.URL{Path: "/invoices/" + url.PathEscape(invoiceID) + "/expire!"}).String()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", expireURL, nil)
+	if err != nil {
+		return
+	}
+
+	req.Header.Add("Authorization", "Basic "+r.secretBasicAuth)
+
+	res, err := r.Client.Do(req)
+	if err != nil {
+		logrus.Error("Expire Invoice", err)
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		logrus.Error("Expire Invoice Response ReadBody", err)
+		result.Status = 400
+		result.Message = err.Error()
+		return
+	}
+
+	if res.StatusCode != 200 {
+		failed := domain.XenditResponseError{}
+		if err = json.Unmarshal(body, &failed); err != nil {
+			logrus.Error("Expire Invoice Response Unmarshal", err)
+			result.Status = 400
+			result.Message = "Expire Invoice Response Unmarshal"
+			return
+		}
+		result.Status = res.StatusCode
+		result.Message = failed.Message
+		return
+	}
+
+	result.Status = res.StatusCode
+	result.Message = "success"
+
+	return
+}
@@ -26,8 +26,15 @@ func (r *xenditRepo) GenereteSnapLink(ctx context.Context, pkg *model.HourPackag
 		items = append(items, map[string]interface{}{
 			"name":     pkg.Name,
 			"quantity": order.Amount,
-			"price":    pkg.Price,
+			"price":    helpers.OrderUnitPrice(&order, pkg.Price),
 		})
+		if order.Overage != nil {
+			items = append(items, map[string]interface{}{
+				"name":     "Overage",
+				"quantity": 1,
+				"price":    order.Overage.Amount,
+			})
+		}
 	} else {
 		if pkgS == nil {
 			result.Status = 400
@@ -37,7 +44,7 @@ func (r *xenditRepo) GenereteSnapLink(ctx context.Context, pkg *model.HourPackag
 		items = append(items, map[string]interface{}{
 			"name":     pkgS.Name,
 			"quantity": order.Amount,
-			"price":    pkgS.Price,
+			"price":    helpers.OrderUnitPrice(&order, pkgS.Price),
 		})
 	}
 
@@ -59,7 +66,7 @@ func (r *xenditRepo) GenereteSnapLink(ctx context.Context, pkg *model.HourPackag
 		PayerEmail:      order.Customer.Email,
 		Description:     r.metadataIssuer,
 		InvoiceDuration: r.xenditInvoiceDuration,
-		Currency:        "IDR",
+		Currency:        invoiceCurrency(order),
 		Locale:          "en",
 		Items:           items,
 		Customer: map[string]interface{}{
@@ -143,3 +150,11 @@ func (r *xenditRepo) GenereteSnapLink(ctx context.Context, pkg *model.HourPackag
 
 	return
 }
+
+// invoiceCurrency currency the invoice is issued in, invoices created before multi currency were issued in IDR
+func invoiceCurrency(order model.Order) string {
+	if order.Currency == "" {
+		return model.CurrencyIDR
+	}
+	return order.Currency
+}
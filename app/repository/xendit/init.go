@@ -17,6 +17,7 @@ type xenditRepo struct {
 	baseURL               *url.URL
 	generateQrisURL       string
 	generateSnapURL       string
+	refundURL             string
 	secret                string
 	secretBasicAuth       string
 	metadataIssuer        string
@@ -38,10 +39,13 @@ func NewXenditRepo() XenditRepo {
 		secretBasicAuth:       base64.StdEncoding.EncodeToString([]byte(secret + ":")),
 		generateQrisURL:       baseURL.ResolveReference(&url.URL{Path: "/qr_codes"}).String(),
 		generateSnapURL:       baseURL.ResolveReference(&url.URL{Path: "/v2/invoices"}).String(),
+		refundURL:             baseURL.ResolveReference(&url.URL{Path: "/refunds"}).String(),
 		xenditInvoiceDuration: int64(xenditInvoiceDuration),
 	}
 }
 
 type XenditRepo interface {
 	GenereteSnapLink(ctx context.Context, pkg *model.HourPackage, pkgS *model.ServerPackage, order model.Order) (response.Base, error)
+	CreateRefund(ctx context.Context, order model.Order, referenceID string, amount float64, reason string) (response.Base, error)
+	ExpireInvoice(ctx context.Context, invoiceID string) (response.Base, error)
 }
@@ -0,0 +1,93 @@
+package xenditrepo
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+func (r *xenditRepo) CreateRefund(ctx context.Context, order model.Order, referenceID string, amount float64, reason string) (result response.Base, err error) {
+	createRefundDataApi := struct {
+		ReferenceId string                 `json:"reference_id"`
+		InvoiceId   string                 `json:"invoice_id"`
+		Currency    string                 `json:"currency"`
+		Amount      float64                `json:"amount"`
+		Reason      string                 `json:"reason"`
+		Metadata    map[string]interface{} `json:"metadata"`
+	}{
+		ReferenceId: referenceID,
+		InvoiceId:   order.Invoice.InvoiceXenditId,
+		Currency:    invoiceCurrency(order),
+		Amount:      amount,
+		Reason:      "OTHERS",
+		Metadata: map[string]interface{}{
+			"issuer":      r.metadataIssuer,
+			"orderNumber": order.OrderNumber,
+			"note":        reason,
+		},
+	}
+
+	jsonByte, err := json.Marshal(createRefundDataApi)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", r.refundURL, strings.NewReader(string(jsonByte)))
+	if err != nil {
+		return
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Basic "+r.secretBasicAuth)
+	// a retry of the same credit note does not refund twice
+	req.Header.Add("Idempotency-key", referenceID)
+
+	res, err := r.Client.Do(req)
+	if err != nil {
+		logrus.Error("Create Refund", err)
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		logrus.Error("Create Refund Response ReadBody", err)
+		result.Status = 400
+		result.Message = err.Error()
+		return
+	}
+
+	if res.StatusCode != 200 {
+		failed := domain.XenditResponseError{}
+		if err = json.Unmarshal(body, &failed); err != nil {
+			logrus.Error("Create Refund Response Unmarshal", err)
+			result.Status = 400
+			result.Message = "Create Refund Response Unmarshal"
+			return
+		}
+		result.Status = res.StatusCode
+		result.Message = failed.Message
+		return
+	}
+
+	success := map[string]interface{}{}
+	if err = json.Unmarshal(body, &success); err != nil {
+		logrus.Error("Create Refund Response Unmarshal", err)
+		result.Status = 400
+		result.Message = err.Error()
+		return
+	}
+
+	result.Status = res.StatusCode
+	result.Message = "success"
+	result.Data = success
+
+	return
+}
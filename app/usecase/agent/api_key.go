@@ -0,0 +1,292 @@
+package usecase_agent
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// _validateAPIKeyRequest shared validation of create and update, returns the parsed scopes
+func (u *agentUsecase) _validateAPIKeyRequest(payload domain.APIKeyRequest, errValidation map[string]string) []model.APIKeyScope {
+	if payload.Name == "" {
+		errValidation["name"] = "name field is required"
+	}
+	if len(payload.Scopes) == 0 {
+		errValidation["scopes"] = "scopes field is required"
+	}
+	if payload.RateLimit < 0 {
+		errValidation["rateLimit"] = "rateLimit field must be 0 or more"
+	}
+
+	scopes := make([]model.APIKeyScope, 0, len(payload.Scopes))
+	for _, scope := range payload.Scopes {
+		if !helpers.IsValidAPIKeyScope(scope) {
+			errValidation["scopes"] = "scope " + scope + " is not available"
+			continue
+		}
+		scopes = append(scopes, model.APIKeyScope(scope))
+	}
+
+	return scopes
+}
+
+func (u *agentUsecase) GetAPIKeyScopeList(ctx context.Context, claim domain.JWTClaimAgent) response.Base {
+	return response.Success(helpers.APIKeyScopes())
+}
+
+func (u *agentUsecase) GetAPIKeyList(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	paramQuery := options["query"].(url.Values)
+	page, limit, offset := yurekahelpers.GetLimitOffset(paramQuery)
+
+	fetchOptions := map[string]interface{}{
+		"limit":     limit,
+		"offset":    offset,
+		"companyID": claim.CompanyID,
+	}
+
+	// filtering
+	if paramQuery.Get("q") != "" {
+		fetchOptions["q"] = paramQuery.Get("q")
+	}
+	if paramQuery.Get("customerID") != "" {
+		fetchOptions["customerID"] = paramQuery.Get("customerID")
+	}
+	if paramQuery.Get("active") != "" {
+		fetchOptions["active"] = paramQuery.Get("active") == "true"
+	}
+
+	// count first
+	totalDocuments := u.mongodbRepo.CountAPIKey(ctx, fetchOptions)
+	if totalDocuments == 0 {
+		return response.Success(response.List{
+			List:  []interface{}{},
+			Page:  page,
+			Limit: limit,
+			Total: totalDocuments,
+		})
+	}
+
+	cur, err := u.mongodbRepo.FetchAPIKeyList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.APIKey{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("APIKey Decode ", err)
+			continue
+		}
+		list = append(list, row)
+	}
+
+	return response.Success(response.List{
+		List:  list,
+		Page:  page,
+		Limit: limit,
+		Total: totalDocuments,
+	})
+}
+
+func (u *agentUsecase) GetAPIKeyDetail(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check api key
+	apiKey, err := u.mongodbRepo.FetchOneAPIKey(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if apiKey == nil {
+		return response.Error(http.StatusBadRequest, "api key not found")
+	}
+
+	return response.Success(apiKey)
+}
+
+// CreateAPIKey the plain key is only returned once, only its hash is stored
+func (u *agentUsecase) CreateAPIKey(ctx context.Context, claim domain.JWTClaimAgent, payload domain.APIKeyRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	scopes := u._validateAPIKeyRequest(payload, errValidation)
+	if payload.CustomerID == "" {
+		errValidation["customerID"] = "customerID field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id":        payload.CustomerID,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	rateLimit := payload.RateLimit
+	if rateLimit == 0 {
+		rateLimit = helpers.GetAPIKeyRateLimit()
+	}
+
+	key, prefix, hash := helpers.NewAPIKey()
+	now := time.Now()
+	apiKey := model.APIKey{
+		ID:      primitive.NewObjectID(),
+		Company: claim.Company,
+		Customer: model.UserNested{
+			ID:    customer.ID.Hex(),
+			Name:  customer.Name,
+			Email: customer.Email,
+		},
+		Name:      payload.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    scopes,
+		RateLimit: rateLimit,
+		CreatedBy: claim.User,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := u.mongodbRepo.CreateAPIKey(ctx, &apiKey); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"apiKey": apiKey,
+		"key":    key,
+	})
+}
+
+// UpdateAPIKey the linked customer is kept, create a new key to act as another customer
+func (u *agentUsecase) UpdateAPIKey(ctx context.Context, claim domain.JWTClaimAgent, id string, payload domain.APIKeyRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	scopes := u._validateAPIKeyRequest(payload, errValidation)
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check api key
+	apiKey, err := u.mongodbRepo.FetchOneAPIKey(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if apiKey == nil {
+		return response.Error(http.StatusBadRequest, "api key not found")
+	}
+	if apiKey.RevokedAt != nil {
+		return response.Error(http.StatusBadRequest, "api key already revoked")
+	}
+
+	apiKey.Name = payload.Name
+	apiKey.Scopes = scopes
+	if payload.RateLimit > 0 {
+		apiKey.RateLimit = payload.RateLimit
+	}
+	apiKey.UpdatedAt = time.Now()
+
+	if err := u.mongodbRepo.UpdateOneAPIKey(ctx, apiKey); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(apiKey)
+}
+
+// RotateAPIKey replaces the secret right away, the old key stops working
+func (u *agentUsecase) RotateAPIKey(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check api key
+	apiKey, err := u.mongodbRepo.FetchOneAPIKey(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if apiKey == nil {
+		return response.Error(http.StatusBadRequest, "api key not found")
+	}
+	if apiKey.RevokedAt != nil {
+		return response.Error(http.StatusBadRequest, "api key already revoked")
+	}
+
+	key, prefix, hash := helpers.NewAPIKey()
+	now := time.Now()
+	apiKey.Prefix = prefix
+	apiKey.KeyHash = hash
+	apiKey.RotatedAt = &now
+	apiKey.UpdatedAt = now
+
+	if err := u.mongodbRepo.UpdateOneAPIKey(ctx, apiKey); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"apiKey": apiKey,
+		"key":    key,
+	})
+}
+
+func (u *agentUsecase) RevokeAPIKey(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check api key
+	apiKey, err := u.mongodbRepo.FetchOneAPIKey(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if apiKey == nil {
+		return response.Error(http.StatusBadRequest, "api key not found")
+	}
+	if apiKey.RevokedAt != nil {
+		return response.Error(http.StatusBadRequest, "api key already revoked")
+	}
+
+	now := time.Now()
+	apiKey.RevokedAt = &now
+	apiKey.UpdatedAt = now
+
+	if err := u.mongodbRepo.UpdateOneAPIKey(ctx, apiKey); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(apiKey)
+}
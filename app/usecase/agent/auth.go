@@ -9,8 +9,6 @@ import (
 	"time"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -33,6 +31,13 @@ func (u *agentUsecase) Login(ctx context.Context, payload domain.LoginRequest) r
 		return response.ErrorValidation(errValidation, "error validation")
 	}
 
+	// slow down repeated failures on the account or from the same address
+	throttle := helpers.NewLoginThrottle(u.redisRepo)
+	if wait := throttle.Wait(ctx, model.SessionAgent, payload.Email, payload.IPAddress); wait > 0 {
+		u._recordSecurityEvent(ctx, model.SecurityLoginThrottled, "", model.UserNested{Email: payload.Email}, payload, "too many attempts")
+		return response.Error(http.StatusTooManyRequests, helpers.LoginWaitMessage(wait))
+	}
+
 	// check the db
 	user, err := u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
 		"email": payload.Email,
@@ -42,9 +47,17 @@ func (u *agentUsecase) Login(ctx context.Context, payload domain.LoginRequest) r
 	}
 
 	if user == nil {
+		throttle.Fail(ctx, model.SessionAgent, payload.Email, payload.IPAddress)
+		u._recordSecurityEvent(ctx, model.SecurityLoginFailed, "", model.UserNested{Email: payload.Email}, payload, "user not found")
 		return response.Error(http.StatusBadRequest, "user not found")
 	}
 
+	if user.Lockout.IsActive() {
+		nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+		u._recordSecurityEvent(ctx, model.SecurityLoginFailed, user.Company.ID, nested, payload, "account locked")
+		return response.Error(http.StatusForbidden, "account is locked, check your email to unlock it or try again later")
+	}
+
 	// check company
 	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
 		"id": user.Company.ID,
@@ -59,22 +72,40 @@ func (u *agentUsecase) Login(ctx context.Context, payload domain.LoginRequest) r
 
 	// check password
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password)); err != nil {
-		return response.Error(http.StatusBadRequest, "Wrong password")
+		return u._loginFailed(ctx, throttle, user, company, payload)
+	}
+
+	throttle.Reset(ctx, model.SessionAgent, payload.Email)
+	if user.Lockout != nil {
+		// the expired lockout is cleared on the first good login
+		u.mongodbRepo.UpdateOneAgent(ctx, map[string]interface{}{"id": user.ID}, map[string]interface{}{"lockout": nil})
+		user.Lockout = nil
+	}
+	u._recordSecurityEvent(ctx, model.SecurityLoginSuccess, user.Company.ID, model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}, payload, "")
+
+	// agent passwords expire, the clock starts on the first login for older accounts
+	policy := helpers.GetPasswordPolicy(u._CacheConfig(ctx))
+	if user.PasswordChangedAt == nil {
+		now := time.Now()
+		user.PasswordChangedAt = &now
+		u.mongodbRepo.UpdateOneAgent(ctx, map[string]interface{}{"id": user.ID}, map[string]interface{}{"passwordChangedAt": now})
+	} else if !user.MustChangePassword && helpers.IsPasswordExpired(policy, user.PasswordChangedAt) {
+		user.MustChangePassword = true
+		u.mongodbRepo.UpdateOneAgent(ctx, map[string]interface{}{"id": user.ID}, map[string]interface{}{"mustChangePassword": true})
+	}
+
+	// second step with the authenticator app
+	if user.TwoFactor.Enabled || helpers.IsTwoFactorRequired(company) {
+		return u._twoFactorChallenge(user)
 	}
 
+	return u._completeLogin(ctx, user, nil)
+}
+
+// _completeLogin issue the tokens, recovery codes are included once right after enrollment
+func (u *agentUsecase) _completeLogin(ctx context.Context, user *model.Agent, recoveryCodes []string) response.Base {
 	// generate token
-	tokenString, err := helpers.GenerateJWTTokenAgent(domain.JWTClaimAgent{
-		UserID:    user.ID.Hex(),
-		CompanyID: user.Company.ID,
-		Role:      string(user.Role),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ID:        uuid.NewString(),
-			Issuer:    "agent",
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(helpers.GetJWTTTL()) * time.Minute)),
-		},
-	})
+	tokenString, refreshToken, err := u._issueToken(ctx, user)
 	if err != nil {
 		return response.Error(http.StatusBadRequest, err.Error())
 	}
@@ -87,10 +118,16 @@ func (u *agentUsecase) Login(ctx context.Context, payload domain.LoginRequest) r
 			"lastActivityAt": time.Now(),
 		})
 
-	return response.Success(map[string]interface{}{
-		"user":  user,
-		"token": tokenString,
-	})
+	result := map[string]interface{}{
+		"user":         user,
+		"token":        tokenString,
+		"refreshToken": refreshToken,
+	}
+	if recoveryCodes != nil {
+		result["recoveryCodes"] = recoveryCodes
+	}
+
+	return response.Success(result)
 }
 
 func (u *agentUsecase) SendEmailPasswordReset(ctx context.Context, payload domain.EmailPasswordResetRequest) response.Base {
@@ -178,9 +215,6 @@ func (u *agentUsecase) PasswordReset(ctx context.Context, payload domain.Passwor
 	if payload.Password == "" {
 		errValidation["password"] = "password field is required"
 	}
-	if len(payload.Password) < 8 {
-		errValidation["password"] = "password must be at least 8 characters"
-	}
 	if len(errValidation) > 0 {
 		return response.ErrorValidation(errValidation, "error validation")
 	}
@@ -197,16 +231,35 @@ func (u *agentUsecase) PasswordReset(ctx context.Context, payload domain.Passwor
 		return response.Error(http.StatusBadRequest, "password reset token not valid")
 	}
 
+	policy := helpers.GetPasswordPolicy(u._CacheConfig(ctx))
+	if msg := helpers.ValidatePassword(policy, payload.Password, agent.Email); msg != "" {
+		return response.ErrorValidation(map[string]string{"password": msg}, "error validation")
+	}
+	if helpers.IsPasswordReused(policy, payload.Password, agent.Password, agent.PasswordHistory) {
+		return response.ErrorValidation(map[string]string{
+			"password": "password was used recently, choose a different one",
+		}, "error validation")
+	}
+
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
 
 	// update user
+	now := time.Now()
 	agent.PasswordResetToken = ""
+	agent.PasswordHistory = helpers.NextPasswordHistory(policy, agent.Password, agent.PasswordHistory)
 	agent.Password = string(hashedPassword)
-	agent.UpdatedAt = time.Now()
+	agent.PasswordChangedAt = &now
+	agent.MustChangePassword = false
+	agent.UpdatedAt = now
 
 	// save
 	u.mongodbRepo.UpdateAgent(ctx, agent)
 
+	// end the sessions signed in with the old password
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionAgent, agent.ID.Hex(), model.RevokePasswordChanged); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
 	return response.Success(agent)
 }
 
@@ -180,7 +180,8 @@ func (u *agentUsecase) CreateCustomer(ctx context.Context, claim domain.JWTClaim
 	// 	return response.Error(http.StatusBadRequest, "product not found")
 	// }
 
-	password := helpers.RandomString(5)
+	// one time password, it has to be changed on the first login
+	password := helpers.GeneratePassword(helpers.GetPasswordPolicy(u._CacheConfig(ctx)))
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 
 	t := time.Now()
@@ -217,15 +218,17 @@ func (u *agentUsecase) CreateCustomer(ctx context.Context, claim domain.JWTClaim
 		// 	Image: product.Logo.URL,
 		// 	Code:  product.Code,
 		// },
-		Company:       claim.Company,
-		IsNeedBalance: isNeedBalance,
-		Subscription:  subscription,
-		JobTitle:      "customer",
-		Role:          "customer",
-		IsVerified:    true,
-		VerifiedAt:    &t,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		Company:            claim.Company,
+		IsNeedBalance:      isNeedBalance,
+		Subscription:       subscription,
+		JobTitle:           "customer",
+		Role:               "customer",
+		IsVerified:         true,
+		VerifiedAt:         &t,
+		PasswordChangedAt:  &t,
+		MustChangePassword: true,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
 	}
 
 	err = u.mongodbRepo.CreateCustomer(ctx, newCustomer)
@@ -362,5 +365,10 @@ func (u *agentUsecase) DeleteCustomer(ctx context.Context, claim domain.JWTClaim
 		"customerTotal": -1,
 	})
 
+	// end the sessions of the deleted user
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionCustomer, customer.ID.Hex(), model.RevokeUserDeleted); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
 	return response.Success("Customer deleted successfully")
 }
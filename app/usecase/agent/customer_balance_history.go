@@ -0,0 +1,172 @@
+package usecase_agent
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+func (u *agentUsecase) GetCustomerBalanceHistoryList(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	paramQuery := options["query"].(url.Values)
+	page, limit, offset := yurekahelpers.GetLimitOffset(paramQuery)
+
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id":        options["id"],
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	fetchOptions := map[string]interface{}{
+		"limit":      limit,
+		"offset":     offset,
+		"customerID": customer.ID.Hex(),
+		"sort":       "createdAt",
+		"dir":        "desc",
+	}
+
+	// filtering
+	if paramQuery.Get("sort") != "" {
+		fetchOptions["sort"] = paramQuery.Get("sort")
+	}
+	if paramQuery.Get("dir") != "" {
+		fetchOptions["dir"] = paramQuery.Get("dir")
+	}
+	if paramQuery.Get("referenceType") != "" {
+		fetchOptions["referenceType"] = model.ReferenceType(paramQuery.Get("referenceType"))
+	}
+	if paramQuery.Get("wallet") != "" {
+		fetchOptions["wallet"] = model.WalletType(paramQuery.Get("wallet"))
+	}
+
+	// count first
+	totalDocuments := u.mongodbRepo.CountCustomerBalanceHistory(ctx, fetchOptions)
+
+	if totalDocuments == 0 {
+		return response.Success(domain.ResponseList{
+			List: response.List{
+				List:  []interface{}{},
+				Page:  page,
+				Limit: limit,
+				Total: totalDocuments,
+			},
+			TotalPage: helpers.GetTotalPage(totalDocuments, limit),
+		})
+	}
+
+	cur, err := u.mongodbRepo.FetchCustomerBalanceHistoryList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.CustomerBalanceHistory{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("CustomerBalanceHistory Decode ", err)
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+
+		list = append(list, row)
+	}
+
+	return response.Success(domain.ResponseList{
+		List: response.List{
+			List:  list,
+			Page:  page,
+			Limit: limit,
+			Total: totalDocuments,
+		},
+		TotalPage: helpers.GetTotalPage(totalDocuments, limit),
+	})
+}
+
+func (u *agentUsecase) GetCustomerBalanceStatement(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	paramQuery := options["query"].(url.Values)
+
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id":        options["id"],
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	// month format YYYY-MM, default current month
+	month := paramQuery.Get("month")
+	if month == "" {
+		month = time.Now().Format("2006-01")
+	}
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return response.ErrorValidation(map[string]string{
+			"month": "invalid month format, use YYYY-MM",
+		}, "error validation")
+	}
+	end := start.AddDate(0, 1, 0)
+
+	// opening balance is everything recorded before the month
+	in, out, err := u.mongodbRepo.SumCustomerBalanceHistory(ctx, map[string]interface{}{
+		"customerID":    customer.ID.Hex(),
+		"createdBefore": start,
+		"wallet":        model.WalletCustomer,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	cur, err := u.mongodbRepo.FetchCustomerBalanceHistoryList(ctx, map[string]interface{}{
+		"customerID":    customer.ID.Hex(),
+		"createdFrom":   start,
+		"createdBefore": end,
+		"wallet":        model.WalletCustomer,
+		"sort":          "createdAt",
+		"dir":           "asc",
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	defer cur.Close(ctx)
+
+	rows := make([]model.CustomerBalanceHistory, 0)
+	if err := cur.All(ctx, &rows); err != nil {
+		logrus.Error("CustomerBalanceHistory Decode ", err)
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	file, err := helpers.GenerateBalanceStatement(in-out, rows)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(domain.BalanceStatementResponse{
+		FileName: "statement-" + customer.Name + "-" + month + ".xlsx",
+		File:     file,
+	})
+}
@@ -3,12 +3,15 @@ package usecase_agent
 import (
 	"app/domain"
 	"app/domain/model"
+	"app/helpers"
 	"context"
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
 )
 
 func (u *agentUsecase) GetTotalTicket(ctx context.Context, claim domain.JWTClaimAgent) response.Base {
@@ -94,6 +97,72 @@ func (u *agentUsecase) GetTotalTicketNow(ctx context.Context, claim domain.JWTCl
 	return response.Success(result)
 }
 
+// GetAgentWorkload active tickets of every agent against the company capacity
+func (u *agentUsecase) GetAgentWorkload(ctx context.Context, claim domain.JWTClaimAgent) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	cur, err := u.mongodbRepo.FetchAgentList(ctx, map[string]interface{}{
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	defer cur.Close(ctx)
+
+	rows := make([]model.Agent, 0)
+	agentIDs := make([]string, 0)
+	for cur.Next(ctx) {
+		row := model.Agent{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("Agent Decode ", err)
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+
+		rows = append(rows, row)
+		agentIDs = append(agentIDs, row.ID.Hex())
+	}
+
+	loads, err := helpers.GetAgentLoads(ctx, u.mongodbRepo, company, agentIDs)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	var totalActive, totalAtCapacity int64
+	agents := make([]map[string]interface{}, 0)
+	for _, row := range rows {
+		load := loads[row.ID.Hex()]
+		totalActive += load.Active
+		if !load.Available {
+			totalAtCapacity++
+		}
+
+		agents = append(agents, map[string]interface{}{
+			"id":    row.ID.Hex(),
+			"name":  row.Name,
+			"email": row.Email,
+			"load":  load,
+		})
+	}
+
+	return response.Success(map[string]interface{}{
+		"capacity":        helpers.GetAgentCapacity(company),
+		"totalActive":     totalActive,
+		"totalAtCapacity": totalAtCapacity,
+		"agents":          agents,
+	})
+}
+
 func (u *agentUsecase) GetDataDashboard(ctx context.Context, claim domain.JWTClaimAgent, query url.Values) response.Base {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
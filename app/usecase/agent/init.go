@@ -2,9 +2,11 @@ package usecase_agent
 
 import (
 	mongorepo "app/app/repository/mongo"
+	provisionerrepo "app/app/repository/provisioner"
 	redisrepo "app/app/repository/redis"
 	s3Repo "app/app/repository/s3"
 	"app/domain"
+	"app/domain/model"
 	"context"
 	"net/http"
 	"net/url"
@@ -15,24 +17,27 @@ import (
 )
 
 type agentUsecase struct {
-	mongodbRepo    mongorepo.MongoDBRepo
-	contextTimeout time.Duration
-	redisRepo      redisrepo.RedisRepo
-	s3Repo         s3Repo.S3Repo
+	mongodbRepo     mongorepo.MongoDBRepo
+	contextTimeout  time.Duration
+	redisRepo       redisrepo.RedisRepo
+	s3Repo          s3Repo.S3Repo
+	provisionerRepo provisionerrepo.ProvisionerRepo
 }
 
 type RepoInjection struct {
 	MongoDBRepo mongorepo.MongoDBRepo
 	Redis       redisrepo.RedisRepo
 	S3Repo      s3Repo.S3Repo
+	Provisioner provisionerrepo.ProvisionerRepo
 }
 
 func NewAppAgentUsecase(r RepoInjection, timeout time.Duration) AgentUsecase {
 	return &agentUsecase{
-		mongodbRepo:    r.MongoDBRepo,
-		contextTimeout: timeout,
-		redisRepo:      r.Redis,
-		s3Repo:         r.S3Repo,
+		mongodbRepo:     r.MongoDBRepo,
+		contextTimeout:  timeout,
+		redisRepo:       r.Redis,
+		s3Repo:          r.S3Repo,
+		provisionerRepo: r.Provisioner,
 	}
 }
 
@@ -42,6 +47,24 @@ type AgentUsecase interface {
 	SendEmailPasswordReset(ctx context.Context, payload domain.EmailPasswordResetRequest) response.Base
 	PasswordReset(ctx context.Context, payload domain.PasswordResetRequest) response.Base
 	GetMe(ctx context.Context, claim domain.JWTClaimAgent) response.Base
+	RefreshToken(ctx context.Context, payload domain.RefreshTokenRequest) response.Base
+	SSOAuthorize(ctx context.Context, payload domain.SSOAuthorizeRequest) response.Base
+	SSOCallback(ctx context.Context, payload domain.SSOCallbackRequest) response.Base
+	GetMyPermissions(ctx context.Context, claim domain.JWTClaimAgent) response.Base
+	Logout(ctx context.Context, claim domain.JWTClaimAgent) response.Base
+	LogoutAll(ctx context.Context, claim domain.JWTClaimAgent) response.Base
+	UnlockAccount(ctx context.Context, payload domain.UnlockAccountRequest) response.Base
+
+	// security event
+	GetSecurityEventList(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base
+
+	// two factor
+	SetupTwoFactorLogin(ctx context.Context, payload domain.TwoFactorChallengeRequest) response.Base
+	VerifyTwoFactorLogin(ctx context.Context, payload domain.TwoFactorLoginRequest) response.Base
+	SetupTwoFactor(ctx context.Context, claim domain.JWTClaimAgent) response.Base
+	EnableTwoFactor(ctx context.Context, claim domain.JWTClaimAgent, payload domain.TwoFactorCodeRequest) response.Base
+	DisableTwoFactor(ctx context.Context, claim domain.JWTClaimAgent, payload domain.DisableTwoFactorRequest) response.Base
+	RegenerateRecoveryCodes(ctx context.Context, claim domain.JWTClaimAgent, payload domain.TwoFactorCodeRequest) response.Base
 
 	// Ticket
 	GetTicketList(ctx context.Context, claim domain.JWTClaimAgent, query url.Values) response.Base
@@ -55,6 +78,8 @@ type AgentUsecase interface {
 	ResumeLoggingTicket(ctx context.Context, claim domain.JWTClaimAgent, payload domain.LoggingTicketRequest) response.Base
 	EditTimeTrack(ctx context.Context, claim domain.JWTClaimAgent, ticketId string, payload domain.TimeTrackRequest) response.Base
 	AssignTicketToMe(ctx context.Context, claim domain.JWTClaimAgent, ticketId string) response.Base
+	UpdateTicketPresence(ctx context.Context, claim domain.JWTClaimAgent, ticketID string, payload domain.TicketPresenceRequest) response.Base
+	SubscribeTicketPresence(ctx context.Context, claim domain.JWTClaimAgent, ticketID string) (<-chan *model.TicketPresence, *response.Base)
 
 	// Ticket Comment
 	CreateTicketComment(ctx context.Context, claim domain.JWTClaimAgent, payload domain.TicketCommentRequest) response.Base
@@ -72,6 +97,7 @@ type AgentUsecase interface {
 	GetTotalTicket(ctx context.Context, claim domain.JWTClaimAgent) response.Base
 	GetTotalTicketNow(ctx context.Context, claim domain.JWTClaimAgent) response.Base
 	GetDataDashboard(ctx context.Context, claim domain.JWTClaimAgent, query url.Values) response.Base
+	GetAgentWorkload(ctx context.Context, claim domain.JWTClaimAgent) response.Base
 
 	// Ticket Timelogs
 	GetTicketTimeLogsList(ctx context.Context, claim domain.JWTClaimAgent, query url.Values) response.Base
@@ -97,6 +123,8 @@ type AgentUsecase interface {
 	CreateCustomer(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base
 	UpdateCustomer(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base
 	DeleteCustomer(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base
+	GetCustomerBalanceHistoryList(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base
+	GetCustomerBalanceStatement(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base
 
 	// company
 	GetCompanyDetail(ctx context.Context, claim domain.JWTClaimAgent) response.Base
@@ -106,6 +134,10 @@ type AgentUsecase interface {
 	ChangeDomain(ctx context.Context, claim domain.JWTClaimAgent, payload domain.ChangeDomainRequest) response.Base
 	UpdateProfile(ctx context.Context, claim domain.JWTClaimAgent, payload domain.UpdateProfileRequest) response.Base
 	ChangeColor(ctx context.Context, claim domain.JWTClaimAgent, payload domain.ChangeColorMode) response.Base
+	ChangeOveragePolicy(ctx context.Context, claim domain.JWTClaimAgent, payload domain.ChangeOveragePolicyRequest) response.Base
+	ChangePasswordlessLogin(ctx context.Context, claim domain.JWTClaimAgent, payload domain.ChangePasswordlessLoginRequest) response.Base
+	ChangeAutoAssignTicket(ctx context.Context, claim domain.JWTClaimAgent, payload domain.ChangeAutoAssignTicketRequest) response.Base
+	ChangeAgentCapacity(ctx context.Context, claim domain.JWTClaimAgent, payload domain.ChangeAgentCapacityRequest) response.Base
 	UploadAgentProfilePicture(ctx context.Context, claim domain.JWTClaimAgent, payload domain.UploadAttachment, request *http.Request) response.Base
 
 	// Agent
@@ -114,6 +146,7 @@ type AgentUsecase interface {
 	CreateAgent(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base
 	UpdateAgent(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base
 	DeleteAgent(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base
+	BulkUpdateAgentCategory(ctx context.Context, claim domain.JWTClaimAgent, payload domain.BulkAgentCategoryRequest) response.Base
 
 	// Config
 	GetConfig(ctx context.Context) response.Base
@@ -125,9 +158,32 @@ type AgentUsecase interface {
 	UpdateTicketCategory(ctx context.Context, claim domain.JWTClaimAgent, id string, payload domain.TicketCategoryRequest) response.Base
 	DeleteTicketCategory(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base
 
+	// role
+	GetPermissionList(ctx context.Context, claim domain.JWTClaimAgent) response.Base
+	GetRoleList(ctx context.Context, claim domain.JWTClaimAgent, query url.Values) response.Base
+	GetRoleDetail(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base
+	CreateRole(ctx context.Context, claim domain.JWTClaimAgent, payload domain.RoleRequest) response.Base
+	UpdateRole(ctx context.Context, claim domain.JWTClaimAgent, id string, payload domain.RoleRequest) response.Base
+	DeleteRole(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base
+
+	// api key
+	GetAPIKeyScopeList(ctx context.Context, claim domain.JWTClaimAgent) response.Base
+	GetAPIKeyList(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base
+	GetAPIKeyDetail(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base
+	CreateAPIKey(ctx context.Context, claim domain.JWTClaimAgent, payload domain.APIKeyRequest) response.Base
+	UpdateAPIKey(ctx context.Context, claim domain.JWTClaimAgent, id string, payload domain.APIKeyRequest) response.Base
+	RotateAPIKey(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base
+	RevokeAPIKey(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base
+
 	// Notification
 	GetNotificationList(ctx context.Context, claim domain.JWTClaimAgent, query url.Values) response.Base
 	GetNotificationDetail(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base
 	ReadAllNotification(ctx context.Context, claim domain.JWTClaimAgent) response.Base
 	GetNotificationCount(ctx context.Context, claim domain.JWTClaimAgent) response.Base
+
+	// Server Resource
+	GetServerResourceList(ctx context.Context, claim domain.JWTClaimAgent, query url.Values) response.Base
+	GetServerResourceDetail(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base
+	TakeProvisioningTask(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base
+	CompleteProvisioningTask(ctx context.Context, claim domain.JWTClaimAgent, id string, payload domain.CompleteProvisioningTaskRequest) response.Base
 }
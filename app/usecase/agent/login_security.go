@@ -0,0 +1,142 @@
+package usecase_agent
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+// _recordSecurityEvent keep the login audit, a failing insert never blocks the login
+func (u *agentUsecase) _recordSecurityEvent(ctx context.Context, eventType model.SecurityEventType, companyID string, user model.UserNested, payload domain.LoginRequest, reason string) {
+	event := helpers.NewSecurityEvent(eventType, model.SessionAgent, companyID, user, payload.IPAddress, payload.UserAgent, reason)
+	if err := u.mongodbRepo.CreateSecurityEvent(ctx, event); err != nil {
+		logrus.Error("CreateSecurityEvent:", err)
+	}
+}
+
+// _loginFailed count the failure and lock the account once the limit is reached
+func (u *agentUsecase) _loginFailed(ctx context.Context, throttle *helpers.LoginThrottle, user *model.Agent, company *model.Company, payload domain.LoginRequest) response.Base {
+	nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+	failures := throttle.Fail(ctx, model.SessionAgent, payload.Email, payload.IPAddress)
+	u._recordSecurityEvent(ctx, model.SecurityLoginFailed, user.Company.ID, nested, payload, "wrong password")
+	if failures < helpers.GetLoginMaxAttempts() {
+		return response.Error(http.StatusBadRequest, "Wrong password")
+	}
+
+	return u._lockAccount(ctx, throttle, user, company, payload)
+}
+
+// _lockAccount lock the account once the failures of the password or the two factor step reach the limit
+func (u *agentUsecase) _lockAccount(ctx context.Context, throttle *helpers.LoginThrottle, user *model.Agent, company *model.Company, payload domain.LoginRequest) response.Base {
+	nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+	lockout, token := helpers.NewAccountLockout("too many failed login attempts")
+	if err := u.mongodbRepo.UpdateOneAgent(ctx, map[string]interface{}{
+		"id": user.ID,
+	}, map[string]interface{}{
+		"lockout":   lockout,
+		"updatedAt": time.Now(),
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	throttle.Reset(ctx, model.SessionAgent, user.Email)
+	u._recordSecurityEvent(ctx, model.SecurityAccountLocked, user.Company.ID, nested, payload, lockout.Reason)
+
+	config := u._CacheConfig(ctx)
+	go helpers.SendAccountLockedEmail(config, company, user.Name, user.Email, config.AgentLink, token)
+
+	return response.Error(http.StatusForbidden, "account is locked after too many failed login attempts, check your email to unlock it")
+}
+
+func (u *agentUsecase) UnlockAccount(ctx context.Context, payload domain.UnlockAccountRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if payload.Token == "" {
+		return response.ErrorValidation(map[string]string{
+			"token": "token field is required",
+		}, "error validation")
+	}
+
+	user, err := u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
+		"lockoutToken": helpers.HashUnlockToken(payload.Token),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	if user == nil || !user.Lockout.IsActive() {
+		return response.Error(http.StatusBadRequest, "unlock link is invalid or expired")
+	}
+
+	if err = u.mongodbRepo.UpdateOneAgent(ctx, map[string]interface{}{
+		"id": user.ID,
+	}, map[string]interface{}{
+		"lockout":   nil,
+		"updatedAt": time.Now(),
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	helpers.NewLoginThrottle(u.redisRepo).Reset(ctx, model.SessionAgent, user.Email)
+
+	nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+	u._recordSecurityEvent(ctx, model.SecurityAccountUnlocked, user.Company.ID, nested, domain.LoginRequest{}, "unlocked by email link")
+
+	return response.Success("account unlocked, you can login now")
+}
+
+func (u *agentUsecase) GetSecurityEventList(ctx context.Context, claim domain.JWTClaimAgent, options map[string]interface{}) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	paramQuery := options["query"].(url.Values)
+	fetchOptions, errValidation := helpers.SecurityEventFilter(paramQuery)
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+	page, limit, offset := yurekahelpers.GetLimitOffset(paramQuery)
+	fetchOptions["limit"] = limit
+	fetchOptions["offset"] = offset
+	fetchOptions["companyID"] = claim.CompanyID
+
+	// count first
+	totalDocuments := u.mongodbRepo.CountSecurityEvent(ctx, fetchOptions)
+	if totalDocuments == 0 {
+		return response.Success(response.List{
+			List:  []interface{}{},
+			Page:  page,
+			Limit: limit,
+			Total: totalDocuments,
+		})
+	}
+
+	cur, err := u.mongodbRepo.FetchSecurityEventList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.SecurityEvent{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("SecurityEvent Decode ", err)
+			continue
+		}
+		list = append(list, row)
+	}
+
+	return response.Success(response.List{
+		List:  list,
+		Page:  page,
+		Limit: limit,
+		Total: totalDocuments,
+	})
+}
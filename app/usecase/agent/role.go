@@ -0,0 +1,281 @@
+package usecase_agent
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// _findRole default role by its code or the custom role of the company
+func (u *agentUsecase) _findRole(ctx context.Context, companyID string, scope model.RoleScope, code string) (*model.Role, error) {
+	if role := helpers.DefaultRole(scope, code); role != nil {
+		return role, nil
+	}
+
+	return u.mongodbRepo.FetchOneRole(ctx, map[string]interface{}{
+		"companyID": companyID,
+		"scope":     string(scope),
+		"code":      code,
+	})
+}
+
+// _canGrantRole the actor may only give a role whose permissions it holds itself
+func (u *agentUsecase) _canGrantRole(ctx context.Context, claim domain.JWTClaimAgent, role *model.Role) (bool, error) {
+	actorRole, err := u._findRole(ctx, claim.CompanyID, model.RoleScopeAgent, claim.Role)
+	if err != nil || actorRole == nil {
+		return false, err
+	}
+
+	return actorRole.HasPermission(role.Permissions...), nil
+}
+
+// _hasPermission check the role of the agent grants every given permission
+func (u *agentUsecase) _hasPermission(ctx context.Context, claim domain.JWTClaimAgent, permissions ...model.Permission) (bool, error) {
+	role, err := u._findRole(ctx, claim.CompanyID, model.RoleScopeAgent, claim.Role)
+	if err != nil || role == nil {
+		return false, err
+	}
+
+	return role.HasPermission(permissions...), nil
+}
+
+func (u *agentUsecase) GetPermissionList(ctx context.Context, claim domain.JWTClaimAgent) response.Base {
+	return response.Success(map[string]interface{}{
+		string(model.RoleScopeAgent):    helpers.ScopePermissions(model.RoleScopeAgent),
+		string(model.RoleScopeCustomer): helpers.ScopePermissions(model.RoleScopeCustomer),
+	})
+}
+
+func (u *agentUsecase) GetRoleList(ctx context.Context, claim domain.JWTClaimAgent, query url.Values) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	fetchOptions := map[string]interface{}{
+		"companyID": claim.CompanyID,
+		"sort":      "createdAt",
+		"dir":       "asc",
+	}
+
+	// default roles first
+	list := make([]interface{}, 0)
+	for _, scope := range []model.RoleScope{model.RoleScopeAgent, model.RoleScopeCustomer} {
+		if query.Get("scope") != "" && query.Get("scope") != string(scope) {
+			continue
+		}
+		for _, role := range helpers.DefaultRoles(scope) {
+			list = append(list, role)
+		}
+	}
+
+	// filtering
+	if query.Get("scope") != "" {
+		fetchOptions["scope"] = query.Get("scope")
+	}
+
+	cur, err := u.mongodbRepo.FetchRoleList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		row := model.Role{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("Role Decode ", err)
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+
+		list = append(list, row)
+	}
+
+	return response.Success(list)
+}
+
+func (u *agentUsecase) GetRoleDetail(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check role
+	role, err := u.mongodbRepo.FetchOneRole(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if role == nil {
+		return response.Error(http.StatusBadRequest, "role not found")
+	}
+
+	return response.Success(role)
+}
+
+func (u *agentUsecase) CreateRole(ctx context.Context, claim domain.JWTClaimAgent, payload domain.RoleRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	scope := model.RoleScope(payload.Scope)
+	permissions := make([]model.Permission, 0, len(payload.Permissions))
+	for _, permission := range payload.Permissions {
+		permissions = append(permissions, model.Permission(permission))
+	}
+
+	// validating request
+	errValidation := make(map[string]string)
+	if !helpers.IsRoleScope(payload.Scope) {
+		errValidation["scope"] = "scope field must be agent or customer"
+	}
+	if !helpers.IsValidRoleCode(payload.Code) {
+		errValidation["code"] = "code field must be 2-32 lowercase letters, numbers, - or _"
+	} else if helpers.DefaultRole(scope, payload.Code) != nil {
+		errValidation["code"] = "code field is reserved for a default role"
+	}
+	if payload.Name == "" {
+		errValidation["name"] = "name field is required"
+	}
+	if permission, ok := helpers.ValidatePermissions(scope, permissions); !ok {
+		errValidation["permissions"] = "permission " + string(permission) + " is not available for " + payload.Scope
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check code
+	existing, err := u.mongodbRepo.FetchOneRole(ctx, map[string]interface{}{
+		"companyID": claim.CompanyID,
+		"scope":     payload.Scope,
+		"code":      payload.Code,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if existing != nil {
+		return response.Error(http.StatusBadRequest, "role code already exists")
+	}
+
+	now := time.Now()
+	role := model.Role{
+		ID:          primitive.NewObjectID(),
+		Company:     claim.Company,
+		Scope:       scope,
+		Code:        payload.Code,
+		Name:        payload.Name,
+		Permissions: permissions,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := u.mongodbRepo.CreateRole(ctx, &role); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(role)
+}
+
+// UpdateRole the scope and code are kept since users reference the code
+func (u *agentUsecase) UpdateRole(ctx context.Context, claim domain.JWTClaimAgent, id string, payload domain.RoleRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check role
+	role, err := u.mongodbRepo.FetchOneRole(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if role == nil {
+		return response.Error(http.StatusBadRequest, "role not found")
+	}
+
+	permissions := make([]model.Permission, 0, len(payload.Permissions))
+	for _, permission := range payload.Permissions {
+		permissions = append(permissions, model.Permission(permission))
+	}
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Name == "" {
+		errValidation["name"] = "name field is required"
+	}
+	if permission, ok := helpers.ValidatePermissions(role.Scope, permissions); !ok {
+		errValidation["permissions"] = "permission " + string(permission) + " is not available for " + string(role.Scope)
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	role.Name = payload.Name
+	role.Permissions = permissions
+	role.UpdatedAt = time.Now()
+
+	if err := u.mongodbRepo.UpdateOneRole(ctx, role); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(role)
+}
+
+func (u *agentUsecase) DeleteRole(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check role
+	role, err := u.mongodbRepo.FetchOneRole(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if role == nil {
+		return response.Error(http.StatusBadRequest, "role not found")
+	}
+
+	// check role is not in use
+	options := map[string]interface{}{
+		"companyID": claim.CompanyID,
+		"role":      role.Code,
+	}
+	total := u.mongodbRepo.CountAgent(ctx, options)
+	if role.Scope == model.RoleScopeCustomer {
+		total = u.mongodbRepo.CountCustomer(ctx, options)
+	}
+	if total > 0 {
+		return response.Error(http.StatusBadRequest, "role is still given to users")
+	}
+
+	now := time.Now()
+	role.DeletedAt = &now
+
+	if err := u.mongodbRepo.UpdateOneRole(ctx, role); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(nil)
+}
+
+// GetMyPermissions role of the logged in agent, used by the frontend to hide what is not allowed
+func (u *agentUsecase) GetMyPermissions(ctx context.Context, claim domain.JWTClaimAgent) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	role, err := u._findRole(ctx, claim.CompanyID, model.RoleScopeAgent, claim.Role)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if role == nil {
+		return response.Error(http.StatusBadRequest, "role not found")
+	}
+
+	return response.Success(role)
+}
@@ -0,0 +1,263 @@
+package usecase_agent
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+func (u *agentUsecase) GetServerResourceList(ctx context.Context, claim domain.JWTClaimAgent, query url.Values) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	page, limit, offset := yurekahelpers.GetLimitOffset(query)
+
+	fetchOptions := map[string]interface{}{
+		"limit":     limit,
+		"offset":    offset,
+		"companyID": claim.CompanyID,
+	}
+
+	// filtering
+	if query.Get("sort") != "" {
+		fetchOptions["sort"] = query.Get("sort")
+	}
+	if query.Get("dir") != "" {
+		fetchOptions["dir"] = query.Get("dir")
+	}
+	if query.Get("q") != "" {
+		fetchOptions["q"] = query.Get("q")
+	}
+	if query.Get("status") != "" {
+		fetchOptions["status"] = query.Get("status")
+	}
+	if query.Get("taskStatus") != "" {
+		fetchOptions["taskStatus"] = query.Get("taskStatus")
+	}
+	if query.Get("customerID") != "" {
+		fetchOptions["customerID"] = query.Get("customerID")
+	}
+	if query.Get("mine") == "true" {
+		fetchOptions["taskAgentID"] = claim.UserID
+	}
+
+	// count first
+	totalDocuments := u.mongodbRepo.CountServerResource(ctx, fetchOptions)
+	if totalDocuments == 0 {
+		return response.Success(domain.ResponseList{
+			List: response.List{
+				List:  []interface{}{},
+				Page:  page,
+				Limit: limit,
+				Total: totalDocuments,
+			},
+			TotalPage: helpers.GetTotalPage(totalDocuments, limit),
+		})
+	}
+
+	// check server resource list
+	cur, err := u.mongodbRepo.FetchServerResourceList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.ServerResource{}
+		err := cur.Decode(&row)
+		if err != nil {
+			logrus.Error("Server Resource Decode ", err)
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+
+		list = append(list, row)
+	}
+
+	return response.Success(domain.ResponseList{
+		List: response.List{
+			List:  list,
+			Page:  page,
+			Limit: limit,
+			Total: totalDocuments,
+		},
+		TotalPage: helpers.GetTotalPage(totalDocuments, limit),
+	})
+}
+
+func (u *agentUsecase) GetServerResourceDetail(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check server resource
+	resource, err := u.mongodbRepo.FetchOneServerResource(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if resource == nil {
+		return response.Error(http.StatusBadRequest, "server not found")
+	}
+
+	return response.Success(resource)
+}
+
+func (u *agentUsecase) TakeProvisioningTask(ctx context.Context, claim domain.JWTClaimAgent, id string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check server resource
+	resource, err := u.mongodbRepo.FetchOneServerResource(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if resource == nil {
+		return response.Error(http.StatusBadRequest, "server not found")
+	}
+
+	if resource.Status != model.ServerRequested || resource.Task == nil || resource.Task.Status != model.TaskOpen {
+		return response.Error(http.StatusBadRequest, "provisioning task is not open")
+	}
+
+	// claim the task, only one agent can take it
+	now := time.Now()
+	agent := &model.AgentNested{
+		ID:    claim.UserID,
+		Name:  claim.User.Name,
+		Email: claim.User.Email,
+	}
+	taken, err := u.mongodbRepo.UpdatePartialServerResource(ctx, map[string]interface{}{
+		"id":           resource.ID,
+		"status":       string(model.ServerRequested),
+		"taskStatus":   string(model.TaskOpen),
+		"taskAssigned": false,
+	}, map[string]interface{}{
+		"task.status":    model.TaskInProgress,
+		"task.agent":     agent,
+		"task.startedAt": now,
+		"updatedAt":      now,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if !taken {
+		return response.Error(http.StatusConflict, "provisioning task is already taken")
+	}
+
+	resource.Task.Status = model.TaskInProgress
+	resource.Task.Agent = agent
+	resource.Task.StartedAt = &now
+	resource.Provider = u.provisionerRepo.Name()
+	helpers.SetServerResourceStatus(resource, model.ServerProvisioning, "Provisioning started", claim.User)
+
+	// ask the provider, the local one leave the work to the agent
+	result, err := u.provisionerRepo.Provision(ctx, *resource)
+	if err != nil {
+		// release the task so it can be taken again
+		if _, err := u.mongodbRepo.UpdatePartialServerResource(ctx, map[string]interface{}{
+			"id":          resource.ID,
+			"taskAgentID": claim.UserID,
+		}, map[string]interface{}{
+			"task.status":    model.TaskOpen,
+			"task.agent":     nil,
+			"task.startedAt": nil,
+			"updatedAt":      time.Now(),
+		}); err != nil {
+			logrus.Error("UpdatePartialServerResource: ", err)
+		}
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	resource.ExternalID = result.ExternalID
+	resource.Hostname = result.Hostname
+	resource.IPAddress = result.IPAddress
+	if result.Ready {
+		_completeProvisioningTask(resource, "Provisioned by "+resource.Provider, claim.User)
+	}
+
+	// save
+	if err := u.mongodbRepo.UpdateOneServerResource(ctx, resource); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(resource)
+}
+
+func (u *agentUsecase) CompleteProvisioningTask(ctx context.Context, claim domain.JWTClaimAgent, id string, payload domain.CompleteProvisioningTaskRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validate
+	errValidation := make(map[string]string)
+	if strings.TrimSpace(payload.Hostname) == "" && strings.TrimSpace(payload.IPAddress) == "" {
+		errValidation["hostname"] = "hostname or ip address is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check server resource
+	resource, err := u.mongodbRepo.FetchOneServerResource(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if resource == nil {
+		return response.Error(http.StatusBadRequest, "server not found")
+	}
+
+	if resource.Status != model.ServerProvisioning || resource.Task == nil || resource.Task.Status != model.TaskInProgress {
+		return response.Error(http.StatusBadRequest, "provisioning task is not in progress")
+	}
+
+	// only the agent working on the task or a server manager may complete it
+	if resource.Task.Agent != nil && resource.Task.Agent.ID != claim.UserID {
+		canManage, err := u._hasPermission(ctx, claim, model.PermissionServerManage)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		if !canManage {
+			return response.Error(http.StatusForbidden, "provisioning task is taken by another agent")
+		}
+	}
+
+	if payload.ExternalID != "" {
+		resource.ExternalID = payload.ExternalID
+	}
+	resource.Hostname = strings.TrimSpace(payload.Hostname)
+	resource.IPAddress = strings.TrimSpace(payload.IPAddress)
+	resource.Note = payload.Note
+	_completeProvisioningTask(resource, "Server is ready", claim.User)
+
+	// save
+	if err := u.mongodbRepo.UpdateOneServerResource(ctx, resource); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(resource)
+}
+
+// _completeProvisioningTask close the task and activate the server
+func _completeProvisioningTask(resource *model.ServerResource, note string, by model.UserNested) {
+	now := time.Now()
+	resource.Task.Status = model.TaskDone
+	resource.Task.Note = note
+	resource.Task.CompletedAt = &now
+	helpers.SetServerResourceStatus(resource, model.ServerActive, note, by)
+}
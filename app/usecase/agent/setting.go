@@ -30,8 +30,9 @@ func (u *agentUsecase) ChangePassword(ctx context.Context, claim domain.JWTClaim
 	if payload.NewPassword == payload.OldPassword {
 		errValidation["newPassword"] = "new password must be different from old password"
 	}
-	if len(payload.NewPassword) < 8 {
-		errValidation["newPassword"] = "new password must be at least 8 characters"
+	policy := helpers.GetPasswordPolicy(u._CacheConfig(ctx))
+	if msg := helpers.ValidatePassword(policy, payload.NewPassword, claim.User.Email); msg != "" {
+		errValidation["newPassword"] = msg
 	}
 	if len(errValidation) > 0 {
 		return response.ErrorValidation(errValidation, "error validation")
@@ -53,16 +54,31 @@ func (u *agentUsecase) ChangePassword(ctx context.Context, claim domain.JWTClaim
 		return response.Error(http.StatusBadRequest, "Wrong password")
 	}
 
+	if helpers.IsPasswordReused(policy, payload.NewPassword, agent.Password, agent.PasswordHistory) {
+		return response.ErrorValidation(map[string]string{
+			"newPassword": "password was used recently, choose a different one",
+		}, "error validation")
+	}
+
 	// hash password
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.NewPassword), bcrypt.DefaultCost)
 
 	// update password
+	now := time.Now()
+	agent.PasswordHistory = helpers.NextPasswordHistory(policy, agent.Password, agent.PasswordHistory)
 	agent.Password = string(hashedPassword)
+	agent.PasswordChangedAt = &now
+	agent.MustChangePassword = false
 	err = u.mongodbRepo.UpdateAgent(ctx, agent)
 	if err != nil {
 		return response.Error(http.StatusBadRequest, err.Error())
 	}
 
+	// end the sessions signed in with the old password
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionAgent, agent.ID.Hex(), model.RevokePasswordChanged); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
 	return response.Success(agent)
 }
 
@@ -305,6 +321,145 @@ func (u *agentUsecase) ChangeColor(ctx context.Context, claim domain.JWTClaimAge
 	return response.Success(company)
 }
 
+func (u *agentUsecase) ChangeOveragePolicy(ctx context.Context, claim domain.JWTClaimAgent, payload domain.ChangeOveragePolicyRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	errValidation := make(map[string]string)
+
+	// validating request
+	policy := model.OveragePolicy(payload.Policy)
+	if policy != model.OverageBlock && policy != model.OverageBillNextOrder && policy != model.OveragePauseTimer {
+		errValidation["policy"] = "policy field must be block, bill_next_order or pause_timer"
+	}
+
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{"id": claim.CompanyID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	company.Settings.Overage = policy
+
+	if err = u.mongodbRepo.UpdatePartialCompany(
+		ctx,
+		map[string]interface{}{"id": claim.CompanyID},
+		map[string]interface{}{
+			"settings.overage": company.Settings.Overage,
+		}); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error())
+	}
+
+	return response.Success(company)
+}
+
+// ChangePasswordlessLogin let the customers sign in with an emailed link, passwords keep working
+func (u *agentUsecase) ChangePasswordlessLogin(ctx context.Context, claim domain.JWTClaimAgent, payload domain.ChangePasswordlessLoginRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	if payload.Enabled == nil {
+		return response.ErrorValidation(map[string]string{"enabled": "enabled field is required"}, "error validation")
+	}
+
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{"id": claim.CompanyID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	company.Settings.PasswordlessLogin = *payload.Enabled
+
+	if err = u.mongodbRepo.UpdatePartialCompany(
+		ctx,
+		map[string]interface{}{"id": claim.CompanyID},
+		map[string]interface{}{
+			"settings.passwordlessLogin": company.Settings.PasswordlessLogin,
+		}); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error())
+	}
+
+	return response.Success(company)
+}
+
+func (u *agentUsecase) ChangeAutoAssignTicket(ctx context.Context, claim domain.JWTClaimAgent, payload domain.ChangeAutoAssignTicketRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	if payload.Enabled == nil {
+		return response.ErrorValidation(map[string]string{"enabled": "enabled field is required"}, "error validation")
+	}
+
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{"id": claim.CompanyID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	company.Settings.AutoAssignTicket = *payload.Enabled
+
+	if err = u.mongodbRepo.UpdatePartialCompany(
+		ctx,
+		map[string]interface{}{"id": claim.CompanyID},
+		map[string]interface{}{
+			"settings.autoAssignTicket": company.Settings.AutoAssignTicket,
+		}); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error())
+	}
+
+	return response.Success(company)
+}
+
+func (u *agentUsecase) ChangeAgentCapacity(ctx context.Context, claim domain.JWTClaimAgent, payload domain.ChangeAgentCapacityRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	if payload.Capacity == nil {
+		return response.ErrorValidation(map[string]string{"capacity": "capacity field is required"}, "error validation")
+	}
+	if *payload.Capacity < 0 {
+		return response.ErrorValidation(map[string]string{"capacity": "capacity field is invalid"}, "error validation")
+	}
+
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{"id": claim.CompanyID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	company.Settings.AgentCapacity = *payload.Capacity
+
+	if err = u.mongodbRepo.UpdatePartialCompany(
+		ctx,
+		map[string]interface{}{"id": claim.CompanyID},
+		map[string]interface{}{
+			"settings.agentCapacity": company.Settings.AgentCapacity,
+		}); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error())
+	}
+
+	return response.Success(company)
+}
+
 func (u *agentUsecase) UploadAgentProfilePicture(ctx context.Context, claim domain.JWTClaimAgent, payload domain.UploadAttachment, request *http.Request) response.Base {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
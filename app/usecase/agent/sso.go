@@ -0,0 +1,200 @@
+package usecase_agent
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SSOAuthorize url of the company identity provider the agent is redirected to
+func (u *agentUsecase) SSOAuthorize(ctx context.Context, payload domain.SSOAuthorizeRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.AccessKey == "" {
+		errValidation["accessKey"] = "accessKey field is required"
+	}
+	if payload.RedirectURI == "" {
+		errValidation["redirectUri"] = "redirectUri field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"accessKey": payload.AccessKey,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+	if !helpers.IsSSOEnabled(company, model.SessionAgent) {
+		return response.Error(http.StatusBadRequest, "single sign-on is not enabled for this company")
+	}
+
+	authorizationURL, err := helpers.OIDCAuthorizationURL(ctx, helpers.GetJWTSecretKeyAgent(), company, model.SessionAgent, payload.RedirectURI, payload.Binding)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"authorizationUrl": authorizationURL,
+	})
+}
+
+// SSOCallback finish the login with the code returned by the identity provider,
+// the authenticator app is still asked when the agent or the company requires it
+func (u *agentUsecase) SSOCallback(ctx context.Context, payload domain.SSOCallbackRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.State == "" {
+		errValidation["state"] = "state field is required"
+	}
+	if payload.Code == "" {
+		errValidation["code"] = "code field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	state, err := helpers.ParseOIDCState(helpers.GetJWTSecretKeyAgent(), model.SessionAgent, payload.State, payload.Binding)
+	if err != nil {
+		return response.Error(http.StatusUnauthorized, err.Error())
+	}
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": state.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+	if !helpers.IsSSOEnabled(company, model.SessionAgent) {
+		return response.Error(http.StatusBadRequest, "single sign-on is not enabled for this company")
+	}
+
+	identity, err := helpers.CompleteOIDCLogin(ctx, helpers.GetJWTSecretKeyAgent(), company, state, payload.Code)
+	if err != nil {
+		return response.Error(http.StatusUnauthorized, err.Error())
+	}
+
+	user, errResponse := u._ssoAgent(ctx, company, identity)
+	if errResponse != nil {
+		return *errResponse
+	}
+	if user.Lockout.IsActive() {
+		return response.Error(http.StatusForbidden, "account is locked, check your email to unlock it or try again later")
+	}
+
+	// second step with the authenticator app
+	if user.TwoFactor.Enabled || helpers.IsTwoFactorRequired(company) {
+		return u._twoFactorChallenge(user)
+	}
+
+	return u._completeLogin(ctx, user, nil)
+}
+
+// _ssoAgent find the agent linked to the identity, linking by email or provisioning it on the first login
+func (u *agentUsecase) _ssoAgent(ctx context.Context, company *model.Company, identity *helpers.OIDCIdentity) (*model.Agent, *response.Base) {
+	now := time.Now()
+	link := &model.SSOIdentity{
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		LastLoginAt: &now,
+	}
+
+	user, err := u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
+		"ssoIssuer":  identity.Issuer,
+		"ssoSubject": identity.Subject,
+	})
+	if err != nil {
+		errResponse := response.Error(http.StatusInternalServerError, err.Error())
+		return nil, &errResponse
+	}
+	if user == nil && identity.EmailVerified {
+		// only an email verified by the identity provider links an existing account
+		user, err = u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
+			"email": identity.Email,
+		})
+		if err != nil {
+			errResponse := response.Error(http.StatusInternalServerError, err.Error())
+			return nil, &errResponse
+		}
+	}
+
+	if user != nil {
+		if user.Company.ID != company.ID.Hex() {
+			errResponse := response.Error(http.StatusBadRequest, "email already in use for "+user.Company.Name)
+			return nil, &errResponse
+		}
+		if user.SSO != nil && user.SSO.Subject != identity.Subject {
+			errResponse := response.Error(http.StatusBadRequest, "account is linked to another identity")
+			return nil, &errResponse
+		}
+
+		user.SSO = link
+		user.UpdatedAt = now
+		if err := u.mongodbRepo.UpdateOneAgent(ctx, map[string]interface{}{
+			"id": user.ID,
+		}, map[string]interface{}{
+			"sso":       user.SSO,
+			"updatedAt": user.UpdatedAt,
+		}); err != nil {
+			errResponse := response.Error(http.StatusInternalServerError, err.Error())
+			return nil, &errResponse
+		}
+
+		return user, nil
+	}
+
+	// provision the agent, it has no password and can only login through the identity provider
+	user = &model.Agent{
+		ID:    primitive.NewObjectID(),
+		Name:  identity.Name,
+		Email: identity.Email,
+		Company: model.CompanyNested{
+			ID:       company.ID.Hex(),
+			Name:     company.Name,
+			Image:    company.Logo.URL,
+			Type:     company.Type,
+			Code:     company.Code,
+			LogoUrl:  company.Logo.URL,
+			Settings: company.Settings,
+		},
+		JobTitle:  identity.JobTitle,
+		Role:      model.AgentRole,
+		SSO:       link,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if company.SSO.DefaultCategory.ID != "" {
+		helpers.SetAgentCategories(user, []model.AgentCategory{{
+			ID:          company.SSO.DefaultCategory.ID,
+			Name:        company.SSO.DefaultCategory.Name,
+			Proficiency: helpers.AgentProficiencyDefault,
+		}})
+	}
+	if err := u.mongodbRepo.CreateAgent(ctx, user); err != nil {
+		errResponse := response.Error(http.StatusInternalServerError, err.Error())
+		return nil, &errResponse
+	}
+
+	return user, nil
+}
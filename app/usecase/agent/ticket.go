@@ -10,6 +10,7 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -39,9 +40,13 @@ func (u *agentUsecase) GetTicketList(ctx context.Context, claim domain.JWTClaimA
 		"companyID": claim.CompanyID,
 	}
 
-	// filtering
-	if agent.Role == "agent" {
-		fetchOptions["categoryID"] = agent.Category.ID
+	// filtering, agents only see their categories unless the role allows every ticket
+	viewAll, err := u._hasPermission(ctx, claim, model.PermissionTicketViewAll)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if !viewAll {
+		fetchOptions["categoryIDs"] = helpers.GetAgentCategoryIDs(*agent)
 	}
 
 	if query.Get("sort") != "" {
@@ -88,10 +93,15 @@ func (u *agentUsecase) GetTicketList(ctx context.Context, claim domain.JWTClaimA
 		fetchOptions["priority"] = query.Get("priority")
 	}
 
-	if query.Get("categoryID") != "" {
+	// narrowing down to one category stays within the agent categories
+	if query.Get("categoryID") != "" && (viewAll || slices.Contains(helpers.GetAgentCategoryIDs(*agent), query.Get("categoryID"))) {
 		fetchOptions["categoryID"] = query.Get("categoryID")
 	}
 
+	if query.Get("language") != "" {
+		fetchOptions["language"] = strings.ToLower(query.Get("language"))
+	}
+
 	if query.Get("completedBy") != "" {
 		fetchOptions["completedBy"] = query.Get("completedBy")
 	}
@@ -294,8 +304,8 @@ func (u *agentUsecase) GetTicketDetail(ctx context.Context, claim domain.JWTClai
 
 	// check ticket
 	ticket, err := u.mongodbRepo.FetchOneTicket(ctx, map[string]interface{}{
-		"id":         ticketId,
-		"commpanyID": claim.CompanyID,
+		"id":        ticketId,
+		"companyID": claim.CompanyID,
 	})
 
 	if err != nil {
@@ -306,6 +316,18 @@ func (u *agentUsecase) GetTicketDetail(ctx context.Context, claim domain.JWTClai
 		return response.Error(http.StatusBadRequest, "ticket not found")
 	}
 
+	// loading the ticket marks the agent as viewing, the comment count is sent back on reply
+	presence, err := helpers.NewPresenceTracker(u.redisRepo).Update(ctx, ticket.ID.Hex(), model.AgentNested(claim.User), model.PresenceViewing)
+	if err != nil {
+		logrus.Error("GetTicketDetail presence:", err)
+	}
+	ticket.Presence = presence
+
+	commentCount := u.mongodbRepo.CountTicketComment(ctx, map[string]interface{}{
+		"ticketID": ticket.ID.Hex(),
+	})
+	ticket.CommentCount = &commentCount
+
 	return response.Success(ticket.Format(claim.UserID))
 }
 
@@ -416,6 +438,33 @@ func (u *agentUsecase) StartLoggingTicket(ctx context.Context, claim domain.JWTC
 		return response.Error(http.StatusBadRequest, "log already running")
 	}
 
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": ticket.Customer.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	// find company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": ticket.Company.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// check customer balance under the company overage policy
+	if helpers.IsBalanceLimited(company, customer) && !helpers.CanRunTimer(helpers.GetOveragePolicy(company), helpers.TimeRemaining(company, customer)) {
+		return response.Error(http.StatusBadRequest, "the customer doesn't have enough time balance")
+	}
+
 	// update ticket
 	startAt := time.Now()
 	ticket.LogTime.StartAt = &startAt
@@ -453,17 +502,6 @@ func (u *agentUsecase) StartLoggingTicket(ctx context.Context, claim domain.JWTC
 		CreatedAt:              time.Now(),
 	})
 
-	// find company
-	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
-		"id": ticket.Company.ID,
-	})
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, err.Error())
-	}
-	if company == nil {
-		return response.Error(http.StatusBadRequest, "company not found")
-	}
-
 	go _sendActivityNotification(ticket, company)
 
 	return response.Success(ticket)
@@ -709,6 +747,22 @@ func (u *agentUsecase) ResumeLoggingTicket(ctx context.Context, claim domain.JWT
 		}
 	}
 
+	// find company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": ticket.Company.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// check customer balance under the company overage policy
+	if helpers.IsBalanceLimited(company, customer) && !helpers.CanRunTimer(helpers.GetOveragePolicy(company), helpers.TimeRemaining(company, customer)) {
+		return response.Error(http.StatusBadRequest, "the customer doesn't have enough time balance")
+	}
+
 	//check pause history
 	if len(ticket.LogTime.PauseHistory) == 0 {
 		return response.Error(http.StatusBadRequest, "no pause history found")
@@ -754,17 +808,6 @@ func (u *agentUsecase) ResumeLoggingTicket(ctx context.Context, claim domain.JWT
 		CreatedAt:              time.Now(),
 	})
 
-	// find company
-	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
-		"id": ticket.Company.ID,
-	})
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, err.Error())
-	}
-	if company == nil {
-		return response.Error(http.StatusBadRequest, "company not found")
-	}
-
 	// notification
 	go _sendActivityNotification(ticket, company)
 
@@ -812,6 +855,29 @@ func (u *agentUsecase) CreateTicketComment(ctx context.Context, claim domain.JWT
 		return response.Error(http.StatusBadRequest, "ticked not found")
 	}
 
+	// someone else replied after the agent loaded the ticket
+	if payload.CommentCount != nil && !payload.Force {
+		commentCount := u.mongodbRepo.CountTicketComment(ctx, map[string]interface{}{
+			"ticketID": ticket.ID.Hex(),
+		})
+		if commentCount > *payload.CommentCount {
+			newReplies, errResponse := u._repliesByOthersSince(ctx, ticket.ID.Hex(), UserID, *payload.CommentCount)
+			if errResponse != nil {
+				return *errResponse
+			}
+			if newReplies > 0 {
+				presence, _ := helpers.NewPresenceTracker(u.redisRepo).Get(ctx, ticket.ID.Hex())
+				errResponse := response.Error(http.StatusConflict, "the ticket has new replies since you loaded it")
+				errResponse.Data = map[string]interface{}{
+					"commentCount": commentCount,
+					"newReplies":   newReplies,
+					"presence":     presence,
+				}
+				return errResponse
+			}
+		}
+	}
+
 	// check company
 	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
 		"id": ticket.Company.ID,
@@ -867,14 +933,18 @@ func (u *agentUsecase) CreateTicketComment(ctx context.Context, claim domain.JWT
 		}
 	}
 
-	// check customer balance
-	if customer.IsNeedBalance {
+	// check customer balance, pooled company draw from the company wallet
+	overagePolicy := helpers.GetOveragePolicy(company)
+	if helpers.IsPooledWallet(company) {
+		if payload.Status == model.InProgress && !helpers.CanRunTimer(overagePolicy, helpers.PooledTimeRemaining(company, customer)) {
+			return response.Error(http.StatusBadRequest, "the customer doesn't have enough time balance")
+		}
+	} else if customer.IsNeedBalance {
 		if payload.Status == model.InProgress {
 			if !(now.After(customer.Subscription.StartAt) && now.Before(customer.Subscription.EndAt)) || customer.Subscription.Status != model.Active {
 				return response.Error(http.StatusBadRequest, "the customer doesn't have active subscription")
 			}
-			timeRemaining := customer.Subscription.Balance.Time.Total - customer.Subscription.Balance.Time.Used
-			if customer.Subscription.Balance == nil || timeRemaining < 0 {
+			if customer.Subscription.Balance == nil || !helpers.CanRunTimer(overagePolicy, helpers.TimeRemaining(company, customer)) {
 				return response.Error(http.StatusBadRequest, "the customer doesn't have enough time balance")
 			}
 		}
@@ -962,6 +1032,11 @@ func (u *agentUsecase) CreateTicketComment(ctx context.Context, claim domain.JWT
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
+	// the reply is sent, the agent stops typing
+	if _, err := helpers.NewPresenceTracker(u.redisRepo).Update(ctx, ticket.ID.Hex(), model.AgentNested(claim.User), model.PresenceViewing); err != nil {
+		logrus.Error("CreateTicketComment presence:", err)
+	}
+
 	// update ticket & ticket LogTime
 	if err := u._updateTicketAndTimelog(ctx, ticket, payload.Status, claim.User, company); err != nil {
 		return response.Error(http.StatusInternalServerError, err.Error())
@@ -1633,12 +1708,30 @@ func (u *agentUsecase) _updateTimeBalance(ctx context.Context, ticket *model.Tic
 		return fmt.Errorf("customer not found")
 	}
 
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": customer.Company.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// pooled company draw from the company wallet
+	if helpers.IsPooledWallet(company) {
+		if err := helpers.DrawCompanyWallet(ctx, u.mongodbRepo, ticket, customer, company, int64(duration)); err != nil {
+			return err
+		}
+		helpers.CheckLowBalance(ctx, u.mongodbRepo, u._CacheConfig(ctx), ticket, customer, company, helpers.TimeRemaining(company, customer))
+		return nil
+	}
+
 	// check need balance
 	if !customer.IsNeedBalance {
 		return
 	}
 
 	usedTime := int64(customer.Subscription.Balance.Time.Used) + int64(duration)
+	customer.Subscription.Balance.Time.Used = usedTime
 
 	if err := u.mongodbRepo.UpdateOneCustomer(
 		ctx,
@@ -1651,19 +1744,16 @@ func (u *agentUsecase) _updateTimeBalance(ctx context.Context, ticket *model.Tic
 
 	}
 
-	if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, &model.CustomerBalanceHistory{
-		ID:       primitive.NewObjectID(),
-		Customer: ticket.Customer,
-		Out:      int64(ticket.LogTime.DurationInSeconds),
-		Reference: model.Reference{
-			UniqueID: ticket.ID.Hex(),
-			Type:     model.TicketReference},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}); err != nil {
+	if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, helpers.NewCustomerBalanceHistory(customer, 0, int64(duration), model.Reference{
+		UniqueID: ticket.ID.Hex(),
+		Number:   ticket.Code,
+		Type:     model.TicketReference,
+	}, "Ticket "+ticket.Subject)); err != nil {
 		return err
 	}
 
+	helpers.CheckLowBalance(ctx, u.mongodbRepo, u._CacheConfig(ctx), ticket, customer, company, helpers.TimeRemaining(company, customer))
+
 	return nil
 }
 
@@ -1706,6 +1796,21 @@ func (u *agentUsecase) AssignTicketToMe(ctx context.Context, claim domain.JWTCla
 		}
 	}
 
+	// check workload capacity, leads can go over it
+	load, err := helpers.GetAgentLoad(ctx, u.mongodbRepo, company, claim.UserID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if !load.Available {
+		override, err := u._hasPermission(ctx, claim, model.PermissionTicketOverCapacity)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		if !override {
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("you reached the capacity of %d active tickets", load.Capacity))
+		}
+	}
+
 	// assign agent
 	ticket.Agent = append(ticket.Agent, model.AgentNested{
 		ID:    claim.User.ID,
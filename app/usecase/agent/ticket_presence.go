@@ -0,0 +1,136 @@
+package usecase_agent
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+func (u *agentUsecase) UpdateTicketPresence(ctx context.Context, claim domain.JWTClaimAgent, ticketID string, payload domain.TicketPresenceRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	switch payload.State {
+	case model.PresenceViewing, model.PresenceTyping, model.PresenceLeft:
+	case "":
+		return response.ErrorValidation(map[string]string{"state": "state field is required"}, "error validation")
+	default:
+		return response.ErrorValidation(map[string]string{"state": "state field is invalid"}, "error validation")
+	}
+
+	// check ticket
+	ticket, err := u.mongodbRepo.FetchOneTicket(ctx, map[string]interface{}{
+		"id":        ticketID,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if ticket == nil {
+		return response.Error(http.StatusBadRequest, "ticket not found")
+	}
+
+	presence, err := helpers.NewPresenceTracker(u.redisRepo).Update(ctx, ticket.ID.Hex(), model.AgentNested(claim.User), payload.State)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(presence)
+}
+
+// SubscribeTicketPresence presence of the ticket on every change and heartbeat, until ctx is done
+func (u *agentUsecase) SubscribeTicketPresence(ctx context.Context, claim domain.JWTClaimAgent, ticketID string) (<-chan *model.TicketPresence, *response.Base) {
+	checkCtx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check ticket
+	ticket, err := u.mongodbRepo.FetchOneTicket(checkCtx, map[string]interface{}{
+		"id":        ticketID,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		errResponse := response.Error(http.StatusInternalServerError, err.Error())
+		return nil, &errResponse
+	}
+	if ticket == nil {
+		errResponse := response.Error(http.StatusBadRequest, "ticket not found")
+		return nil, &errResponse
+	}
+
+	tracker := helpers.NewPresenceTracker(u.redisRepo)
+	messages, unsubscribe := tracker.Subscribe(ctx, ticket.ID.Hex())
+
+	updates := make(chan *model.TicketPresence)
+	go func() {
+		defer close(updates)
+		defer unsubscribe()
+
+		heartbeat := time.NewTicker(helpers.GetPresenceHeartbeat())
+		defer heartbeat.Stop()
+
+		// the current presence first
+		presence, err := tracker.Get(ctx, ticket.ID.Hex())
+		for {
+			if err != nil {
+				logrus.Error("SubscribeTicketPresence:", err)
+			} else {
+				select {
+				case updates <- presence:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-heartbeat.C:
+				presence, err = tracker.Get(ctx, ticket.ID.Hex())
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				presence = &model.TicketPresence{}
+				err = json.Unmarshal(message, presence)
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// _repliesByOthersSince replies after the first seen comments, the agent's own replies from another tab are not a conflict
+func (u *agentUsecase) _repliesByOthersSince(ctx context.Context, ticketID string, agentID string, seen int64) (int64, *response.Base) {
+	cur, err := u.mongodbRepo.FetchTicketCommentList(ctx, map[string]interface{}{
+		"ticketID": ticketID,
+		"sort":     "createdAt",
+		"dir":      "asc",
+		"offset":   seen,
+	})
+	if err != nil {
+		errResponse := response.Error(http.StatusInternalServerError, err.Error())
+		return 0, &errResponse
+	}
+	defer cur.Close(ctx)
+
+	comments := make([]model.TicketComment, 0)
+	for cur.Next(ctx) {
+		row := model.TicketComment{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("TicketComment Decode ", err)
+			errResponse := response.Error(http.StatusInternalServerError, err.Error())
+			return 0, &errResponse
+		}
+		comments = append(comments, row)
+	}
+
+	return helpers.CountRepliesByOthers(comments, agentID), nil
+}
@@ -0,0 +1,316 @@
+package usecase_agent
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// _twoFactorChallenge answer the password step, the token is exchanged for a session with a code from the app
+func (u *agentUsecase) _twoFactorChallenge(user *model.Agent) response.Base {
+	challengeToken, err := helpers.GenerateTwoFactorChallenge(helpers.GetJWTSecretKeyAgent(), "agent", user.ID.Hex())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"twoFactorRequired":      true,
+		"twoFactorSetupRequired": !user.TwoFactor.Enabled,
+		"challengeToken":         challengeToken,
+	})
+}
+
+// _agentFromChallenge the user of a challenge still open, it ends once exchanged or after too many wrong codes
+func (u *agentUsecase) _agentFromChallenge(ctx context.Context, throttle *helpers.LoginThrottle, challengeToken string) (*model.Agent, string, response.Base) {
+	agentID, challengeID, ok := helpers.ParseTwoFactorChallenge(helpers.GetJWTSecretKeyAgent(), "agent", challengeToken)
+	if !ok || throttle.TwoFactorChallengeEnded(ctx, challengeID) {
+		return nil, "", response.Error(http.StatusUnauthorized, "challenge token is invalid or expired, please login again")
+	}
+
+	agent, err := u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
+		"id": agentID,
+	})
+	if err != nil {
+		return nil, "", response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if agent == nil {
+		return nil, "", response.Error(http.StatusBadRequest, "user not found")
+	}
+	if agent.Lockout.IsActive() {
+		return nil, "", response.Error(http.StatusForbidden, "account is locked, try again later")
+	}
+
+	return agent, challengeID, response.Base{}
+}
+
+func (u *agentUsecase) _saveTwoFactor(ctx context.Context, agent *model.Agent) error {
+	agent.UpdatedAt = time.Now()
+	return u.mongodbRepo.UpdateOneAgent(ctx, map[string]interface{}{
+		"id": agent.ID,
+	}, map[string]interface{}{
+		"twoFactor": agent.TwoFactor,
+		"updatedAt": agent.UpdatedAt,
+	})
+}
+
+// SetupTwoFactorLogin enrollment during login when the company requires two factor authentication
+func (u *agentUsecase) SetupTwoFactorLogin(ctx context.Context, payload domain.TwoFactorChallengeRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	agent, _, errResponse := u._agentFromChallenge(ctx, helpers.NewLoginThrottle(u.redisRepo), payload.ChallengeToken)
+	if agent == nil {
+		return errResponse
+	}
+	if agent.TwoFactor.Enabled {
+		return response.Error(http.StatusBadRequest, "two factor authentication is already enabled")
+	}
+
+	return u._setupTwoFactor(ctx, agent)
+}
+
+// VerifyTwoFactorLogin second login step, a pending enrollment is confirmed by the same code
+func (u *agentUsecase) VerifyTwoFactorLogin(ctx context.Context, payload domain.TwoFactorLoginRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.ChallengeToken == "" {
+		errValidation["challengeToken"] = "challengeToken field is required"
+	}
+	if payload.Code == "" {
+		errValidation["code"] = "code field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	throttle := helpers.NewLoginThrottle(u.redisRepo)
+	agent, challengeID, errResponse := u._agentFromChallenge(ctx, throttle, payload.ChallengeToken)
+	if agent == nil {
+		return errResponse
+	}
+	if wait := throttle.Wait(ctx, model.SessionAgent, agent.Email, payload.IPAddress); wait > 0 {
+		return response.Error(http.StatusTooManyRequests, helpers.LoginWaitMessage(wait))
+	}
+
+	var recoveryCodes []string
+	if agent.TwoFactor.Enabled {
+		if !helpers.VerifyTwoFactorCode(&agent.TwoFactor, payload.Code) {
+			return u._twoFactorFailed(ctx, throttle, agent, challengeID, payload)
+		}
+	} else {
+		codes, ok := helpers.EnableTwoFactor(&agent.TwoFactor, payload.Code)
+		if !ok {
+			return u._twoFactorFailed(ctx, throttle, agent, challengeID, payload)
+		}
+		recoveryCodes = codes
+	}
+
+	if err := u._saveTwoFactor(ctx, agent); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	throttle.EndTwoFactorChallenge(ctx, challengeID)
+	throttle.Reset(ctx, model.SessionAgent, agent.Email)
+
+	return u._completeLogin(ctx, agent, recoveryCodes)
+}
+
+// _twoFactorFailed count the wrong code, the challenge ends after a few and the account locks like for passwords
+func (u *agentUsecase) _twoFactorFailed(ctx context.Context, throttle *helpers.LoginThrottle, agent *model.Agent, challengeID string, payload domain.TwoFactorLoginRequest) response.Base {
+	loginPayload := domain.LoginRequest{Email: agent.Email, IPAddress: payload.IPAddress, UserAgent: payload.UserAgent}
+	nested := model.UserNested{ID: agent.ID.Hex(), Name: agent.Name, Email: agent.Email}
+	challengeFailures, failures := throttle.FailTwoFactor(ctx, model.SessionAgent, agent.Email, payload.IPAddress, challengeID)
+	u._recordSecurityEvent(ctx, model.SecurityLoginFailed, agent.Company.ID, nested, loginPayload, "wrong two factor code")
+
+	if failures >= helpers.GetLoginMaxAttempts() {
+		company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+			"id": agent.Company.ID,
+		})
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		if company == nil {
+			return response.Error(http.StatusBadRequest, "company not found")
+		}
+		throttle.EndTwoFactorChallenge(ctx, challengeID)
+		return u._lockAccount(ctx, throttle, agent, company, loginPayload)
+	}
+	if challengeFailures >= helpers.GetTwoFactorMaxAttempts() {
+		return response.Error(http.StatusUnauthorized, "too many invalid codes, please login again")
+	}
+
+	return response.Error(http.StatusBadRequest, "two factor code is invalid")
+}
+
+func (u *agentUsecase) SetupTwoFactor(ctx context.Context, claim domain.JWTClaimAgent) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	agent, err := u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
+		"id": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if agent == nil {
+		return response.Error(http.StatusBadRequest, "agent not found")
+	}
+	if agent.TwoFactor.Enabled {
+		return response.Error(http.StatusBadRequest, "two factor authentication is already enabled")
+	}
+
+	return u._setupTwoFactor(ctx, agent)
+}
+
+// _setupTwoFactor keep a pending secret until it is confirmed by a code from the app
+func (u *agentUsecase) _setupTwoFactor(ctx context.Context, agent *model.Agent) response.Base {
+	agent.TwoFactor.PendingSecret = helpers.GenerateTOTPSecret()
+	if err := u._saveTwoFactor(ctx, agent); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"secret":          agent.TwoFactor.PendingSecret,
+		"provisioningUri": helpers.TOTPProvisioningURI(helpers.GetTwoFactorIssuer(), agent.Email, agent.TwoFactor.PendingSecret),
+	})
+}
+
+func (u *agentUsecase) EnableTwoFactor(ctx context.Context, claim domain.JWTClaimAgent, payload domain.TwoFactorCodeRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Code == "" {
+		errValidation["code"] = "code field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	agent, err := u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
+		"id": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if agent == nil {
+		return response.Error(http.StatusBadRequest, "agent not found")
+	}
+	if agent.TwoFactor.Enabled {
+		return response.Error(http.StatusBadRequest, "two factor authentication is already enabled")
+	}
+	if agent.TwoFactor.PendingSecret == "" {
+		return response.Error(http.StatusBadRequest, "two factor authentication is not set up")
+	}
+
+	recoveryCodes, ok := helpers.EnableTwoFactor(&agent.TwoFactor, payload.Code)
+	if !ok {
+		return response.Error(http.StatusBadRequest, "two factor code is invalid")
+	}
+
+	if err := u._saveTwoFactor(ctx, agent); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"twoFactor":     agent.TwoFactor,
+		"recoveryCodes": recoveryCodes,
+	})
+}
+
+func (u *agentUsecase) DisableTwoFactor(ctx context.Context, claim domain.JWTClaimAgent, payload domain.DisableTwoFactorRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Password == "" {
+		errValidation["password"] = "password field is required"
+	}
+	if payload.Code == "" {
+		errValidation["code"] = "code field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// company may enforce it
+	if claim.Company.Settings.RequireTwoFactor {
+		return response.Error(http.StatusBadRequest, "two factor authentication is required by your company")
+	}
+
+	agent, err := u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
+		"id": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if agent == nil {
+		return response.Error(http.StatusBadRequest, "agent not found")
+	}
+	if !agent.TwoFactor.Enabled {
+		return response.Error(http.StatusBadRequest, "two factor authentication is not enabled")
+	}
+
+	// check password and code
+	if err = bcrypt.CompareHashAndPassword([]byte(agent.Password), []byte(payload.Password)); err != nil {
+		return response.Error(http.StatusBadRequest, "Wrong password")
+	}
+	if !helpers.VerifyTwoFactorCode(&agent.TwoFactor, payload.Code) {
+		return response.Error(http.StatusBadRequest, "two factor code is invalid")
+	}
+
+	agent.TwoFactor = model.TwoFactor{}
+	if err := u._saveTwoFactor(ctx, agent); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(agent)
+}
+
+// RegenerateRecoveryCodes replace every recovery code, the old ones stop working
+func (u *agentUsecase) RegenerateRecoveryCodes(ctx context.Context, claim domain.JWTClaimAgent, payload domain.TwoFactorCodeRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Code == "" {
+		errValidation["code"] = "code field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	agent, err := u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
+		"id": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if agent == nil {
+		return response.Error(http.StatusBadRequest, "agent not found")
+	}
+	if !helpers.VerifyTwoFactorCode(&agent.TwoFactor, payload.Code) {
+		return response.Error(http.StatusBadRequest, "two factor code is invalid")
+	}
+
+	recoveryCodes, hashes := helpers.GenerateRecoveryCodes()
+	agent.TwoFactor.RecoveryCodes = hashes
+	if err := u._saveTwoFactor(ctx, agent); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"recoveryCodes": recoveryCodes,
+	})
+}
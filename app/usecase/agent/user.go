@@ -5,8 +5,11 @@ import (
 	"app/domain/model"
 	"app/helpers"
 	"context"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
@@ -42,6 +45,14 @@ func (u *agentUsecase) GetAgentList(ctx context.Context, claim domain.JWTClaimAg
 		fetchOptions["q"] = paramQuery.Get("q")
 	}
 
+	if paramQuery.Get("categoryID") != "" {
+		fetchOptions["categoryIDs"] = strings.Split(paramQuery.Get("categoryID"), ",")
+	}
+
+	if paramQuery.Get("language") != "" {
+		fetchOptions["language"] = strings.ToLower(paramQuery.Get("language"))
+	}
+
 	// count first
 	totalDocuments := u.mongodbRepo.CountAgent(ctx, fetchOptions)
 
@@ -54,6 +65,17 @@ func (u *agentUsecase) GetAgentList(ctx context.Context, claim domain.JWTClaimAg
 		})
 	}
 
+	// company capacity for the agent load
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
 	// check agent list
 	cur, err := u.mongodbRepo.FetchAgentList(ctx, fetchOptions)
 
@@ -68,7 +90,8 @@ func (u *agentUsecase) GetAgentList(ctx context.Context, claim domain.JWTClaimAg
 
 	defer cur.Close(ctx)
 
-	list := make([]interface{}, 0)
+	agents := make([]model.Agent, 0)
+	agentIDs := make([]string, 0)
 	for cur.Next(ctx) {
 		row := model.Agent{}
 		err := cur.Decode(&row)
@@ -82,6 +105,20 @@ func (u *agentUsecase) GetAgentList(ctx context.Context, claim domain.JWTClaimAg
 			})
 		}
 
+		agents = append(agents, row)
+		agentIDs = append(agentIDs, row.ID.Hex())
+	}
+
+	loads, err := helpers.GetAgentLoads(ctx, u.mongodbRepo, company, agentIDs)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	list := make([]interface{}, 0)
+	for _, row := range agents {
+		load := loads[row.ID.Hex()]
+		row.Load = &load
+
 		list = append(list, row)
 	}
 
@@ -137,11 +174,17 @@ func (u *agentUsecase) CreateAgent(ctx context.Context, claim domain.JWTClaimAge
 
 	if payload.Role == "" {
 		errValidation["role"] = "role field is required"
-	} else if !helpers.InArrayString(payload.Role, []string{"admin", "agent"}) {
-		errValidation["role"] = "role field must be admin or agent"
+	} else if role, err := u._findRole(ctx, claim.CompanyID, model.RoleScopeAgent, payload.Role); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	} else if role == nil {
+		errValidation["role"] = "role not found"
+	} else if allowed, err := u._canGrantRole(ctx, claim, role); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	} else if !allowed {
+		errValidation["role"] = "role has permissions you do not have"
 	}
-	if payload.CategoryId == "" {
-		errValidation["category"] = "category field is required"
+	if payload.CategoryId == "" && len(payload.Categories) == 0 {
+		errValidation["categories"] = "categories field is required"
 	}
 
 	if len(errValidation) > 0 {
@@ -162,33 +205,35 @@ func (u *agentUsecase) CreateAgent(ctx context.Context, claim domain.JWTClaimAge
 	}
 
 	//check category
-	category, err := u.mongodbRepo.FetchOneTicketCategory(ctx, map[string]interface{}{
-		"id":        payload.CategoryId,
-		"companyID": claim.CompanyID,
-	})
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, err.Error())
-	}
-	if category == nil {
-		return response.Error(http.StatusBadRequest, "category not found")
+	categories, errRes := u._agentCategories(ctx, claim.CompanyID, payload.CategoryId, payload.Categories)
+	if errRes != nil {
+		return *errRes
 	}
 
-	password := helpers.RandomString(5)
+	// one time password, it has to be changed on the first login
+	password := helpers.GeneratePassword(helpers.GetPasswordPolicy(u._CacheConfig(ctx)))
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	now := time.Now()
 
 	// create agent
 	newAgent := &model.Agent{
-		ID:        primitive.NewObjectID(),
-		Name:      payload.Name,
-		Email:     payload.Email,
-		Password:  string(hashedPassword),
-		Company:   claim.Company,
-		JobTitle:  payload.JobTitle,
-		Role:      model.UserRole(payload.Role),
-		Category:  model.TicketCategoryFK{ID: category.ID.Hex(), Name: category.Name},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
+		ID:                 primitive.NewObjectID(),
+		Name:               payload.Name,
+		Email:              payload.Email,
+		Password:           string(hashedPassword),
+		PasswordChangedAt:  &now,
+		MustChangePassword: true,
+		Company:            claim.Company,
+		JobTitle:           payload.JobTitle,
+		Role:               model.UserRole(payload.Role),
+		Languages:          []string{},
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if payload.Languages != nil {
+		newAgent.Languages = helpers.NormalizeLanguages(*payload.Languages)
+	}
+	helpers.SetAgentCategories(newAgent, categories)
 
 	err = u.mongodbRepo.CreateAgent(ctx, newAgent)
 
@@ -255,11 +300,17 @@ func (u *agentUsecase) UpdateAgent(ctx context.Context, claim domain.JWTClaimAge
 	}
 	if payload.Role == "" {
 		errValidation["role"] = "role field is required"
-	} else if !helpers.InArrayString(payload.Role, []string{"admin", "agent"}) {
-		errValidation["role"] = "role field must be admin or agent"
+	} else if role, err := u._findRole(ctx, claim.CompanyID, model.RoleScopeAgent, payload.Role); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	} else if role == nil {
+		errValidation["role"] = "role not found"
+	} else if allowed, err := u._canGrantRole(ctx, claim, role); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	} else if !allowed {
+		errValidation["role"] = "role has permissions you do not have"
 	}
-	if payload.CategoryId == "" {
-		errValidation["category"] = "category field is required"
+	if payload.CategoryId == "" && len(payload.Categories) == 0 {
+		errValidation["categories"] = "categories field is required"
 	}
 
 	if len(errValidation) > 0 {
@@ -289,15 +340,9 @@ func (u *agentUsecase) UpdateAgent(ctx context.Context, claim domain.JWTClaimAge
 	}
 
 	//check category
-	category, err := u.mongodbRepo.FetchOneTicketCategory(ctx, map[string]interface{}{
-		"id":        payload.CategoryId,
-		"companyID": claim.CompanyID,
-	})
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, err.Error())
-	}
-	if category == nil {
-		return response.Error(http.StatusBadRequest, "category not found")
+	categories, errRes := u._agentCategories(ctx, claim.CompanyID, payload.CategoryId, payload.Categories)
+	if errRes != nil {
+		return *errRes
 	}
 
 	// update agent
@@ -305,19 +350,24 @@ func (u *agentUsecase) UpdateAgent(ctx context.Context, claim domain.JWTClaimAge
 	agent.Email = payload.Email
 	agent.JobTitle = payload.JobTitle
 	agent.Role = model.UserRole(payload.Role)
-	agent.Category = model.TicketCategoryFK{ID: category.ID.Hex(), Name: category.Name}
+	if payload.Languages != nil {
+		agent.Languages = helpers.NormalizeLanguages(*payload.Languages)
+	}
+	helpers.SetAgentCategories(agent, categories)
 	agent.UpdatedAt = time.Now()
 
 	if err := u.mongodbRepo.UpdateOneAgent(
 		ctx,
 		map[string]interface{}{"id": agent.ID},
 		map[string]interface{}{
-			"name":      agent.Name,
-			"jobTitle":  agent.JobTitle,
-			"email":     agent.Email,
-			"role":      agent.Role,
-			"category":  agent.Category,
-			"updatedAt": agent.UpdatedAt,
+			"name":       agent.Name,
+			"jobTitle":   agent.JobTitle,
+			"email":      agent.Email,
+			"role":       agent.Role,
+			"category":   agent.Category,
+			"categories": agent.Categories,
+			"languages":  agent.Languages,
+			"updatedAt":  agent.UpdatedAt,
 		}); err != nil {
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
@@ -358,5 +408,139 @@ func (u *agentUsecase) DeleteAgent(ctx context.Context, claim domain.JWTClaimAge
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
+	// end the sessions of the deleted user
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionAgent, agent.ID.Hex(), model.RevokeUserDeleted); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
 	return response.Success("Agent deleted successfully")
 }
+
+// _agentCategories resolve the requested categories of the company, the legacy categoryId gets the default proficiency
+func (u *agentUsecase) _agentCategories(ctx context.Context, companyID string, categoryID string, requests []domain.AgentCategoryRequest) ([]model.AgentCategory, *response.Base) {
+	if len(requests) == 0 && categoryID != "" {
+		requests = []domain.AgentCategoryRequest{{CategoryId: categoryID, Proficiency: helpers.AgentProficiencyDefault}}
+	}
+
+	errValidation := make(map[string]string)
+	categories := make([]model.AgentCategory, 0)
+	seen := map[string]bool{}
+	for i, request := range requests {
+		key := "categories." + strconv.Itoa(i)
+		if request.Proficiency == 0 {
+			request.Proficiency = helpers.AgentProficiencyDefault
+		}
+		if !helpers.IsValidAgentProficiency(request.Proficiency) {
+			errValidation[key+".proficiency"] = fmt.Sprintf("proficiency must be between %d and %d", helpers.AgentProficiencyMin, helpers.AgentProficiencyMax)
+			continue
+		}
+		if seen[request.CategoryId] {
+			errValidation[key+".categoryId"] = "category is duplicated"
+			continue
+		}
+		seen[request.CategoryId] = true
+
+		category, err := u.mongodbRepo.FetchOneTicketCategory(ctx, map[string]interface{}{
+			"id":        request.CategoryId,
+			"companyID": companyID,
+		})
+		if err != nil {
+			res := response.Error(http.StatusInternalServerError, err.Error())
+			return nil, &res
+		}
+		if category == nil {
+			errValidation[key+".categoryId"] = "category not found"
+			continue
+		}
+
+		categories = append(categories, model.AgentCategory{
+			ID:          category.ID.Hex(),
+			Name:        category.Name,
+			Proficiency: request.Proficiency,
+		})
+	}
+
+	if len(errValidation) > 0 {
+		res := response.ErrorValidation(errValidation, "error validation")
+		return nil, &res
+	}
+
+	return categories, nil
+}
+
+func (u *agentUsecase) BulkUpdateAgentCategory(ctx context.Context, claim domain.JWTClaimAgent, payload domain.BulkAgentCategoryRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	errValidation := make(map[string]string)
+
+	// validating request
+	if len(payload.AgentIds) == 0 {
+		errValidation["agentIds"] = "agentIds field is required"
+	}
+
+	switch payload.Action {
+	case helpers.AgentCategoryAdd, helpers.AgentCategoryRemove, helpers.AgentCategorySet:
+	case "":
+		errValidation["action"] = "action field is required"
+	default:
+		errValidation["action"] = "action field is invalid"
+	}
+
+	if len(payload.Categories) == 0 {
+		errValidation["categories"] = "categories field is required"
+	}
+
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	categories, errRes := u._agentCategories(ctx, claim.CompanyID, "", payload.Categories)
+	if errRes != nil {
+		return *errRes
+	}
+
+	// agents of the company only
+	cur, err := u.mongodbRepo.FetchAgentList(ctx, map[string]interface{}{
+		"ids":       payload.AgentIds,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	defer cur.Close(ctx)
+
+	agents := make([]model.Agent, 0)
+	for cur.Next(ctx) {
+		row := model.Agent{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("Agent Decode ", err)
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		agents = append(agents, row)
+	}
+
+	if len(agents) == 0 {
+		return response.Error(http.StatusBadRequest, "agent not found")
+	}
+
+	now := time.Now()
+	for i := range agents {
+		agent := &agents[i]
+		helpers.SetAgentCategories(agent, helpers.MergeAgentCategories(helpers.GetAgentCategories(*agent), payload.Action, categories))
+		agent.UpdatedAt = now
+
+		if err := u.mongodbRepo.UpdateOneAgent(
+			ctx,
+			map[string]interface{}{"id": agent.ID},
+			map[string]interface{}{
+				"category":   agent.Category,
+				"categories": agent.Categories,
+				"updatedAt":  agent.UpdatedAt,
+			}); err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return response.Success(agents)
+}
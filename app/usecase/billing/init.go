@@ -0,0 +1,24 @@
+package usecase_billing
+
+import (
+	mongorepo "app/app/repository/mongo"
+	s3repo "app/app/repository/s3"
+)
+
+// Billing subscription changes applied once an order is paid, shared by the payment webhook and the superadmin approval
+type Billing struct {
+	mongodbRepo mongorepo.MongoDBRepo
+	s3Repo      s3repo.S3Repo
+}
+
+type RepoInjection struct {
+	MongoDBRepo mongorepo.MongoDBRepo
+	S3Repo      s3repo.S3Repo
+}
+
+func NewBilling(r RepoInjection) *Billing {
+	return &Billing{
+		mongodbRepo: r.MongoDBRepo,
+		s3Repo:      r.S3Repo,
+	}
+}
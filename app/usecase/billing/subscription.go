@@ -0,0 +1,665 @@
+package usecase_billing
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// CreateSubscription activate the paid order, the subscription runs from startAt and expires counting from expireFrom
+func (b *Billing) CreateSubscription(ctx context.Context, config model.Config, order *model.Order, customer *model.Customer, oneHourPackage *model.HourPackage, startAt, expireFrom time.Time) (err error) {
+	// check company
+	company, err := b.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": customer.Company.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if company == nil {
+		return fmt.Errorf("company not found")
+	}
+
+	// generate invoice and receipt
+	documents := helpers.GenerateOrderDocuments(ctx, b.mongodbRepo, b.s3Repo, order, company, &config)
+
+	subscription, err := b._activateSubscription(ctx, config, order, customer, company, oneHourPackage, startAt, expireFrom)
+	if err != nil {
+		return
+	}
+
+	// send email
+	go b.SendEmailCustomerOrder(config, order, customer, company, subscription, documents)
+
+	return
+}
+
+// _activateSubscription write the subscription and balance of the order, it returns the subscription the customer is told about
+func (b *Billing) _activateSubscription(ctx context.Context, config model.Config, order *model.Order, customer *model.Customer, company *model.Company, oneHourPackage *model.HourPackage, startAt, expireFrom time.Time) (subscription *model.CustomerSubscription, err error) {
+	// first paid order after the trial, the order is activated anyway
+	if err := b._convertTrial(ctx, order, customer, company); err != nil {
+		logrus.Errorf("convert trial of order %s: %v", order.OrderNumber, err)
+	}
+
+	// package change replace the current subscription
+	if order.Change != nil {
+		return b.ChangeSubscription(ctx, order, customer, company, oneHourPackage)
+	}
+
+	// renewal order extend the existing subscription
+	if order.Renewal != nil {
+		return b.RenewSubscription(ctx, config, order, customer, oneHourPackage)
+	}
+
+	// hours of pooled company are credited to the company wallet
+	if order.Type == model.HOUR_TYPE && order.IsCompanyWallet {
+		return b._creditCompanyWallet(ctx, order, customer, company, oneHourPackage)
+	}
+
+	// get current time
+	now := time.Now()
+
+	// check active subscription
+	fetchOptions := map[string]interface{}{
+		"customerID": customer.ID.Hex(),
+		"orderType":  order.Type,
+	}
+
+	if order.Type == model.SERVER_TYPE {
+		fetchOptions["serverPackageId"] = order.ServerPackage.ID
+	} else {
+		fetchOptions["status"] = model.Active
+		fetchOptions["isCompanyWallet"] = false
+	}
+
+	activeSubscription, err := b.mongodbRepo.FetchOneCustomerSubscription(ctx, fetchOptions)
+	if err != nil {
+		return
+	}
+
+	//set expiry date
+	expiredAt := expireFrom
+
+	if order.Type == model.HOUR_TYPE {
+		// set active subscription to expired
+		if activeSubscription != nil {
+			activeSubscription.Status = model.Expired
+			activeSubscription.UpdatedAt = now
+
+			// save
+			if err = b.mongodbRepo.UpdateOneCustomerSubscription(ctx, activeSubscription); err != nil {
+				return
+			}
+		}
+
+		expiredAt = helpers.HourSubscriptionExpiredAt(order, expiredAt)
+
+		// add time balance
+		// the billed overage is credited back on top of the package
+		timeBalance := oneHourPackage.Duration.TotalinSeconds + order.OverageSeconds()
+		if customer.Subscription == nil {
+			customer.Subscription = &model.Subscription{}
+		}
+		if balance := customer.Subscription.Balance; balance != nil {
+			timeBalance += balance.Time.Total
+			balance.Time.Total = timeBalance
+		} else {
+			customer.Subscription.Balance = &model.Balance{
+				Time: model.TimeBalance{
+					Total: timeBalance,
+				},
+			}
+		}
+
+		// set subscription to customer
+		customer.Subscription = &model.Subscription{
+			Status: model.Active,
+			HourPackage: &model.HourPackageFK{
+				ID:      oneHourPackage.ID.Hex(),
+				Name:    oneHourPackage.Name,
+				Hours:   oneHourPackage.Duration.Hours,
+				Benefit: oneHourPackage.Benefit,
+				Price:   oneHourPackage.Price,
+			},
+			Balance: customer.Subscription.Balance,
+			StartAt: startAt,
+			EndAt:   expiredAt,
+		}
+		customer.UpdatedAt = now
+
+		// save
+		if err = b.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+			"id": customer.ID.Hex(),
+		}, map[string]interface{}{
+			"subscription": customer.Subscription,
+			"balanceAlert": model.BalanceAlert{},
+			"updatedAt":    customer.UpdatedAt,
+		}); err != nil {
+			return
+		}
+	} else {
+		expiredAt = expiredAt.AddDate(0, 0, int(order.ServerPackage.Validity*order.Amount))
+		if activeSubscription != nil {
+			if activeSubscription.ExpiredAt.Before(now) {
+				expiredAt = now.AddDate(0, 0, int(order.ServerPackage.Validity*order.Amount))
+			} else {
+				expiredAt = activeSubscription.ExpiredAt.AddDate(0, 0, int(order.ServerPackage.Validity*order.Amount))
+			}
+			activeSubscription.Status = model.Active
+			activeSubscription.ExpiredAt = expiredAt
+			activeSubscription.ExtendedBy = append(activeSubscription.ExtendedBy, model.OrderFK{
+				ID:          order.ID.Hex(),
+				OrderNumber: order.OrderNumber,
+				Type:        order.Type,
+			})
+			activeSubscription.UpdatedAt = now
+
+			// save
+			if err = b.mongodbRepo.UpdateOneCustomerSubscription(ctx, activeSubscription); err != nil {
+				return
+			}
+
+			// extend the server, a new one is requested when it was terminated
+			if err = b.SyncServerResource(ctx, activeSubscription, customer); err != nil {
+				return
+			}
+		}
+	}
+
+	if order.Type == model.HOUR_TYPE || activeSubscription == nil {
+		// create customer subscription
+		newCustomerSubscription := &model.CustomerSubscription{
+			ID: primitive.NewObjectID(),
+			Customer: model.CustomerFK{
+				ID:    customer.ID.Hex(),
+				Name:  customer.Name,
+				Email: customer.Email,
+			},
+			HourPackage:   order.HourPackage,
+			ServerPackage: order.ServerPackage,
+			Order: model.OrderFK{
+				ID:          order.ID.Hex(),
+				OrderNumber: order.OrderNumber,
+				Type:        order.Type,
+			},
+			Status:    model.Active,
+			IsTrial:   order.Trial != nil,
+			ExpiredAt: expiredAt,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		// save
+		if err = b.mongodbRepo.CreateCustomerSubscription(ctx, newCustomerSubscription); err != nil {
+			return
+		}
+
+		// request the server for the new subscription
+		if order.Type == model.SERVER_TYPE {
+			if err = b.SyncServerResource(ctx, newCustomerSubscription, customer); err != nil {
+				return
+			}
+		}
+
+		return newCustomerSubscription, nil
+	}
+
+	return activeSubscription, nil
+}
+
+func (b *Billing) _creditCompanyWallet(ctx context.Context, order *model.Order, customer *model.Customer, company *model.Company, oneHourPackage *model.HourPackage) (newCustomerSubscription *model.CustomerSubscription, err error) {
+	now := time.Now()
+	seconds := oneHourPackage.Duration.TotalinSeconds + order.OverageSeconds()
+
+	// wallet may be removed after the order was created
+	if company.Wallet == nil {
+		company.Wallet = &model.CompanyWallet{}
+		if err = b.mongodbRepo.UpdatePartialCompany(ctx, map[string]interface{}{
+			"id": company.ID,
+		}, map[string]interface{}{
+			"wallet": company.Wallet,
+		}); err != nil {
+			return
+		}
+	}
+
+	// add time balance to the wallet
+	if err = b.mongodbRepo.IncrementOneCompany(ctx, company.ID.Hex(), map[string]int64{
+		"wallet.time.total": seconds,
+	}); err != nil {
+		return
+	}
+	company.Wallet.Time.Total += seconds
+
+	// create company wallet history
+	if err = b.mongodbRepo.CreateCustomerBalanceHistory(ctx, helpers.NewCompanyBalanceHistory(company, customer, seconds, 0, model.Reference{
+		UniqueID: order.ID.Hex(),
+		Number:   order.OrderNumber,
+		Type:     model.OrderReference,
+	}, "Purchase "+oneHourPackage.Name)); err != nil {
+		return
+	}
+
+	// keep the purchase on customer subscription, pooled hours do not expire the member balance
+	newCustomerSubscription = &model.CustomerSubscription{
+		ID: primitive.NewObjectID(),
+		Customer: model.CustomerFK{
+			ID:    customer.ID.Hex(),
+			Name:  customer.Name,
+			Email: customer.Email,
+		},
+		HourPackage: order.HourPackage,
+		Order: model.OrderFK{
+			ID:          order.ID.Hex(),
+			OrderNumber: order.OrderNumber,
+			Type:        order.Type,
+		},
+		Status:          model.Active,
+		IsCompanyWallet: true,
+		IsTrial:         order.Trial != nil,
+		ExpiredAt:       helpers.HourSubscriptionExpiredAt(order, now),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	// save
+	if err = b.mongodbRepo.CreateCustomerSubscription(ctx, newCustomerSubscription); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// GrantTrial activate the trial order once per customer or company, the grant is claimed first so a concurrent grant stops there
+func (b *Billing) GrantTrial(ctx context.Context, config model.Config, order *model.Order, customer *model.Customer, company *model.Company, pkg *model.HourPackage, startAt, expireFrom time.Time) (granted bool, err error) {
+	grant := helpers.NewTrialGrant(order, helpers.HourSubscriptionExpiredAt(order, order.CreatedAt))
+	if helpers.IsCompanyTrial(company) {
+		granted, err = b.mongodbRepo.GrantCompanyTrial(ctx, company.ID, grant)
+	} else {
+		granted, err = b.mongodbRepo.GrantCustomerTrial(ctx, customer.ID, grant)
+	}
+	if err != nil || !granted {
+		return
+	}
+
+	// only database writes run in the transaction, it may be retried
+	var subscription *model.CustomerSubscription
+	err = b.mongodbRepo.WithTransaction(ctx, func(ctx context.Context) (err error) {
+		// a retried attempt start again from the stored customer and company
+		storedCustomer, err := b.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+			"id": customer.ID,
+		})
+		if err != nil {
+			return err
+		}
+		storedCompany, err := b.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+			"id": company.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if storedCustomer == nil || storedCompany == nil {
+			return fmt.Errorf("customer or company not found")
+		}
+		*customer, *company = *storedCustomer, *storedCompany
+
+		// create customer subscription like a paid order, the trial is free so no invoice and receipt
+		subscription, err = b._activateSubscription(ctx, config, order, customer, company, pkg, startAt, expireFrom)
+		if err != nil {
+			return err
+		}
+
+		// create customer balance history, company wallet write its own
+		if !order.IsCompanyWallet {
+			if err := b.mongodbRepo.CreateCustomerBalanceHistory(ctx, helpers.NewCustomerBalanceHistory(customer, pkg.Duration.TotalinSeconds, 0, model.Reference{
+				UniqueID: order.ID.Hex(),
+				Number:   order.OrderNumber,
+				Type:     model.OrderReference,
+			}, "Trial "+pkg.Name)); err != nil {
+				return err
+			}
+		}
+
+		return b.mongodbRepo.CreateOrder(ctx, order)
+	})
+	if err != nil {
+		// give the trial back, nothing was granted
+		var errRelease error
+		if helpers.IsCompanyTrial(company) {
+			errRelease = b.mongodbRepo.UpdatePartialCompany(ctx, map[string]interface{}{
+				"id": company.ID,
+			}, map[string]interface{}{
+				"trial": nil,
+			})
+		} else {
+			errRelease = b.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+				"id": customer.ID,
+			}, map[string]interface{}{
+				"trial": nil,
+			})
+		}
+		if errRelease != nil {
+			logrus.Errorf("release trial of order %s: %v", order.OrderNumber, errRelease)
+		}
+		return false, err
+	}
+
+	if helpers.IsCompanyTrial(company) {
+		company.Trial = grant
+	} else {
+		customer.Trial = grant
+	}
+
+	// send email once committed
+	go b.SendEmailCustomerOrder(config, order, customer, company, subscription, nil)
+
+	return true, nil
+}
+
+// RenewSubscription extend the renewed subscription from its expiry, unused hours above the carry-over are forfeited
+func (b *Billing) RenewSubscription(ctx context.Context, config model.Config, order *model.Order, customer *model.Customer, oneHourPackage *model.HourPackage) (subscription *model.CustomerSubscription, err error) {
+	now := time.Now()
+
+	// check renewed subscription
+	subscription, err = b.mongodbRepo.FetchOneCustomerSubscription(ctx, map[string]interface{}{
+		"id": order.Renewal.SubscriptionID,
+	})
+	if err != nil {
+		return
+	}
+	if subscription == nil {
+		return nil, fmt.Errorf("customer subscription not found")
+	}
+
+	// paid during the grace period still extend from the expiry, lapsed subscription start again from now
+	startAt := subscription.ExpiredAt
+	if subscription.Status != model.Active {
+		startAt = now
+	}
+
+	expiredAt := startAt
+	if order.Type == model.HOUR_TYPE {
+		expiredAt = startAt.AddDate(0, helpers.GetSubscriptionDuration(), 0)
+
+		if customer.Subscription == nil {
+			customer.Subscription = &model.Subscription{}
+		}
+		balance := customer.Subscription.Balance
+		if balance == nil {
+			balance = &model.Balance{}
+			customer.Subscription.Balance = balance
+		}
+
+		// unused time above the carry-over is forfeited
+		remaining := balance.Time.Total - balance.Time.Used
+		if forfeited := remaining - helpers.RenewalCarryOver(config, remaining); forfeited > 0 {
+			balance.Time.Used += forfeited
+			if err = b.mongodbRepo.CreateCustomerBalanceHistory(ctx, helpers.NewCustomerBalanceHistory(customer, 0, forfeited, model.Reference{
+				UniqueID: subscription.ID.Hex(),
+				Number:   subscription.Order.OrderNumber,
+				Type:     model.ExpiredReference,
+			}, "Unused time not carried over")); err != nil {
+				return
+			}
+		}
+
+		// add time balance
+		balance.Time.Total += oneHourPackage.Duration.TotalinSeconds + order.OverageSeconds()
+
+		customer.Subscription = &model.Subscription{
+			Status:      model.Active,
+			HourPackage: order.HourPackage,
+			Balance:     balance,
+			StartAt:     now,
+			EndAt:       expiredAt,
+		}
+		customer.UpdatedAt = now
+
+		// save
+		if err = b.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+			"id": customer.ID.Hex(),
+		}, map[string]interface{}{
+			"subscription": customer.Subscription,
+			"balanceAlert": model.BalanceAlert{},
+			"updatedAt":    customer.UpdatedAt,
+		}); err != nil {
+			return
+		}
+	} else {
+		expiredAt = startAt.AddDate(0, 0, int(order.ServerPackage.Validity*order.Amount))
+	}
+
+	// extend subscription
+	subscription.Status = model.Active
+	subscription.ExpiredAt = expiredAt
+	subscription.Order = model.OrderFK{
+		ID:          order.ID.Hex(),
+		OrderNumber: order.OrderNumber,
+		Type:        order.Type,
+	}
+	subscription.Renewal = nil
+	subscription.GraceUntil = nil
+	subscription.UpdatedAt = now
+
+	// save
+	if err = b.mongodbRepo.UpdateOneCustomerSubscription(ctx, subscription); err != nil {
+		return
+	}
+
+	if order.Type == model.SERVER_TYPE {
+		if err = b.SyncServerResource(ctx, subscription, customer); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// ChangeSubscription replace the current subscription with the ordered package, the credited unused time leaves the balance
+func (b *Billing) ChangeSubscription(ctx context.Context, order *model.Order, customer *model.Customer, company *model.Company, oneHourPackage *model.HourPackage) (newCustomerSubscription *model.CustomerSubscription, err error) {
+	now := time.Now()
+	expiredAt := now.AddDate(0, helpers.GetSubscriptionDuration(), 0)
+
+	// check replaced subscription
+	subscription, err := b.mongodbRepo.FetchOneCustomerSubscription(ctx, map[string]interface{}{
+		"id": order.Change.SubscriptionID,
+	})
+	if err != nil {
+		return
+	}
+
+	newCustomerSubscription = &model.CustomerSubscription{
+		ID: primitive.NewObjectID(),
+		Customer: model.CustomerFK{
+			ID:    customer.ID.Hex(),
+			Name:  customer.Name,
+			Email: customer.Email,
+		},
+		HourPackage: order.HourPackage,
+		Order: model.OrderFK{
+			ID:          order.ID.Hex(),
+			OrderNumber: order.OrderNumber,
+			Type:        order.Type,
+		},
+		Status:    model.Active,
+		ExpiredAt: expiredAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err = b.mongodbRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		// expire replaced subscription, auto renewal moves to the new package
+		if subscription != nil && subscription.Status == model.Active {
+			newCustomerSubscription.AutoRenew = subscription.AutoRenew
+
+			subscription.Status = model.Expired
+			subscription.AutoRenew = false
+			subscription.Renewal = nil
+			subscription.GraceUntil = nil
+			subscription.UpdatedAt = now
+			if err := b.mongodbRepo.UpdateOneCustomerSubscription(ctx, subscription); err != nil {
+				return err
+			}
+		}
+
+		if customer.Subscription == nil {
+			customer.Subscription = &model.Subscription{}
+		}
+		balance := customer.Subscription.Balance
+		if balance == nil {
+			balance = &model.Balance{}
+			customer.Subscription.Balance = balance
+		}
+
+		// unused time credited on the order leaves the balance, the time used since the order was created is already gone
+		unused := helpers.PackageChangeUnused(company, customer, &order.Change.From)
+		if unused > order.Change.UnusedSeconds {
+			unused = order.Change.UnusedSeconds
+		}
+		order.Change.UnusedSeconds = unused
+		if unused > 0 {
+			balance.Time.Used += unused
+			if err := b.mongodbRepo.CreateCustomerBalanceHistory(ctx, helpers.NewCustomerBalanceHistory(customer, 0, unused, model.Reference{
+				UniqueID: order.ID.Hex(),
+				Number:   order.OrderNumber,
+				Type:     model.OrderReference,
+			}, "Package change from "+order.Change.From.Name)); err != nil {
+				return err
+			}
+		}
+
+		// add time balance, the credit above the order total comes as time of the new package
+		balance.Time.Total += oneHourPackage.Duration.TotalinSeconds + order.OverageSeconds() + order.Change.CarrySeconds
+
+		customer.Subscription = &model.Subscription{
+			Status:      model.Active,
+			HourPackage: order.HourPackage,
+			Balance:     balance,
+			StartAt:     now,
+			EndAt:       expiredAt,
+		}
+		customer.UpdatedAt = now
+
+		if err := b.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+			"id": customer.ID.Hex(),
+		}, map[string]interface{}{
+			"subscription": customer.Subscription,
+			"balanceAlert": model.BalanceAlert{},
+			"updatedAt":    customer.UpdatedAt,
+		}); err != nil {
+			return err
+		}
+
+		return b.mongodbRepo.CreateCustomerSubscription(ctx, newCustomerSubscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func (b *Billing) SendEmailCustomerOrder(config model.Config, order *model.Order, customer *model.Customer, company *model.Company, customerSubscription *model.CustomerSubscription, documents []helpers.OrderDocumentFile) (err error) {
+	loginLink := helpers.StringReplacer(config.LoginLink, map[string]string{
+		"base_url_frontend": company.Settings.Domain.FullUrl,
+	})
+
+	//get package name
+	var packageName string
+	if order.Type == model.HOUR_TYPE {
+		packageName = order.HourPackage.Name
+	} else {
+		packageName = order.ServerPackage.Name
+	}
+
+	// send email
+	mail := helpers.NewSMTPMailer(company)
+	mail.To([]string{customer.Email})
+	mail.Subject(config.Email.Template.PackageActivated.Title)
+	mail.Body(helpers.StringReplacer(config.Email.Template.PackageActivated.Body, map[string]string{
+		"package_type":   cases.Title(language.English).String(string(order.Type)),
+		"package_name":   packageName,
+		"price":          helpers.FormatOrderPrice(order, &config),
+		"payment_method": order.Invoice.PaymentMethod,
+		"order_number":   order.OrderNumber,
+		"purchase_date":  order.CreatedAt.Format("2006-01-02"),
+		"expire_date":    customerSubscription.ExpiredAt.Format("2006-01-02"),
+		"login_link":     loginLink,
+		"customer_name":  order.Customer.Name,
+	}))
+
+	// attach invoice and receipt
+	for _, document := range documents {
+		mail.Attachment(bytes.NewReader(document.Content), document.Name, "application/pdf")
+	}
+
+	// send
+	if err := mail.Send(); err != nil {
+		logrus.Errorf("Send Email to %s error %v", customer.Email, err)
+	}
+
+	return
+}
+
+// SyncServerResource request the server of the subscription or extend the live one, a suspended server is resumed by the cron
+func (b *Billing) SyncServerResource(ctx context.Context, subscription *model.CustomerSubscription, customer *model.Customer) (err error) {
+	resource, err := b.mongodbRepo.FetchOneServerResource(ctx, map[string]interface{}{
+		"subscriptionID": subscription.ID.Hex(),
+		"status":         helpers.LiveServerStatus(),
+	})
+	if err != nil {
+		return
+	}
+	if resource == nil {
+		return b.mongodbRepo.CreateServerResource(ctx, helpers.NewServerResource(subscription, customer))
+	}
+
+	resource.ExpiredAt = subscription.ExpiredAt
+	resource.UpdatedAt = time.Now()
+	return b.mongodbRepo.UpdateOneServerResource(ctx, resource)
+}
+
+// _convertTrial mark the trial of the customer or its company as converted on the first paid order
+func (b *Billing) _convertTrial(ctx context.Context, order *model.Order, customer *model.Customer, company *model.Company) (err error) {
+	grant := helpers.TrialGrantOf(company, customer)
+	if order.Trial != nil || grant == nil || grant.ConvertedAt != nil {
+		return
+	}
+
+	now := time.Now()
+	grant.ConvertedAt = &now
+	if helpers.IsCompanyTrial(company) {
+		err = b.mongodbRepo.UpdatePartialCompany(ctx, map[string]interface{}{
+			"id": company.ID,
+		}, map[string]interface{}{
+			"trial.convertedAt": now,
+		})
+	} else {
+		err = b.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+			"id": customer.ID,
+		}, map[string]interface{}{
+			"trial.convertedAt": now,
+		})
+	}
+	if err != nil {
+		return
+	}
+
+	// the trial order is counted on the superadmin dashboard
+	_, err = b.mongodbRepo.UpdatePartialOrder(ctx, map[string]interface{}{
+		"id":             grant.Order.ID,
+		"trial":          true,
+		"trialConverted": false,
+	}, map[string]interface{}{
+		"trial.convertedAt": now,
+		"updatedAt":         now,
+	})
+	return
+}
@@ -9,8 +9,6 @@ import (
 	"time"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
@@ -51,6 +49,13 @@ func (u *appUsecase) Login(ctx context.Context, payload domain.LoginRequest) res
 		return response.Error(http.StatusBadRequest, "company not found")
 	}
 
+	// slow down repeated failures on the account or from the same address
+	throttle := helpers.NewLoginThrottle(u.redisRepo)
+	if wait := throttle.Wait(ctx, model.SessionCustomer, payload.Email, payload.IPAddress); wait > 0 {
+		u._recordSecurityEvent(ctx, model.SecurityLoginThrottled, company.ID.Hex(), model.UserNested{Email: payload.Email}, payload, "too many attempts")
+		return response.Error(http.StatusTooManyRequests, helpers.LoginWaitMessage(wait))
+	}
+
 	// check the db
 	user, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
 		"email": payload.Email,
@@ -61,9 +66,17 @@ func (u *appUsecase) Login(ctx context.Context, payload domain.LoginRequest) res
 	}
 
 	if user == nil {
+		throttle.Fail(ctx, model.SessionCustomer, payload.Email, payload.IPAddress)
+		u._recordSecurityEvent(ctx, model.SecurityLoginFailed, company.ID.Hex(), model.UserNested{Email: payload.Email}, payload, "user not found")
 		return response.Error(http.StatusBadRequest, "user not found")
 	}
 
+	if user.Lockout.IsActive() {
+		nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+		u._recordSecurityEvent(ctx, model.SecurityLoginFailed, user.Company.ID, nested, payload, "account locked")
+		return response.Error(http.StatusForbidden, "account is locked, check your email to unlock it or try again later")
+	}
+
 	// check companyProduct
 	// companyProduct, err := u.mongodbRepo.FetchOneCompanyProduct(ctx, map[string]interface{}{
 	// 	"id": user.CompanyProduct.ID,
@@ -78,35 +91,31 @@ func (u *appUsecase) Login(ctx context.Context, payload domain.LoginRequest) res
 
 	// check password
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password)); err != nil {
-		return response.Error(http.StatusBadRequest, "Wrong password")
+		return u._loginFailed(ctx, throttle, user, company, payload)
 	}
 
 	if !user.IsVerified {
 		return response.Error(http.StatusBadRequest, "user email not verified")
 	}
 
+	throttle.Reset(ctx, model.SessionCustomer, payload.Email)
+	if user.Lockout != nil {
+		// the expired lockout is cleared on the first good login
+		u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{"id": user.ID}, map[string]interface{}{"lockout": nil})
+		user.Lockout = nil
+	}
+	u._recordSecurityEvent(ctx, model.SecurityLoginSuccess, user.Company.ID, model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}, payload, "")
+
 	// generate token
-	tokenString, err := helpers.GenerateJWTTokenCustomer(domain.JWTClaimUser{
-
-		UserID:    user.ID.Hex(),
-		CompanyID: user.Company.ID,
-		// CompanyProductID: user.CompanyProduct.ID,
-		Role: string(user.Role),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ID:        uuid.NewString(),
-			Issuer:    "member",
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(helpers.GetJWTTTL()) * time.Minute)),
-		},
-	})
+	tokenString, refreshToken, err := u._issueToken(ctx, user)
 	if err != nil {
 		return response.Error(http.StatusBadRequest, err.Error())
 	}
 
 	return response.Success(map[string]interface{}{
-		"user":  user,
-		"token": tokenString,
+		"user":         user,
+		"token":        tokenString,
+		"refreshToken": refreshToken,
 	})
 }
 
@@ -126,6 +135,8 @@ func (u *appUsecase) Register(ctx context.Context, payload domain.RegisterReques
 
 	if payload.Password == "" {
 		errValidation["password"] = "password field is required"
+	} else if msg := helpers.ValidatePassword(helpers.GetPasswordPolicy(u._CacheConfig(ctx)), payload.Password, payload.Email); msg != "" {
+		errValidation["password"] = msg
 	}
 
 	if payload.AccessKey == "" {
@@ -217,6 +228,10 @@ func (u *appUsecase) Register(ctx context.Context, payload domain.RegisterReques
 			return response.Success(existingUser)
 		}
 
+		if existingUser.IsGuest {
+			return response.Error(http.StatusBadRequest, "email already used to submit a ticket, request a password reset to activate the account")
+		}
+
 		return response.Error(http.StatusBadRequest, "email already taken")
 	}
 
@@ -250,14 +265,15 @@ func (u *appUsecase) Register(ctx context.Context, payload domain.RegisterReques
 		Email:   payload.Email,
 		Company: newCompanyNested,
 		// CompanyProduct: newCompanyProduct,
-		Password:      string(hashedPassword),
-		Role:          model.AdminRole,
-		IsNeedBalance: true,
-		Subscription:  &subscription,
-		Token:         defaultToken,
-		IsVerified:    defaultVerified,
-		CreatedAt:     now,
-		UpdatedAt:     now,
+		Password:          string(hashedPassword),
+		PasswordChangedAt: &now,
+		Role:              model.AdminRole,
+		IsNeedBalance:     true,
+		Subscription:      &subscription,
+		Token:             defaultToken,
+		IsVerified:        defaultVerified,
+		CreatedAt:         now,
+		UpdatedAt:         now,
 	}
 
 	err = u.mongodbRepo.CreateCustomer(ctx, &newUser)
@@ -266,6 +282,11 @@ func (u *appUsecase) Register(ctx context.Context, payload domain.RegisterReques
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
+	// free trial hours
+	if err := u._grantTrial(ctx, &newUser, company); err != nil {
+		logrus.Error("grant trial: ", err)
+	}
+
 	// need verify email
 	if config.Registration.VerifyEmail {
 		go _sendEmailRegistration(config, newUser, company)
@@ -429,9 +450,6 @@ func (u *appUsecase) PasswordReset(ctx context.Context, payload domain.PasswordR
 	if payload.Password == "" {
 		errValidation["password"] = "password field is required"
 	}
-	if len(payload.Password) < 8 {
-		errValidation["password"] = "password must be at least 8 characters"
-	}
 	if len(errValidation) > 0 {
 		return response.ErrorValidation(errValidation, "error validation")
 	}
@@ -448,12 +466,26 @@ func (u *appUsecase) PasswordReset(ctx context.Context, payload domain.PasswordR
 		return response.Error(http.StatusBadRequest, "password reset token not valid")
 	}
 
+	policy := helpers.GetPasswordPolicy(u._CacheConfig(ctx))
+	if msg := helpers.ValidatePassword(policy, payload.Password, user.Email); msg != "" {
+		return response.ErrorValidation(map[string]string{"password": msg}, "error validation")
+	}
+	if helpers.IsPasswordReused(policy, payload.Password, user.Password, user.PasswordHistory) {
+		return response.ErrorValidation(map[string]string{
+			"password": "password was used recently, choose a different one",
+		}, "error validation")
+	}
+
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
 
 	// update user
+	now := time.Now()
 	user.PasswordResetToken = ""
+	user.PasswordHistory = helpers.NextPasswordHistory(policy, user.Password, user.PasswordHistory)
 	user.Password = string(hashedPassword)
-	user.UpdatedAt = time.Now()
+	user.PasswordChangedAt = &now
+	user.MustChangePassword = false
+	user.UpdatedAt = now
 
 	// save
 	u.mongodbRepo.UpdateOneCustomer(context.Background(), map[string]interface{}{
@@ -461,9 +493,28 @@ func (u *appUsecase) PasswordReset(ctx context.Context, payload domain.PasswordR
 	}, map[string]interface{}{
 		"passwordResetToken": user.PasswordResetToken,
 		"password":           user.Password,
+		"passwordHistory":    user.PasswordHistory,
+		"passwordChangedAt":  user.PasswordChangedAt,
+		"mustChangePassword": false,
 		"updatedAt":          user.UpdatedAt,
 	})
 
+	// the guest of a public ticket submission proved the email, it becomes a regular account
+	if user.IsGuest {
+		u.mongodbRepo.UpdateOneCustomer(context.Background(), map[string]interface{}{
+			"id": user.ID,
+		}, map[string]interface{}{
+			"isGuest":    false,
+			"isVerified": true,
+			"verifiedAt": now,
+		})
+	}
+
+	// end the sessions signed in with the old password
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionCustomer, user.ID.Hex(), model.RevokePasswordChanged); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
 	return response.Success(user)
 }
 
@@ -505,6 +556,8 @@ func (u *appUsecase) RegisterB2B(ctx context.Context, payload domain.RegisterReq
 
 	if payload.Password == "" {
 		errValidation["password"] = "password field is required"
+	} else if msg := helpers.ValidatePassword(helpers.GetPasswordPolicy(u._CacheConfig(ctx)), payload.Password, payload.Email); msg != "" {
+		errValidation["password"] = msg
 	}
 
 	// if payload.CompanyProductId == "" {
@@ -595,6 +648,10 @@ func (u *appUsecase) RegisterB2B(ctx context.Context, payload domain.RegisterReq
 			return response.Success(existingUser)
 		}
 
+		if existingUser.IsGuest {
+			return response.Error(http.StatusBadRequest, "email already used to submit a ticket, request a password reset to activate the account")
+		}
+
 		return response.Error(http.StatusBadRequest, "email already taken")
 	}
 
@@ -607,6 +664,7 @@ func (u *appUsecase) RegisterB2B(ctx context.Context, payload domain.RegisterReq
 		defaultToken = helpers.RandomString(64)
 	}
 
+	now := time.Now()
 	newUser := model.Customer{
 
 		ID:      primitive.NewObjectID(),
@@ -614,12 +672,13 @@ func (u *appUsecase) RegisterB2B(ctx context.Context, payload domain.RegisterReq
 		Email:   payload.Email,
 		Company: newCompanyNested,
 		// CompanyProduct: newCompanyProduct,
-		Password:   string(hashedPassword),
-		Role:       model.CustomerRole,
-		Token:      defaultToken,
-		IsVerified: defaultVerified,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		Password:          string(hashedPassword),
+		PasswordChangedAt: &now,
+		Role:              model.CustomerRole,
+		Token:             defaultToken,
+		IsVerified:        defaultVerified,
+		CreatedAt:         now,
+		UpdatedAt:         now,
 	}
 
 	err = u.mongodbRepo.CreateCustomer(ctx, &newUser)
@@ -628,6 +687,11 @@ func (u *appUsecase) RegisterB2B(ctx context.Context, payload domain.RegisterReq
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
+	// free trial hours
+	if err := u._grantTrial(ctx, &newUser, company); err != nil {
+		logrus.Error("grant trial: ", err)
+	}
+
 	// need verify email
 	if config.Registration.VerifyEmail {
 		go _sendEmailRegistration(config, newUser, company)
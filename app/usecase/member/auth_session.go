@@ -0,0 +1,118 @@
+package usecase_member
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+)
+
+// _issueToken start a session for the customer and sign its first access token
+func (u *appUsecase) _issueToken(ctx context.Context, user *model.Customer) (token string, refreshToken string, err error) {
+	session, refreshToken := helpers.NewAuthSession(model.SessionCustomer, model.UserNested{
+		ID:    user.ID.Hex(),
+		Name:  user.Name,
+		Email: user.Email,
+	}, user.Company.ID)
+	if err = u.mongodbRepo.CreateAuthSession(ctx, session); err != nil {
+		return
+	}
+
+	token, err = u._generateToken(user, session.ID.Hex())
+	return
+}
+
+func (u *appUsecase) _generateToken(user *model.Customer, sessionID string) (string, error) {
+	return helpers.GenerateJWTTokenCustomer(domain.JWTClaimUser{
+		UserID:           user.ID.Hex(),
+		CompanyID:        user.Company.ID,
+		Role:             string(user.Role),
+		SessionID:        sessionID,
+		RegisteredClaims: helpers.NewAccessTokenClaims("member"),
+	})
+}
+
+// RefreshToken rotate the refresh token and sign a new access token, a rotated token used again ends the session
+func (u *appUsecase) RefreshToken(ctx context.Context, payload domain.RefreshTokenRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.RefreshToken == "" {
+		errValidation["refreshToken"] = "refreshToken field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	session, refreshToken, err := helpers.RefreshAuthSession(ctx, u.mongodbRepo, u.redisRepo, model.SessionCustomer, payload.RefreshToken)
+	if helpers.IsRefreshRejected(err) {
+		return response.Error(http.StatusUnauthorized, err.Error())
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	// check user
+	user, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": session.User.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if user == nil {
+		return response.Error(http.StatusUnauthorized, "user not found")
+	}
+
+	token, err := u._generateToken(user, session.ID.Hex())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"token":        token,
+		"refreshToken": refreshToken,
+	})
+}
+
+func (u *appUsecase) Logout(ctx context.Context, claim domain.JWTClaimUser) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check session
+	session, err := u.mongodbRepo.FetchOneAuthSession(ctx, map[string]interface{}{
+		"id":       claim.SessionID,
+		"userType": string(model.SessionCustomer),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if session == nil {
+		return response.Error(http.StatusBadRequest, "session not found")
+	}
+
+	if err := helpers.RevokeStoredAuthSession(ctx, u.mongodbRepo, u.redisRepo, session, model.RevokeLogout); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(session)
+}
+
+// LogoutAll end every session of the user, including the current one
+func (u *appUsecase) LogoutAll(ctx context.Context, claim domain.JWTClaimUser) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	total, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionCustomer, claim.UserID, model.RevokeLogoutAll)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"total": total,
+	})
+}
@@ -0,0 +1,154 @@
+package usecase_member
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+func (u *appUsecase) GetCompanyWallet(ctx context.Context, claim domain.JWTClaimUser) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+	if !helpers.IsPooledWallet(company) {
+		return response.Error(http.StatusBadRequest, "company wallet is not active")
+	}
+
+	return response.Success(helpers.CompanyWalletFormat(company).Wallet)
+}
+
+func (u *appUsecase) GetCompanyWalletHistoryList(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	page, limit, offset := yurekahelpers.GetLimitOffset(query)
+
+	fetchOptions := map[string]interface{}{
+		"limit":     limit,
+		"offset":    offset,
+		"companyID": claim.CompanyID,
+		"wallet":    model.WalletCompany,
+		"sort":      "createdAt",
+		"dir":       "desc",
+	}
+
+	// filtering
+	if query.Get("sort") != "" {
+		fetchOptions["sort"] = query.Get("sort")
+	}
+	if query.Get("dir") != "" {
+		fetchOptions["dir"] = query.Get("dir")
+	}
+	if query.Get("customerId") != "" {
+		fetchOptions["customerID"] = query.Get("customerId")
+	}
+	if query.Get("referenceType") != "" {
+		fetchOptions["referenceType"] = model.ReferenceType(query.Get("referenceType"))
+	}
+
+	// count first
+	totalDocuments := u.mongodbRepo.CountCustomerBalanceHistory(ctx, fetchOptions)
+
+	if totalDocuments == 0 {
+		return response.Success(domain.ResponseList{
+			List: response.List{
+				List:  []interface{}{},
+				Page:  page,
+				Limit: limit,
+				Total: totalDocuments,
+			},
+			TotalPage: helpers.GetTotalPage(totalDocuments, limit),
+		})
+	}
+
+	cur, err := u.mongodbRepo.FetchCustomerBalanceHistoryList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.CustomerBalanceHistory{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("CustomerBalanceHistory Decode ", err)
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+
+		list = append(list, row)
+	}
+
+	return response.Success(domain.ResponseList{
+		List: response.List{
+			List:  list,
+			Page:  page,
+			Limit: limit,
+			Total: totalDocuments,
+		},
+		TotalPage: helpers.GetTotalPage(totalDocuments, limit),
+	})
+}
+
+func (u *appUsecase) UpdateUserWalletCap(ctx context.Context, claim domain.JWTClaimUser, options map[string]interface{}) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	payload := options["payload"].(domain.UpdateWalletCapRequest)
+
+	// validating request
+	if payload.Limit < 0 {
+		return response.ErrorValidation(map[string]string{
+			"limit": "limit field must be 0 or greater",
+		}, "error validation")
+	}
+
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id":        options["id"],
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	// update cap
+	customer.WalletCap.Limit = payload.Limit
+	if payload.ResetUsed {
+		customer.WalletCap.Used = 0
+	}
+	customer.UpdatedAt = time.Now()
+
+	if err := u.mongodbRepo.UpdateOneCustomer(
+		ctx,
+		map[string]interface{}{"id": customer.ID},
+		map[string]interface{}{
+			"walletCap": customer.WalletCap,
+			"updatedAt": customer.UpdatedAt,
+		}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(customer)
+}
@@ -0,0 +1,145 @@
+package usecase_member
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+func (u *appUsecase) GetBalanceHistoryList(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	page, limit, offset := yurekahelpers.GetLimitOffset(query)
+
+	fetchOptions := map[string]interface{}{
+		"limit":      limit,
+		"offset":     offset,
+		"customerID": claim.UserID,
+		"sort":       "createdAt",
+		"dir":        "desc",
+	}
+
+	// filtering
+	if query.Get("sort") != "" {
+		fetchOptions["sort"] = query.Get("sort")
+	}
+	if query.Get("dir") != "" {
+		fetchOptions["dir"] = query.Get("dir")
+	}
+	if query.Get("referenceType") != "" {
+		fetchOptions["referenceType"] = model.ReferenceType(query.Get("referenceType"))
+	}
+	if query.Get("wallet") != "" {
+		fetchOptions["wallet"] = model.WalletType(query.Get("wallet"))
+	}
+
+	// count first
+	totalDocuments := u.mongodbRepo.CountCustomerBalanceHistory(ctx, fetchOptions)
+
+	if totalDocuments == 0 {
+		return response.Success(domain.ResponseList{
+			List: response.List{
+				List:  []interface{}{},
+				Page:  page,
+				Limit: limit,
+				Total: totalDocuments,
+			},
+			TotalPage: helpers.GetTotalPage(totalDocuments, limit),
+		})
+	}
+
+	cur, err := u.mongodbRepo.FetchCustomerBalanceHistoryList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.CustomerBalanceHistory{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("CustomerBalanceHistory Decode ", err)
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+
+		list = append(list, row)
+	}
+
+	return response.Success(domain.ResponseList{
+		List: response.List{
+			List:  list,
+			Page:  page,
+			Limit: limit,
+			Total: totalDocuments,
+		},
+		TotalPage: helpers.GetTotalPage(totalDocuments, limit),
+	})
+}
+
+func (u *appUsecase) GetBalanceStatement(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// month format YYYY-MM, default current month
+	month := query.Get("month")
+	if month == "" {
+		month = time.Now().Format("2006-01")
+	}
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return response.ErrorValidation(map[string]string{
+			"month": "invalid month format, use YYYY-MM",
+		}, "error validation")
+	}
+	end := start.AddDate(0, 1, 0)
+
+	// opening balance is everything recorded before the month
+	in, out, err := u.mongodbRepo.SumCustomerBalanceHistory(ctx, map[string]interface{}{
+		"customerID":    claim.UserID,
+		"createdBefore": start,
+		"wallet":        model.WalletCustomer,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	cur, err := u.mongodbRepo.FetchCustomerBalanceHistoryList(ctx, map[string]interface{}{
+		"customerID":    claim.UserID,
+		"createdFrom":   start,
+		"createdBefore": end,
+		"wallet":        model.WalletCustomer,
+		"sort":          "createdAt",
+		"dir":           "asc",
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	defer cur.Close(ctx)
+
+	rows := make([]model.CustomerBalanceHistory, 0)
+	if err := cur.All(ctx, &rows); err != nil {
+		logrus.Error("CustomerBalanceHistory Decode ", err)
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	file, err := helpers.GenerateBalanceStatement(in-out, rows)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(domain.BalanceStatementResponse{
+		FileName: "statement-" + month + ".xlsx",
+		File:     file,
+	})
+}
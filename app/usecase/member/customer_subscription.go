@@ -102,7 +102,7 @@ func (u *appUsecase) GetCustomerSubscriptionDetail(ctx context.Context, claim do
 	}
 
 	// update order status if expired
-	if customerSubscription.Status == model.Active && customerSubscription.ExpiredAt.Before(time.Now()) {
+	if customerSubscription.Status == model.Active && customerSubscription.EndsAt().Before(time.Now()) {
 		customerSubscription.Status = model.Expired
 		customerSubscription.UpdatedAt = time.Now()
 
@@ -115,3 +115,115 @@ func (u *appUsecase) GetCustomerSubscriptionDetail(ctx context.Context, claim do
 
 	return response.Success(customerSubscription)
 }
+
+func (u *appUsecase) EnableAutoRenewal(ctx context.Context, claim domain.JWTClaimUser, customerSubscriptionID string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check customer subscription
+	customerSubscription, err := u.mongodbRepo.FetchOneCustomerSubscription(ctx, map[string]interface{}{
+		"id":         customerSubscriptionID,
+		"customerID": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customerSubscription == nil {
+		return response.Error(http.StatusBadRequest, "customer subscription not found")
+	}
+
+	if customerSubscription.Status != model.Active {
+		return response.Error(http.StatusBadRequest, "only active subscription can be renewed")
+	}
+
+	if customerSubscription.IsCompanyWallet {
+		return response.Error(http.StatusBadRequest, "company wallet subscription can't be renewed automatically")
+	}
+
+	if customerSubscription.IsTrial {
+		return response.Error(http.StatusBadRequest, "trial subscription can't be renewed")
+	}
+
+	if customerSubscription.AutoRenew {
+		return response.Error(http.StatusBadRequest, "auto renewal already enabled")
+	}
+
+	// update
+	customerSubscription.AutoRenew = true
+	customerSubscription.UpdatedAt = time.Now()
+	if err := u.mongodbRepo.UpdateOneCustomerSubscription(ctx, customerSubscription); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(customerSubscription)
+}
+
+func (u *appUsecase) CancelRenewal(ctx context.Context, claim domain.JWTClaimUser, customerSubscriptionID string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check customer subscription
+	customerSubscription, err := u.mongodbRepo.FetchOneCustomerSubscription(ctx, map[string]interface{}{
+		"id":         customerSubscriptionID,
+		"customerID": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customerSubscription == nil {
+		return response.Error(http.StatusBadRequest, "customer subscription not found")
+	}
+
+	if !customerSubscription.AutoRenew {
+		return response.Error(http.StatusBadRequest, "auto renewal is not enabled")
+	}
+
+	// expire the renewal order waiting for payment
+	if customerSubscription.Renewal != nil {
+		order, err := u.mongodbRepo.FetchOneOrder(ctx, map[string]interface{}{
+			"id": customerSubscription.Renewal.Order.ID,
+		})
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+
+		if order != nil && order.Status == model.STATUS_PENDING {
+			if order.Invoice.InvoiceXenditId != "" {
+				if result, err := u.xenditRepo.ExpireInvoice(ctx, order.Invoice.InvoiceXenditId); err != nil {
+					logrus.Errorf("ExpireInvoice %s: %v", order.OrderNumber, err)
+				} else if result.Status != 200 {
+					logrus.Errorf("ExpireInvoice %s: %s", order.OrderNumber, result.Message)
+				}
+			}
+
+			if _, err := u.mongodbRepo.UpdatePartialOrder(ctx, map[string]interface{}{
+				"id":     order.ID,
+				"status": []string{string(model.STATUS_PENDING)},
+			}, map[string]interface{}{
+				"status":         model.STATUS_EXPIRED,
+				"payment.status": string(model.STATUS_EXPIRED),
+				"updatedAt":      time.Now(),
+			}); err != nil {
+				return response.Error(http.StatusInternalServerError, err.Error())
+			}
+		}
+	}
+
+	// the grace period ends with the renewal
+	customerSubscription.AutoRenew = false
+	customerSubscription.Renewal = nil
+	customerSubscription.GraceUntil = nil
+	customerSubscription.UpdatedAt = time.Now()
+	if err := u.mongodbRepo.UpdatePartialCustomerSubscription(ctx, map[string]interface{}{
+		"id": customerSubscription.ID,
+	}, map[string]interface{}{
+		"autoRenew":  customerSubscription.AutoRenew,
+		"renewal":    customerSubscription.Renewal,
+		"graceUntil": customerSubscription.GraceUntil,
+		"updatedAt":  customerSubscription.UpdatedAt,
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(customerSubscription)
+}
@@ -23,6 +23,7 @@ func (u *appUsecase) GetHourPackageList(ctx context.Context, claim domain.JWTCla
 		"limit":  limit,
 		"offset": offset,
 		"status": string(model.HourPackageActive),
+		"type":   string(model.HourPackageTypeRegular),
 	}
 
 	// filtering
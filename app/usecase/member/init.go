@@ -5,6 +5,7 @@ import (
 	redisrepo "app/app/repository/redis"
 	s3repo "app/app/repository/s3"
 	xenditrepo "app/app/repository/xendit"
+	usecase_billing "app/app/usecase/billing"
 	"app/domain"
 	"net/http"
 	"net/url"
@@ -21,6 +22,7 @@ type appUsecase struct {
 	redisRepo      redisrepo.RedisRepo
 	s3Repo         s3repo.S3Repo
 	xenditRepo     xenditrepo.XenditRepo
+	billing        *usecase_billing.Billing
 }
 
 type RepoInjection struct {
@@ -37,6 +39,10 @@ func NewAppUsecase(r RepoInjection, timeout time.Duration) AppUsecase {
 		redisRepo:      r.Redis,
 		s3Repo:         r.S3Repo,
 		xenditRepo:     r.XenditRepo,
+		billing: usecase_billing.NewBilling(usecase_billing.RepoInjection{
+			MongoDBRepo: r.MongoDBRepo,
+			S3Repo:      r.S3Repo,
+		}),
 	}
 }
 
@@ -48,11 +54,25 @@ type AppUsecase interface {
 	SendEmailPasswordReset(ctx context.Context, payload domain.EmailPasswordResetRequest) response.Base
 	PasswordReset(ctx context.Context, payload domain.PasswordResetRequest) response.Base
 	GetMe(ctx context.Context, claim domain.JWTClaimUser) response.Base
+	RefreshToken(ctx context.Context, payload domain.RefreshTokenRequest) response.Base
+	SSOAuthorize(ctx context.Context, payload domain.SSOAuthorizeRequest) response.Base
+	SSOCallback(ctx context.Context, payload domain.SSOCallbackRequest) response.Base
+	GetMyPermissions(ctx context.Context, claim domain.JWTClaimUser) response.Base
+	Logout(ctx context.Context, claim domain.JWTClaimUser) response.Base
+	LogoutAll(ctx context.Context, claim domain.JWTClaimUser) response.Base
+	UnlockAccount(ctx context.Context, payload domain.UnlockAccountRequest) response.Base
+	RequestMagicLink(ctx context.Context, payload domain.MagicLinkRequest) response.Base
+	MagicLinkLogin(ctx context.Context, payload domain.MagicLinkLoginRequest) response.Base
 	RegisterB2B(ctx context.Context, payload domain.RegisterRequest) response.Base
 
 	// Ticket
 	GetTicketList(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base
 	CreateTicket(ctx context.Context, claim domain.JWTClaimUser, payload domain.TicketRequest) response.Base
+	GetPublicTicketForm(ctx context.Context, query url.Values) response.Base
+	SubmitPublicTicket(ctx context.Context, payload domain.PublicTicketRequest) response.Base
+	GetPublicTicketDetail(ctx context.Context, token string) response.Base
+	GetPublicTicketCommentList(ctx context.Context, token string, query url.Values) response.Base
+	CreatePublicTicketComment(ctx context.Context, payload domain.PublicTicketCommentRequest) response.Base
 	GetTicketDetail(ctx context.Context, claim domain.JWTClaimUser, TicketID string) response.Base
 	CloseTicket(ctx context.Context, claim domain.JWTClaimUser, payload domain.CloseTicketRequest) response.Base
 	CloseTicketByEmail(ctx context.Context, payload domain.CloseTicketbyEmailRequest) response.Base
@@ -82,8 +102,10 @@ type AppUsecase interface {
 	GetOrderDetail(ctx context.Context, claim domain.JWTClaimUser, orderID string) response.Base
 	CreateHourOrder(ctx context.Context, claim domain.JWTClaimUser, payload domain.OrderRequest) response.Base
 	CreateServerOrder(ctx context.Context, claim domain.JWTClaimUser, payload domain.OrderRequest) response.Base
+	CreateChangePackageOrder(ctx context.Context, claim domain.JWTClaimUser, payload domain.ChangePackageOrderRequest) response.Base
 	ConfirmOrder(ctx context.Context, claim domain.JWTClaimUser, payload domain.ConfrimOrderRequest) response.Base
 	UploadAttachmentOrder(ctx context.Context, claim domain.JWTClaimUser, payload domain.UploadAttachment, request *http.Request) response.Base
+	GetOrderDocument(ctx context.Context, claim domain.JWTClaimUser, orderID string, documentType string) response.Base
 
 	// Hour Package
 	GetHourPackageList(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base
@@ -92,6 +114,16 @@ type AppUsecase interface {
 	// Customer Subscription
 	GetCustomerSubscriptionList(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base
 	GetCustomerSubscriptionDetail(ctx context.Context, claim domain.JWTClaimUser, customerSubscriptionID string) response.Base
+	EnableAutoRenewal(ctx context.Context, claim domain.JWTClaimUser, customerSubscriptionID string) response.Base
+	CancelRenewal(ctx context.Context, claim domain.JWTClaimUser, customerSubscriptionID string) response.Base
+
+	// Server Resource
+	GetMyServerList(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base
+	GetMyServerDetail(ctx context.Context, claim domain.JWTClaimUser, id string) response.Base
+
+	// Balance History
+	GetBalanceHistoryList(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base
+	GetBalanceStatement(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base
 
 	// User
 	GetUserList(ctx context.Context, claim domain.JWTClaimUser, options map[string]interface{}) response.Base
@@ -99,6 +131,13 @@ type AppUsecase interface {
 	CreateUser(ctx context.Context, claim domain.JWTClaimUser, options map[string]interface{}) response.Base
 	UpdateUser(ctx context.Context, claim domain.JWTClaimUser, options map[string]interface{}) response.Base
 	DeleteUser(ctx context.Context, claim domain.JWTClaimUser, options map[string]interface{}) response.Base
+	GetInvitationList(ctx context.Context, claim domain.JWTClaimUser, options map[string]interface{}) response.Base
+	ResendInvitation(ctx context.Context, claim domain.JWTClaimUser, id string) response.Base
+	RevokeInvitation(ctx context.Context, claim domain.JWTClaimUser, id string) response.Base
+	BulkInviteUser(ctx context.Context, claim domain.JWTClaimUser, request *http.Request) response.Base
+	GetInvitationDetail(ctx context.Context, token string) response.Base
+	AcceptInvitation(ctx context.Context, payload domain.AcceptInvitationRequest) response.Base
+	UpdateUserWalletCap(ctx context.Context, claim domain.JWTClaimUser, options map[string]interface{}) response.Base
 
 	// Project
 	GetProjectList(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base
@@ -109,9 +148,12 @@ type AppUsecase interface {
 
 	//Company
 	GetCompanyDetailByDomain(ctx context.Context, options map[string]interface{}) response.Base
+	GetCompanyWallet(ctx context.Context, claim domain.JWTClaimUser) response.Base
+	GetCompanyWalletHistoryList(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base
 
 	// Setting
 	ChangePassword(ctx context.Context, claim domain.JWTClaimUser, payload domain.ChangePasswordRequest) response.Base
+	ChangeCurrency(ctx context.Context, claim domain.JWTClaimUser, payload domain.ChangeCurrencyRequest) response.Base
 
 	// Ticket Category
 	GetTicketCategoriesList(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base
@@ -0,0 +1,545 @@
+package usecase_member
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// _validateInvitation the fields of a single invitation, shared by the form and the csv
+func (u *appUsecase) _validateInvitation(ctx context.Context, claim domain.JWTClaimUser, payload *domain.CreateUserRequest) (map[string]string, error) {
+	payload.Email = strings.ToLower(strings.TrimSpace(payload.Email))
+
+	errValidation := make(map[string]string)
+	if payload.Name == "" {
+		errValidation["name"] = "name field is required"
+	}
+
+	if payload.Email == "" {
+		errValidation["email"] = "email field is required"
+	} else if !helpers.IsValidEmail(payload.Email) {
+		errValidation["email"] = "email field is invalid"
+	}
+
+	if payload.JobTitle == "" {
+		errValidation["jobTitle"] = "jobTitle field is required"
+	}
+
+	if payload.Role == "" {
+		errValidation["role"] = "role field is required"
+	} else if role, err := u._findRole(ctx, claim.CompanyID, model.RoleScopeCustomer, payload.Role); err != nil {
+		return nil, err
+	} else if role == nil {
+		errValidation["role"] = "role not found"
+	} else if allowed, err := u._canGrantRole(ctx, claim, role); err != nil {
+		return nil, err
+	} else if !allowed {
+		errValidation["role"] = "role has permissions you do not have"
+	}
+
+	return errValidation, nil
+}
+
+// _inviteUser create the invitation and email the accept link, the error is the response shown to the admin
+func (u *appUsecase) _inviteUser(ctx context.Context, claim domain.JWTClaimUser, company *model.Company, payload domain.CreateUserRequest) (*model.CustomerInvitation, *response.Base) {
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"email": payload.Email,
+	})
+	if err != nil {
+		errResponse := response.Error(http.StatusInternalServerError, err.Error())
+		return nil, &errResponse
+	}
+	if customer != nil {
+		errResponse := response.Error(http.StatusBadRequest, "email already in use")
+		return nil, &errResponse
+	}
+
+	// check pending invitation
+	pending, err := u.mongodbRepo.FetchOneCustomerInvitation(ctx, map[string]interface{}{
+		"companyID": claim.CompanyID,
+		"email":     payload.Email,
+		"status":    string(model.InvitationPending),
+	})
+	if err != nil {
+		errResponse := response.Error(http.StatusInternalServerError, err.Error())
+		return nil, &errResponse
+	}
+	if pending != nil {
+		errResponse := response.Error(http.StatusBadRequest, "email already invited, resend the pending invitation")
+		return nil, &errResponse
+	}
+
+	token, hash := helpers.NewInvitationToken()
+	now := time.Now()
+	invitation := &model.CustomerInvitation{
+		ID:         primitive.NewObjectID(),
+		Company:    claim.Company,
+		Name:       payload.Name,
+		Email:      payload.Email,
+		JobTitle:   payload.JobTitle,
+		Role:       model.UserRole(payload.Role),
+		TokenHash:  hash,
+		Status:     model.InvitationPending,
+		InvitedBy:  claim.User,
+		SentCount:  1,
+		LastSentAt: now,
+		ExpiredAt:  now.Add(helpers.GetInvitationTTL()),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := u.mongodbRepo.CreateCustomerInvitation(ctx, invitation); err != nil {
+		errResponse := response.Error(http.StatusInternalServerError, err.Error())
+		return nil, &errResponse
+	}
+
+	go helpers.SendCustomerInvitationEmail(u._CacheConfig(ctx), company, invitation, token)
+
+	return invitation, nil
+}
+
+func (u *appUsecase) GetInvitationList(ctx context.Context, claim domain.JWTClaimUser, options map[string]interface{}) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	paramQuery := options["query"].(url.Values)
+	page, limit, offset := yurekahelpers.GetLimitOffset(paramQuery)
+
+	fetchOptions := map[string]interface{}{
+		"limit":     limit,
+		"offset":    offset,
+		"companyID": claim.CompanyID,
+	}
+
+	// filtering
+	if paramQuery.Get("sort") != "" {
+		fetchOptions["sort"] = paramQuery.Get("sort")
+	}
+	if paramQuery.Get("dir") != "" {
+		fetchOptions["dir"] = paramQuery.Get("dir")
+	}
+	if paramQuery.Get("q") != "" {
+		fetchOptions["q"] = paramQuery.Get("q")
+	}
+	if paramQuery.Get("status") != "" {
+		fetchOptions["status"] = paramQuery.Get("status")
+	}
+
+	// count first
+	totalDocuments := u.mongodbRepo.CountCustomerInvitation(ctx, fetchOptions)
+	if totalDocuments == 0 {
+		return response.Success(response.List{
+			List:  []interface{}{},
+			Page:  page,
+			Limit: limit,
+			Total: totalDocuments,
+		})
+	}
+
+	cur, err := u.mongodbRepo.FetchCustomerInvitationList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.CustomerInvitation{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("CustomerInvitation Decode ", err)
+			continue
+		}
+		list = append(list, row.Format())
+	}
+
+	return response.Success(response.List{
+		List:  list,
+		Page:  page,
+		Limit: limit,
+		Total: totalDocuments,
+	})
+}
+
+// ResendInvitation new link and a new expiry, the previous link stops working
+func (u *appUsecase) ResendInvitation(ctx context.Context, claim domain.JWTClaimUser, id string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check invitation
+	invitation, err := u.mongodbRepo.FetchOneCustomerInvitation(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if invitation == nil {
+		return response.Error(http.StatusBadRequest, "invitation not found")
+	}
+	if invitation.Status != model.InvitationPending {
+		return response.Error(http.StatusBadRequest, "invitation is already "+string(invitation.Status))
+	}
+
+	// get company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	token, hash := helpers.NewInvitationToken()
+	now := time.Now()
+	invitation.TokenHash = hash
+	invitation.SentCount++
+	invitation.LastSentAt = now
+	invitation.ExpiredAt = now.Add(helpers.GetInvitationTTL())
+	invitation.UpdatedAt = now
+
+	if err := u.mongodbRepo.UpdateOneCustomerInvitation(ctx, invitation); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	go helpers.SendCustomerInvitationEmail(u._CacheConfig(ctx), company, invitation, token)
+
+	return response.Success(invitation.Format())
+}
+
+func (u *appUsecase) RevokeInvitation(ctx context.Context, claim domain.JWTClaimUser, id string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check invitation
+	invitation, err := u.mongodbRepo.FetchOneCustomerInvitation(ctx, map[string]interface{}{
+		"id":        id,
+		"companyID": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if invitation == nil {
+		return response.Error(http.StatusBadRequest, "invitation not found")
+	}
+	if invitation.Status != model.InvitationPending {
+		return response.Error(http.StatusBadRequest, "invitation is already "+string(invitation.Status))
+	}
+
+	// only a pending invitation is revoked, expired ones included, it may be accepted meanwhile
+	status := model.InvitationPending
+	if invitation.IsExpired() {
+		status = model.InvitationExpired
+	}
+	now := time.Now()
+	matched, err := u.mongodbRepo.UpdatePartialCustomerInvitation(ctx, map[string]interface{}{
+		"id":     invitation.ID,
+		"status": string(status),
+	}, map[string]interface{}{
+		"status":    model.InvitationRevoked,
+		"revokedAt": now,
+		"updatedAt": now,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if !matched {
+		return response.Error(http.StatusBadRequest, "invitation is no longer pending")
+	}
+	invitation.Status = model.InvitationRevoked
+	invitation.RevokedAt = &now
+	invitation.UpdatedAt = now
+
+	return response.Success(invitation.Format())
+}
+
+// BulkInviteUser csv with the columns name, email, jobTitle and role, every row is reported
+func (u *appUsecase) BulkInviteUser(ctx context.Context, claim domain.JWTClaimUser, request *http.Request) response.Base {
+	// a long file outlasts the usual timeout, every row gets its own in _bulkInviteRow
+	file, uploadedFile, err := request.FormFile("file")
+	if err != nil || file == nil || uploadedFile == nil {
+		return response.ErrorValidation(map[string]string{"file": "file field is required"}, "error validation")
+	}
+	defer file.Close()
+
+	if !helpers.InArrayString(uploadedFile.Header.Get("Content-Type"), []string{"text/csv", "application/vnd.ms-excel"}) {
+		return response.ErrorValidation(map[string]string{"file": "field file is not valid type"}, "error validation")
+	}
+
+	// get company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	reader := csv.NewReader(bufio.NewReader(file))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	results := make([]map[string]interface{}, 0)
+	invited := 0
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "csv line "+strconv.Itoa(line)+" is not valid")
+		}
+
+		// header
+		if line == 1 && len(record) > 1 && strings.EqualFold(strings.TrimSpace(record[1]), "email") {
+			continue
+		}
+		if len(results) >= helpers.InvitationBulkLimit {
+			return response.Error(http.StatusBadRequest, "csv can not have more than "+strconv.Itoa(helpers.InvitationBulkLimit)+" rows")
+		}
+
+		for len(record) < 4 {
+			record = append(record, "")
+		}
+		payload := domain.CreateUserRequest{
+			Name:     strings.TrimSpace(record[0]),
+			Email:    record[1],
+			JobTitle: strings.TrimSpace(record[2]),
+			Role:     strings.TrimSpace(record[3]),
+		}
+		if payload.Role == "" {
+			payload.Role = string(model.CustomerRole)
+		}
+
+		result := map[string]interface{}{
+			"line":  line,
+			"email": strings.TrimSpace(record[1]),
+		}
+		results = append(results, result)
+
+		invitation, errValidation, errResponse := u._bulkInviteRow(ctx, claim, company, payload)
+		if errResponse != nil {
+			return *errResponse
+		}
+		if len(errValidation) > 0 {
+			result["status"] = "failed"
+			result["errors"] = errValidation
+			continue
+		}
+
+		invited++
+		result["status"] = "invited"
+		result["invitationId"] = invitation.ID.Hex()
+	}
+
+	return response.Success(map[string]interface{}{
+		"total":   len(results),
+		"invited": invited,
+		"failed":  len(results) - invited,
+		"results": results,
+	})
+}
+
+// _bulkInviteRow invite one csv row with its own timeout, the validation errors are reported on the row
+func (u *appUsecase) _bulkInviteRow(ctx context.Context, claim domain.JWTClaimUser, company *model.Company, payload domain.CreateUserRequest) (*model.CustomerInvitation, map[string]string, *response.Base) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	errValidation, err := u._validateInvitation(ctx, claim, &payload)
+	if err != nil {
+		errResponse := response.Error(http.StatusInternalServerError, err.Error())
+		return nil, nil, &errResponse
+	}
+	if len(errValidation) > 0 {
+		return nil, errValidation, nil
+	}
+
+	invitation, errResponse := u._inviteUser(ctx, claim, company, payload)
+	if errResponse != nil {
+		if errResponse.Status == http.StatusInternalServerError {
+			return nil, nil, errResponse
+		}
+		return nil, map[string]string{"email": errResponse.Message}, nil
+	}
+
+	return invitation, nil, nil
+}
+
+// GetInvitationDetail what the invitee sees on the accept page
+func (u *appUsecase) GetInvitationDetail(ctx context.Context, token string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if token == "" {
+		return response.ErrorValidation(map[string]string{"token": "token field is required"}, "error validation")
+	}
+
+	invitation, err := u.mongodbRepo.FetchOneCustomerInvitation(ctx, map[string]interface{}{
+		"tokenHash": helpers.HashInvitationToken(token),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if invitation == nil {
+		return response.Error(http.StatusBadRequest, "invitation not valid")
+	}
+	invitation.Format()
+
+	return response.Success(map[string]interface{}{
+		"name":      invitation.Name,
+		"email":     invitation.Email,
+		"jobTitle":  invitation.JobTitle,
+		"status":    invitation.Status,
+		"expiredAt": invitation.ExpiredAt,
+		"invitedBy": invitation.InvitedBy.Name,
+		"company": map[string]interface{}{
+			"name":    invitation.Company.Name,
+			"logoUrl": invitation.Company.LogoUrl,
+		},
+	})
+}
+
+// AcceptInvitation the invitee chooses the password and is signed in
+func (u *appUsecase) AcceptInvitation(ctx context.Context, payload domain.AcceptInvitationRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Token == "" {
+		errValidation["token"] = "token field is required"
+	}
+	if payload.Password == "" {
+		errValidation["password"] = "password field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check invitation
+	invitation, err := u.mongodbRepo.FetchOneCustomerInvitation(ctx, map[string]interface{}{
+		"tokenHash": helpers.HashInvitationToken(payload.Token),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if invitation == nil {
+		return response.Error(http.StatusBadRequest, "invitation not valid")
+	}
+	if invitation.IsExpired() {
+		return response.Error(http.StatusBadRequest, "invitation expired, ask your admin to resend it")
+	}
+	if invitation.Status != model.InvitationPending {
+		return response.Error(http.StatusBadRequest, "invitation is already "+string(invitation.Status))
+	}
+
+	if msg := helpers.ValidatePassword(helpers.GetPasswordPolicy(u._CacheConfig(ctx)), payload.Password, invitation.Email); msg != "" {
+		return response.ErrorValidation(map[string]string{"password": msg}, "error validation")
+	}
+
+	// the email could have registered since the invitation
+	existing, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"email": invitation.Email,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if existing != nil {
+		return response.Error(http.StatusBadRequest, "email already in use")
+	}
+
+	if payload.Name == "" {
+		payload.Name = invitation.Name
+	}
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+	now := time.Now()
+
+	// claim the invitation first so the same token can not create two accounts
+	matched, err := u.mongodbRepo.UpdatePartialCustomerInvitation(ctx, map[string]interface{}{
+		"id":        invitation.ID,
+		"tokenHash": invitation.TokenHash,
+		"status":    string(model.InvitationPending),
+	}, map[string]interface{}{
+		"status":     model.InvitationAccepted,
+		"acceptedAt": now,
+		"updatedAt":  now,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if !matched {
+		return response.Error(http.StatusBadRequest, "invitation is no longer pending")
+	}
+
+	// create customer
+	newUser := &model.Customer{
+		ID:                primitive.NewObjectID(),
+		Name:              payload.Name,
+		Email:             invitation.Email,
+		Password:          string(hashedPassword),
+		Company:           invitation.Company,
+		IsNeedBalance:     invitation.Company.Type == "B2C",
+		JobTitle:          invitation.JobTitle,
+		Role:              invitation.Role,
+		IsVerified:        true,
+		VerifiedAt:        &now,
+		PasswordChangedAt: &now,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := u.mongodbRepo.CreateCustomer(ctx, newUser); err != nil {
+		// give the invitation back so it can be accepted again
+		u.mongodbRepo.UpdatePartialCustomerInvitation(ctx, map[string]interface{}{
+			"id": invitation.ID,
+		}, map[string]interface{}{
+			"status":     model.InvitationPending,
+			"acceptedAt": nil,
+			"updatedAt":  time.Now(),
+		})
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	if _, err := u.mongodbRepo.UpdatePartialCustomerInvitation(ctx, map[string]interface{}{
+		"id": invitation.ID,
+	}, map[string]interface{}{
+		"customer": &model.UserNested{
+			ID:    newUser.ID.Hex(),
+			Name:  newUser.Name,
+			Email: newUser.Email,
+		},
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	// generate token
+	tokenString, refreshToken, err := u._issueToken(ctx, newUser)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"user":         newUser,
+		"token":        tokenString,
+		"refreshToken": refreshToken,
+	})
+}
@@ -2,6 +2,7 @@ package usecase_member
 
 import (
 	"app/domain/model"
+	"app/helpers"
 	"context"
 	"encoding/json"
 	"time"
@@ -39,3 +40,13 @@ func (u *appUsecase) _CacheConfig(ctx context.Context) (config model.Config) {
 
 	return
 }
+
+// _orderRates rate from the base currency to the order currency and to IDR, 0 when no rate is available.
+// IDR falls back to the config rate until a rate history is kept
+func (u *appUsecase) _orderRates(ctx context.Context, config model.Config, currency string) (rate float64, dollarInIdr float64, err error) {
+	if rate, err = helpers.ExchangeRate(ctx, u.mongodbRepo, config, currency); err != nil {
+		return
+	}
+	dollarInIdr, err = helpers.ExchangeRate(ctx, u.mongodbRepo, config, model.CurrencyIDR)
+	return
+}
@@ -0,0 +1,85 @@
+package usecase_member
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+// _recordSecurityEvent keep the login audit, a failing insert never blocks the login
+func (u *appUsecase) _recordSecurityEvent(ctx context.Context, eventType model.SecurityEventType, companyID string, user model.UserNested, payload domain.LoginRequest, reason string) {
+	event := helpers.NewSecurityEvent(eventType, model.SessionCustomer, companyID, user, payload.IPAddress, payload.UserAgent, reason)
+	if err := u.mongodbRepo.CreateSecurityEvent(ctx, event); err != nil {
+		logrus.Error("CreateSecurityEvent:", err)
+	}
+}
+
+// _loginFailed count the failure and lock the account once the limit is reached
+func (u *appUsecase) _loginFailed(ctx context.Context, throttle *helpers.LoginThrottle, user *model.Customer, company *model.Company, payload domain.LoginRequest) response.Base {
+	nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+	failures := throttle.Fail(ctx, model.SessionCustomer, payload.Email, payload.IPAddress)
+	u._recordSecurityEvent(ctx, model.SecurityLoginFailed, user.Company.ID, nested, payload, "wrong password")
+	if failures < helpers.GetLoginMaxAttempts() {
+		return response.Error(http.StatusBadRequest, "Wrong password")
+	}
+
+	lockout, token := helpers.NewAccountLockout("too many failed login attempts")
+	if err := u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+		"id": user.ID,
+	}, map[string]interface{}{
+		"lockout":   lockout,
+		"updatedAt": time.Now(),
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	throttle.Reset(ctx, model.SessionCustomer, payload.Email)
+	u._recordSecurityEvent(ctx, model.SecurityAccountLocked, user.Company.ID, nested, payload, lockout.Reason)
+
+	config := u._CacheConfig(ctx)
+	go helpers.SendAccountLockedEmail(config, company, user.Name, user.Email, company.Settings.Domain.FullUrl, token)
+
+	return response.Error(http.StatusForbidden, "account is locked after too many failed login attempts, check your email to unlock it")
+}
+
+func (u *appUsecase) UnlockAccount(ctx context.Context, payload domain.UnlockAccountRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if payload.Token == "" {
+		return response.ErrorValidation(map[string]string{
+			"token": "token field is required",
+		}, "error validation")
+	}
+
+	user, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"lockoutToken": helpers.HashUnlockToken(payload.Token),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	if user == nil || !user.Lockout.IsActive() {
+		return response.Error(http.StatusBadRequest, "unlock link is invalid or expired")
+	}
+
+	if err = u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+		"id": user.ID,
+	}, map[string]interface{}{
+		"lockout":   nil,
+		"updatedAt": time.Now(),
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	helpers.NewLoginThrottle(u.redisRepo).Reset(ctx, model.SessionCustomer, user.Email)
+
+	nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+	u._recordSecurityEvent(ctx, model.SecurityAccountUnlocked, user.Company.ID, nested, domain.LoginRequest{}, "unlocked by email link")
+
+	return response.Success("account unlocked, you can login now")
+}
@@ -0,0 +1,173 @@
+package usecase_member
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+)
+
+// RequestMagicLink email a login link, the answer is the same whether the customer exists or not
+func (u *appUsecase) RequestMagicLink(ctx context.Context, payload domain.MagicLinkRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	payload.Email = strings.ToLower(strings.TrimSpace(payload.Email))
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Email == "" {
+		errValidation["email"] = "email field is required"
+	}
+	if payload.AccessKey == "" {
+		errValidation["accessKey"] = "accessKey field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"accessKey": payload.AccessKey,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+	if !helpers.IsPasswordlessEnabled(company) {
+		return response.Error(http.StatusBadRequest, "passwordless login is not enabled for this company")
+	}
+
+	sent := response.Success(map[string]interface{}{
+		"message": "if the email is registered a sign in link is on its way",
+	})
+
+	// a flood of links to one inbox is dropped
+	limiter := helpers.NewRateLimiter(u.redisRepo)
+	if allowed, _, _ := limiter.Allow(ctx, "magic-link:"+company.ID.Hex()+":"+payload.Email, helpers.GetMagicLinkRequestLimit(), time.Hour); !allowed {
+		return sent
+	}
+
+	// check customer
+	user, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"email":     payload.Email,
+		"companyID": company.ID.Hex(),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if user == nil || user.Lockout.IsActive() {
+		return sent
+	}
+
+	magicLink, token := helpers.NewMagicLink(company.ID.Hex())
+	user.MagicLink = magicLink
+	if err := u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+		"id": user.ID,
+	}, map[string]interface{}{
+		"magicLink": user.MagicLink,
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+	u._recordSecurityEvent(ctx, model.SecurityMagicLinkSent, company.ID.Hex(), nested, domain.LoginRequest{
+		Email:     payload.Email,
+		IPAddress: payload.IPAddress,
+		UserAgent: payload.UserAgent,
+	}, "")
+
+	go helpers.SendMagicLinkEmail(u._CacheConfig(ctx), company, user, token)
+
+	return sent
+}
+
+// MagicLinkLogin sign in with the emailed link, it works once
+func (u *appUsecase) MagicLinkLogin(ctx context.Context, payload domain.MagicLinkLoginRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	if payload.Token == "" {
+		return response.ErrorValidation(map[string]string{"token": "token field is required"}, "error validation")
+	}
+
+	// the link is used up whatever happens next
+	user, err := u.mongodbRepo.ConsumeCustomerMagicLink(ctx, helpers.HashMagicLinkToken(payload.Token), time.Now())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if user == nil {
+		return response.Error(http.StatusBadRequest, "sign in link not valid or expired, request a new one")
+	}
+	if user.MagicLink.CompanyID != user.Company.ID {
+		return response.Error(http.StatusBadRequest, "sign in link expired, request a new one")
+	}
+	user.MagicLink = nil
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": user.Company.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+	if !helpers.IsPasswordlessEnabled(company) {
+		return response.Error(http.StatusBadRequest, "passwordless login is not enabled for this company")
+	}
+
+	loginPayload := domain.LoginRequest{
+		Email:     user.Email,
+		IPAddress: payload.IPAddress,
+		UserAgent: payload.UserAgent,
+	}
+	nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+	if user.Lockout.IsActive() {
+		u._recordSecurityEvent(ctx, model.SecurityLoginFailed, company.ID.Hex(), nested, loginPayload, "account locked")
+		return response.Error(http.StatusForbidden, "account is locked, check your email to unlock it or try again later")
+	}
+
+	// opening the link proves the email, a guest becomes a regular account
+	if !user.IsVerified || user.IsGuest {
+		now := time.Now()
+		user.IsVerified = true
+		user.IsGuest = false
+		user.VerifiedAt = &now
+		user.Token = ""
+		if err := u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+			"id": user.ID,
+		}, map[string]interface{}{
+			"isVerified": true,
+			"isGuest":    false,
+			"verifiedAt": user.VerifiedAt,
+			"token":      "",
+		}); err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	helpers.NewLoginThrottle(u.redisRepo).Reset(ctx, model.SessionCustomer, user.Email)
+	u._recordSecurityEvent(ctx, model.SecurityLoginSuccess, company.ID.Hex(), nested, loginPayload, "magic link")
+
+	// generate token
+	tokenString, refreshToken, err := u._issueToken(ctx, user)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"user":         user,
+		"token":        tokenString,
+		"refreshToken": refreshToken,
+	})
+}
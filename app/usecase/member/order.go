@@ -139,6 +139,7 @@ func (u *appUsecase) CreateHourOrder(ctx context.Context, claim domain.JWTClaimU
 	// check hour package
 	oneHourPackage, err := u.mongodbRepo.FetchOneHourPackage(ctx, map[string]interface{}{
 		"id":     payload.PackageID,
+		"type":   string(model.HourPackageTypeRegular),
 		"status": string(model.HourPackageActive),
 	})
 	if err != nil {
@@ -148,6 +149,28 @@ func (u *appUsecase) CreateHourOrder(ctx context.Context, claim domain.JWTClaimU
 		return response.Error(http.StatusBadRequest, "hour package not found")
 	}
 
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": claim.User.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
 	// count order
 	count := u.mongodbRepo.CountOrder(ctx, map[string]interface{}{
 		"today": true,
@@ -162,11 +185,28 @@ func (u *appUsecase) CreateHourOrder(ctx context.Context, claim domain.JWTClaimU
 	// generate order number
 	orderNumber := helpers.GenerateFormattedCode("TRX", count+1, randomChar)
 
+	// charge in the customer currency, the rates are frozen on the order
+	currency := helpers.CustomerCurrency(customer)
+	rate, dollarInIdr, err := u._orderRates(ctx, config, currency)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if rate == 0 {
+		return response.Error(http.StatusBadRequest, "exchange rate of "+currency+" is not available")
+	}
+	price := helpers.PackagePrice(oneHourPackage.Price, oneHourPackage.Prices, currency, rate)
+
 	tax := 0
 	adminFee := 0
 	discount := 0
 
-	subTotal := oneHourPackage.Price * 1
+	// time used beyond the balance is billed on this order
+	overage := helpers.NewOrderOverage(company, customer, oneHourPackage, price, currency)
+
+	subTotal := price * 1
+	if overage != nil {
+		subTotal += overage.Amount
+	}
 	grandTotal := subTotal + float64(adminFee) + float64(tax) - float64(discount)
 
 	// create order
@@ -197,6 +237,253 @@ func (u *appUsecase) CreateHourOrder(ctx context.Context, claim domain.JWTClaimU
 		UpdatedAt:   time.Now(),
 	}
 
+	order.Currency = currency
+	order.ExchangeRate = rate
+	order.DollarInIdr = dollarInIdr
+	order.UnitPrice = price
+
+	// hours of pooled company are credited to the company wallet
+	order.IsCompanyWallet = helpers.IsPooledWallet(company)
+	order.Overage = overage
+
+	if !config.ManualPayment.IsActive {
+		// do generete snap link
+		result, err := u.xenditRepo.GenereteSnapLink(context.Background(), oneHourPackage, nil, *order)
+		if err != nil || result.Status != 200 {
+			if result.Status != 0 {
+				return response.Error(400, result.Message)
+			}
+
+			return response.Error(400, err.Error())
+		}
+		respDataXendit, _ := result.Data.(domain.XenditGenereteSnapLinkResponseSuccess)
+		// update snap order
+		order.Payment.Status = respDataXendit.Status
+		order.Invoice.InvoiceXenditId = respDataXendit.ID
+		order.Invoice.InvoiceURL = respDataXendit.InvoiceURL
+		order.Invoice.InvoiceExternalId = respDataXendit.ExternalID
+		order.Payment.Snap = respDataXendit
+		order.UpdatedAt = time.Now()
+		order.ExpiredAt = respDataXendit.ExpiryDate
+	} else {
+		order.Payment.Status = string(model.STATUS_PENDING)
+		order.Invoice.BankCode = config.ManualPayment.BankName
+		order.Invoice.MerchantName = config.ManualPayment.AccountName
+		order.Invoice.PaymentMethod = "MANUAL_PAYMENT"
+		order.Invoice.PaymentChannel = config.ManualPayment.BankName
+		order.Invoice.PaymentDestination = config.ManualPayment.AccountNumber
+		order.Invoice.SwiftCode = config.ManualPayment.SwiftCode
+		order.ExpiredAt = time.Now().Add(time.Second * time.Duration(config.ManualPayment.DurationInSecond))
+	}
+
+	// save
+	if err := u.mongodbRepo.CreateOrder(ctx, order); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(order)
+}
+
+func (u *appUsecase) CreateChangePackageOrder(ctx context.Context, claim domain.JWTClaimUser, payload domain.ChangePackageOrderRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating
+	errValidation := make(map[string]string)
+	if payload.SubscriptionID == "" {
+		errValidation["subscriptionId"] = "subscriptionId field is required"
+	}
+	if payload.PackageID == "" {
+		errValidation["packageId"] = "packageId field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check current subscription
+	subscription, err := u.mongodbRepo.FetchOneCustomerSubscription(ctx, map[string]interface{}{
+		"id":         payload.SubscriptionID,
+		"customerID": claim.UserID,
+		"orderType":  model.HOUR_TYPE,
+		"status":     model.Active,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if subscription == nil || subscription.HourPackage == nil {
+		return response.Error(http.StatusBadRequest, "customer subscription not found")
+	}
+	if subscription.IsCompanyWallet {
+		return response.Error(http.StatusBadRequest, "company wallet subscription can't change package")
+	}
+	if subscription.Renewal != nil {
+		return response.Error(http.StatusBadRequest, "subscription renewal is waiting for payment")
+	}
+
+	// check hour package
+	oneHourPackage, err := u.mongodbRepo.FetchOneHourPackage(ctx, map[string]interface{}{
+		"id":     payload.PackageID,
+		"type":   string(model.HourPackageTypeRegular),
+		"status": string(model.HourPackageActive),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if oneHourPackage == nil {
+		return response.Error(http.StatusBadRequest, "hour package not found")
+	}
+	if oneHourPackage.ID.Hex() == subscription.HourPackage.ID {
+		return response.Error(http.StatusBadRequest, "hour package is the current package")
+	}
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": claim.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+	if helpers.IsPooledWallet(company) {
+		return response.Error(http.StatusBadRequest, "company wallet subscription can't change package")
+	}
+
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": claim.User.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	// get from config
+	config := u._CacheConfig(ctx)
+
+	// charge in the customer currency, the rates are frozen on the order
+	currency := helpers.CustomerCurrency(customer)
+	rate, dollarInIdr, err := u._orderRates(ctx, config, currency)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if rate == 0 {
+		return response.Error(http.StatusBadRequest, "exchange rate of "+currency+" is not available")
+	}
+	price := helpers.PackagePrice(oneHourPackage.Price, oneHourPackage.Prices, currency, rate)
+
+	// prorate the unused time of the current package at what was paid for it
+	paidOrder, err := u.mongodbRepo.FetchOneOrder(ctx, map[string]interface{}{
+		"id": subscription.Order.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	change := helpers.NewPackageChange(subscription, paidOrder, company, customer, oneHourPackage, price, currency, rate)
+	credit := helpers.PackageChangeCredit(change)
+
+	// time used beyond the balance is billed on this order
+	overage := helpers.NewOrderOverage(company, customer, oneHourPackage, price, currency)
+
+	// count order
+	count := u.mongodbRepo.CountOrder(ctx, map[string]interface{}{
+		"today": true,
+	})
+
+	// generate random char
+	randomChar := helpers.RandomChar(3)
+
+	// generate order number
+	orderNumber := helpers.GenerateFormattedCode("TRX", count+1, randomChar)
+
+	tax := 0
+	adminFee := 0
+
+	subTotal := price * 1
+	if overage != nil {
+		subTotal += overage.Amount
+	}
+	// a downgrade may be covered by the credit, what is left over is carried as time of the new package
+	discount := credit
+	if total := subTotal + float64(adminFee) + float64(tax); discount > total {
+		change.CarrySeconds = helpers.PackageChangeCarrySeconds(credit, total, price, oneHourPackage)
+		discount = total
+	}
+	grandTotal := subTotal + float64(adminFee) + float64(tax) - discount
+
+	// create order
+	order := &model.Order{
+		ID: primitive.NewObjectID(),
+		HourPackage: &model.HourPackageFK{
+			ID:      oneHourPackage.ID.Hex(),
+			Name:    oneHourPackage.Name,
+			Hours:   oneHourPackage.Duration.Hours,
+			Benefit: oneHourPackage.Benefit,
+			Price:   oneHourPackage.Price,
+		},
+		Customer: model.CustomerFK{
+			ID:    claim.User.ID,
+			Name:  claim.User.Name,
+			Email: claim.User.Email,
+		},
+		OrderNumber: orderNumber,
+		Amount:      1,
+		Type:        model.HOUR_TYPE,
+		Tax:         float64(tax),
+		AdminFee:    float64(adminFee),
+		Discount:    discount,
+		SubTotal:    subTotal,
+		GrandTotal:  grandTotal,
+		Status:      model.STATUS_PENDING,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	order.Currency = currency
+	order.ExchangeRate = rate
+	order.DollarInIdr = dollarInIdr
+	order.UnitPrice = price
+	order.Overage = overage
+	order.Change = change
+
+	// nothing to pay, the package is changed right away
+	if grandTotal <= 0 {
+		now := time.Now()
+		order.Status = model.STATUS_PAID
+		order.Payment = model.Payment{
+			Status: string(model.STATUS_PAID),
+			PaidAt: &now,
+		}
+		order.Invoice.PaymentMethod = "CREDIT"
+		order.PaidAt = &now
+		order.ExpiredAt = now
+
+		if err := u.mongodbRepo.CreateOrder(ctx, order); err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+
+		subscription, err := u.billing.ChangeSubscription(ctx, order, customer, company, oneHourPackage)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+
+		// send email
+		go u.billing.SendEmailCustomerOrder(config, order, customer, company, subscription, nil)
+
+		// unused time is recomputed when the change is applied
+		if _, err := u.mongodbRepo.UpdatePartialOrder(ctx, map[string]interface{}{
+			"id": order.ID,
+		}, map[string]interface{}{
+			"change": order.Change,
+		}); err != nil {
+			logrus.Error("UpdatePartialOrder: ", err)
+		}
+
+		return response.Success(order)
+	}
+
 	if !config.ManualPayment.IsActive {
 		// do generete snap link
 		result, err := u.xenditRepo.GenereteSnapLink(context.Background(), oneHourPackage, nil, *order)
@@ -266,6 +553,17 @@ func (u *appUsecase) CreateServerOrder(ctx context.Context, claim domain.JWTClai
 		return response.Error(http.StatusBadRequest, "server package is customizable, please contact our support")
 	}
 
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": claim.User.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
 	// count order
 	count := u.mongodbRepo.CountOrder(ctx, map[string]interface{}{
 		"today": true,
@@ -274,6 +572,17 @@ func (u *appUsecase) CreateServerOrder(ctx context.Context, claim domain.JWTClai
 	// get from config
 	config := u._CacheConfig(ctx)
 
+	// charge in the customer currency, the rates are frozen on the order
+	currency := helpers.CustomerCurrency(customer)
+	rate, dollarInIdr, err := u._orderRates(ctx, config, currency)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if rate == 0 {
+		return response.Error(http.StatusBadRequest, "exchange rate of "+currency+" is not available")
+	}
+	price := helpers.PackagePrice(oneServerPackage.Price, oneServerPackage.Prices, currency, rate)
+
 	// generate random char
 	randomChar := helpers.RandomChar(3)
 
@@ -284,7 +593,7 @@ func (u *appUsecase) CreateServerOrder(ctx context.Context, claim domain.JWTClai
 	adminFee := 0
 	discount := 0
 
-	subTotal := oneServerPackage.Price * float64(payload.Amount)
+	subTotal := price * float64(payload.Amount)
 	grandTotal := subTotal + float64(adminFee) + float64(tax) - float64(discount)
 
 	// create order
@@ -315,6 +624,10 @@ func (u *appUsecase) CreateServerOrder(ctx context.Context, claim domain.JWTClai
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	order.Currency = currency
+	order.ExchangeRate = rate
+	order.DollarInIdr = dollarInIdr
+	order.UnitPrice = price
 
 	if !config.ManualPayment.IsActive {
 		// do generete snap link
@@ -542,3 +855,42 @@ func (u *appUsecase) ConfirmOrder(ctx context.Context, claim domain.JWTClaimUser
 
 	return response.Success(order)
 }
+
+func (u *appUsecase) GetOrderDocument(ctx context.Context, claim domain.JWTClaimUser, orderID string, documentType string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check order
+	order, err := u.mongodbRepo.FetchOneOrder(ctx, map[string]interface{}{
+		"id":         orderID,
+		"customerID": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if order == nil {
+		return response.Error(http.StatusBadRequest, "order not found")
+	}
+
+	var document *model.MediaFK
+	switch documentType {
+	case "invoice":
+		document = order.Document.Invoice
+	case "receipt":
+		document = order.Document.Receipt
+	default:
+		return response.Error(http.StatusBadRequest, "document type only can be invoice or receipt")
+	}
+	if document == nil {
+		return response.Error(http.StatusBadRequest, "document not available")
+	}
+
+	// refresh private link
+	link, err := u.s3Repo.GetPresignedLink(document.ProviderKey, nil)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	document.URL = link.URL
+
+	return response.Success(document)
+}
@@ -0,0 +1,320 @@
+package usecase_member
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// _findPublicCompany company of a public form by its subdomain or access key
+func (u *appUsecase) _findPublicCompany(ctx context.Context, domain, accessKey string) (*model.Company, error) {
+	if accessKey != "" {
+		return u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+			"accessKey": accessKey,
+		})
+	}
+
+	return u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"subdomain": domain,
+	})
+}
+
+// _publicClaim acts as the customer so the public flow reuses the customer usecases
+func (u *appUsecase) _publicClaim(company *model.Company, customer *model.Customer) domain.JWTClaimUser {
+	return domain.JWTClaimUser{
+		UserID:    customer.ID.Hex(),
+		CompanyID: company.ID.Hex(),
+		Role:      string(customer.Role),
+		User: model.UserNested{
+			ID:    customer.ID.Hex(),
+			Name:  customer.Name,
+			Email: customer.Email,
+		},
+		Company: model.CompanyNested{
+			ID:       company.ID.Hex(),
+			Name:     company.Name,
+			Image:    company.Logo.URL,
+			Type:     company.Type,
+			Code:     company.Code,
+			LogoUrl:  company.Logo.URL,
+			Settings: company.Settings,
+		},
+	}
+}
+
+// _findTrackedTicket ticket of a tracking link with the company and customer behind it
+func (u *appUsecase) _findTrackedTicket(ctx context.Context, token string) (*model.Ticket, *domain.JWTClaimUser, response.Base) {
+	if token == "" {
+		return nil, nil, response.ErrorValidation(map[string]string{"token": "token field is required"}, "error validation")
+	}
+
+	ticket, err := u.mongodbRepo.FetchOneTicket(ctx, map[string]interface{}{
+		"trackToken": helpers.HashTicketTrackToken(token),
+	})
+	if err != nil {
+		return nil, nil, response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if ticket == nil {
+		return nil, nil, response.Error(http.StatusBadRequest, "tracking link not valid")
+	}
+
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": ticket.Company.ID,
+	})
+	if err != nil {
+		return nil, nil, response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return nil, nil, response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": ticket.Customer.ID,
+	})
+	if err != nil {
+		return nil, nil, response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return nil, nil, response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	claim := u._publicClaim(company, customer)
+	return ticket, &claim, response.Base{}
+}
+
+func (u *appUsecase) GetPublicTicketForm(ctx context.Context, query url.Values) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if query.Get("domain") == "" && query.Get("accessKey") == "" {
+		return response.ErrorValidation(map[string]string{"domain": "domain or accessKey field is required"}, "error validation")
+	}
+
+	company, err := u._findPublicCompany(ctx, query.Get("domain"), query.Get("accessKey"))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// categories the visitor can pick
+	cur, err := u.mongodbRepo.FetchTicketCategoryList(ctx, map[string]interface{}{
+		"companyID": company.ID.Hex(),
+		"sort":      "name",
+		"dir":       "asc",
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	defer cur.Close(ctx)
+
+	categories := make([]model.TicketCategoryFK, 0)
+	for cur.Next(ctx) {
+		row := model.TicketCategory{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("TicketCategory Decode ", err)
+			continue
+		}
+		categories = append(categories, model.TicketCategoryFK{
+			ID:   row.ID.Hex(),
+			Name: row.Name,
+		})
+	}
+
+	return response.Success(map[string]interface{}{
+		"company": map[string]interface{}{
+			"name":      company.Name,
+			"logoUrl":   company.Logo.URL,
+			"colorMode": company.Settings.ColorMode,
+		},
+		"categories": categories,
+		"priorities": []model.TicketPriority{model.PriorityLow, model.PriorityMedium, model.PriorityHigh, model.PriorityCritical},
+		"captcha": map[string]string{
+			"provider": helpers.GetCaptchaProvider(),
+			"siteKey":  helpers.GetCaptchaSiteKey(),
+		},
+	})
+}
+
+// SubmitPublicTicket the visitor becomes a guest customer of the company, the tracking link goes to the email
+func (u *appUsecase) SubmitPublicTicket(ctx context.Context, payload domain.PublicTicketRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	payload.Email = strings.ToLower(strings.TrimSpace(payload.Email))
+	if payload.Priority == "" {
+		payload.Priority = string(model.PriorityLow)
+	}
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Domain == "" && payload.AccessKey == "" {
+		errValidation["domain"] = "domain or accessKey field is required"
+	}
+	if payload.Name == "" {
+		errValidation["name"] = "name field is required"
+	}
+	if payload.Email == "" {
+		errValidation["email"] = "email field is required"
+	} else if !helpers.IsValidEmail(payload.Email) {
+		errValidation["email"] = "email field is invalid"
+	}
+	if payload.CaptchaToken == "" {
+		errValidation["captchaToken"] = "captchaToken field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// rate limit by ip and by email
+	limiter := helpers.NewRateLimiter(u.redisRepo)
+	limit := helpers.GetPublicTicketRateLimit()
+	for _, key := range []string{"public-ticket:ip:" + payload.IPAddress, "public-ticket:email:" + payload.Email} {
+		if allowed, _, reset := limiter.Allow(ctx, key, limit, time.Hour); !allowed {
+			return response.Error(http.StatusTooManyRequests, "too many submissions, try again in "+strconv.FormatInt(int64(reset.Minutes())+1, 10)+" minutes")
+		}
+	}
+
+	// captcha
+	valid, err := helpers.NewCaptchaVerifier().Verify(ctx, payload.CaptchaToken, payload.IPAddress)
+	if err != nil {
+		logrus.Error("Captcha Verify ", err)
+		return response.Error(http.StatusInternalServerError, "captcha verification failed")
+	}
+	if !valid {
+		return response.Error(http.StatusBadRequest, "captcha verification failed")
+	}
+
+	// check company
+	company, err := u._findPublicCompany(ctx, payload.Domain, payload.AccessKey)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// check customer, a visitor without an account becomes a guest
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"email":     payload.Email,
+		"companyID": company.ID.Hex(),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer != nil && !customer.IsGuest {
+		// a visitor can not post as a registered customer
+		return response.Error(http.StatusBadRequest, "email belongs to an account, please login to submit a ticket")
+	}
+	if customer == nil {
+		now := time.Now()
+		customer = &model.Customer{
+			ID:    primitive.NewObjectID(),
+			Name:  payload.Name,
+			Email: payload.Email,
+			Company: model.CompanyNested{
+				ID:    company.ID.Hex(),
+				Name:  company.Name,
+				Image: company.Logo.URL,
+				Type:  company.Type,
+				Code:  company.Code,
+			},
+			Role:       model.CustomerRole,
+			JobTitle:   "customer",
+			IsGuest:    true,
+			IsVerified: false,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := u.mongodbRepo.CreateCustomer(ctx, customer); err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+
+		u.mongodbRepo.IncrementOneCompany(ctx, company.ID.Hex(), map[string]int64{
+			"customerTotal": 1,
+		})
+	}
+
+	// create ticket as the customer
+	res := u.CreateTicket(ctx, u._publicClaim(company, customer), domain.TicketRequest{
+		Subject:    payload.Subject,
+		Content:    payload.Content,
+		Priority:   payload.Priority,
+		CategoryId: payload.CategoryId,
+		Language:   payload.Language,
+		Name:       payload.Name,
+	})
+	if res.Status != http.StatusOK {
+		return res
+	}
+	ticket := res.Data.(*model.Ticket)
+
+	// tracking link
+	token, hash := helpers.NewTicketTrackToken()
+	ticket.TrackToken = hash
+	if err := u.mongodbRepo.UpdateTicket(ctx, ticket); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	go helpers.SendTicketTrackingEmail(u._CacheConfig(ctx), company, ticket, token)
+
+	return response.Success(map[string]interface{}{
+		"code":    ticket.Code,
+		"subject": ticket.Subject,
+		"status":  ticket.Status,
+		"message": "ticket submitted, check your email to follow it",
+	})
+}
+
+func (u *appUsecase) GetPublicTicketDetail(ctx context.Context, token string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ticket, claim, res := u._findTrackedTicket(ctx, token)
+	if ticket == nil {
+		return res
+	}
+
+	return u.GetTicketDetail(ctx, *claim, ticket.ID.Hex())
+}
+
+func (u *appUsecase) GetPublicTicketCommentList(ctx context.Context, token string, query url.Values) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ticket, claim, res := u._findTrackedTicket(ctx, token)
+	if ticket == nil {
+		return res
+	}
+
+	return u.GetTicketCommentList(ctx, *claim, ticket.ID.Hex(), query)
+}
+
+func (u *appUsecase) CreatePublicTicketComment(ctx context.Context, payload domain.PublicTicketCommentRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ticket, claim, res := u._findTrackedTicket(ctx, payload.Token)
+	if ticket == nil {
+		return res
+	}
+	if ticket.Status == model.Closed || ticket.Status == model.Cancel {
+		return response.Error(http.StatusBadRequest, "ticket is already "+string(ticket.Status))
+	}
+
+	return u.CreateTicketComment(ctx, *claim, domain.TicketCommentRequest{
+		TicketId: ticket.ID.Hex(),
+		Content:  payload.Content,
+	})
+}
@@ -0,0 +1,50 @@
+package usecase_member
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+)
+
+// _findRole default role by its code or the custom role of the company
+func (u *appUsecase) _findRole(ctx context.Context, companyID string, scope model.RoleScope, code string) (*model.Role, error) {
+	if role := helpers.DefaultRole(scope, code); role != nil {
+		return role, nil
+	}
+
+	return u.mongodbRepo.FetchOneRole(ctx, map[string]interface{}{
+		"companyID": companyID,
+		"scope":     string(scope),
+		"code":      code,
+	})
+}
+
+// _canGrantRole the actor may only give a role whose permissions it holds itself
+func (u *appUsecase) _canGrantRole(ctx context.Context, claim domain.JWTClaimUser, role *model.Role) (bool, error) {
+	actorRole, err := u._findRole(ctx, claim.CompanyID, model.RoleScopeCustomer, claim.Role)
+	if err != nil || actorRole == nil {
+		return false, err
+	}
+
+	return actorRole.HasPermission(role.Permissions...), nil
+}
+
+// GetMyPermissions role of the logged in customer, used by the frontend to hide what is not allowed
+func (u *appUsecase) GetMyPermissions(ctx context.Context, claim domain.JWTClaimUser) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	role, err := u._findRole(ctx, claim.CompanyID, model.RoleScopeCustomer, claim.Role)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if role == nil {
+		return response.Error(http.StatusBadRequest, "role not found")
+	}
+
+	return response.Success(role)
+}
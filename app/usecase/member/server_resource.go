@@ -0,0 +1,101 @@
+package usecase_member
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+
+	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+func (u *appUsecase) GetMyServerList(ctx context.Context, claim domain.JWTClaimUser, query url.Values) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	page, limit, offset := yurekahelpers.GetLimitOffset(query)
+
+	fetchOptions := map[string]interface{}{
+		"limit":      limit,
+		"offset":     offset,
+		"customerID": claim.UserID,
+	}
+
+	// filtering
+	if query.Get("sort") != "" {
+		fetchOptions["sort"] = query.Get("sort")
+	}
+	if query.Get("dir") != "" {
+		fetchOptions["dir"] = query.Get("dir")
+	}
+	if query.Get("status") != "" {
+		fetchOptions["status"] = query.Get("status")
+	}
+
+	// count first
+	totalDocuments := u.mongodbRepo.CountServerResource(ctx, fetchOptions)
+	if totalDocuments == 0 {
+		return response.Success(domain.ResponseList{
+			List: response.List{
+				List:  []interface{}{},
+				Page:  page,
+				Limit: limit,
+				Total: totalDocuments,
+			},
+			TotalPage: helpers.GetTotalPage(totalDocuments, limit),
+		})
+	}
+
+	// check server resource list
+	cur, err := u.mongodbRepo.FetchServerResourceList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.ServerResource{}
+		err := cur.Decode(&row)
+		if err != nil {
+			logrus.Error("Server Resource Decode ", err)
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+
+		list = append(list, row)
+	}
+
+	return response.Success(domain.ResponseList{
+		List: response.List{
+			List:  list,
+			Page:  page,
+			Limit: limit,
+			Total: totalDocuments,
+		},
+		TotalPage: helpers.GetTotalPage(totalDocuments, limit),
+	})
+}
+
+func (u *appUsecase) GetMyServerDetail(ctx context.Context, claim domain.JWTClaimUser, id string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check server resource
+	resource, err := u.mongodbRepo.FetchOneServerResource(ctx, map[string]interface{}{
+		"id":         id,
+		"customerID": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if resource == nil {
+		return response.Error(http.StatusBadRequest, "server not found")
+	}
+
+	return response.Success(resource)
+}
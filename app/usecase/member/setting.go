@@ -2,8 +2,11 @@ package usecase_member
 
 import (
 	"app/domain"
+	"app/domain/model"
+	"app/helpers"
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
@@ -28,8 +31,9 @@ func (u *appUsecase) ChangePassword(ctx context.Context, claim domain.JWTClaimUs
 	if payload.NewPassword == payload.OldPassword {
 		errValidation["newPassword"] = "new password must be different from old password"
 	}
-	if len(payload.NewPassword) < 8 {
-		errValidation["newPassword"] = "new password must be at least 8 characters"
+	policy := helpers.GetPasswordPolicy(u._CacheConfig(ctx))
+	if msg := helpers.ValidatePassword(policy, payload.NewPassword, claim.User.Email); msg != "" {
+		errValidation["newPassword"] = msg
 	}
 	if len(errValidation) > 0 {
 		return response.ErrorValidation(errValidation, "error validation")
@@ -53,17 +57,88 @@ func (u *appUsecase) ChangePassword(ctx context.Context, claim domain.JWTClaimUs
 		return response.Error(http.StatusBadRequest, "Wrong old password")
 	}
 
+	if helpers.IsPasswordReused(policy, payload.NewPassword, customer.Password, customer.PasswordHistory) {
+		return response.ErrorValidation(map[string]string{
+			"newPassword": "password was used recently, choose a different one",
+		}, "error validation")
+	}
+
 	// hash new password
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.NewPassword), bcrypt.DefaultCost)
 
 	// update customer password
+	now := time.Now()
+	customer.PasswordHistory = helpers.NextPasswordHistory(policy, customer.Password, customer.PasswordHistory)
 	customer.Password = string(hashedPassword)
+	customer.PasswordChangedAt = &now
+	customer.MustChangePassword = false
+
+	// save
+	if err := u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+		"id": customer.ID,
+	}, map[string]interface{}{
+		"password":           customer.Password,
+		"passwordHistory":    customer.PasswordHistory,
+		"passwordChangedAt":  customer.PasswordChangedAt,
+		"mustChangePassword": false,
+		"updatedAt":          now,
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	// end the sessions signed in with the old password
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionCustomer, customer.ID.Hex(), model.RevokePasswordChanged); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(customer)
+}
+
+// ChangeCurrency currency new orders are charged in, existing orders keep their own
+func (u *appUsecase) ChangeCurrency(ctx context.Context, claim domain.JWTClaimUser, payload domain.ChangeCurrencyRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	payload.Currency = strings.ToUpper(strings.TrimSpace(payload.Currency))
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Currency == "" {
+		errValidation["currency"] = "currency field is required"
+	} else if !helpers.IsCurrencyCode(payload.Currency) {
+		errValidation["currency"] = "currency must be a 3 letter currency code"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// only currencies with a rate can be charged
+	rate, err := helpers.ExchangeRate(ctx, u.mongodbRepo, u._CacheConfig(ctx), payload.Currency)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if rate == 0 {
+		return response.Error(http.StatusBadRequest, "currency "+payload.Currency+" is not supported")
+	}
+
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	customer.Currency = payload.Currency
 
 	// save
 	if err := u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
 		"id": customer.ID,
 	}, map[string]interface{}{
-		"password":  customer.Password,
+		"currency":  customer.Currency,
 		"updatedAt": time.Now(),
 	}); err != nil {
 		return response.Error(http.StatusInternalServerError, err.Error())
@@ -0,0 +1,212 @@
+package usecase_member
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SSOAuthorize url of the company identity provider the customer is redirected to
+func (u *appUsecase) SSOAuthorize(ctx context.Context, payload domain.SSOAuthorizeRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.AccessKey == "" {
+		errValidation["accessKey"] = "accessKey field is required"
+	}
+	if payload.RedirectURI == "" {
+		errValidation["redirectUri"] = "redirectUri field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"accessKey": payload.AccessKey,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+	if !helpers.IsSSOEnabled(company, model.SessionCustomer) {
+		return response.Error(http.StatusBadRequest, "single sign-on is not enabled for this company")
+	}
+
+	authorizationURL, err := helpers.OIDCAuthorizationURL(ctx, helpers.GetJWTSecretKeyCustomer(), company, model.SessionCustomer, payload.RedirectURI, payload.Binding)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"authorizationUrl": authorizationURL,
+	})
+}
+
+// SSOCallback finish the login with the code returned by the identity provider
+func (u *appUsecase) SSOCallback(ctx context.Context, payload domain.SSOCallbackRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.State == "" {
+		errValidation["state"] = "state field is required"
+	}
+	if payload.Code == "" {
+		errValidation["code"] = "code field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	state, err := helpers.ParseOIDCState(helpers.GetJWTSecretKeyCustomer(), model.SessionCustomer, payload.State, payload.Binding)
+	if err != nil {
+		return response.Error(http.StatusUnauthorized, err.Error())
+	}
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": state.CompanyID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+	if !helpers.IsSSOEnabled(company, model.SessionCustomer) {
+		return response.Error(http.StatusBadRequest, "single sign-on is not enabled for this company")
+	}
+
+	identity, err := helpers.CompleteOIDCLogin(ctx, helpers.GetJWTSecretKeyCustomer(), company, state, payload.Code)
+	if err != nil {
+		return response.Error(http.StatusUnauthorized, err.Error())
+	}
+
+	user, errResponse := u._ssoCustomer(ctx, company, identity)
+	if errResponse != nil {
+		return *errResponse
+	}
+
+	// generate token
+	tokenString, refreshToken, err := u._issueToken(ctx, user)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"user":         user,
+		"token":        tokenString,
+		"refreshToken": refreshToken,
+	})
+}
+
+// _ssoCustomer find the customer linked to the identity, linking by email or provisioning it on the first login
+func (u *appUsecase) _ssoCustomer(ctx context.Context, company *model.Company, identity *helpers.OIDCIdentity) (*model.Customer, *response.Base) {
+	now := time.Now()
+	link := &model.SSOIdentity{
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		LastLoginAt: &now,
+	}
+
+	user, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"ssoIssuer":  identity.Issuer,
+		"ssoSubject": identity.Subject,
+	})
+	if err != nil {
+		errResponse := response.Error(http.StatusInternalServerError, err.Error())
+		return nil, &errResponse
+	}
+	if user == nil && identity.EmailVerified {
+		// only an email verified by the identity provider links an existing account
+		user, err = u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+			"email": identity.Email,
+		})
+		if err != nil {
+			errResponse := response.Error(http.StatusInternalServerError, err.Error())
+			return nil, &errResponse
+		}
+	}
+
+	if user != nil {
+		if user.Company.ID != company.ID.Hex() {
+			errResponse := response.Error(http.StatusBadRequest, "email already in use for "+user.Company.Name)
+			return nil, &errResponse
+		}
+		if user.SSO != nil && user.SSO.Subject != identity.Subject {
+			errResponse := response.Error(http.StatusBadRequest, "account is linked to another identity")
+			return nil, &errResponse
+		}
+
+		// the identity provider vouches for the email
+		user.SSO = link
+		user.UpdatedAt = now
+		update := map[string]interface{}{
+			"sso":       user.SSO,
+			"updatedAt": user.UpdatedAt,
+		}
+		if !user.IsVerified {
+			user.IsVerified = true
+			user.VerifiedAt = &now
+			update["isVerified"] = user.IsVerified
+			update["verifiedAt"] = user.VerifiedAt
+		}
+		if err := u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+			"id": user.ID,
+		}, update); err != nil {
+			errResponse := response.Error(http.StatusInternalServerError, err.Error())
+			return nil, &errResponse
+		}
+
+		return user, nil
+	}
+
+	// provision the customer, it has no password and can only login through the identity provider
+	user = &model.Customer{
+		ID:    primitive.NewObjectID(),
+		Name:  identity.Name,
+		Email: identity.Email,
+		Company: model.CompanyNested{
+			ID:    company.ID.Hex(),
+			Name:  company.Name,
+			Image: company.Logo.URL,
+			Type:  company.Type,
+		},
+		IsNeedBalance: company.Type == "B2C",
+		JobTitle:      identity.JobTitle,
+		Role:          model.CustomerRole,
+		IsVerified:    true,
+		VerifiedAt:    &now,
+		SSO:           link,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := u.mongodbRepo.CreateCustomer(ctx, user); err != nil {
+		errResponse := response.Error(http.StatusInternalServerError, err.Error())
+		return nil, &errResponse
+	}
+
+	u.mongodbRepo.IncrementOneCompany(ctx, company.ID.Hex(), map[string]int64{
+		"customerTotal": 1,
+	})
+
+	// free trial hours
+	if err := u._grantTrial(ctx, user, company); err != nil {
+		logrus.Error("grant trial: ", err)
+	}
+
+	return user, nil
+}
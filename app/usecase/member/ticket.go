@@ -225,13 +225,29 @@ func (u *appUsecase) CreateTicket(ctx context.Context, claim domain.JWTClaimUser
 	// get from config
 	config := u._CacheConfig(ctx)
 
-	// check is need balance
-	if customer.IsNeedBalance {
+	// get company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": customer.Company.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// pooled company draw from the company wallet, otherwise check is need balance
+	overagePolicy := helpers.GetOveragePolicy(company)
+	if helpers.IsPooledWallet(company) {
+		if !helpers.CanOpenTicket(overagePolicy, helpers.PooledTimeRemaining(company, customer), config.MinimumCredit) {
+			return response.Error(http.StatusBadRequest, "you don't have enough time balance")
+		}
+	} else if customer.IsNeedBalance {
 		if !(now.After(customer.Subscription.StartAt) && now.Before(customer.Subscription.EndAt)) || customer.Subscription.Status != model.Active {
 			return response.Error(http.StatusBadRequest, "you don't have active subscription")
 		}
 
-		if customer.Subscription.Balance == nil || customer.Subscription.Balance.Time.Remaining.Total < config.MinimumCredit {
+		if customer.Subscription.Balance == nil || !helpers.CanOpenTicket(overagePolicy, customer.Subscription.Balance.Time.Remaining.Total, config.MinimumCredit) {
 			return response.Error(http.StatusBadRequest, "you don't have enough time balance")
 		}
 	}
@@ -328,6 +344,7 @@ func (u *appUsecase) CreateTicket(ctx context.Context, claim domain.JWTClaimUser
 		},
 		Status:    model.Open,
 		Priority:  model.TicketPriority(payload.Priority),
+		Language:  strings.ToLower(strings.TrimSpace(payload.Language)),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -365,6 +382,12 @@ func (u *appUsecase) CreateTicket(ctx context.Context, claim domain.JWTClaimUser
 		}
 	}
 
+	// route by category and language skills
+	routedAgents, errRes := u._routeTicket(ctx, company, ticket)
+	if errRes != nil {
+		return *errRes
+	}
+
 	if err := u.mongodbRepo.CreateTicket(ctx, ticket); err != nil {
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
@@ -384,34 +407,9 @@ func (u *appUsecase) CreateTicket(ctx context.Context, claim domain.JWTClaimUser
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
-	// get company
-	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
-		"id": customer.Company.ID,
-	})
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, err.Error())
-	}
-	if company == nil {
-		return response.Error(http.StatusBadRequest, "company not found")
-	}
-
-	// get company agent
-	companyAgents, err := u.mongodbRepo.FetchAgentList(ctx, map[string]interface{}{
-		"companyID": company.ID.Hex(),
-	})
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, err.Error())
-	}
-
-	// get all agent email
+	// get routed agent email
 	agentEmails := make([]string, 0)
-	for companyAgents.Next(ctx) {
-		row := model.Agent{}
-		err := companyAgents.Decode(&row)
-		if err != nil {
-			logrus.Error("Agent Decode ", err)
-			return response.Error(http.StatusInternalServerError, err.Error())
-		}
+	for _, row := range routedAgents {
 		agentEmails = append(agentEmails, row.Email)
 	}
 
@@ -438,6 +436,52 @@ func (u *appUsecase) CreateTicket(ctx context.Context, claim domain.JWTClaimUser
 	return response.Success(ticket)
 }
 
+// _routeTicket agents to notify about a new ticket, best skill match first. Every agent is notified when nobody
+// handles the category, and the best match below the capacity is assigned when the company enables auto assign
+func (u *appUsecase) _routeTicket(ctx context.Context, company *model.Company, ticket *model.Ticket) ([]model.Agent, *response.Base) {
+	cur, err := u.mongodbRepo.FetchAgentList(ctx, map[string]interface{}{
+		"companyID": company.ID.Hex(),
+	})
+	if err != nil {
+		res := response.Error(http.StatusInternalServerError, err.Error())
+		return nil, &res
+	}
+	defer cur.Close(ctx)
+
+	agents := make([]model.Agent, 0)
+	for cur.Next(ctx) {
+		row := model.Agent{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("Agent Decode ", err)
+			res := response.Error(http.StatusInternalServerError, err.Error())
+			return nil, &res
+		}
+		agents = append(agents, row)
+	}
+
+	ranked := helpers.RankAgentsBySkill(agents, ticket)
+	if len(ranked) == 0 {
+		return agents, nil
+	}
+
+	if company.Settings.AutoAssignTicket {
+		agent, err := helpers.PickAgentByLoad(ctx, u.mongodbRepo, company, ranked, ticket)
+		if err != nil {
+			res := response.Error(http.StatusInternalServerError, err.Error())
+			return nil, &res
+		}
+		if agent != nil {
+			ticket.Agent = []model.AgentNested{{
+				ID:    agent.ID.Hex(),
+				Name:  agent.Name,
+				Email: agent.Email,
+			}}
+		}
+	}
+
+	return ranked, nil
+}
+
 func (u *appUsecase) _createNotification(ctx context.Context, ticket *model.Ticket, company *model.CompanyNested) (err error) {
 	// notif
 	var title string
@@ -460,10 +504,12 @@ func (u *appUsecase) _createNotification(ctx context.Context, ticket *model.Tick
 			ID:      ticket.ID.Hex(),
 			Subject: ticket.Subject,
 		},
-		Category:  *ticket.Category,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	if ticket.Category != nil {
+		notification.Category = *ticket.Category
+	}
 
 	if err := u.mongodbRepo.CreateNotification(ctx, notification); err != nil {
 		logrus.Error(err)
@@ -559,6 +605,17 @@ func (u *appUsecase) CloseTicket(ctx context.Context, claim domain.JWTClaimUser,
 		return response.Error(400, "ticket only can be close if status is in progress or resolve")
 	}
 
+	// find company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": ticket.Company.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
 	// only create timelogs if ticket is in progress
 	if ticket.Status == model.InProgress {
 		ticket.LogTime.EndAt = &now
@@ -609,10 +666,15 @@ func (u *appUsecase) CloseTicket(ctx context.Context, claim domain.JWTClaimUser,
 			return response.Error(http.StatusInternalServerError, err.Error())
 		}
 
-		// check need balance
-		if customer.IsNeedBalance {
+		// pooled company draw from the company wallet, otherwise check need balance
+		if helpers.IsPooledWallet(company) {
+			if err := helpers.DrawCompanyWallet(ctx, u.mongodbRepo, ticket, customer, company, int64(duration)); err != nil {
+				return response.Error(http.StatusInternalServerError, err.Error())
+			}
+		} else if customer.IsNeedBalance {
 			// calculate time balance
 			usedTime := customer.Subscription.Balance.Time.Used + int64(duration)
+			customer.Subscription.Balance.Time.Used = usedTime
 
 			// save update time balance customer
 			if err := u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
@@ -625,20 +687,16 @@ func (u *appUsecase) CloseTicket(ctx context.Context, claim domain.JWTClaimUser,
 			}
 
 			// create customer balance history
-			if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, &model.CustomerBalanceHistory{
-				ID:       primitive.NewObjectID(),
-				Customer: ticket.Customer,
-				Out:      int64(ticket.LogTime.DurationInSeconds),
-				Reference: model.Reference{
-					UniqueID: ticket.ID.Hex(),
-					Type:     model.TicketReference,
-				},
-				CreatedAt: now,
-				UpdatedAt: now,
-			}); err != nil {
+			if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, helpers.NewCustomerBalanceHistory(customer, 0, int64(duration), model.Reference{
+				UniqueID: ticket.ID.Hex(),
+				Number:   ticket.Code,
+				Type:     model.TicketReference,
+			}, "Ticket "+ticket.Subject)); err != nil {
 				return response.Error(http.StatusInternalServerError, err.Error())
 			}
 		}
+
+		helpers.CheckLowBalance(ctx, u.mongodbRepo, u._CacheConfig(ctx), ticket, customer, company, helpers.TimeRemaining(company, customer))
 	}
 
 	// update ticket
@@ -650,17 +708,6 @@ func (u *appUsecase) CloseTicket(ctx context.Context, claim domain.JWTClaimUser,
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
-	// find company
-	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
-		"id": ticket.Company.ID,
-	})
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, err.Error())
-	}
-	if company == nil {
-		return response.Error(http.StatusBadRequest, "company not found")
-	}
-
 	// get config
 	config := u._CacheConfig(ctx)
 
@@ -1108,13 +1155,29 @@ func (u *appUsecase) ReopenTicket(ctx context.Context, claim domain.JWTClaimUser
 	// get from config
 	config := u._CacheConfig(ctx)
 
-	// check is need balance
-	if customer.IsNeedBalance {
+	// find company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": ticket.Company.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// pooled company draw from the company wallet, otherwise check is need balance
+	overagePolicy := helpers.GetOveragePolicy(company)
+	if helpers.IsPooledWallet(company) {
+		if !helpers.CanOpenTicket(overagePolicy, helpers.PooledTimeRemaining(company, customer), config.MinimumCredit) {
+			return response.Error(http.StatusBadRequest, "you don't have enough time balance")
+		}
+	} else if customer.IsNeedBalance {
 		if !(now.After(customer.Subscription.StartAt) && now.Before(customer.Subscription.EndAt)) || customer.Subscription.Status != model.Active {
 			return response.Error(http.StatusBadRequest, "you don't have active subscription")
 		}
 
-		if customer.Subscription.Balance == nil || customer.Subscription.Balance.Time.Remaining.Total < config.MinimumCredit {
+		if customer.Subscription.Balance == nil || !helpers.CanOpenTicket(overagePolicy, customer.Subscription.Balance.Time.Remaining.Total, config.MinimumCredit) {
 			return response.Error(http.StatusBadRequest, "you don't have enough time balance")
 		}
 	}
@@ -1128,17 +1191,6 @@ func (u *appUsecase) ReopenTicket(ctx context.Context, claim domain.JWTClaimUser
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
-	// find company
-	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
-		"id": ticket.Company.ID,
-	})
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, err.Error())
-	}
-	if company == nil {
-		return response.Error(http.StatusBadRequest, "company not found")
-	}
-
 	// check agent assigned
 	if len(ticket.Agent) > 0 {
 		// send notification to assigned agent
@@ -0,0 +1,35 @@
+package usecase_member
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"time"
+)
+
+// _grantTrial grant the auto trial package at registration, with the same order, subscription and ledger as a paid hour order
+func (u *appUsecase) _grantTrial(ctx context.Context, customer *model.Customer, company *model.Company) (err error) {
+	if !helpers.CanGrantTrial(company, customer) {
+		return
+	}
+
+	// check trial package
+	pkg, err := u.mongodbRepo.FetchOneHourPackage(ctx, map[string]interface{}{
+		"type":      string(model.HourPackageTypeTrial),
+		"autoGrant": true,
+		"status":    string(model.HourPackageActive),
+	})
+	if err != nil || pkg == nil || !pkg.IsTrial() {
+		return
+	}
+
+	// count order
+	count := u.mongodbRepo.CountOrder(ctx, map[string]interface{}{
+		"today": true,
+	})
+	order := helpers.NewTrialOrder(customer, company, pkg, helpers.GenerateFormattedCode("TRX", count+1, helpers.RandomChar(3)), model.UserNested{})
+
+	now := time.Now()
+	_, err = u.billing.GrantTrial(ctx, u._CacheConfig(ctx), order, customer, company, pkg, now, now)
+	return
+}
@@ -12,8 +12,6 @@ import (
 	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
 	"github.com/sirupsen/logrus"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func (u *appUsecase) GetUserList(ctx context.Context, claim domain.JWTClaimUser, options map[string]interface{}) response.Base {
@@ -114,6 +112,7 @@ func (u *appUsecase) GetUserDetail(ctx context.Context, claim domain.JWTClaimUse
 	return response.Success(helpers.CustomerBalanceFormat(customer))
 }
 
+// CreateUser invite the user, the account is created once the invitation is accepted
 func (u *appUsecase) CreateUser(ctx context.Context, claim domain.JWTClaimUser, options map[string]interface{}) response.Base {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
@@ -121,44 +120,13 @@ func (u *appUsecase) CreateUser(ctx context.Context, claim domain.JWTClaimUser,
 	// payload
 	payload := options["payload"].(domain.CreateUserRequest)
 
-	errValidation := make(map[string]string)
-
 	// validating request
-	if payload.Name == "" {
-		errValidation["name"] = "name field is required"
-	}
-
-	if payload.Email == "" {
-		errValidation["email"] = "email field is required"
-	} else if !helpers.IsValidEmail(payload.Email) {
-		errValidation["email"] = "email field is invalid"
-	}
-
-	if payload.JobTitle == "" {
-		errValidation["jobTitle"] = "jobTitle field is required"
-	}
-
-	if payload.Role == "" {
-		errValidation["role"] = "role field is required"
-	} else if !helpers.InArrayString(payload.Role, []string{"admin", "customer"}) {
-		errValidation["role"] = "role field must be admin or customer"
-	}
-
-	if len(errValidation) > 0 {
-		return response.ErrorValidation(errValidation, "error validation")
-	}
-
-	// check customer
-	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
-		"email": payload.Email,
-	})
-
+	errValidation, err := u._validateInvitation(ctx, claim, &payload)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
-
-	if customer != nil {
-		return response.Error(http.StatusBadRequest, "email already in use")
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
 	}
 
 	// get company
@@ -172,69 +140,12 @@ func (u *appUsecase) CreateUser(ctx context.Context, claim domain.JWTClaimUser,
 		return response.Error(http.StatusUnauthorized, "company not found")
 	}
 
-	password := helpers.RandomString(5)
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-
-	t := time.Now()
-
-	isNeedBalance := false
-	if claim.Company.Type == "B2C" {
-		isNeedBalance = true
-	}
-
-	// create customer
-	newUser := &model.Customer{
-		ID:       primitive.NewObjectID(),
-		Name:     payload.Name,
-		Email:    payload.Email,
-		Password: string(hashedPassword),
-		// CompanyProduct: claim.CompanyProduct,
-		Company:       claim.Company,
-		IsNeedBalance: isNeedBalance,
-		Subscription:  nil,
-		JobTitle:      payload.JobTitle,
-		Role:          model.UserRole(payload.Role),
-		IsVerified:    true,
-		VerifiedAt:    &t,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+	invitation, errResponse := u._inviteUser(ctx, claim, company, payload)
+	if errResponse != nil {
+		return *errResponse
 	}
 
-	err = u.mongodbRepo.CreateCustomer(ctx, newUser)
-
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, err.Error())
-	}
-
-	// get from config
-	config := u._CacheConfig(ctx)
-
-	go func() {
-		_sendEmailCustomerCrendential(config, *newUser, password, *company)
-	}()
-
-	return response.Success(newUser)
-}
-
-func _sendEmailCustomerCrendential(config model.Config, customer model.Customer, password string, company model.Company) {
-	loginLink := helpers.StringReplacer(config.LoginLink, map[string]string{
-		"base_url_frontend": company.Settings.Domain.FullUrl,
-	})
-	// send email
-	mail := helpers.NewSMTPMailer(&company)
-	mail.To([]string{customer.Email})
-	mail.Subject(config.Email.Template.DefaultUser.Title)
-	mail.Body(helpers.StringReplacer(config.Email.Template.DefaultUser.Body, map[string]string{
-		"title":      config.Email.Template.DefaultUser.Title,
-		"email":      customer.Email,
-		"password":   password,
-		"login_link": loginLink,
-	}))
-
-	// send
-	if err := mail.Send(); err != nil {
-		logrus.Errorf("Send Email to %s error %v", customer.Email, err)
-	}
+	return response.Success(invitation)
 }
 
 func (u *appUsecase) UpdateUser(ctx context.Context, claim domain.JWTClaimUser, options map[string]interface{}) response.Base {
@@ -256,8 +167,14 @@ func (u *appUsecase) UpdateUser(ctx context.Context, claim domain.JWTClaimUser,
 	}
 	if payload.Role == "" {
 		errValidation["role"] = "role field is required"
-	} else if !helpers.InArrayString(payload.Role, []string{"admin", "customer"}) {
-		errValidation["role"] = "role field must be admin or customer"
+	} else if role, err := u._findRole(ctx, claim.CompanyID, model.RoleScopeCustomer, payload.Role); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	} else if role == nil {
+		errValidation["role"] = "role not found"
+	} else if allowed, err := u._canGrantRole(ctx, claim, role); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	} else if !allowed {
+		errValidation["role"] = "role has permissions you do not have"
 	}
 
 	if len(errValidation) > 0 {
@@ -342,5 +259,10 @@ func (u *appUsecase) DeleteUser(ctx context.Context, claim domain.JWTClaimUser,
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
+	// end the sessions of the deleted user
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionCustomer, customer.ID.Hex(), model.RevokeUserDeleted); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
 	return response.Success("User deleted successfully")
 }
@@ -140,28 +140,34 @@ func (u *superadminUsecase) CreateAgent(ctx context.Context, claim domain.JWTCla
 	// get from config
 	config := u._CacheConfig(ctx)
 
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(helpers.RandomString(5)), bcrypt.DefaultCost)
+	// one time password, it has to be changed on the first login
+	password := helpers.GeneratePassword(helpers.GetPasswordPolicy(config))
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	now := time.Now()
 
 	newAgent := model.Agent{
-		ID:       primitive.NewObjectID(),
-		Name:     payload.Name,
-		Email:    payload.Email,
-		Password: string(hashedPassword),
-		Role:     model.AgentRole,
-		Category: model.TicketCategoryFK{
-			ID:   payload.Category,
-			Name: category.Name,
-		},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
+		ID:                 primitive.NewObjectID(),
+		Name:               payload.Name,
+		Email:              payload.Email,
+		Password:           string(hashedPassword),
+		PasswordChangedAt:  &now,
+		MustChangePassword: true,
+		Role:               model.AgentRole,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	helpers.SetAgentCategories(&newAgent, []model.AgentCategory{{
+		ID:          category.ID.Hex(),
+		Name:        category.Name,
+		Proficiency: helpers.AgentProficiencyDefault,
+	}})
 
 	err = u.mongodbRepo.CreateAgent(ctx, &newAgent)
 	if err != nil {
 		return response.Error(http.StatusBadRequest, err.Error())
 	}
 
-	go _sendEmailAgentCrendential(config, newAgent, string(hashedPassword), company)
+	go _sendEmailAgentCrendential(config, newAgent, password, company)
 
 	return response.Success(newAgent)
 }
@@ -251,6 +257,11 @@ func (u *superadminUsecase) DeleteAgent(ctx context.Context, claim domain.JWTCla
 		return response.Error(http.StatusBadRequest, err.Error())
 	}
 
+	// end the sessions of the deleted user
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionAgent, agent.ID.Hex(), model.RevokeUserDeleted); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
 	return response.Success(agent)
 }
 
@@ -268,18 +279,41 @@ func (u *superadminUsecase) ResetPasswordAgent(ctx context.Context, claim domain
 		return response.Error(http.StatusBadRequest, "agent not found")
 	}
 
-	defaultPassword := helpers.RandomString(5)
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": agent.Company.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// one time password, it has to be changed on the first login
+	config := u._CacheConfig(ctx)
+	policy := helpers.GetPasswordPolicy(config)
+	defaultPassword := helpers.GeneratePassword(policy)
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(defaultPassword), bcrypt.DefaultCost)
 
 	now := time.Now()
 	agent.UpdatedAt = now
+	agent.PasswordHistory = helpers.NextPasswordHistory(policy, agent.Password, agent.PasswordHistory)
 	agent.Password = string(hashedPassword)
+	agent.PasswordChangedAt = &now
+	agent.MustChangePassword = true
 
 	err = u.mongodbRepo.UpdateAgent(ctx, agent)
 	if err != nil {
 		return response.Error(http.StatusBadRequest, err.Error())
 	}
 
+	// end the sessions signed in with the old password
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionAgent, agent.ID.Hex(), model.RevokePasswordChanged); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	go _sendEmailAgentCrendential(config, *agent, defaultPassword, company)
+
 	return response.Success(agent)
 }
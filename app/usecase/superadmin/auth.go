@@ -2,14 +2,12 @@ package usecase_superadmin
 
 import (
 	"app/domain"
+	"app/domain/model"
 	"app/helpers"
 	"context"
 	"net/http"
-	"time"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -29,6 +27,13 @@ func (u *superadminUsecase) Login(ctx context.Context, payload domain.Superadmin
 		return response.ErrorValidation(errValidation, "error validation")
 	}
 
+	// slow down repeated failures on the account or from the same address
+	throttle := helpers.NewLoginThrottle(u.redisRepo)
+	if wait := throttle.Wait(ctx, model.SessionSuperadmin, payload.Email, payload.IPAddress); wait > 0 {
+		u._recordSecurityEvent(ctx, model.SecurityLoginThrottled, model.SessionSuperadmin, "", model.UserNested{Email: payload.Email}, payload, "too many attempts")
+		return response.Error(http.StatusTooManyRequests, helpers.LoginWaitMessage(wait))
+	}
+
 	// check the db
 	user, err := u.mongodbRepo.FetchOneSuperadmin(ctx, map[string]interface{}{
 		"email": payload.Email,
@@ -37,33 +42,56 @@ func (u *superadminUsecase) Login(ctx context.Context, payload domain.Superadmin
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 	if user == nil {
+		throttle.Fail(ctx, model.SessionSuperadmin, payload.Email, payload.IPAddress)
+		u._recordSecurityEvent(ctx, model.SecurityLoginFailed, model.SessionSuperadmin, "", model.UserNested{Email: payload.Email}, payload, "user not found")
 		return response.Error(http.StatusBadRequest, "user not found")
 	}
 
+	nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+	if user.Lockout.IsActive() {
+		u._recordSecurityEvent(ctx, model.SecurityLoginFailed, model.SessionSuperadmin, "", nested, payload, "account locked")
+		return response.Error(http.StatusForbidden, "account is locked, try again later")
+	}
+
 	// check password
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password)); err != nil {
-		return response.Error(http.StatusBadRequest, "Wrong password")
+		return u._loginFailed(ctx, throttle, user, payload)
+	}
+
+	throttle.Reset(ctx, model.SessionSuperadmin, payload.Email)
+	if user.Lockout != nil {
+		// the expired lockout is cleared on the first good login
+		u.mongodbRepo.UpdateOneSuperadmin(ctx, map[string]interface{}{"id": user.ID}, map[string]interface{}{"lockout": nil})
+		user.Lockout = nil
+	}
+	u._recordSecurityEvent(ctx, model.SecurityLoginSuccess, model.SessionSuperadmin, "", nested, payload, "")
+
+	// second step with the authenticator app
+	if user.TwoFactor.Enabled {
+		return u._twoFactorChallenge(user)
 	}
 
+	return u._completeLogin(ctx, user, nil)
+}
+
+// _completeLogin issue the tokens, recovery codes are included once right after enrollment
+func (u *superadminUsecase) _completeLogin(ctx context.Context, user *model.Superadmin, recoveryCodes []string) response.Base {
 	// generate token
-	tokenString, err := helpers.GenerateJWTTokenSuperadmin(domain.JWTClaimSuperadmin{
-		UserID: user.ID.Hex(),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ID:        uuid.NewString(),
-			Issuer:    "superadmin",
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(helpers.GetJWTTTL()) * time.Minute)),
-		},
-	})
+	tokenString, refreshToken, err := u._issueToken(ctx, user)
 	if err != nil {
 		return response.Error(http.StatusBadRequest, err.Error())
 	}
 
-	return response.Success(map[string]interface{}{
-		"user":  user,
-		"token": tokenString,
-	})
+	result := map[string]interface{}{
+		"user":         user,
+		"token":        tokenString,
+		"refreshToken": refreshToken,
+	}
+	if recoveryCodes != nil {
+		result["recoveryCodes"] = recoveryCodes
+	}
+
+	return response.Success(result)
 }
 
 func (u *superadminUsecase) GetMe(ctx context.Context, claim domain.JWTClaimSuperadmin) response.Base {
@@ -339,17 +339,21 @@ func (u *superadminUsecase) CreateCompany(ctx context.Context, claim domain.JWTC
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
-	password := helpers.RandomString(5)
+	// one time password, it has to be changed on the first login
+	password := helpers.GeneratePassword(helpers.GetPasswordPolicy(config))
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	now := time.Now()
 
 	// create agent
 	newAgent := &model.Agent{
-		ID:       primitive.NewObjectID(),
-		Name:     payload.Name,
-		Email:    payload.Email,
-		JobTitle: "Admin",
-		Password: string(hashedPassword),
-		Role:     model.AdminRole,
+		ID:                 primitive.NewObjectID(),
+		Name:               payload.Name,
+		Email:              payload.Email,
+		JobTitle:           "Admin",
+		Password:           string(hashedPassword),
+		PasswordChangedAt:  &now,
+		MustChangePassword: true,
+		Role:               model.AdminRole,
 		Company: model.CompanyNested{
 			ID:       company.ID.Hex(),
 			Name:     company.Name,
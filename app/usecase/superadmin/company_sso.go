@@ -0,0 +1,117 @@
+package usecase_superadmin
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+)
+
+// UpdateCompanySSO configure the OpenID Connect identity provider of a company
+func (u *superadminUsecase) UpdateCompanySSO(ctx context.Context, claim domain.JWTClaimSuperadmin, options map[string]interface{}) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	payload := options["payload"].(domain.UpdateCompanySSORequest)
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": options["id"],
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// the secret is kept unless a new one is sent
+	clientSecret := ""
+	if company.SSO != nil {
+		clientSecret = company.SSO.ClientSecret
+	}
+	if payload.ClientSecret != nil {
+		clientSecret = *payload.ClientSecret
+	}
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Enabled {
+		if err := helpers.ValidateOIDCIssuer(payload.Issuer); err != nil {
+			errValidation["issuer"] = err.Error()
+		}
+		if payload.ClientID == "" {
+			errValidation["clientId"] = "clientId field is required"
+		}
+		if len(payload.RedirectURIs) == 0 {
+			errValidation["redirectUris"] = "redirectUris field is required"
+		}
+		if !payload.Agent && !payload.Customer {
+			errValidation["agent"] = "enable single sign-on for agents, customers or both"
+		}
+	}
+	for _, redirectURI := range payload.RedirectURIs {
+		if parsed, err := url.Parse(redirectURI); err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+			errValidation["redirectUris"] = "redirectUris must be absolute urls"
+		}
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check category
+	defaultCategory := model.TicketCategoryFK{}
+	if payload.DefaultCategoryID != "" {
+		category, err := u.mongodbRepo.FetchOneTicketCategory(ctx, map[string]interface{}{
+			"id":        payload.DefaultCategoryID,
+			"companyID": company.ID.Hex(),
+		})
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		if category == nil {
+			return response.Error(http.StatusBadRequest, "category not found")
+		}
+		defaultCategory = model.TicketCategoryFK{ID: category.ID.Hex(), Name: category.Name}
+	}
+
+	// the provider must be reachable before it is switched on
+	if payload.Enabled {
+		if _, err := helpers.FetchOIDCDiscovery(ctx, payload.Issuer); err != nil {
+			return response.Error(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	now := time.Now()
+	company.SSO = &model.CompanySSO{
+		Enabled:         payload.Enabled,
+		Issuer:          payload.Issuer,
+		ClientID:        payload.ClientID,
+		ClientSecret:    clientSecret,
+		Scopes:          payload.Scopes,
+		RedirectURIs:    payload.RedirectURIs,
+		ClaimMapping:    payload.ClaimMapping,
+		AllowedDomains:  payload.AllowedDomains,
+		Agent:           payload.Agent,
+		Customer:        payload.Customer,
+		DefaultCategory: defaultCategory,
+		UpdatedAt:       &now,
+	}
+	company.UpdatedAt = now
+
+	if err := u.mongodbRepo.UpdatePartialCompany(ctx, map[string]interface{}{
+		"id": company.ID,
+	}, map[string]interface{}{
+		"sso":       company.SSO,
+		"updatedAt": company.UpdatedAt,
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(company)
+}
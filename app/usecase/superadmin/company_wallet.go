@@ -0,0 +1,52 @@
+package usecase_superadmin
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+)
+
+func (u *superadminUsecase) UpdateCompanyWallet(ctx context.Context, claim domain.JWTClaimSuperadmin, options map[string]interface{}) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	payload := options["payload"].(domain.UpdateCompanyWalletRequest)
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": options["id"],
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	if company.Type == "B2C" {
+		return response.Error(http.StatusBadRequest, "company type must be B2B")
+	}
+
+	// keep the balance when the pool is turned off, it is used again when turned back on
+	if company.Wallet == nil {
+		company.Wallet = &model.CompanyWallet{}
+	}
+	company.Wallet.IsPooled = payload.IsPooled
+	company.UpdatedAt = time.Now()
+
+	if err := u.mongodbRepo.UpdatePartialCompany(ctx, map[string]interface{}{
+		"id": company.ID,
+	}, map[string]interface{}{
+		"wallet":    company.Wallet,
+		"updatedAt": company.UpdatedAt,
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(helpers.CompanyWalletFormat(company))
+}
@@ -0,0 +1,101 @@
+package usecase_superadmin
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+
+	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+)
+
+func (u *superadminUsecase) GetCreditNoteList(ctx context.Context, claim domain.JWTClaimSuperadmin, query url.Values) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	page, limit, offset := yurekahelpers.GetLimitOffset(query)
+
+	fetchOptions := map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+		"sort":   "createdAt",
+		"dir":    "desc",
+	}
+
+	// filtering
+	if query.Get("orderId") != "" {
+		fetchOptions["orderID"] = query.Get("orderId")
+	}
+	if query.Get("customerId") != "" {
+		fetchOptions["customerID"] = query.Get("customerId")
+	}
+
+	// count first
+	total := u.mongodbRepo.CountCreditNote(ctx, fetchOptions)
+	if total == 0 {
+		return response.Success(domain.ResponseList{
+			List: response.List{
+				List:  []interface{}{},
+				Page:  page,
+				Limit: limit,
+				Total: total,
+			},
+			TotalPage: helpers.GetTotalPage(total, limit),
+		})
+	}
+
+	cur, err := u.mongodbRepo.FetchCreditNoteList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.CreditNote{}
+		if err := cur.Decode(&row); err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		list = append(list, row)
+	}
+
+	return response.Success(domain.ResponseList{
+		List: response.List{
+			List:  list,
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+		TotalPage: helpers.GetTotalPage(total, limit),
+	})
+}
+
+func (u *superadminUsecase) GetCreditNoteDetail(ctx context.Context, creditNoteID string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check credit note
+	creditNote, err := u.mongodbRepo.FetchOneCreditNote(ctx, map[string]interface{}{
+		"id": creditNoteID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if creditNote == nil {
+		return response.Error(http.StatusBadRequest, "credit note not found")
+	}
+
+	// refresh private link
+	if creditNote.Document != nil {
+		link, err := u.s3Repo.GetPresignedLink(creditNote.Document.ProviderKey, nil)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		creditNote.Document.URL = link.URL
+	}
+
+	return response.Success(creditNote)
+}
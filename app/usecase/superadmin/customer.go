@@ -125,7 +125,9 @@ func (u *superadminUsecase) CreateCustomer(ctx context.Context, claim domain.JWT
 		return response.ErrorValidation(errValidation, "error validation")
 	}
 
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(helpers.RandomString(5)), bcrypt.DefaultCost)
+	// one time password, it has to be changed on the first login
+	password := helpers.GeneratePassword(helpers.GetPasswordPolicy(u._CacheConfig(ctx)))
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 
 	// check the user
 	existingUser, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
@@ -197,19 +199,22 @@ func (u *superadminUsecase) CreateCustomer(ctx context.Context, claim domain.JWT
 		}
 	}
 
+	now := time.Now()
 	newUser := model.Customer{
 		ID:      primitive.NewObjectID(),
 		Company: newCompanyNested,
 		// CompanyProduct: newCompanyProductNested,
-		Name:          payload.Name,
-		Email:         payload.Email,
-		Password:      string(hashedPassword),
-		Role:          model.AdminRole,
-		IsVerified:    true,
-		IsNeedBalance: isNeedBalance,
-		Subscription:  subscription,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		Name:               payload.Name,
+		Email:              payload.Email,
+		Password:           string(hashedPassword),
+		PasswordChangedAt:  &now,
+		MustChangePassword: true,
+		Role:               model.AdminRole,
+		IsVerified:         true,
+		IsNeedBalance:      isNeedBalance,
+		Subscription:       subscription,
+		CreatedAt:          now,
+		UpdatedAt:          now,
 	}
 
 	err = u.mongodbRepo.CreateCustomer(ctx, &newUser)
@@ -217,7 +222,7 @@ func (u *superadminUsecase) CreateCustomer(ctx context.Context, claim domain.JWT
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
-	go _sendEmailCustomerCrendential(config, newUser, string(hashedPassword), *company)
+	go _sendEmailCustomerCrendential(config, newUser, password, *company)
 
 	return response.Success(newUser)
 
@@ -319,6 +324,11 @@ func (u *superadminUsecase) DeleteCustomer(ctx context.Context, claim domain.JWT
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
+	// end the sessions of the deleted user
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionCustomer, customer.ID.Hex(), model.RevokeUserDeleted); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
 	return response.Success(customer)
 }
 
@@ -336,24 +346,50 @@ func (u *superadminUsecase) ResetPasswordCustomer(ctx context.Context, claim dom
 		return response.Error(http.StatusBadRequest, "customer not found")
 	}
 
-	defaultPassword := helpers.RandomString(5)
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": customer.Company.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// one time password, it has to be changed on the first login
+	config := u._CacheConfig(ctx)
+	policy := helpers.GetPasswordPolicy(config)
+	defaultPassword := helpers.GeneratePassword(policy)
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(defaultPassword), bcrypt.DefaultCost)
 
 	now := time.Now()
 	customer.UpdatedAt = now
+	customer.PasswordHistory = helpers.NextPasswordHistory(policy, customer.Password, customer.PasswordHistory)
 	customer.Password = string(hashedPassword)
+	customer.PasswordChangedAt = &now
+	customer.MustChangePassword = true
 
 	if err := u.mongodbRepo.UpdateOneCustomer(
 		ctx,
 		map[string]interface{}{"id": customer.ID},
 		map[string]interface{}{
-			"updatedAt": customer.UpdatedAt,
-			"password":  customer.Password,
+			"updatedAt":          customer.UpdatedAt,
+			"password":           customer.Password,
+			"passwordHistory":    customer.PasswordHistory,
+			"passwordChangedAt":  customer.PasswordChangedAt,
+			"mustChangePassword": true,
 		}); err != nil {
 		return response.Error(http.StatusInternalServerError, err.Error())
 	}
 
+	// end the sessions signed in with the old password
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionCustomer, customer.ID.Hex(), model.RevokePasswordChanged); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	go _sendEmailCustomerCrendential(config, *customer, defaultPassword, *company)
+
 	return response.Success(customer)
 }
 
@@ -467,16 +503,19 @@ func (u *superadminUsecase) ImportCustomer(ctx context.Context, claim domain.JWT
 			// 	Image: companyProductMap[record[6]].Logo.URL,
 			// 	Code:  companyProductMap[record[6]].Code,
 			// },
-			Name:          record[1],
-			Email:         record[2],
-			JobTitle:      record[3],
-			IsVerified:    true,
-			Password:      string(hashedPassword),
-			IsNeedBalance: isNeedBalance,
-			Role:          model.AdminRole,
-			CreatedAt:     t,
-			UpdatedAt:     t,
-			VerifiedAt:    &t,
+			Name:       record[1],
+			Email:      record[2],
+			JobTitle:   record[3],
+			IsVerified: true,
+			Password:   string(hashedPassword),
+			// the imported password is known to the importer, so it is only good once
+			PasswordChangedAt:  &t,
+			MustChangePassword: true,
+			IsNeedBalance:      isNeedBalance,
+			Role:               model.AdminRole,
+			CreatedAt:          t,
+			UpdatedAt:          t,
+			VerifiedAt:         &t,
 		})
 
 		count++
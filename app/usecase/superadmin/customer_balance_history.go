@@ -0,0 +1,80 @@
+package usecase_superadmin
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+)
+
+func (u *superadminUsecase) GetCustomerBalanceReconciliation(ctx context.Context, claim domain.JWTClaimSuperadmin, customerId string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": customerId,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	result := model.BalanceReconciliation{
+		Customer: model.CustomerFK{
+			ID:    customer.ID.Hex(),
+			Name:  customer.Name,
+			Email: customer.Email,
+		},
+	}
+
+	// whole ledger
+	result.LedgerIn, result.LedgerOut, err = u.mongodbRepo.SumCustomerBalanceHistory(ctx, map[string]interface{}{
+		"customerID": result.Customer.ID,
+		"wallet":     model.WalletCustomer,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	result.LedgerBalance = result.LedgerIn - result.LedgerOut
+
+	// current period start after the last expired package
+	periodOptions := map[string]interface{}{
+		"customerID": result.Customer.ID,
+		"wallet":     model.WalletCustomer,
+	}
+	lastExpired, err := u.mongodbRepo.FetchOneCustomerBalanceHistory(ctx, map[string]interface{}{
+		"customerID":    result.Customer.ID,
+		"referenceType": model.ExpiredReference,
+		"sort":          "createdAt",
+		"dir":           "desc",
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if lastExpired != nil {
+		periodOptions["createdFrom"] = lastExpired.CreatedAt.Add(time.Millisecond)
+	}
+
+	result.LedgerPeriodIn, result.LedgerPeriodUsed, err = u.mongodbRepo.SumCustomerBalanceHistory(ctx, periodOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	// compare with the balance stored on the customer
+	if customer.Subscription != nil && customer.Subscription.Balance != nil {
+		result.SubscriptionTotal = customer.Subscription.Balance.Time.Total
+		result.SubscriptionUsed = customer.Subscription.Balance.Time.Used
+		result.Remaining = result.SubscriptionTotal - result.SubscriptionUsed
+	}
+
+	result.IsMatched = result.LedgerPeriodIn == result.SubscriptionTotal &&
+		result.LedgerPeriodUsed == result.SubscriptionUsed &&
+		result.LedgerBalance == result.Remaining
+
+	return response.Success(result)
+}
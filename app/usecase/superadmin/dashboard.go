@@ -5,6 +5,7 @@ import (
 	"app/domain/model"
 	"app/helpers"
 	"context"
+	"math"
 	"net/url"
 
 	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
@@ -24,12 +25,28 @@ func (u *superadminUsecase) GetDataDashboard(ctx context.Context, claim domain.J
 	})
 	totalAgent := u.mongodbRepo.CountAgent(ctx, map[string]interface{}{})
 
+	// trial conversion
+	totalTrial := u.mongodbRepo.CountOrder(ctx, map[string]interface{}{
+		"trial": true,
+	})
+	totalTrialConverted := u.mongodbRepo.CountOrder(ctx, map[string]interface{}{
+		"trial":          true,
+		"trialConverted": true,
+	})
+	trialConversionRate := float64(0)
+	if totalTrial > 0 {
+		trialConversionRate = math.Round(float64(totalTrialConverted)/float64(totalTrial)*10000) / 100
+	}
+
 	result := map[string]interface{}{
-		"totalClient":    totalClient,
-		"totalCustomer":  totalCustomer,
-		"totalNewTicket": totalNewTicket,
-		"totalAgent":     totalAgent,
-		"totalOrder":     totalOrder,
+		"totalClient":         totalClient,
+		"totalCustomer":       totalCustomer,
+		"totalNewTicket":      totalNewTicket,
+		"totalAgent":          totalAgent,
+		"totalOrder":          totalOrder,
+		"totalTrial":          totalTrial,
+		"totalTrialConverted": totalTrialConverted,
+		"trialConversionRate": trialConversionRate,
 	}
 
 	return response.Success(result)
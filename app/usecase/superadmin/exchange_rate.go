@@ -0,0 +1,136 @@
+package usecase_superadmin
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (u *superadminUsecase) GetExchangeRateList(ctx context.Context, claim domain.JWTClaimSuperadmin, query url.Values) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	page, limit, offset := yurekahelpers.GetLimitOffset(query)
+
+	fetchOptions := map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+		"sort":   "effectiveAt",
+		"dir":    "desc",
+	}
+
+	// filtering
+	if query.Get("currency") != "" {
+		fetchOptions["currency"] = strings.ToUpper(query.Get("currency"))
+	}
+
+	// count first
+	total := u.mongodbRepo.CountExchangeRate(ctx, fetchOptions)
+	if total == 0 {
+		return response.Success(domain.ResponseList{
+			List: response.List{
+				List:  []interface{}{},
+				Page:  page,
+				Limit: limit,
+				Total: total,
+			},
+			TotalPage: helpers.GetTotalPage(total, limit),
+		})
+	}
+
+	cur, err := u.mongodbRepo.FetchExchangeRateList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.ExchangeRate{}
+		if err := cur.Decode(&row); err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		list = append(list, row)
+	}
+
+	return response.Success(domain.ResponseList{
+		List: response.List{
+			List:  list,
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+		TotalPage: helpers.GetTotalPage(total, limit),
+	})
+}
+
+func (u *superadminUsecase) GetLatestExchangeRate(ctx context.Context, currency string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	exchangeRate, err := u.mongodbRepo.FetchLatestExchangeRate(ctx, strings.ToUpper(currency))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if exchangeRate == nil {
+		return response.Error(http.StatusBadRequest, "exchange rate not found")
+	}
+
+	return response.Success(exchangeRate)
+}
+
+// CreateExchangeRate rates are kept as history, orders keep the rate they were created with
+func (u *superadminUsecase) CreateExchangeRate(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.ExchangeRateRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	payload.Currency = strings.ToUpper(strings.TrimSpace(payload.Currency))
+
+	// validating
+	errValidation := make(map[string]string)
+	if payload.Currency == "" {
+		errValidation["currency"] = "currency field is required"
+	} else if !helpers.IsCurrencyCode(payload.Currency) {
+		errValidation["currency"] = "currency must be a 3 letter currency code"
+	} else if payload.Currency == model.BaseCurrency {
+		errValidation["currency"] = "currency cannot be the base currency " + model.BaseCurrency
+	}
+	if payload.Rate <= 0 {
+		errValidation["rate"] = "rate field must be greater than 0"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	now := time.Now()
+	effectiveAt := now
+	if payload.EffectiveAt != nil {
+		effectiveAt = *payload.EffectiveAt
+	}
+
+	exchangeRate := &model.ExchangeRate{
+		ID:          primitive.NewObjectID(),
+		Currency:    payload.Currency,
+		Rate:        payload.Rate,
+		Note:        payload.Note,
+		CreatedBy:   claim.User,
+		EffectiveAt: effectiveAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := u.mongodbRepo.CreateExchangeRate(ctx, exchangeRate); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(exchangeRate)
+}
@@ -42,6 +42,10 @@ func (u *superadminUsecase) GetHourPackages(ctx context.Context, claim domain.JW
 		fetchOptions["q"] = query.Get("q")
 	}
 
+	if query.Get("type") != "" {
+		fetchOptions["type"] = query.Get("type")
+	}
+
 	// count first
 	totalpackages := u.mongodbRepo.CountHourPackage(ctx, fetchOptions)
 	if totalpackages == 0 {
@@ -104,9 +108,25 @@ func (u *superadminUsecase) CreateHourPackage(ctx context.Context, claim domain.
 		errValidation["benefit"] = "benefit field is required"
 	}
 
-	if payload.Price <= 0 {
+	packageType := model.HourPackageType(payload.Type)
+	if packageType == "" {
+		packageType = model.HourPackageTypeRegular
+	}
+	if !helpers.InArrayString(string(packageType), []string{string(model.HourPackageTypeRegular), string(model.HourPackageTypeTrial)}) {
+		errValidation["type"] = "type only can be " + string(model.HourPackageTypeRegular) + " or " + string(model.HourPackageTypeTrial)
+	}
+
+	// trial is free and limited in days
+	if packageType == model.HourPackageTypeTrial {
+		if payload.TrialDays < 1 {
+			errValidation["trialDays"] = "trialDays field is required"
+		}
+	} else if payload.Price <= 0 {
 		errValidation["price"] = "price field cannot be 0"
 	}
+	if message := helpers.ValidateCurrencyPrices(payload.Prices); message != "" {
+		errValidation["prices"] = message
+	}
 
 	if len(errValidation) > 0 {
 		return response.ErrorValidation(errValidation, "error validation")
@@ -115,16 +135,29 @@ func (u *superadminUsecase) CreateHourPackage(ctx context.Context, claim domain.
 	// count duration in seconds
 	totalInSeconds := payload.DurationHours * 60 * 60
 
+	var trial *model.HourPackageTrial
+	if packageType == model.HourPackageTypeTrial {
+		payload.Price = 0
+		payload.Prices = nil
+		trial = &model.HourPackageTrial{
+			Days:      payload.TrialDays,
+			AutoGrant: payload.AutoGrant,
+		}
+	}
+
 	// create package
 	newpackages := &model.HourPackage{
 		ID:      primitive.NewObjectID(),
 		Name:    payload.Name,
 		Benefit: payload.Benefit,
 		Price:   payload.Price,
+		Prices:  payload.Prices,
 		Duration: model.HourPackageDuration{
 			Hours:          payload.DurationHours,
 			TotalinSeconds: totalInSeconds,
 		},
+		Type:      packageType,
+		Trial:     trial,
 		Status:    model.HourPackageActive,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -181,14 +214,6 @@ func (u *superadminUsecase) UpdateHourPackage(ctx context.Context, claim domain.
 		errValidation["benefit"] = "benefit field is required"
 	}
 
-	if payload.Price <= 0 {
-		errValidation["price"] = "price field cannot be 0"
-	}
-
-	if len(errValidation) > 0 {
-		return response.ErrorValidation(errValidation, "error validation")
-	}
-
 	// check hour package
 	packages, err := u.mongodbRepo.FetchOneHourPackage(ctx, map[string]interface{}{
 		"id": packageId,
@@ -200,6 +225,22 @@ func (u *superadminUsecase) UpdateHourPackage(ctx context.Context, claim domain.
 		return response.Error(http.StatusBadRequest, "hour package not found")
 	}
 
+	// trial is free and limited in days
+	if packages.IsTrial() {
+		if payload.TrialDays < 1 {
+			errValidation["trialDays"] = "trialDays field is required"
+		}
+	} else if payload.Price <= 0 {
+		errValidation["price"] = "price field cannot be 0"
+	}
+	if message := helpers.ValidateCurrencyPrices(payload.Prices); message != "" {
+		errValidation["prices"] = message
+	}
+
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
 	// count duration in seconds
 	totalInSeconds := payload.DurationHours * 60 * 60
 
@@ -207,8 +248,15 @@ func (u *superadminUsecase) UpdateHourPackage(ctx context.Context, claim domain.
 	packages.Name = payload.Name
 	packages.Benefit = payload.Benefit
 	packages.Price = payload.Price
+	packages.Prices = payload.Prices
 	packages.Duration.Hours = payload.DurationHours
 	packages.Duration.TotalinSeconds = totalInSeconds
+	if packages.IsTrial() {
+		packages.Price = 0
+		packages.Prices = nil
+		packages.Trial.Days = payload.TrialDays
+		packages.Trial.AutoGrant = payload.AutoGrant
+	}
 
 	packages.UpdatedAt = time.Now()
 
@@ -290,3 +338,94 @@ func (u *superadminUsecase) DeleteHourPackage(ctx context.Context, claim domain.
 
 	return response.Success(packages)
 }
+
+func (u *superadminUsecase) GrantTrial(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.GrantTrialRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating
+	errValidation := make(map[string]string)
+	if payload.CustomerID == "" {
+		errValidation["customerId"] = "customerId field is required"
+	}
+	if payload.PackageID == "" {
+		errValidation["packageId"] = "packageId field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": payload.CustomerID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": customer.Company.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// check trial package
+	pkg, err := u.mongodbRepo.FetchOneHourPackage(ctx, map[string]interface{}{
+		"id":     payload.PackageID,
+		"type":   string(model.HourPackageTypeTrial),
+		"status": string(model.HourPackageActive),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if pkg == nil || !pkg.IsTrial() {
+		return response.Error(http.StatusBadRequest, "trial package not found")
+	}
+
+	if !helpers.CanGrantTrial(company, customer) {
+		return response.Error(http.StatusBadRequest, "trial already granted")
+	}
+
+	// trial would cut short the package the customer already paid for
+	if !helpers.IsPooledWallet(company) {
+		activeSubscription, err := u.mongodbRepo.FetchOneCustomerSubscription(ctx, map[string]interface{}{
+			"customerID":      customer.ID.Hex(),
+			"orderType":       model.HOUR_TYPE,
+			"status":          model.Active,
+			"isCompanyWallet": false,
+		})
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		if activeSubscription != nil {
+			return response.Error(http.StatusBadRequest, "customer already has an active hour package")
+		}
+	}
+
+	// count order
+	count := u.mongodbRepo.CountOrder(ctx, map[string]interface{}{
+		"today": true,
+	})
+	order := helpers.NewTrialOrder(customer, company, pkg, helpers.GenerateFormattedCode("TRX", count+1, helpers.RandomChar(3)), claim.User)
+
+	// create customer subscription like a paid order
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	granted, err := u.billing.GrantTrial(ctx, u._CacheConfig(ctx), order, customer, company, pkg, today, today.AddDate(0, 0, 1))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if !granted {
+		return response.Error(http.StatusBadRequest, "trial already granted")
+	}
+
+	return response.Success(order)
+}
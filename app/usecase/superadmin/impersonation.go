@@ -0,0 +1,184 @@
+package usecase_superadmin
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+func (u *superadminUsecase) ImpersonateAgent(ctx context.Context, claim domain.JWTClaimSuperadmin, agentId string, payload domain.ImpersonateRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	payload.Reason = strings.TrimSpace(payload.Reason)
+	if payload.Reason == "" {
+		return response.ErrorValidation(map[string]string{
+			"reason": "reason field is required",
+		}, "error validation")
+	}
+
+	agent, err := u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
+		"id": agentId,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if agent == nil {
+		return response.Error(http.StatusBadRequest, "agent not found")
+	}
+
+	user := model.UserNested{ID: agent.ID.Hex(), Name: agent.Name, Email: agent.Email}
+	session, err := u._startImpersonation(ctx, claim, model.SessionAgent, user, agent.Company.ID, payload)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	token, err := helpers.GenerateJWTTokenAgent(domain.JWTClaimAgent{
+		UserID:           agent.ID.Hex(),
+		CompanyID:        agent.Company.ID,
+		Role:             string(agent.Role),
+		SessionID:        session.ID.Hex(),
+		Impersonator:     session.Impersonator,
+		RegisteredClaims: helpers.NewImpersonationClaims("agent", session),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"user":      agent,
+		"token":     token,
+		"session":   session,
+		"expiredAt": session.ExpiredAt,
+	})
+}
+
+func (u *superadminUsecase) ImpersonateCustomer(ctx context.Context, claim domain.JWTClaimSuperadmin, customerId string, payload domain.ImpersonateRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	payload.Reason = strings.TrimSpace(payload.Reason)
+	if payload.Reason == "" {
+		return response.ErrorValidation(map[string]string{
+			"reason": "reason field is required",
+		}, "error validation")
+	}
+
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": customerId,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	user := model.UserNested{ID: customer.ID.Hex(), Name: customer.Name, Email: customer.Email}
+	session, err := u._startImpersonation(ctx, claim, model.SessionCustomer, user, customer.Company.ID, payload)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	token, err := helpers.GenerateJWTTokenCustomer(domain.JWTClaimUser{
+		UserID:           customer.ID.Hex(),
+		CompanyID:        customer.Company.ID,
+		Role:             string(customer.Role),
+		SessionID:        session.ID.Hex(),
+		Impersonator:     session.Impersonator,
+		RegisteredClaims: helpers.NewImpersonationClaims("member", session),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"user":      customer,
+		"token":     token,
+		"session":   session,
+		"expiredAt": session.ExpiredAt,
+	})
+}
+
+// _startImpersonation save the marked session and record who impersonated whom and why
+func (u *superadminUsecase) _startImpersonation(ctx context.Context, claim domain.JWTClaimSuperadmin, userType model.AuthSessionUserType, user model.UserNested, companyID string, payload domain.ImpersonateRequest) (*model.AuthSession, error) {
+	session := helpers.NewImpersonationSession(userType, user, companyID, claim.User)
+	if err := u.mongodbRepo.CreateAuthSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	event := helpers.NewSecurityEvent(model.SecurityImpersonationStarted, userType, companyID, user, payload.IPAddress, payload.UserAgent, payload.Reason)
+	event.Impersonator = session.Impersonator
+	event.Request = &model.SecurityEventRequest{SessionID: session.ID.Hex()}
+	if err := u.mongodbRepo.CreateSecurityEvent(ctx, event); err != nil {
+		// an impersonation without its audit record is not allowed
+		helpers.RevokeStoredAuthSession(ctx, u.mongodbRepo, u.redisRepo, session, model.RevokeImpersonationEnded)
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (u *superadminUsecase) GetImpersonationList(ctx context.Context, claim domain.JWTClaimSuperadmin) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	cur, err := u.mongodbRepo.FetchAuthSessionList(ctx, map[string]interface{}{
+		"impersonated": true,
+		"active":       true,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.AuthSession{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("AuthSession Decode ", err)
+			continue
+		}
+		list = append(list, row)
+	}
+
+	return response.Success(list)
+}
+
+func (u *superadminUsecase) EndImpersonation(ctx context.Context, claim domain.JWTClaimSuperadmin, sessionId string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	session, err := u.mongodbRepo.FetchOneAuthSession(ctx, map[string]interface{}{
+		"id":           sessionId,
+		"impersonated": true,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if session == nil {
+		return response.Error(http.StatusBadRequest, "impersonation not found")
+	}
+	if !session.IsActive() {
+		return response.Error(http.StatusBadRequest, "impersonation has already ended")
+	}
+
+	if err := helpers.RevokeStoredAuthSession(ctx, u.mongodbRepo, u.redisRepo, session, model.RevokeImpersonationEnded); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	event := helpers.NewSecurityEvent(model.SecurityImpersonationEnded, session.UserType, session.CompanyID, session.User, "", "", "ended by "+claim.User.Email)
+	event.Impersonator = session.Impersonator
+	event.Request = &model.SecurityEventRequest{SessionID: session.ID.Hex()}
+	if err := u.mongodbRepo.CreateSecurityEvent(ctx, event); err != nil {
+		logrus.Error("CreateSecurityEvent:", err)
+	}
+
+	return response.Success(session)
+}
@@ -4,6 +4,8 @@ import (
 	mongorepo "app/app/repository/mongo"
 	redisrepo "app/app/repository/redis"
 	s3repo "app/app/repository/s3"
+	xenditrepo "app/app/repository/xendit"
+	usecase_billing "app/app/usecase/billing"
 	"app/domain"
 	"context"
 	"net/http"
@@ -18,12 +20,15 @@ type superadminUsecase struct {
 	contextTimeout time.Duration
 	redisRepo      redisrepo.RedisRepo
 	s3Repo         s3repo.S3Repo
+	xenditRepo     xenditrepo.XenditRepo
+	billing        *usecase_billing.Billing
 }
 
 type RepoInjection struct {
 	MongoDBRepo mongorepo.MongoDBRepo
 	Redis       redisrepo.RedisRepo
 	S3Repo      s3repo.S3Repo
+	XenditRepo  xenditrepo.XenditRepo
 }
 
 func NewAppSuperadminUsecase(r RepoInjection, timeout time.Duration) SuperadminUsecase {
@@ -32,6 +37,11 @@ func NewAppSuperadminUsecase(r RepoInjection, timeout time.Duration) SuperadminU
 		contextTimeout: timeout,
 		redisRepo:      r.Redis,
 		s3Repo:         r.S3Repo,
+		xenditRepo:     r.XenditRepo,
+		billing: usecase_billing.NewBilling(usecase_billing.RepoInjection{
+			MongoDBRepo: r.MongoDBRepo,
+			S3Repo:      r.S3Repo,
+		}),
 	}
 }
 
@@ -39,6 +49,30 @@ type SuperadminUsecase interface {
 	// auth
 	Login(ctx context.Context, payload domain.SuperadminLoginRequest) response.Base
 	GetMe(ctx context.Context, claim domain.JWTClaimSuperadmin) response.Base
+	RefreshToken(ctx context.Context, payload domain.RefreshTokenRequest) response.Base
+	Logout(ctx context.Context, claim domain.JWTClaimSuperadmin) response.Base
+	LogoutAll(ctx context.Context, claim domain.JWTClaimSuperadmin) response.Base
+
+	// two factor
+	SetupTwoFactorLogin(ctx context.Context, payload domain.TwoFactorChallengeRequest) response.Base
+	VerifyTwoFactorLogin(ctx context.Context, payload domain.TwoFactorLoginRequest) response.Base
+	SetupTwoFactor(ctx context.Context, claim domain.JWTClaimSuperadmin) response.Base
+	EnableTwoFactor(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.TwoFactorCodeRequest) response.Base
+	DisableTwoFactor(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.DisableTwoFactorRequest) response.Base
+	RegenerateRecoveryCodes(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.TwoFactorCodeRequest) response.Base
+	UpdateCompanyTwoFactor(ctx context.Context, claim domain.JWTClaimSuperadmin, options map[string]interface{}) response.Base
+	ResetAgentTwoFactor(ctx context.Context, claim domain.JWTClaimSuperadmin, agentId string) response.Base
+
+	// security event
+	GetSecurityEventList(ctx context.Context, claim domain.JWTClaimSuperadmin, options map[string]interface{}) response.Base
+	UnlockAgent(ctx context.Context, claim domain.JWTClaimSuperadmin, agentId string) response.Base
+	UnlockCustomer(ctx context.Context, claim domain.JWTClaimSuperadmin, customerId string) response.Base
+
+	// impersonation
+	ImpersonateAgent(ctx context.Context, claim domain.JWTClaimSuperadmin, agentId string, payload domain.ImpersonateRequest) response.Base
+	ImpersonateCustomer(ctx context.Context, claim domain.JWTClaimSuperadmin, customerId string, payload domain.ImpersonateRequest) response.Base
+	GetImpersonationList(ctx context.Context, claim domain.JWTClaimSuperadmin) response.Base
+	EndImpersonation(ctx context.Context, claim domain.JWTClaimSuperadmin, sessionId string) response.Base
 
 	// ticket
 	GetTotalTicket(ctx context.Context, claim domain.JWTClaimSuperadmin) response.Base
@@ -59,7 +93,18 @@ type SuperadminUsecase interface {
 	GetOrderList(ctx context.Context, claim domain.JWTClaimSuperadmin, query url.Values) response.Base
 	GetOrderDetail(ctx context.Context, orderID string) response.Base
 	UploadAttachmentOrder(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.UploadAttachment, request *http.Request) response.Base
+	GetOrderDocument(ctx context.Context, orderID string, documentType string) response.Base
 	UpdateManualPayment(ctx context.Context, claim domain.JWTClaimSuperadmin, orderID string, payload domain.UpdateManualPaymentRequest) response.Base
+	RefundOrder(ctx context.Context, claim domain.JWTClaimSuperadmin, orderID string, payload domain.RefundOrderRequest) response.Base
+
+	// credit note
+	GetCreditNoteList(ctx context.Context, claim domain.JWTClaimSuperadmin, query url.Values) response.Base
+	GetCreditNoteDetail(ctx context.Context, creditNoteID string) response.Base
+
+	// exchange rate
+	GetExchangeRateList(ctx context.Context, claim domain.JWTClaimSuperadmin, query url.Values) response.Base
+	GetLatestExchangeRate(ctx context.Context, currency string) response.Base
+	CreateExchangeRate(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.ExchangeRateRequest) response.Base
 
 	// dashboard
 	GetDataDashboard(ctx context.Context, claim domain.JWTClaimSuperadmin) response.Base
@@ -73,6 +118,7 @@ type SuperadminUsecase interface {
 	DeleteCustomer(ctx context.Context, claim domain.JWTClaimSuperadmin, customerId string) response.Base
 	ResetPasswordCustomer(ctx context.Context, claim domain.JWTClaimSuperadmin, customerId string) response.Base
 	ImportCustomer(ctx context.Context, claim domain.JWTClaimSuperadmin, request *http.Request) response.Base
+	GetCustomerBalanceReconciliation(ctx context.Context, claim domain.JWTClaimSuperadmin, customerId string) response.Base
 
 	// hour package
 	GetHourPackages(ctx context.Context, claim domain.JWTClaimSuperadmin, query url.Values) response.Base
@@ -81,6 +127,7 @@ type SuperadminUsecase interface {
 	UpdateHourPackage(ctx context.Context, claim domain.JWTClaimSuperadmin, packageId string, payload domain.HourPackageUpdate) response.Base
 	UpdateStatusHourPackage(ctx context.Context, claim domain.JWTClaimSuperadmin, packageId string, payload domain.HourPackageStatusUpdate) response.Base
 	DeleteHourPackage(ctx context.Context, claim domain.JWTClaimSuperadmin, packageId string) response.Base
+	GrantTrial(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.GrantTrialRequest) response.Base
 
 	// agent
 	GetAgents(ctx context.Context, claim domain.JWTClaimSuperadmin, query url.Values) response.Base
@@ -97,6 +144,8 @@ type SuperadminUsecase interface {
 	CreateCompany(ctx context.Context, claim domain.JWTClaimSuperadmin, options map[string]interface{}) response.Base
 	UpdateCompany(ctx context.Context, claim domain.JWTClaimSuperadmin, options map[string]interface{}) response.Base
 	DeleteCompany(ctx context.Context, claim domain.JWTClaimSuperadmin, options map[string]interface{}) response.Base
+	UpdateCompanyWallet(ctx context.Context, claim domain.JWTClaimSuperadmin, options map[string]interface{}) response.Base
+	UpdateCompanySSO(ctx context.Context, claim domain.JWTClaimSuperadmin, options map[string]interface{}) response.Base
 
 	// // company product
 	// UploadCompanyProductLogo(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.UploadAttachment, request *http.Request) response.Base
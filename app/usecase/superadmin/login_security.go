@@ -0,0 +1,169 @@
+package usecase_superadmin
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	yurekahelpers "github.com/Yureka-Teknologi-Cipta/yureka/helpers"
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"github.com/sirupsen/logrus"
+)
+
+// _recordSecurityEvent keep the login audit, a failing insert never blocks the login
+func (u *superadminUsecase) _recordSecurityEvent(ctx context.Context, eventType model.SecurityEventType, userType model.AuthSessionUserType, companyID string, user model.UserNested, payload domain.SuperadminLoginRequest, reason string) {
+	event := helpers.NewSecurityEvent(eventType, userType, companyID, user, payload.IPAddress, payload.UserAgent, reason)
+	if err := u.mongodbRepo.CreateSecurityEvent(ctx, event); err != nil {
+		logrus.Error("CreateSecurityEvent:", err)
+	}
+}
+
+// _loginFailed count the failure and lock the account once the limit is reached,
+// superadmins get no unlock email so the lockout only expires
+func (u *superadminUsecase) _loginFailed(ctx context.Context, throttle *helpers.LoginThrottle, user *model.Superadmin, payload domain.SuperadminLoginRequest) response.Base {
+	nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+	failures := throttle.Fail(ctx, model.SessionSuperadmin, payload.Email, payload.IPAddress)
+	u._recordSecurityEvent(ctx, model.SecurityLoginFailed, model.SessionSuperadmin, "", nested, payload, "wrong password")
+	if failures < helpers.GetLoginMaxAttempts() {
+		return response.Error(http.StatusBadRequest, "Wrong password")
+	}
+
+	return u._lockAccount(ctx, throttle, user, payload)
+}
+
+// _lockAccount lock the account once the failures of the password or the two factor step reach the limit
+func (u *superadminUsecase) _lockAccount(ctx context.Context, throttle *helpers.LoginThrottle, user *model.Superadmin, payload domain.SuperadminLoginRequest) response.Base {
+	nested := model.UserNested{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+	lockout, _ := helpers.NewAccountLockout("too many failed login attempts")
+	if err := u.mongodbRepo.UpdateOneSuperadmin(ctx, map[string]interface{}{
+		"id": user.ID,
+	}, map[string]interface{}{
+		"lockout":   lockout,
+		"updatedAt": time.Now(),
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	throttle.Reset(ctx, model.SessionSuperadmin, user.Email)
+	u._recordSecurityEvent(ctx, model.SecurityAccountLocked, model.SessionSuperadmin, "", nested, payload, lockout.Reason)
+
+	return response.Error(http.StatusForbidden, "account is locked after too many failed login attempts, try again later")
+}
+
+func (u *superadminUsecase) UnlockAgent(ctx context.Context, claim domain.JWTClaimSuperadmin, agentId string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	agent, err := u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
+		"id": agentId,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if agent == nil {
+		return response.Error(http.StatusBadRequest, "agent not found")
+	}
+
+	agent.Lockout = nil
+	agent.UpdatedAt = time.Now()
+	if err := u.mongodbRepo.UpdateOneAgent(ctx, map[string]interface{}{
+		"id": agent.ID,
+	}, map[string]interface{}{
+		"lockout":   nil,
+		"updatedAt": agent.UpdatedAt,
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	helpers.NewLoginThrottle(u.redisRepo).Reset(ctx, model.SessionAgent, agent.Email)
+
+	nested := model.UserNested{ID: agent.ID.Hex(), Name: agent.Name, Email: agent.Email}
+	u._recordSecurityEvent(ctx, model.SecurityAccountUnlocked, model.SessionAgent, agent.Company.ID, nested, domain.SuperadminLoginRequest{}, "unlocked by superadmin "+claim.User.Email)
+
+	return response.Success(agent)
+}
+
+func (u *superadminUsecase) UnlockCustomer(ctx context.Context, claim domain.JWTClaimSuperadmin, customerId string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": customerId,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	customer.Lockout = nil
+	customer.UpdatedAt = time.Now()
+	if err := u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+		"id": customer.ID,
+	}, map[string]interface{}{
+		"lockout":   nil,
+		"updatedAt": customer.UpdatedAt,
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	helpers.NewLoginThrottle(u.redisRepo).Reset(ctx, model.SessionCustomer, customer.Email)
+
+	nested := model.UserNested{ID: customer.ID.Hex(), Name: customer.Name, Email: customer.Email}
+	u._recordSecurityEvent(ctx, model.SecurityAccountUnlocked, model.SessionCustomer, customer.Company.ID, nested, domain.SuperadminLoginRequest{}, "unlocked by superadmin "+claim.User.Email)
+
+	return response.Success(customer)
+}
+
+func (u *superadminUsecase) GetSecurityEventList(ctx context.Context, claim domain.JWTClaimSuperadmin, options map[string]interface{}) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	paramQuery := options["query"].(url.Values)
+	fetchOptions, errValidation := helpers.SecurityEventFilter(paramQuery)
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+	page, limit, offset := yurekahelpers.GetLimitOffset(paramQuery)
+	fetchOptions["limit"] = limit
+	fetchOptions["offset"] = offset
+	if paramQuery.Get("companyID") != "" {
+		fetchOptions["companyID"] = paramQuery.Get("companyID")
+	}
+
+	// count first
+	totalDocuments := u.mongodbRepo.CountSecurityEvent(ctx, fetchOptions)
+	if totalDocuments == 0 {
+		return response.Success(response.List{
+			List:  []interface{}{},
+			Page:  page,
+			Limit: limit,
+			Total: totalDocuments,
+		})
+	}
+
+	cur, err := u.mongodbRepo.FetchSecurityEventList(ctx, fetchOptions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	defer cur.Close(ctx)
+
+	list := make([]interface{}, 0)
+	for cur.Next(ctx) {
+		row := model.SecurityEvent{}
+		if err := cur.Decode(&row); err != nil {
+			logrus.Error("SecurityEvent Decode ", err)
+			continue
+		}
+		list = append(list, row)
+	}
+
+	return response.Success(response.List{
+		List:  list,
+		Page:  page,
+		Limit: limit,
+		Total: totalDocuments,
+	})
+}
@@ -5,7 +5,7 @@ import (
 	"app/domain/model"
 	"app/helpers"
 	"context"
-	"fmt"
+	"errors"
 	"mime/multipart"
 	"net/http"
 	"net/url"
@@ -17,8 +17,6 @@ import (
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 func (u *superadminUsecase) GetOrderList(ctx context.Context, claim domain.JWTClaimSuperadmin, query url.Values) response.Base {
@@ -340,38 +338,22 @@ func (u *superadminUsecase) UpdateManualPayment(ctx context.Context, claim domai
 				},
 			}
 			// create customer subscription
-			if err := u._createSubscription(ctx, order, customer, pkg, nil); err != nil {
+			if err := u._createSubscription(ctx, order, customer, pkg); err != nil {
 				return response.Error(http.StatusInternalServerError, err.Error())
 			}
-			// create customer balance history
-			if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, &model.CustomerBalanceHistory{
-				ID:       primitive.NewObjectID(),
-				Customer: order.Customer,
-				In:       pkg.Duration.TotalinSeconds,
-				Reference: model.Reference{
+			// create customer balance history, company wallet write its own
+			if !order.IsCompanyWallet {
+				if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, helpers.NewCustomerBalanceHistory(customer, pkg.Duration.TotalinSeconds+order.OverageSeconds(), 0, model.Reference{
 					UniqueID: order.ID.Hex(),
+					Number:   order.OrderNumber,
 					Type:     model.OrderReference,
-				},
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			}); err != nil {
-				return response.Error(http.StatusInternalServerError, err.Error())
+				}, "Purchase "+pkg.Name)); err != nil {
+					return response.Error(http.StatusInternalServerError, err.Error())
+				}
 			}
 		} else {
-			pkg := &model.ServerPackage{
-				ID: func() primitive.ObjectID {
-					id, _ := primitive.ObjectIDFromHex(order.ServerPackage.ID)
-					return id
-				}(),
-				Name:         order.ServerPackage.Name,
-				Benefit:      order.ServerPackage.Benefit,
-				Price:        order.ServerPackage.Price,
-				Customizable: order.ServerPackage.Customizable,
-				Validity:     order.ServerPackage.Validity,
-			}
-
 			// create customer subscription
-			if err := u._createSubscription(ctx, order, customer, nil, pkg); err != nil {
+			if err := u._createSubscription(ctx, order, customer, nil); err != nil {
 				return response.Error(http.StatusInternalServerError, err.Error())
 			}
 		}
@@ -386,190 +368,360 @@ func (u *superadminUsecase) UpdateManualPayment(ctx context.Context, claim domai
 	return response.Success(order)
 }
 
-func (u *superadminUsecase) _createSubscription(ctx context.Context, order *model.Order, customer *model.Customer, oneHourPackage *model.HourPackage, _ *model.ServerPackage) (err error) {
-	// get from config
-	config := u._CacheConfig(ctx)
+// _createSubscription activate the approved order, manual approval counts whole days from today
+func (u *superadminUsecase) _createSubscription(ctx context.Context, order *model.Order, customer *model.Customer, oneHourPackage *model.HourPackage) (err error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return u.billing.CreateSubscription(ctx, u._CacheConfig(ctx), order, customer, oneHourPackage, today, today.AddDate(0, 0, 1))
+}
 
-	// check company
-	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
-		"id": customer.Company.ID,
+func (u *superadminUsecase) GetOrderDocument(ctx context.Context, orderID string, documentType string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// check order
+	order, err := u.mongodbRepo.FetchOneOrder(ctx, map[string]interface{}{
+		"id": orderID,
 	})
 	if err != nil {
-		return err
+		return response.Error(http.StatusInternalServerError, err.Error())
 	}
-	if company == nil {
-		return fmt.Errorf("company not found")
+	if order == nil {
+		return response.Error(http.StatusBadRequest, "order not found")
 	}
 
-	// get current time
-	now := time.Now()
-
-	// check active subscription
-	fetchOptions := map[string]interface{}{
-		"customerID": customer.ID.Hex(),
-		"orderType":  order.Type,
+	var document *model.MediaFK
+	switch documentType {
+	case "invoice":
+		document = order.Document.Invoice
+	case "receipt":
+		document = order.Document.Receipt
+	default:
+		return response.Error(http.StatusBadRequest, "document type only can be invoice or receipt")
 	}
-
-	if order.Type == model.SERVER_TYPE {
-		fetchOptions["serverPackageId"] = order.ServerPackage.ID
-	} else {
-		fetchOptions["status"] = model.Active
+	if document == nil {
+		return response.Error(http.StatusBadRequest, "document not available")
 	}
 
-	activeSubscription, err := u.mongodbRepo.FetchOneCustomerSubscription(ctx, fetchOptions)
+	// refresh private link
+	link, err := u.s3Repo.GetPresignedLink(document.ProviderKey, nil)
 	if err != nil {
-		return err
+		return response.Error(http.StatusInternalServerError, err.Error())
 	}
+	document.URL = link.URL
 
-	//set expiry date
-	expiredAt := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	return response.Success(document)
+}
 
-	if order.Type == model.HOUR_TYPE {
-		// set active subscription to expired
-		if activeSubscription != nil {
-			activeSubscription.Status = model.Expired
-			activeSubscription.UpdatedAt = now
+func (u *superadminUsecase) RefundOrder(ctx context.Context, claim domain.JWTClaimSuperadmin, orderID string, payload domain.RefundOrderRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
 
-			// save
-			if err = u.mongodbRepo.UpdateOneCustomerSubscription(ctx, activeSubscription); err != nil {
-				return err
-			}
-		}
+	// check order
+	order, err := u.mongodbRepo.FetchOneOrder(ctx, map[string]interface{}{
+		"id": orderID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if order == nil {
+		return response.Error(http.StatusBadRequest, "order not found")
+	}
 
-		expiredAt = expiredAt.AddDate(0, helpers.GetSubscriptionDuration(), 0)
+	// a refund left pending by a failed save is finalized with its own amount and reference
+	creditNote, err := u.mongodbRepo.FetchOneCreditNote(ctx, map[string]interface{}{
+		"orderID": order.ID.Hex(),
+		"state":   model.RefundStatePending,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if creditNote != nil {
+		payload.Amount = creditNote.Amount
+		payload.Reason = creditNote.Reason
+		payload.Method = string(creditNote.Method)
+	}
 
-		// add time balance
-		timeBalance := oneHourPackage.Duration.TotalinSeconds
-		if balance := customer.Subscription.Balance; balance != nil {
-			timeBalance += balance.Time.Total
-			balance.Time.Total = timeBalance
-		} else {
-			customer.Subscription.Balance = &model.Balance{
-				Time: model.TimeBalance{
-					Total: timeBalance,
-				},
-			}
+	// default method follow how the order was paid
+	if payload.Method == "" {
+		payload.Method = string(model.RefundManual)
+		if order.Invoice.InvoiceXenditId != "" {
+			payload.Method = string(model.RefundGateway)
 		}
+	}
 
-		// set subscription to customer
-		customer.Subscription = &model.Subscription{
-			Status: model.Active,
-			HourPackage: &model.HourPackageFK{
-				ID:      oneHourPackage.ID.Hex(),
-				Name:    oneHourPackage.Name,
-				Hours:   oneHourPackage.Duration.Hours,
-				Benefit: oneHourPackage.Benefit,
-				Price:   oneHourPackage.Price,
-			},
-			Balance: customer.Subscription.Balance,
-			StartAt: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
-			EndAt:   expiredAt,
-		}
-		customer.UpdatedAt = now
+	// validating
+	errValidation := make(map[string]string)
+	if payload.Amount <= 0 {
+		errValidation["amount"] = "amount must be greater than 0"
+	}
+	if payload.Reason == "" {
+		errValidation["reason"] = "reason field is required"
+	}
+	if !helpers.InArrayString(payload.Method, []string{string(model.RefundGateway), string(model.RefundManual)}) {
+		errValidation["method"] = "method only can be " + string(model.RefundGateway) + " or " + string(model.RefundManual)
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
 
-		// save
-		if err = u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
-			"id": customer.ID.Hex(),
-		}, map[string]interface{}{
-			"subscription": customer.Subscription,
-			"updatedAt":    customer.UpdatedAt,
-		}); err != nil {
-			return
-		}
-	} else {
-		expiredAt = expiredAt.AddDate(0, 0, int(order.ServerPackage.Validity*order.Amount))
-		if activeSubscription != nil {
-			if activeSubscription.ExpiredAt.Before(now) {
-				expiredAt = now.AddDate(0, 0, int(order.ServerPackage.Validity*order.Amount))
-			} else {
-				expiredAt = activeSubscription.ExpiredAt.AddDate(0, 0, int(order.ServerPackage.Validity*order.Amount))
-			}
-			activeSubscription.Status = model.Active
-			activeSubscription.ExpiredAt = expiredAt
-			activeSubscription.UpdatedAt = now
+	if order.Status != model.STATUS_PAID {
+		return response.Error(http.StatusBadRequest, "only paid order can be refunded")
+	}
 
-			// save
-			if err = u.mongodbRepo.UpdateOneCustomerSubscription(ctx, activeSubscription); err != nil {
-				return err
-			}
-		}
+	refundedAmount := float64(0)
+	if order.Refund != nil {
+		refundedAmount = order.Refund.Amount
+	}
+	if payload.Amount > order.GrandTotal-refundedAmount {
+		return response.Error(http.StatusBadRequest, "amount exceeds refundable amount")
+	}
+	if payload.Method == string(model.RefundGateway) && order.Invoice.InvoiceXenditId == "" {
+		return response.Error(http.StatusBadRequest, "order is not paid through payment gateway")
 	}
 
-	if order.Type == model.HOUR_TYPE || activeSubscription == nil {
-		// create customer subscription
-		newCustomerSubscription := &model.CustomerSubscription{
-			ID: primitive.NewObjectID(),
-			Customer: model.CustomerFK{
-				ID:    customer.ID.Hex(),
-				Name:  customer.Name,
-				Email: customer.Email,
-			},
-			HourPackage:   order.HourPackage,
-			ServerPackage: order.ServerPackage,
+	// check customer
+	customer, err := u.mongodbRepo.FetchOneCustomer(ctx, map[string]interface{}{
+		"id": order.Customer.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if customer == nil {
+		return response.Error(http.StatusBadRequest, "customer not found")
+	}
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": customer.Company.ID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	// check linked subscription, a server subscription can be extended by later orders
+	subscription, err := u.mongodbRepo.FetchOneCustomerSubscription(ctx, map[string]interface{}{
+		"customerID": customer.ID.Hex(),
+		"orderType":  order.Type,
+		"anyOrderID": order.ID.Hex(),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	now := time.Now()
+	isFullRefund := refundedAmount+payload.Amount >= order.GrandTotal
+	ratio := float64(1)
+	if order.GrandTotal > 0 {
+		ratio = payload.Amount / order.GrandTotal
+	}
+
+	// record the refund as pending before any money moves
+	if creditNote == nil {
+		count := u.mongodbRepo.CountCreditNote(ctx, map[string]interface{}{})
+		creditNote = &model.CreditNote{
+			ID:               primitive.NewObjectID(),
+			CreditNoteNumber: helpers.GenerateFormattedCode("CN", count+1, helpers.RandomChar(3)),
 			Order: model.OrderFK{
 				ID:          order.ID.Hex(),
 				OrderNumber: order.OrderNumber,
 				Type:        order.Type,
 			},
-			Status:    model.Active,
-			ExpiredAt: expiredAt,
+			Customer:  order.Customer,
+			Amount:    payload.Amount,
+			Reason:    payload.Reason,
+			Method:    model.RefundMethod(payload.Method),
+			Status:    model.RefundPartial,
+			State:     model.RefundStatePending,
+			CreatedBy: claim.User,
 			CreatedAt: now,
 			UpdatedAt: now,
 		}
+		if isFullRefund {
+			creditNote.Status = model.RefundFull
+		}
 
-		// save
-		if err = u.mongodbRepo.CreateCustomerSubscription(ctx, newCustomerSubscription); err != nil {
-			return
+		if err := u.mongodbRepo.CreateCreditNote(ctx, creditNote); err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
 		}
+	}
 
-		// send email
-		go u._sendEmailCustomerOrder(config, order, customer, company, newCustomerSubscription)
-	} else {
+	// refund through payment gateway, the credit note id is the idempotency key so a retry is not paid twice
+	if creditNote.Method == model.RefundGateway && creditNote.Gateway == nil {
+		result, err := u.xenditRepo.CreateRefund(ctx, *order, creditNote.ID.Hex(), payload.Amount, payload.Reason)
+		if err != nil {
+			// unknown outcome, the credit note stays pending and is retried
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		if result.Status != http.StatusOK {
+			if _, err := u.mongodbRepo.UpdatePartialCreditNote(ctx, map[string]interface{}{
+				"id":    creditNote.ID,
+				"state": model.RefundStatePending,
+			}, map[string]interface{}{
+				"state":     model.RefundStateFailed,
+				"updatedAt": now,
+			}); err != nil {
+				logrus.Error("RefundOrder failed credit note:", err)
+			}
+			return response.Error(result.Status, result.Message)
+		}
 
-		// send email
-		go u._sendEmailCustomerOrder(config, order, customer, company, activeSubscription)
+		creditNote.Gateway = result.Data
+		if _, err := u.mongodbRepo.UpdatePartialCreditNote(ctx, map[string]interface{}{
+			"id": creditNote.ID,
+		}, map[string]interface{}{
+			"gateway":   creditNote.Gateway,
+			"updatedAt": now,
+		}); err != nil {
+			logrus.Errorf("refund order %s sent to payment gateway with reference %s but failed to save: %v", order.OrderNumber, creditNote.ID.Hex(), err)
+		}
 	}
 
-	return
-}
+	errRefundCompleted := errors.New("refund is already completed")
+	err = u.mongodbRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		// finalize once, a concurrent retry of the same credit note stops here
+		completed, err := u.mongodbRepo.UpdatePartialCreditNote(ctx, map[string]interface{}{
+			"id":    creditNote.ID,
+			"state": model.RefundStatePending,
+		}, map[string]interface{}{
+			"state":       model.RefundStateCompleted,
+			"completedAt": now,
+		})
+		if err != nil {
+			return err
+		}
+		if !completed {
+			return errRefundCompleted
+		}
+		creditNote.State = model.RefundStateCompleted
+		creditNote.CompletedAt = &now
 
-func (u *superadminUsecase) _sendEmailCustomerOrder(config model.Config, order *model.Order, customer *model.Customer, company *model.Company, customerSubscription *model.CustomerSubscription) (err error) {
-	loginLink := helpers.StringReplacer(config.LoginLink, map[string]string{
-		"base_url_frontend": company.Settings.Domain.FullUrl,
+		if order.Type == model.HOUR_TYPE {
+			// claw back unused time
+			if order.IsCompanyWallet && company.Wallet != nil {
+				clawback := int64(float64(order.HourPackage.Hours*60*60*order.Amount) * ratio)
+				if unused := company.Wallet.Time.Total - company.Wallet.Time.Used; clawback > unused {
+					clawback = unused
+				}
+				if clawback < 0 {
+					clawback = 0
+				}
+				creditNote.ClawbackSeconds = clawback
+
+				if clawback > 0 {
+					if err := u.mongodbRepo.IncrementOneCompany(ctx, company.ID.Hex(), map[string]int64{
+						"wallet.time.total": -clawback,
+					}); err != nil {
+						return err
+					}
+					company.Wallet.Time.Total -= clawback
+
+					if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, helpers.NewCompanyBalanceHistory(company, customer, -clawback, 0, model.Reference{
+						UniqueID: order.ID.Hex(),
+						Number:   order.OrderNumber,
+						Type:     model.OrderReference,
+					}, "Refund "+creditNote.CreditNoteNumber)); err != nil {
+						return err
+					}
+				}
+			} else if !order.IsCompanyWallet && customer.Subscription != nil && customer.Subscription.Balance != nil {
+				balance := customer.Subscription.Balance
+				clawback := int64(float64(order.HourPackage.Hours*60*60*order.Amount) * ratio)
+				if unused := balance.Time.Total - balance.Time.Used; clawback > unused {
+					clawback = unused
+				}
+				if clawback < 0 {
+					clawback = 0
+				}
+				balance.Time.Total -= clawback
+				creditNote.ClawbackSeconds = clawback
+
+				if isFullRefund && customer.Subscription.HourPackage != nil && customer.Subscription.HourPackage.ID == order.HourPackage.ID && subscription != nil && subscription.Status == model.Active {
+					customer.Subscription.Status = model.Expired
+				}
+
+				if err := u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
+					"id": customer.ID.Hex(),
+				}, map[string]interface{}{
+					"subscription": customer.Subscription,
+					"updatedAt":    now,
+				}); err != nil {
+					return err
+				}
+
+				if clawback > 0 {
+					if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, helpers.NewCustomerBalanceHistory(customer, -clawback, 0, model.Reference{
+						UniqueID: order.ID.Hex(),
+						Number:   order.OrderNumber,
+						Type:     model.OrderReference,
+					}, "Refund "+creditNote.CreditNoteNumber)); err != nil {
+						return err
+					}
+				}
+			}
+
+			// expire subscription on full refund
+			if subscription != nil && isFullRefund {
+				subscription.Status = model.Expired
+				subscription.UpdatedAt = now
+				if err := u.mongodbRepo.UpdateOneCustomerSubscription(ctx, subscription); err != nil {
+					return err
+				}
+			}
+		} else if subscription != nil {
+			// shorten the server validity proportionally
+			days := int(float64(order.ServerPackage.Validity*order.Amount)*ratio + 0.5)
+			subscription.ExpiredAt = subscription.ExpiredAt.AddDate(0, 0, -days)
+			if isFullRefund || !subscription.ExpiredAt.After(now) {
+				subscription.Status = model.Expired
+			}
+			subscription.UpdatedAt = now
+			if err := u.mongodbRepo.UpdateOneCustomerSubscription(ctx, subscription); err != nil {
+				return err
+			}
+		}
+
+		creditNote.UpdatedAt = now
+		if err := u.mongodbRepo.UpdateOneCreditNote(ctx, creditNote); err != nil {
+			return err
+		}
+
+		// update order
+		order.Refund = &model.OrderRefund{
+			Status:     creditNote.Status,
+			Amount:     refundedAmount + payload.Amount,
+			RefundedAt: now,
+		}
+		if isFullRefund {
+			order.Status = model.STATUS_REFUNDED
+			order.Payment.Status = string(model.STATUS_REFUNDED)
+		}
+		order.UpdatedAt = now
+
+		return u.mongodbRepo.UpdateOneOrder(ctx, order)
 	})
+	if err == errRefundCompleted {
+		return response.Error(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		// the credit note stays pending, refunding the order again finalizes it
+		logrus.Errorf("refund order %s with credit note %s is pending: %v", order.OrderNumber, creditNote.ID.Hex(), err)
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
 
-	//get package name
-	var packageName string
-	if order.Type == model.HOUR_TYPE {
-		packageName = order.HourPackage.Name
+	// generate credit note document
+	document, _, err := helpers.UploadCreditNoteDocument(u.s3Repo, creditNote, order, company)
+	if err != nil {
+		logrus.Error("generate credit note document error:", err)
 	} else {
-		packageName = order.ServerPackage.Name
-	}
-
-	// Format price
-	rupiah := order.GrandTotal * config.DollarInIdr
-	formattedPrice := helpers.FormatFloat("#,###.##", order.GrandTotal)
-	formattedPriceRp := helpers.FormatFloat("#,###.##", rupiah)
-	// send email
-	mail := helpers.NewSMTPMailer(company)
-	mail.To([]string{customer.Email})
-	mail.Subject(config.Email.Template.PackageActivated.Title)
-	mail.Body(helpers.StringReplacer(config.Email.Template.PackageActivated.Body, map[string]string{
-		"package_type":   cases.Title(language.English).String(string(order.Type)),
-		"package_name":   packageName,
-		"price":          "USD " + formattedPrice + " (IDR " + formattedPriceRp + ")",
-		"payment_method": order.Invoice.PaymentMethod,
-		"order_number":   order.OrderNumber,
-		"purchase_date":  order.CreatedAt.Format("2006-01-02"),
-		"expire_date":    customerSubscription.ExpiredAt.Format("2006-01-02"),
-		"login_link":     loginLink,
-		"customer_name":  order.Customer.Name,
-	}))
-
-	// send
-	if err := mail.Send(); err != nil {
-		logrus.Errorf("Send Email to %s error %v", customer.Email, err)
-	}
-
-	return
+		creditNote.Document = document
+		if err := u.mongodbRepo.UpdateOneCreditNote(ctx, creditNote); err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return response.Success(creditNote)
 }
@@ -98,6 +98,9 @@ func (u *superadminUsecase) CreateServerPackage(ctx context.Context, claim domai
 	if !payload.Customizable && payload.Price <= 0 {
 		errValidation["price"] = "price field must be greater than 0"
 	}
+	if message := helpers.ValidateCurrencyPrices(payload.Prices); message != "" {
+		errValidation["prices"] = message
+	}
 
 	if payload.Validity < 1 {
 		errValidation["validity"] = "validity field must be greater than 0"
@@ -113,6 +116,7 @@ func (u *superadminUsecase) CreateServerPackage(ctx context.Context, claim domai
 		Name:         payload.Name,
 		Benefit:      payload.Benefit,
 		Price:        payload.Price,
+		Prices:       payload.Prices,
 		Customizable: payload.Customizable,
 		Validity:     payload.Validity,
 		Status:       model.ServerPackageActive,
@@ -167,6 +171,9 @@ func (u *superadminUsecase) UpdateServerPackage(ctx context.Context, claim domai
 	if !payload.Customizable && payload.Price <= 0 {
 		errValidation["price"] = "price field must be greater than 0"
 	}
+	if message := helpers.ValidateCurrencyPrices(payload.Prices); message != "" {
+		errValidation["prices"] = message
+	}
 
 	if payload.Validity < 1 {
 		errValidation["validity"] = "validity field must be greater than 0"
@@ -191,6 +198,7 @@ func (u *superadminUsecase) UpdateServerPackage(ctx context.Context, claim domai
 	serverPackages.Name = payload.Name
 	serverPackages.Benefit = payload.Benefit
 	serverPackages.Price = payload.Price
+	serverPackages.Prices = payload.Prices
 	serverPackages.Customizable = payload.Customizable
 	serverPackages.Validity = payload.Validity
 	serverPackages.UpdatedAt = time.Now()
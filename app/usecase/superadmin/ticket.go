@@ -228,6 +228,15 @@ func (u *superadminUsecase) AssignAgent(ctx context.Context, claim domain.JWTCla
 			}
 		}
 
+		// Check agent workload capacity
+		load, err := helpers.GetAgentLoad(ctx, u.mongodbRepo, company, agent.ID.Hex())
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		if !load.Available && !payload.Force {
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("agent '%s' reached the capacity of %d active tickets", agent.Name, load.Capacity))
+		}
+
 		// Assign agent
 		newAgent := model.AgentNested{
 			ID:    agent.ID.Hex(),
@@ -223,14 +223,18 @@ func (u *superadminUsecase) CreateTicketComment(ctx context.Context, claim domai
 		}
 	}
 
-	// check customer balance
-	if customer.IsNeedBalance {
+	// check customer balance, pooled company draw from the company wallet
+	overagePolicy := helpers.GetOveragePolicy(company)
+	if helpers.IsPooledWallet(company) {
+		if payload.Status == model.InProgress && !helpers.CanRunTimer(overagePolicy, helpers.PooledTimeRemaining(company, customer)) {
+			return response.Error(http.StatusBadRequest, "the customer doesn't have enough time balance")
+		}
+	} else if customer.IsNeedBalance {
 		if payload.Status == model.InProgress {
 			if !(now.After(customer.Subscription.StartAt) && now.Before(customer.Subscription.EndAt)) || customer.Subscription.Status != model.Active {
 				return response.Error(http.StatusBadRequest, "the customer doesn't have active subscription")
 			}
-			timeRemaining := customer.Subscription.Balance.Time.Total - customer.Subscription.Balance.Time.Used
-			if customer.Subscription.Balance == nil || timeRemaining < 0 {
+			if customer.Subscription.Balance == nil || !helpers.CanRunTimer(overagePolicy, helpers.TimeRemaining(company, customer)) {
 				return response.Error(http.StatusBadRequest, "the customer doesn't have enough time balance")
 			}
 		}
@@ -557,6 +561,23 @@ func (u *superadminUsecase) _updateTimeBalance(ctx context.Context, ticket *mode
 		return fmt.Errorf("customer not found")
 	}
 
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": customer.Company.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// pooled company draw from the company wallet
+	if helpers.IsPooledWallet(company) {
+		if err := helpers.DrawCompanyWallet(ctx, u.mongodbRepo, ticket, customer, company, int64(ticket.LogTime.DurationInSeconds)); err != nil {
+			return err
+		}
+		helpers.CheckLowBalance(ctx, u.mongodbRepo, u._CacheConfig(ctx), ticket, customer, company, helpers.TimeRemaining(company, customer))
+		return nil
+	}
+
 	// check need balance
 	if !customer.IsNeedBalance {
 		return
@@ -564,6 +585,7 @@ func (u *superadminUsecase) _updateTimeBalance(ctx context.Context, ticket *mode
 
 	// count balance
 	usedTime := int64(customer.Subscription.Balance.Time.Used) + int64(ticket.LogTime.DurationInSeconds)
+	customer.Subscription.Balance.Time.Used = usedTime
 
 	if err := u.mongodbRepo.UpdateOneCustomer(
 		ctx,
@@ -576,18 +598,15 @@ func (u *superadminUsecase) _updateTimeBalance(ctx context.Context, ticket *mode
 
 	}
 
-	if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, &model.CustomerBalanceHistory{
-		ID:       primitive.NewObjectID(),
-		Customer: ticket.Customer,
-		Out:      int64(ticket.LogTime.DurationInSeconds),
-		Reference: model.Reference{
-			UniqueID: ticket.ID.Hex(),
-			Type:     model.TicketReference},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}); err != nil {
+	if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, helpers.NewCustomerBalanceHistory(customer, 0, int64(ticket.LogTime.DurationInSeconds), model.Reference{
+		UniqueID: ticket.ID.Hex(),
+		Number:   ticket.Code,
+		Type:     model.TicketReference,
+	}, "Ticket "+ticket.Subject)); err != nil {
 		return err
 	}
 
+	helpers.CheckLowBalance(ctx, u.mongodbRepo, u._CacheConfig(ctx), ticket, customer, company, helpers.TimeRemaining(company, customer))
+
 	return nil
 }
@@ -0,0 +1,402 @@
+package usecase_superadmin
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Yureka-Teknologi-Cipta/yureka/response"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// _twoFactorChallenge answer the password step, the token is exchanged for a session with a code from the app
+func (u *superadminUsecase) _twoFactorChallenge(user *model.Superadmin) response.Base {
+	challengeToken, err := helpers.GenerateTwoFactorChallenge(helpers.GetJWTSecretKeySuperadmin(), "superadmin", user.ID.Hex())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"twoFactorRequired":      true,
+		"twoFactorSetupRequired": !user.TwoFactor.Enabled,
+		"challengeToken":         challengeToken,
+	})
+}
+
+// _superadminFromChallenge the user of a challenge still open, it ends once exchanged or after too many wrong codes
+func (u *superadminUsecase) _superadminFromChallenge(ctx context.Context, throttle *helpers.LoginThrottle, challengeToken string) (*model.Superadmin, string, response.Base) {
+	superadminID, challengeID, ok := helpers.ParseTwoFactorChallenge(helpers.GetJWTSecretKeySuperadmin(), "superadmin", challengeToken)
+	if !ok || throttle.TwoFactorChallengeEnded(ctx, challengeID) {
+		return nil, "", response.Error(http.StatusUnauthorized, "challenge token is invalid or expired, please login again")
+	}
+
+	admin, err := u.mongodbRepo.FetchOneSuperadmin(ctx, map[string]interface{}{
+		"id": superadminID,
+	})
+	if err != nil {
+		return nil, "", response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if admin == nil {
+		return nil, "", response.Error(http.StatusBadRequest, "user not found")
+	}
+	if admin.Lockout.IsActive() {
+		return nil, "", response.Error(http.StatusForbidden, "account is locked, try again later")
+	}
+
+	return admin, challengeID, response.Base{}
+}
+
+func (u *superadminUsecase) _saveTwoFactor(ctx context.Context, admin *model.Superadmin) error {
+	admin.UpdatedAt = time.Now()
+	return u.mongodbRepo.UpdateOneSuperadmin(ctx, map[string]interface{}{
+		"id": admin.ID,
+	}, map[string]interface{}{
+		"twoFactor": admin.TwoFactor,
+		"updatedAt": admin.UpdatedAt,
+	})
+}
+
+// SetupTwoFactorLogin enrollment during login
+func (u *superadminUsecase) SetupTwoFactorLogin(ctx context.Context, payload domain.TwoFactorChallengeRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	admin, _, errResponse := u._superadminFromChallenge(ctx, helpers.NewLoginThrottle(u.redisRepo), payload.ChallengeToken)
+	if admin == nil {
+		return errResponse
+	}
+	if admin.TwoFactor.Enabled {
+		return response.Error(http.StatusBadRequest, "two factor authentication is already enabled")
+	}
+
+	return u._setupTwoFactor(ctx, admin)
+}
+
+// VerifyTwoFactorLogin second login step, a pending enrollment is confirmed by the same code
+func (u *superadminUsecase) VerifyTwoFactorLogin(ctx context.Context, payload domain.TwoFactorLoginRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.ChallengeToken == "" {
+		errValidation["challengeToken"] = "challengeToken field is required"
+	}
+	if payload.Code == "" {
+		errValidation["code"] = "code field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	throttle := helpers.NewLoginThrottle(u.redisRepo)
+	admin, challengeID, errResponse := u._superadminFromChallenge(ctx, throttle, payload.ChallengeToken)
+	if admin == nil {
+		return errResponse
+	}
+	if wait := throttle.Wait(ctx, model.SessionSuperadmin, admin.Email, payload.IPAddress); wait > 0 {
+		return response.Error(http.StatusTooManyRequests, helpers.LoginWaitMessage(wait))
+	}
+
+	var recoveryCodes []string
+	if admin.TwoFactor.Enabled {
+		if !helpers.VerifyTwoFactorCode(&admin.TwoFactor, payload.Code) {
+			return u._twoFactorFailed(ctx, throttle, admin, challengeID, payload)
+		}
+	} else {
+		codes, ok := helpers.EnableTwoFactor(&admin.TwoFactor, payload.Code)
+		if !ok {
+			return u._twoFactorFailed(ctx, throttle, admin, challengeID, payload)
+		}
+		recoveryCodes = codes
+	}
+
+	if err := u._saveTwoFactor(ctx, admin); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	throttle.EndTwoFactorChallenge(ctx, challengeID)
+	throttle.Reset(ctx, model.SessionSuperadmin, admin.Email)
+
+	return u._completeLogin(ctx, admin, recoveryCodes)
+}
+
+// _twoFactorFailed count the wrong code, the challenge ends after a few and the account locks like for passwords
+func (u *superadminUsecase) _twoFactorFailed(ctx context.Context, throttle *helpers.LoginThrottle, admin *model.Superadmin, challengeID string, payload domain.TwoFactorLoginRequest) response.Base {
+	loginPayload := domain.SuperadminLoginRequest{Email: admin.Email, IPAddress: payload.IPAddress, UserAgent: payload.UserAgent}
+	nested := model.UserNested{ID: admin.ID.Hex(), Name: admin.Name, Email: admin.Email}
+	challengeFailures, failures := throttle.FailTwoFactor(ctx, model.SessionSuperadmin, admin.Email, payload.IPAddress, challengeID)
+	u._recordSecurityEvent(ctx, model.SecurityLoginFailed, model.SessionSuperadmin, "", nested, loginPayload, "wrong two factor code")
+
+	if failures >= helpers.GetLoginMaxAttempts() {
+		throttle.EndTwoFactorChallenge(ctx, challengeID)
+		return u._lockAccount(ctx, throttle, admin, loginPayload)
+	}
+	if challengeFailures >= helpers.GetTwoFactorMaxAttempts() {
+		return response.Error(http.StatusUnauthorized, "too many invalid codes, please login again")
+	}
+
+	return response.Error(http.StatusBadRequest, "two factor code is invalid")
+}
+
+func (u *superadminUsecase) SetupTwoFactor(ctx context.Context, claim domain.JWTClaimSuperadmin) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	admin, err := u.mongodbRepo.FetchOneSuperadmin(ctx, map[string]interface{}{
+		"id": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if admin == nil {
+		return response.Error(http.StatusBadRequest, "user not found")
+	}
+	if admin.TwoFactor.Enabled {
+		return response.Error(http.StatusBadRequest, "two factor authentication is already enabled")
+	}
+
+	return u._setupTwoFactor(ctx, admin)
+}
+
+// _setupTwoFactor keep a pending secret until it is confirmed by a code from the app
+func (u *superadminUsecase) _setupTwoFactor(ctx context.Context, admin *model.Superadmin) response.Base {
+	admin.TwoFactor.PendingSecret = helpers.GenerateTOTPSecret()
+	if err := u._saveTwoFactor(ctx, admin); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"secret":          admin.TwoFactor.PendingSecret,
+		"provisioningUri": helpers.TOTPProvisioningURI(helpers.GetTwoFactorIssuer(), admin.Email, admin.TwoFactor.PendingSecret),
+	})
+}
+
+func (u *superadminUsecase) EnableTwoFactor(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.TwoFactorCodeRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Code == "" {
+		errValidation["code"] = "code field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	admin, err := u.mongodbRepo.FetchOneSuperadmin(ctx, map[string]interface{}{
+		"id": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if admin == nil {
+		return response.Error(http.StatusBadRequest, "user not found")
+	}
+	if admin.TwoFactor.Enabled {
+		return response.Error(http.StatusBadRequest, "two factor authentication is already enabled")
+	}
+	if admin.TwoFactor.PendingSecret == "" {
+		return response.Error(http.StatusBadRequest, "two factor authentication is not set up")
+	}
+
+	recoveryCodes, ok := helpers.EnableTwoFactor(&admin.TwoFactor, payload.Code)
+	if !ok {
+		return response.Error(http.StatusBadRequest, "two factor code is invalid")
+	}
+
+	if err := u._saveTwoFactor(ctx, admin); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"twoFactor":     admin.TwoFactor,
+		"recoveryCodes": recoveryCodes,
+	})
+}
+
+func (u *superadminUsecase) DisableTwoFactor(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.DisableTwoFactorRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Password == "" {
+		errValidation["password"] = "password field is required"
+	}
+	if payload.Code == "" {
+		errValidation["code"] = "code field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	admin, err := u.mongodbRepo.FetchOneSuperadmin(ctx, map[string]interface{}{
+		"id": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if admin == nil {
+		return response.Error(http.StatusBadRequest, "user not found")
+	}
+	if !admin.TwoFactor.Enabled {
+		return response.Error(http.StatusBadRequest, "two factor authentication is not enabled")
+	}
+
+	// check password and code
+	if err = bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(payload.Password)); err != nil {
+		return response.Error(http.StatusBadRequest, "Wrong password")
+	}
+	if !helpers.VerifyTwoFactorCode(&admin.TwoFactor, payload.Code) {
+		return response.Error(http.StatusBadRequest, "two factor code is invalid")
+	}
+
+	admin.TwoFactor = model.TwoFactor{}
+	if err := u._saveTwoFactor(ctx, admin); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(admin)
+}
+
+// RegenerateRecoveryCodes replace every recovery code, the old ones stop working
+func (u *superadminUsecase) RegenerateRecoveryCodes(ctx context.Context, claim domain.JWTClaimSuperadmin, payload domain.TwoFactorCodeRequest) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Code == "" {
+		errValidation["code"] = "code field is required"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	admin, err := u.mongodbRepo.FetchOneSuperadmin(ctx, map[string]interface{}{
+		"id": claim.UserID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if admin == nil {
+		return response.Error(http.StatusBadRequest, "user not found")
+	}
+	if !helpers.VerifyTwoFactorCode(&admin.TwoFactor, payload.Code) {
+		return response.Error(http.StatusBadRequest, "two factor code is invalid")
+	}
+
+	recoveryCodes, hashes := helpers.GenerateRecoveryCodes()
+	admin.TwoFactor.RecoveryCodes = hashes
+	if err := u._saveTwoFactor(ctx, admin); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(map[string]interface{}{
+		"recoveryCodes": recoveryCodes,
+	})
+}
+
+// UpdateCompanyTwoFactor enforce two factor authentication for the agents of a company,
+// agents not enrolled yet are logged out and enroll on their next login
+func (u *superadminUsecase) UpdateCompanyTwoFactor(ctx context.Context, claim domain.JWTClaimSuperadmin, options map[string]interface{}) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	payload := options["payload"].(domain.CompanyTwoFactorRequest)
+
+	// validating request
+	errValidation := make(map[string]string)
+	if payload.Required == nil {
+		errValidation["required"] = "required field only accept true or false"
+	}
+	if len(errValidation) > 0 {
+		return response.ErrorValidation(errValidation, "error validation")
+	}
+
+	// check company
+	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
+		"id": options["id"],
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if company == nil {
+		return response.Error(http.StatusBadRequest, "company not found")
+	}
+
+	company.Settings.RequireTwoFactor = *payload.Required
+	company.UpdatedAt = time.Now()
+
+	if err := u.mongodbRepo.UpdatePartialCompany(ctx, map[string]interface{}{
+		"id": company.ID,
+	}, map[string]interface{}{
+		"settings.requireTwoFactor": company.Settings.RequireTwoFactor,
+		"updatedAt":                 company.UpdatedAt,
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	if company.Settings.RequireTwoFactor {
+		cur, err := u.mongodbRepo.FetchAgentList(ctx, map[string]interface{}{
+			"companyID": company.ID.Hex(),
+		})
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, err.Error())
+		}
+		defer cur.Close(ctx)
+
+		for cur.Next(ctx) {
+			row := model.Agent{}
+			if err := cur.Decode(&row); err != nil {
+				return response.Error(http.StatusInternalServerError, err.Error())
+			}
+			if row.TwoFactor.Enabled {
+				continue
+			}
+			if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionAgent, row.ID.Hex(), model.RevokeTwoFactorRequired); err != nil {
+				return response.Error(http.StatusInternalServerError, err.Error())
+			}
+		}
+	}
+
+	return response.Success(company)
+}
+
+// ResetAgentTwoFactor for an agent who lost both the app and the recovery codes
+func (u *superadminUsecase) ResetAgentTwoFactor(ctx context.Context, claim domain.JWTClaimSuperadmin, agentId string) response.Base {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	agent, err := u.mongodbRepo.FetchOneAgent(ctx, map[string]interface{}{
+		"id": agentId,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+	if agent == nil {
+		return response.Error(http.StatusBadRequest, "agent not found")
+	}
+
+	agent.TwoFactor = model.TwoFactor{}
+	agent.UpdatedAt = time.Now()
+	if err := u.mongodbRepo.UpdateOneAgent(ctx, map[string]interface{}{
+		"id": agent.ID,
+	}, map[string]interface{}{
+		"twoFactor": agent.TwoFactor,
+		"updatedAt": agent.UpdatedAt,
+	}); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	// sessions were signed in with the old factor
+	if _, err := helpers.RevokeUserAuthSessions(ctx, u.mongodbRepo, u.redisRepo, model.SessionAgent, agent.ID.Hex(), model.RevokeTwoFactorReset); err != nil {
+		return response.Error(http.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(agent)
+}
@@ -3,6 +3,8 @@ package usecase_webhook
 import (
 	mongorepo "app/app/repository/mongo"
 	redisrepo "app/app/repository/redis"
+	s3repo "app/app/repository/s3"
+	usecase_billing "app/app/usecase/billing"
 	"context"
 	"time"
 
@@ -13,11 +15,14 @@ type webhookUsecase struct {
 	mongodbRepo    mongorepo.MongoDBRepo
 	contextTimeout time.Duration
 	redisRepo      redisrepo.RedisRepo
+	s3Repo         s3repo.S3Repo
+	billing        *usecase_billing.Billing
 }
 
 type RepoInjection struct {
 	MongoDBRepo mongorepo.MongoDBRepo
 	Redis       redisrepo.RedisRepo
+	S3Repo      s3repo.S3Repo
 }
 
 func NewAppWebhookUsecase(r RepoInjection, timeout time.Duration) WebhookUsecase {
@@ -25,6 +30,11 @@ func NewAppWebhookUsecase(r RepoInjection, timeout time.Duration) WebhookUsecase
 		mongodbRepo:    r.MongoDBRepo,
 		contextTimeout: timeout,
 		redisRepo:      r.Redis,
+		s3Repo:         r.S3Repo,
+		billing: usecase_billing.NewBilling(usecase_billing.RepoInjection{
+			MongoDBRepo: r.MongoDBRepo,
+			S3Repo:      r.S3Repo,
+		}),
 	}
 }
 
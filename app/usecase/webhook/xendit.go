@@ -5,16 +5,11 @@ import (
 	"app/domain/model"
 	"app/helpers"
 	"context"
-	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/Yureka-Teknologi-Cipta/yureka/response"
-	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 func (u *webhookUsecase) HandleWebhook(ctx context.Context, options map[string]interface{}) response.Base {
@@ -84,39 +79,23 @@ func (u *webhookUsecase) HandleWebhook(ctx context.Context, options map[string]i
 			}
 
 			// create customer subscription
-			if err := u._createSubscription(ctx, order, customer, pkg, nil); err != nil {
+			if err := u._createSubscription(ctx, order, customer, pkg); err != nil {
 				return response.Error(http.StatusInternalServerError, err.Error())
 			}
 
-			// create customer balance history
-			if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, &model.CustomerBalanceHistory{
-				ID:       primitive.NewObjectID(),
-				Customer: order.Customer,
-				In:       pkg.Duration.TotalinSeconds,
-				Reference: model.Reference{
+			// create customer balance history, company wallet write its own
+			if !order.IsCompanyWallet {
+				if err := u.mongodbRepo.CreateCustomerBalanceHistory(ctx, helpers.NewCustomerBalanceHistory(customer, pkg.Duration.TotalinSeconds+order.OverageSeconds(), 0, model.Reference{
 					UniqueID: order.ID.Hex(),
+					Number:   order.OrderNumber,
 					Type:     model.OrderReference,
-				},
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			}); err != nil {
-				return response.Error(http.StatusInternalServerError, err.Error())
+				}, "Purchase "+pkg.Name)); err != nil {
+					return response.Error(http.StatusInternalServerError, err.Error())
+				}
 			}
 		} else {
-			pkg := &model.ServerPackage{
-				ID: func() primitive.ObjectID {
-					id, _ := primitive.ObjectIDFromHex(order.ServerPackage.ID)
-					return id
-				}(),
-				Name:         order.ServerPackage.Name,
-				Benefit:      order.ServerPackage.Benefit,
-				Price:        order.ServerPackage.Price,
-				Customizable: order.ServerPackage.Customizable,
-				Validity:     order.ServerPackage.Validity,
-			}
-
 			// create customer subscription
-			if err := u._createSubscription(ctx, order, customer, nil, pkg); err != nil {
+			if err := u._createSubscription(ctx, order, customer, nil); err != nil {
 				return response.Error(http.StatusInternalServerError, err.Error())
 			}
 		}
@@ -130,184 +109,8 @@ func (u *webhookUsecase) HandleWebhook(ctx context.Context, options map[string]i
 	return response.Success(order)
 }
 
-func (u *webhookUsecase) _createSubscription(ctx context.Context, order *model.Order, customer *model.Customer, oneHourPackage *model.HourPackage, _ *model.ServerPackage) (err error) {
-	// get from config
-	config := u._CacheConfig(ctx)
-
-	// check company
-	company, err := u.mongodbRepo.FetchOneCompany(ctx, map[string]interface{}{
-		"id": customer.Company.ID,
-	})
-	if err != nil {
-		return err
-	}
-	if company == nil {
-		return fmt.Errorf("company not found")
-	}
-
-	// get current time
+// _createSubscription activate the paid order from the payment time
+func (u *webhookUsecase) _createSubscription(ctx context.Context, order *model.Order, customer *model.Customer, oneHourPackage *model.HourPackage) (err error) {
 	now := time.Now()
-
-	// check active subscription
-	fetchOptions := map[string]interface{}{
-		"customerID": customer.ID.Hex(),
-		"orderType":  order.Type,
-	}
-
-	if order.Type == model.SERVER_TYPE {
-		fetchOptions["serverPackageId"] = order.ServerPackage.ID
-	} else {
-		fetchOptions["status"] = model.Active
-	}
-
-	activeSubscription, err := u.mongodbRepo.FetchOneCustomerSubscription(ctx, fetchOptions)
-	if err != nil {
-		return err
-	}
-
-	//set expiry date
-	expiredAt := now
-
-	if order.Type == model.HOUR_TYPE {
-		// set active subscription to expired
-		if activeSubscription != nil {
-			activeSubscription.Status = model.Expired
-			activeSubscription.UpdatedAt = now
-
-			// save
-			if err = u.mongodbRepo.UpdateOneCustomerSubscription(ctx, activeSubscription); err != nil {
-				return err
-			}
-		}
-
-		expiredAt = now.AddDate(0, helpers.GetSubscriptionDuration(), 0)
-
-		// add time balance
-		timeBalance := oneHourPackage.Duration.TotalinSeconds
-		if balance := customer.Subscription.Balance; balance != nil {
-			timeBalance += balance.Time.Total
-			balance.Time.Total = timeBalance
-		} else {
-			customer.Subscription.Balance = &model.Balance{
-				Time: model.TimeBalance{
-					Total: timeBalance,
-				},
-			}
-		}
-
-		// set subscription to customer
-		customer.Subscription = &model.Subscription{
-			Status: model.Active,
-			HourPackage: &model.HourPackageFK{
-				ID:      oneHourPackage.ID.Hex(),
-				Name:    oneHourPackage.Name,
-				Hours:   oneHourPackage.Duration.Hours,
-				Benefit: oneHourPackage.Benefit,
-				Price:   oneHourPackage.Price,
-			},
-			Balance: customer.Subscription.Balance,
-			StartAt: now,
-			EndAt:   expiredAt,
-		}
-		customer.UpdatedAt = now
-
-		// save
-		if err = u.mongodbRepo.UpdateOneCustomer(ctx, map[string]interface{}{
-			"id": customer.ID.Hex(),
-		}, map[string]interface{}{
-			"subscription": customer.Subscription,
-			"updatedAt":    customer.UpdatedAt,
-		}); err != nil {
-			return
-		}
-	} else {
-		expiredAt = now.AddDate(0, 0, int(order.ServerPackage.Validity*order.Amount))
-		if activeSubscription != nil {
-			if activeSubscription.ExpiredAt.Before(now) {
-				expiredAt = now.AddDate(0, 0, int(order.ServerPackage.Validity*order.Amount))
-			} else {
-				expiredAt = activeSubscription.ExpiredAt.AddDate(0, 0, int(order.ServerPackage.Validity*order.Amount))
-			}
-			activeSubscription.Status = model.Active
-			activeSubscription.ExpiredAt = expiredAt
-			activeSubscription.UpdatedAt = now
-
-			// save
-			if err = u.mongodbRepo.UpdateOneCustomerSubscription(ctx, activeSubscription); err != nil {
-				return err
-			}
-		}
-	}
-
-	if order.Type == model.HOUR_TYPE || activeSubscription == nil {
-		// create customer subscription
-		newCustomerSubscription := &model.CustomerSubscription{
-			ID: primitive.NewObjectID(),
-			Customer: model.CustomerFK{
-				ID:    customer.ID.Hex(),
-				Name:  customer.Name,
-				Email: customer.Email,
-			},
-			HourPackage:   order.HourPackage,
-			ServerPackage: order.ServerPackage,
-			Order: model.OrderFK{
-				ID:          order.ID.Hex(),
-				OrderNumber: order.OrderNumber,
-				Type:        order.Type,
-			},
-			Status:    model.Active,
-			ExpiredAt: expiredAt,
-			CreatedAt: now,
-			UpdatedAt: now,
-		}
-
-		// save
-		if err = u.mongodbRepo.CreateCustomerSubscription(ctx, newCustomerSubscription); err != nil {
-			return
-		}
-
-		// send email
-		go u._sendEmailCustomerOrder(config, order, customer, company, newCustomerSubscription)
-	} else {
-
-		// send email
-		go u._sendEmailCustomerOrder(config, order, customer, company, activeSubscription)
-	}
-
-	return
-}
-
-func (u *webhookUsecase) _sendEmailCustomerOrder(config model.Config, order *model.Order, customer *model.Customer, company *model.Company, customerSubscription *model.CustomerSubscription) (err error) {
-	loginLink := helpers.StringReplacer(config.LoginLink, map[string]string{
-		"base_url_frontend": company.Settings.Domain.FullUrl,
-	})
-
-	//get package name
-	var packageName string
-	if order.Type == model.HOUR_TYPE {
-		packageName = order.HourPackage.Name
-	} else {
-		packageName = order.ServerPackage.Name
-	}
-	// send email
-	mail := helpers.NewSMTPMailer(company)
-	mail.To([]string{customer.Email})
-	mail.Subject(config.Email.Template.PackageActivated.Title)
-	mail.Body(helpers.StringReplacer(config.Email.Template.PackageActivated.Body, map[string]string{
-		"package_type":   cases.Title(language.English).String(string(order.Type)),
-		"package_name":   packageName,
-		"price":          strconv.FormatFloat(order.GrandTotal, 'f', 2, 64),
-		"payment_method": order.Invoice.PaymentMethod,
-		"order_number":   order.OrderNumber,
-		"purchase_date":  order.CreatedAt.Format("2006-01-02"),
-		"expire_date":    customerSubscription.ExpiredAt.Format("2006-01-02"),
-		"login_link":     loginLink,
-	}))
-
-	// send
-	if err := mail.Send(); err != nil {
-		logrus.Errorf("Send Email to %s error %v", customer.Email, err)
-	}
-
-	return
+	return u.billing.CreateSubscription(ctx, u._CacheConfig(ctx), order, customer, oneHourPackage, now, now)
 }
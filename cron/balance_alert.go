@@ -0,0 +1,149 @@
+package cronjob
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WatchRunningTimerBalance alert low balance while the agent timer is running and pause it when the company policy says so
+func (cj *cronjob) WatchRunningTimerBalance() {
+	cj.cron.AddFunc("*/1 * * * *", func() {
+		t := time.Now()
+		logrus.Info("WatchRunningTimerBalance: cron started at ", t)
+
+		cur, err := cj.mongodbRepo.FetchTicketList(cj.ctx, map[string]interface{}{
+			"status":    []string{string(model.InProgress)},
+			"logStatus": string(model.Running),
+		})
+		if err != nil {
+			logrus.Error("FetchTicketList: ", err)
+			return
+		}
+
+		defer cur.Close(cj.ctx)
+
+		config := cj._CacheConfig(cj.ctx)
+
+		for cur.Next(cj.ctx) {
+			ticket := model.Ticket{}
+			if err := cur.Decode(&ticket); err != nil {
+				logrus.Error("Ticket Decode ", err)
+				continue
+			}
+
+			// check customer
+			customer, err := cj.mongodbRepo.FetchOneCustomer(cj.ctx, map[string]interface{}{
+				"id": ticket.Customer.ID,
+			})
+			if err != nil || customer == nil {
+				logrus.Error("FetchOneCustomer: ", err)
+				continue
+			}
+
+			// check company
+			company, err := cj.mongodbRepo.FetchOneCompany(cj.ctx, map[string]interface{}{
+				"id": ticket.Company.ID,
+			})
+			if err != nil || company == nil {
+				logrus.Error("FetchOneCompany: ", err)
+				continue
+			}
+
+			if !helpers.IsBalanceLimited(company, customer) {
+				continue
+			}
+
+			// the running segment is only deducted on pause or close
+			remaining := helpers.TimeRemaining(company, customer) - int64(_runningSegment(&ticket, t))
+
+			if remaining <= 0 && helpers.GetOveragePolicy(company) == model.OveragePauseTimer {
+				if err := cj._pauseTimer(&ticket, customer, company); err != nil {
+					logrus.Errorf("Pause timer ticket %s error %v", ticket.Code, err)
+					continue
+				}
+			}
+
+			helpers.CheckLowBalance(cj.ctx, cj.mongodbRepo, config, &ticket, customer, company, remaining)
+		}
+	})
+
+	logrus.Info("Cron WatchRunningTimerBalance added")
+}
+
+// _runningSegment seconds since the timer was started or last resumed
+func _runningSegment(ticket *model.Ticket, now time.Time) int {
+	if len(ticket.LogTime.PauseHistory) == 0 {
+		if ticket.LogTime.StartAt == nil {
+			return 0
+		}
+		return int(now.Sub(*ticket.LogTime.StartAt).Seconds())
+	}
+
+	lastPause := ticket.LogTime.PauseHistory[len(ticket.LogTime.PauseHistory)-1]
+	if lastPause.ResumedAt == nil {
+		return 0
+	}
+	return int(now.Sub(*lastPause.ResumedAt).Seconds())
+}
+
+// _pauseTimer pause the agent timer and deduct the running segment, same as the agent pause log
+func (cj *cronjob) _pauseTimer(ticket *model.Ticket, customer *model.Customer, company *model.Company) (err error) {
+	now := time.Now()
+	duration := _runningSegment(ticket, now)
+	pause := model.PauseHistory{
+		PausedAt: now,
+	}
+
+	// the agent may have paused or closed the timer since the ticket was read
+	paused, err := cj.mongodbRepo.PauseTicketTimer(cj.ctx, ticket, pause, duration)
+	if err != nil || !paused {
+		return
+	}
+	ticket.LogTime.DurationInSeconds += duration
+	ticket.LogTime.PauseHistory = append(ticket.LogTime.PauseHistory, pause)
+	ticket.LogTime.Status = model.Paused
+	ticket.UpdatedAt = now
+
+	// close the running timelogs
+	timelogs, err := cj.mongodbRepo.FetchOneTicketlogs(cj.ctx, map[string]interface{}{
+		"ticket.id": ticket.ID.Hex(),
+		"sort":      "createdAt",
+		"dir":       "desc",
+	})
+	if err != nil {
+		return
+	}
+	if timelogs != nil {
+		timelogs.EndAt = &now
+		timelogs.DurationInSeconds = duration
+		timelogs.UpdatedAt = &now
+		if err = cj.mongodbRepo.UpdateTicketlogs(cj.ctx, timelogs); err != nil {
+			return
+		}
+	}
+
+	// pooled company draw from the company wallet, the segment already reached the member cap so the part past it is overage
+	if helpers.IsPooledWallet(company) {
+		return helpers.DrawCompanyWallet(cj.ctx, cj.mongodbRepo, ticket, customer, company, int64(duration))
+	}
+
+	if customer.Subscription == nil || customer.Subscription.Balance == nil {
+		return
+	}
+
+	if err = cj.mongodbRepo.IncrementOneCustomer(cj.ctx, customer.ID.Hex(), map[string]int64{
+		"subscription.balance.time.used": int64(duration),
+	}); err != nil {
+		return
+	}
+	customer.Subscription.Balance.Time.Used += int64(duration)
+
+	return cj.mongodbRepo.CreateCustomerBalanceHistory(cj.ctx, helpers.NewCustomerBalanceHistory(customer, 0, int64(duration), model.Reference{
+		UniqueID: ticket.ID.Hex(),
+		Number:   ticket.Code,
+		Type:     model.TicketReference,
+	}, "Ticket "+ticket.Subject))
+}
@@ -60,7 +60,9 @@ package cronjob
 
 import (
 	mongorepo "app/app/repository/mongo"
+	provisionerrepo "app/app/repository/provisioner"
 	redisrepo "app/app/repository/redis"
+	xenditrepo "app/app/repository/xendit"
 	"context"
 
 	"github.com/robfig/cron/v3"
@@ -68,10 +70,12 @@ import (
 )
 
 type cronjob struct {
-	ctx         context.Context
-	cron        *cron.Cron
-	mongodbRepo mongorepo.MongoDBRepo
-	redisRepo   redisrepo.RedisRepo
+	ctx             context.Context
+	cron            *cron.Cron
+	mongodbRepo     mongorepo.MongoDBRepo
+	redisRepo       redisrepo.RedisRepo
+	xenditRepo      xenditrepo.XenditRepo
+	provisionerRepo provisionerrepo.ProvisionerRepo
 }
 
 type RepoInjection struct {
@@ -79,14 +83,18 @@ type RepoInjection struct {
 	Cron        *cron.Cron
 	MongoDBRepo mongorepo.MongoDBRepo
 	Redis       redisrepo.RedisRepo
+	XenditRepo  xenditrepo.XenditRepo
+	Provisioner provisionerrepo.ProvisionerRepo
 }
 
 func NewCronjob(r RepoInjection) CronjobHandler {
 	return &cronjob{
-		ctx:         r.Ctx,
-		cron:        r.Cron,
-		mongodbRepo: r.MongoDBRepo,
-		redisRepo:   r.Redis,
+		ctx:             r.Ctx,
+		cron:            r.Cron,
+		mongodbRepo:     r.MongoDBRepo,
+		redisRepo:       r.Redis,
+		xenditRepo:      r.XenditRepo,
+		provisionerRepo: r.Provisioner,
 	}
 }
 
@@ -97,6 +105,12 @@ type CronjobHandler interface {
 func (cj *cronjob) Run(runInBackground bool) {
 	cj.SyncExpiredSubscription()
 	cj.AutoCloseResolvedTickets()
+	cj.ExpireOverdueOrders()
+	cj.RemindPendingOrders()
+	cj.NotifyWaitingApprovalOrders()
+	cj.WatchRunningTimerBalance()
+	cj.RenewSubscriptions()
+	cj.SyncServerResources()
 
 	// starting cron
 	logrus.Info("Cronjob started")
@@ -0,0 +1,294 @@
+package cronjob
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func (cj *cronjob) ExpireOverdueOrders() {
+	cj.cron.AddFunc("*/5 * * * *", func() {
+		t := time.Now()
+		logrus.Info("ExpireOverdueOrders: cron started at ", t)
+
+		cur, err := cj.mongodbRepo.FetchOrderList(cj.ctx, map[string]interface{}{
+			"status":        []string{string(model.STATUS_PENDING)},
+			"expiredBefore": t,
+		})
+		if err != nil {
+			logrus.Error("FetchOrderList: ", err)
+			return
+		}
+
+		defer cur.Close(cj.ctx)
+
+		config := cj._CacheConfig(cj.ctx)
+
+		for cur.Next(cj.ctx) {
+			order := model.Order{}
+			if err := cur.Decode(&order); err != nil {
+				logrus.Error("Order Decode ", err)
+				continue
+			}
+
+			// cancel the invoice on payment gateway, the order stays pending while the invoice can still be paid
+			if order.Invoice.InvoiceXenditId != "" {
+				result, err := cj.xenditRepo.ExpireInvoice(cj.ctx, order.Invoice.InvoiceXenditId)
+				if err != nil {
+					logrus.Errorf("ExpireInvoice %s: %v", order.OrderNumber, err)
+					continue
+				}
+				if result.Status != 200 {
+					logrus.Errorf("ExpireInvoice %s: %s", order.OrderNumber, result.Message)
+					continue
+				}
+			}
+
+			// update status, skipped when the order got paid meanwhile
+			now := time.Now()
+			expired, err := cj.mongodbRepo.UpdatePartialOrder(cj.ctx, map[string]interface{}{
+				"id":     order.ID,
+				"status": []string{string(model.STATUS_PENDING)},
+			}, map[string]interface{}{
+				"status":         model.STATUS_EXPIRED,
+				"payment.status": string(model.STATUS_EXPIRED),
+				"updatedAt":      now,
+			})
+			if err != nil {
+				logrus.Error("UpdatePartialOrder: ", err)
+				continue
+			}
+			if !expired {
+				continue
+			}
+			order.Status = model.STATUS_EXPIRED
+			order.Payment.Status = string(model.STATUS_EXPIRED)
+			order.UpdatedAt = now
+
+			company := cj._fetchOrderCompany(&order)
+			if company == nil {
+				continue
+			}
+
+			// send email
+			template := cj._orderTemplate(config.Email.Template.OrderExpired, model.TemplateEmailConfig{
+				Title: "Your order has expired",
+				Body:  "Dear {{customer_name}},<br><br>Your order <b>{{order_number}}</b> has expired because the payment was not received before {{expired_at}}.<br><br>Please create a new order if you still want to purchase {{package_name}}.",
+			})
+			cj._sendEmailOrder(template, &order, company)
+		}
+	})
+
+	logrus.Info("Cron ExpireOverdueOrders added")
+}
+
+func (cj *cronjob) RemindPendingOrders() {
+	cj.cron.AddFunc("*/5 * * * *", func() {
+		t := time.Now()
+		logrus.Info("RemindPendingOrders: cron started at ", t)
+
+		config := cj._CacheConfig(cj.ctx)
+
+		// remind one hour before expired by default
+		before := config.PaymentReminder.BeforeExpiredInSecond
+		if before <= 0 {
+			before = 60 * 60
+		}
+
+		cur, err := cj.mongodbRepo.FetchOrderList(cj.ctx, map[string]interface{}{
+			"status":              []string{string(model.STATUS_PENDING)},
+			"expiredAfter":        t,
+			"expiredBefore":       t.Add(time.Duration(before) * time.Second),
+			"paymentReminderSent": false,
+		})
+		if err != nil {
+			logrus.Error("FetchOrderList: ", err)
+			return
+		}
+
+		defer cur.Close(cj.ctx)
+
+		for cur.Next(cj.ctx) {
+			order := model.Order{}
+			if err := cur.Decode(&order); err != nil {
+				logrus.Error("Order Decode ", err)
+				continue
+			}
+
+			company := cj._fetchOrderCompany(&order)
+			if company == nil {
+				continue
+			}
+
+			// send email
+			template := cj._orderTemplate(config.Email.Template.PaymentReminder, model.TemplateEmailConfig{
+				Title: "Complete your payment",
+				Body:  "Dear {{customer_name}},<br><br>Your order <b>{{order_number}}</b> for {{package_name}} is waiting for payment and will expire at {{expired_at}}.<br><br>{{payment_link}}",
+			})
+			cj._sendEmailOrder(template, &order, company)
+
+			now := time.Now()
+			if _, err := cj.mongodbRepo.UpdatePartialOrder(cj.ctx, map[string]interface{}{
+				"id":     order.ID,
+				"status": []string{string(model.STATUS_PENDING)},
+			}, map[string]interface{}{
+				"reminder.paymentSentAt": now,
+				"updatedAt":              now,
+			}); err != nil {
+				logrus.Error("UpdatePartialOrder: ", err)
+			}
+		}
+	})
+
+	logrus.Info("Cron RemindPendingOrders added")
+}
+
+func (cj *cronjob) NotifyWaitingApprovalOrders() {
+	cj.cron.AddFunc("*/15 * * * *", func() {
+		t := time.Now()
+		logrus.Info("NotifyWaitingApprovalOrders: cron started at ", t)
+
+		cur, err := cj.mongodbRepo.FetchOrderList(cj.ctx, map[string]interface{}{
+			"status":              []string{string(model.STATUS_WAITING_APPROVAL)},
+			"waitingApprovalSent": false,
+		})
+		if err != nil {
+			logrus.Error("FetchOrderList: ", err)
+			return
+		}
+
+		defer cur.Close(cj.ctx)
+
+		// collect superadmin emails
+		superadmins := []string{}
+		curSuperadmin, err := cj.mongodbRepo.FetchSuperadminList(cj.ctx, map[string]interface{}{})
+		if err != nil {
+			logrus.Error("FetchSuperadminList: ", err)
+			return
+		}
+		defer curSuperadmin.Close(cj.ctx)
+		for curSuperadmin.Next(cj.ctx) {
+			row := model.Superadmin{}
+			if err := curSuperadmin.Decode(&row); err != nil {
+				logrus.Error("Superadmin Decode ", err)
+				continue
+			}
+			superadmins = append(superadmins, row.Email)
+		}
+		if len(superadmins) == 0 {
+			return
+		}
+
+		config := cj._CacheConfig(cj.ctx)
+
+		for cur.Next(cj.ctx) {
+			order := model.Order{}
+			if err := cur.Decode(&order); err != nil {
+				logrus.Error("Order Decode ", err)
+				continue
+			}
+
+			company := cj._fetchOrderCompany(&order)
+			if company == nil {
+				continue
+			}
+
+			template := cj._orderTemplate(config.Email.Template.WaitingApproval, model.TemplateEmailConfig{
+				Title: "Manual payment waiting for approval",
+				Body:  "Order <b>{{order_number}}</b> from {{customer_name}} ({{customer_email}}) for {{package_name}} has a manual transfer waiting for approval.",
+			})
+
+			mail := helpers.NewSMTPMailer(company)
+			mail.To(superadmins)
+			mail.Subject(helpers.StringReplacer(template.Title, cj._orderReplacer(&order)))
+			mail.Body(helpers.StringReplacer(template.Body, cj._orderReplacer(&order)))
+			if err := mail.Send(); err != nil {
+				logrus.Errorf("Send Email waiting approval %s error %v", order.OrderNumber, err)
+				continue
+			}
+
+			now := time.Now()
+			if _, err := cj.mongodbRepo.UpdatePartialOrder(cj.ctx, map[string]interface{}{
+				"id":     order.ID,
+				"status": []string{string(model.STATUS_WAITING_APPROVAL)},
+			}, map[string]interface{}{
+				"reminder.waitingApprovalSentAt": now,
+				"updatedAt":                      now,
+			}); err != nil {
+				logrus.Error("UpdatePartialOrder: ", err)
+			}
+		}
+	})
+
+	logrus.Info("Cron NotifyWaitingApprovalOrders added")
+}
+
+func (cj *cronjob) _fetchOrderCompany(order *model.Order) *model.Company {
+	// check customer
+	customer, err := cj.mongodbRepo.FetchOneCustomer(cj.ctx, map[string]interface{}{
+		"id": order.Customer.ID,
+	})
+	if err != nil || customer == nil {
+		logrus.Error("FetchOneCustomer: ", err)
+		return nil
+	}
+
+	// check company
+	company, err := cj.mongodbRepo.FetchOneCompany(cj.ctx, map[string]interface{}{
+		"id": customer.Company.ID,
+	})
+	if err != nil || company == nil {
+		logrus.Error("FetchOneCompany: ", err)
+		return nil
+	}
+
+	return company
+}
+
+// _orderTemplate use the configured template and fall back to the default one when it is not set
+func (cj *cronjob) _orderTemplate(template, fallback model.TemplateEmailConfig) model.TemplateEmailConfig {
+	if template.Title == "" || template.Body == "" {
+		return fallback
+	}
+	return template
+}
+
+func (cj *cronjob) _orderReplacer(order *model.Order) map[string]string {
+	packageName := ""
+	if order.HourPackage != nil {
+		packageName = order.HourPackage.Name
+	} else if order.ServerPackage != nil {
+		packageName = order.ServerPackage.Name
+	}
+
+	paymentLink := ""
+	if order.Invoice.InvoiceURL != "" {
+		paymentLink = `<a href="` + order.Invoice.InvoiceURL + `">Pay now</a>`
+	}
+
+	return map[string]string{
+		"customer_name":  order.Customer.Name,
+		"customer_email": order.Customer.Email,
+		"order_number":   order.OrderNumber,
+		"package_name":   packageName,
+		"price":          order.OrderCurrency() + " " + helpers.FormatFloat("#,###.##", order.GrandTotal),
+		"expired_at":     order.ExpiredAt.Format("2006-01-02 15:04"),
+		"payment_link":   paymentLink,
+	}
+}
+
+func (cj *cronjob) _sendEmailOrder(template model.TemplateEmailConfig, order *model.Order, company *model.Company) {
+	replacer := cj._orderReplacer(order)
+
+	mail := helpers.NewSMTPMailer(company)
+	mail.To([]string{order.Customer.Email})
+	mail.Subject(helpers.StringReplacer(template.Title, replacer))
+	mail.Body(helpers.StringReplacer(template.Body, replacer))
+
+	// send
+	if err := mail.Send(); err != nil {
+		logrus.Errorf("Send Email to %s error %v", order.Customer.Email, err)
+	}
+}
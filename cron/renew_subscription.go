@@ -0,0 +1,245 @@
+package cronjob
+
+import (
+	"app/domain"
+	"app/domain/model"
+	"app/helpers"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (cj *cronjob) RenewSubscriptions() {
+	cj.cron.AddFunc("0 * * * *", func() {
+		t := time.Now()
+		logrus.Info("RenewSubscriptions: cron started at ", t)
+
+		config := cj._CacheConfig(cj.ctx)
+
+		// create the renewal order three days before expired by default
+		before := config.AutoRenewal.BeforeExpiredInSecond
+		if before <= 0 {
+			before = 3 * 24 * 60 * 60
+		}
+
+		cur, err := cj.mongodbRepo.FetchCustomerSubscriptionList(cj.ctx, map[string]interface{}{
+			"status":        model.Active,
+			"autoRenew":     true,
+			"hasRenewal":    false,
+			"expiredBefore": t.Add(time.Duration(before) * time.Second),
+		}, false)
+		if err != nil {
+			logrus.Error("FetchCustomerSubscriptionList: ", err)
+			return
+		}
+
+		defer cur.Close(cj.ctx)
+
+		for cur.Next(cj.ctx) {
+			row := model.CustomerSubscription{}
+			if err := cur.Decode(&row); err != nil {
+				logrus.Error("Subscription Decode ", err)
+				continue
+			}
+
+			order, company, err := cj._createRenewalOrder(config, &row)
+			if err != nil {
+				logrus.Errorf("Renewal order subscription %s error %v", row.ID.Hex(), err)
+				continue
+			}
+
+			// mark renewal as waiting for payment
+			row.Renewal = &model.SubscriptionRenewal{
+				Order: model.OrderFK{
+					ID:          order.ID.Hex(),
+					OrderNumber: order.OrderNumber,
+					Type:        order.Type,
+				},
+				CreatedAt: time.Now(),
+			}
+			row.UpdatedAt = time.Now()
+			if err := cj.mongodbRepo.UpdateOneCustomerSubscription(cj.ctx, &row); err != nil {
+				logrus.Error("UpdateOneCustomerSubscription: ", err)
+				continue
+			}
+
+			// send invoice
+			template := cj._orderTemplate(config.Email.Template.RenewalInvoice, model.TemplateEmailConfig{
+				Title: "Your subscription renewal invoice",
+				Body:  "Dear {{customer_name}},<br><br>Your subscription of {{package_name}} will be renewed. Please complete the payment of order <b>{{order_number}}</b> ({{price}}) before {{expired_at}}.<br><br>{{payment_link}}",
+			})
+			cj._sendEmailOrder(template, order, company)
+		}
+	})
+
+	logrus.Info("Cron RenewSubscriptions added")
+}
+
+// _createRenewalOrder create the order of the same package at its current price
+func (cj *cronjob) _createRenewalOrder(config model.Config, subscription *model.CustomerSubscription) (*model.Order, *model.Company, error) {
+	// check customer
+	customer, err := cj.mongodbRepo.FetchOneCustomer(cj.ctx, map[string]interface{}{
+		"id": subscription.Customer.ID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if customer == nil {
+		return nil, nil, fmt.Errorf("customer not found")
+	}
+
+	// check company
+	company, err := cj.mongodbRepo.FetchOneCompany(cj.ctx, map[string]interface{}{
+		"id": customer.Company.ID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if company == nil {
+		return nil, nil, fmt.Errorf("company not found")
+	}
+
+	// charge in the customer currency, the rates are frozen on the order
+	currency := helpers.CustomerCurrency(customer)
+	rate, err := helpers.ExchangeRate(cj.ctx, cj.mongodbRepo, config, currency)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rate == 0 {
+		return nil, nil, fmt.Errorf("exchange rate of %s is not available", currency)
+	}
+	dollarInIdr, err := helpers.ExchangeRate(cj.ctx, cj.mongodbRepo, config, model.CurrencyIDR)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// count order
+	count := cj.mongodbRepo.CountOrder(cj.ctx, map[string]interface{}{
+		"today": true,
+	})
+
+	order := &model.Order{
+		ID: primitive.NewObjectID(),
+		Customer: model.CustomerFK{
+			ID:    customer.ID.Hex(),
+			Name:  customer.Name,
+			Email: customer.Email,
+		},
+		OrderNumber: helpers.GenerateFormattedCode("TRX", count+1, helpers.RandomChar(3)),
+		Amount:      1,
+		Type:        subscription.Order.Type,
+		Renewal: &model.OrderRenewal{
+			SubscriptionID: subscription.ID.Hex(),
+		},
+		Currency:     currency,
+		ExchangeRate: rate,
+		DollarInIdr:  dollarInIdr,
+		Status:       model.STATUS_PENDING,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	var oneHourPackage *model.HourPackage
+	var oneServerPackage *model.ServerPackage
+	if subscription.Order.Type == model.HOUR_TYPE {
+		if subscription.HourPackage == nil {
+			return nil, nil, fmt.Errorf("hour package not found")
+		}
+
+		// pooled hours are bought by the company
+		if helpers.IsPooledWallet(company) {
+			return nil, nil, fmt.Errorf("company wallet is pooled")
+		}
+
+		oneHourPackage, err = cj.mongodbRepo.FetchOneHourPackage(cj.ctx, map[string]interface{}{
+			"id":     subscription.HourPackage.ID,
+			"type":   string(model.HourPackageTypeRegular),
+			"status": string(model.HourPackageActive),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if oneHourPackage == nil {
+			return nil, nil, fmt.Errorf("hour package not found")
+		}
+
+		order.HourPackage = &model.HourPackageFK{
+			ID:      oneHourPackage.ID.Hex(),
+			Name:    oneHourPackage.Name,
+			Hours:   oneHourPackage.Duration.Hours,
+			Benefit: oneHourPackage.Benefit,
+			Price:   oneHourPackage.Price,
+		}
+		order.UnitPrice = helpers.PackagePrice(oneHourPackage.Price, oneHourPackage.Prices, currency, rate)
+		order.SubTotal = order.UnitPrice
+
+		// time used beyond the balance is billed on this order
+		if order.Overage = helpers.NewOrderOverage(company, customer, oneHourPackage, order.UnitPrice, currency); order.Overage != nil {
+			order.SubTotal += order.Overage.Amount
+		}
+	} else {
+		if subscription.ServerPackage == nil {
+			return nil, nil, fmt.Errorf("server package not found")
+		}
+
+		oneServerPackage, err = cj.mongodbRepo.FetchOneServerPackage(cj.ctx, map[string]interface{}{
+			"id":     subscription.ServerPackage.ID,
+			"status": string(model.ServerPackageActive),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if oneServerPackage == nil {
+			return nil, nil, fmt.Errorf("server package not found")
+		}
+
+		order.ServerPackage = &model.ServerPackageFK{
+			ID:           oneServerPackage.ID.Hex(),
+			Name:         oneServerPackage.Name,
+			Customizable: oneServerPackage.Customizable,
+			Validity:     oneServerPackage.Validity,
+			Benefit:      oneServerPackage.Benefit,
+			Price:        oneServerPackage.Price,
+		}
+		order.UnitPrice = helpers.PackagePrice(oneServerPackage.Price, oneServerPackage.Prices, currency, rate)
+		order.SubTotal = order.UnitPrice
+	}
+	order.GrandTotal = order.SubTotal + order.AdminFee + order.Tax - order.Discount
+
+	if !config.ManualPayment.IsActive {
+		// do generete snap link
+		result, err := cj.xenditRepo.GenereteSnapLink(cj.ctx, oneHourPackage, oneServerPackage, *order)
+		if err != nil {
+			return nil, nil, err
+		}
+		if result.Status != 200 {
+			return nil, nil, errors.New(result.Message)
+		}
+		respDataXendit, _ := result.Data.(domain.XenditGenereteSnapLinkResponseSuccess)
+		order.Payment.Status = respDataXendit.Status
+		order.Invoice.InvoiceXenditId = respDataXendit.ID
+		order.Invoice.InvoiceURL = respDataXendit.InvoiceURL
+		order.Invoice.InvoiceExternalId = respDataXendit.ExternalID
+		order.Payment.Snap = respDataXendit
+		order.ExpiredAt = respDataXendit.ExpiryDate
+	} else {
+		order.Payment.Status = string(model.STATUS_PENDING)
+		order.Invoice.BankCode = config.ManualPayment.BankName
+		order.Invoice.MerchantName = config.ManualPayment.AccountName
+		order.Invoice.PaymentMethod = "MANUAL_PAYMENT"
+		order.Invoice.PaymentChannel = config.ManualPayment.BankName
+		order.Invoice.PaymentDestination = config.ManualPayment.AccountNumber
+		order.Invoice.SwiftCode = config.ManualPayment.SwiftCode
+		order.ExpiredAt = time.Now().Add(time.Second * time.Duration(config.ManualPayment.DurationInSecond))
+	}
+
+	// save
+	if err := cj.mongodbRepo.CreateOrder(cj.ctx, order); err != nil {
+		return nil, nil, err
+	}
+
+	return order, company, nil
+}
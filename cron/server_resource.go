@@ -0,0 +1,119 @@
+package cronjob
+
+import (
+	"app/domain/model"
+	"app/helpers"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SyncServerResources suspend the server once its subscription ended, resume it when renewed and terminate it after the retention
+func (cj *cronjob) SyncServerResources() {
+	cj.cron.AddFunc("30 * * * *", func() {
+		t := time.Now()
+		logrus.Info("SyncServerResources: cron started at ", t)
+
+		cur, err := cj.mongodbRepo.FetchServerResourceList(cj.ctx, map[string]interface{}{
+			"status": []string{string(model.ServerActive), string(model.ServerSuspended)},
+		})
+		if err != nil {
+			logrus.Error("FetchServerResourceList: ", err)
+			return
+		}
+
+		defer cur.Close(cj.ctx)
+
+		config := cj._CacheConfig(cj.ctx)
+
+		for cur.Next(cj.ctx) {
+			row := model.ServerResource{}
+			if err := cur.Decode(&row); err != nil {
+				logrus.Error("Server Resource Decode ", err)
+				continue
+			}
+
+			// check subscription
+			subscription, err := cj.mongodbRepo.FetchOneCustomerSubscription(cj.ctx, map[string]interface{}{
+				"id": row.SubscriptionID,
+			})
+			if err != nil || subscription == nil {
+				logrus.Error("FetchOneCustomerSubscription: ", err)
+				continue
+			}
+
+			// the server is kept during the renewal grace period
+			endsAt := subscription.EndsAt()
+			running := subscription.Status == model.Active && endsAt.After(t)
+			row.ExpiredAt = endsAt
+
+			switch {
+			case row.Status == model.ServerActive && !running:
+				if err := cj.provisionerRepo.Suspend(cj.ctx, row); err != nil {
+					logrus.Errorf("Suspend server %s error %v", row.ID.Hex(), err)
+					continue
+				}
+				row.SuspendedAt = &t
+				helpers.SetServerResourceStatus(&row, model.ServerSuspended, "Subscription expired", model.UserNested{})
+				cj._sendEmailServerSuspended(config, &row)
+
+			case row.Status == model.ServerSuspended && running:
+				if err := cj.provisionerRepo.Resume(cj.ctx, row); err != nil {
+					logrus.Errorf("Resume server %s error %v", row.ID.Hex(), err)
+					continue
+				}
+				row.SuspendedAt = nil
+				helpers.SetServerResourceStatus(&row, model.ServerActive, "Subscription renewed", model.UserNested{})
+
+			case row.Status == model.ServerSuspended && row.SuspendedAt != nil && t.Sub(*row.SuspendedAt) >= helpers.ServerTerminateAfter(config):
+				if err := cj.provisionerRepo.Terminate(cj.ctx, row); err != nil {
+					logrus.Errorf("Terminate server %s error %v", row.ID.Hex(), err)
+					continue
+				}
+				row.TerminatedAt = &t
+				helpers.SetServerResourceStatus(&row, model.ServerTerminated, "Not renewed after suspension", model.UserNested{})
+
+			default:
+				row.UpdatedAt = t
+			}
+
+			if err := cj.mongodbRepo.UpdateOneServerResource(cj.ctx, &row); err != nil {
+				logrus.Error("UpdateOneServerResource: ", err)
+			}
+		}
+	})
+
+	logrus.Info("Cron SyncServerResources added")
+}
+
+func (cj *cronjob) _sendEmailServerSuspended(config model.Config, resource *model.ServerResource) {
+	// check company
+	company, err := cj.mongodbRepo.FetchOneCompany(cj.ctx, map[string]interface{}{
+		"id": resource.Company.ID,
+	})
+	if err != nil || company == nil {
+		logrus.Error("FetchOneCompany: ", err)
+		return
+	}
+
+	template := cj._orderTemplate(config.Email.Template.ServerSuspended, model.TemplateEmailConfig{
+		Title: "Your server has been suspended",
+		Body:  "Dear {{customer_name}},<br><br>Your server of {{package_name}} has been suspended because the subscription has expired. Renew the subscription before {{terminated_at}} to keep your server.",
+	})
+	replacer := map[string]string{
+		"customer_name": resource.Customer.Name,
+		"package_name":  resource.ServerPackage.Name,
+		"hostname":      resource.Hostname,
+		"terminated_at": resource.SuspendedAt.Add(helpers.ServerTerminateAfter(config)).Format("02 January 2006"),
+	}
+
+	// send email
+	mail := helpers.NewSMTPMailer(company)
+	mail.To([]string{resource.Customer.Email})
+	mail.Subject(helpers.StringReplacer(template.Title, replacer))
+	mail.Body(helpers.StringReplacer(template.Body, replacer))
+
+	if err := mail.Send(); err != nil {
+		logrus.Errorf("Send Email server suspended to %s error %v", resource.Customer.Email, err)
+	}
+}
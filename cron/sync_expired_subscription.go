@@ -26,6 +26,9 @@ func (cj *cronjob) SyncExpiredSubscription() {
 
 		defer cur.Close(cj.ctx)
 
+		// get from config
+		config := cj._CacheConfig(cj.ctx)
+
 		for cur.Next(cj.ctx) {
 			row := model.CustomerSubscription{}
 			err := cur.Decode(&row)
@@ -39,10 +42,16 @@ func (cj *cronjob) SyncExpiredSubscription() {
 					"id": row.Customer.ID,
 				})
 				if err == nil && customer != nil {
+					// renewal waiting for payment keep the subscription during the grace period
+					if graceUntil := helpers.RenewalGraceUntil(config, &row); graceUntil != nil && t.Before(*graceUntil) {
+						cj._startRenewalGracePeriod(&row, customer, *graceUntil)
+						return
+					}
+
 					// update status
 					row.Status = model.Expired
 					row.UpdatedAt = time.Now()
-					if err := cj.mongodbRepo.UpdatePartialCustomerSubscription(cj.ctx, map[string]interface{}{"id": customer.ID},
+					if err := cj.mongodbRepo.UpdatePartialCustomerSubscription(cj.ctx, map[string]interface{}{"id": row.ID},
 						map[string]interface{}{
 							"status":    row.Status,
 							"updatedAt": time.Now(),
@@ -50,7 +59,14 @@ func (cj *cronjob) SyncExpiredSubscription() {
 						logrus.Error("UpdatePartialCustomerSubscription: ", err)
 					}
 
-					if row.Order.Type == model.HOUR_TYPE {
+					// pooled hours live on the company wallet, member balance is untouched
+					if row.Order.Type == model.HOUR_TYPE && !row.IsCompanyWallet {
+						// remaining hours are forfeited when the package expired
+						remaining := int64(0)
+						if customer.Subscription != nil && customer.Subscription.Balance != nil {
+							remaining = customer.Subscription.Balance.Time.Total - customer.Subscription.Balance.Time.Used
+						}
+
 						// update customer
 						customer.Subscription.Status = model.Expired
 						customer.UpdatedAt = time.Now()
@@ -66,6 +82,17 @@ func (cj *cronjob) SyncExpiredSubscription() {
 							}); err != nil {
 							logrus.Error("UpdateOneCustomer: ", err)
 						}
+
+						// record the forfeited hours on the ledger
+						if remaining > 0 {
+							if err := cj.mongodbRepo.CreateCustomerBalanceHistory(cj.ctx, helpers.NewCustomerBalanceHistory(customer, 0, remaining, model.Reference{
+								UniqueID: row.ID.Hex(),
+								Number:   row.Order.OrderNumber,
+								Type:     model.ExpiredReference,
+							}, "Package expired")); err != nil {
+								logrus.Error("CreateCustomerBalanceHistory: ", err)
+							}
+						}
 					}
 
 					// check company
@@ -73,9 +100,6 @@ func (cj *cronjob) SyncExpiredSubscription() {
 						"id": customer.Company.ID,
 					})
 					if err == nil && company != nil {
-						// get from config
-						config := cj._CacheConfig(cj.ctx)
-
 						// send email
 						cj._sendEmailExpSubscription(config, &row, company)
 					} else {
@@ -93,6 +117,32 @@ func (cj *cronjob) SyncExpiredSubscription() {
 	logrus.Info("Cron SyncExpiredSubscription added")
 }
 
+// _startRenewalGracePeriod keep the subscription and the customer package active until the grace period ends
+func (cj *cronjob) _startRenewalGracePeriod(row *model.CustomerSubscription, customer *model.Customer, graceUntil time.Time) {
+	if row.GraceUntil != nil {
+		return
+	}
+
+	if err := cj.mongodbRepo.UpdatePartialCustomerSubscription(cj.ctx, map[string]interface{}{"id": row.ID},
+		map[string]interface{}{
+			"graceUntil": graceUntil,
+			"updatedAt":  time.Now(),
+		}); err != nil {
+		logrus.Error("UpdatePartialCustomerSubscription: ", err)
+		return
+	}
+
+	if row.Order.Type == model.HOUR_TYPE && customer.Subscription != nil {
+		if err := cj.mongodbRepo.UpdateOneCustomer(cj.ctx, map[string]interface{}{"id": customer.ID},
+			map[string]interface{}{
+				"subscription.endAt": graceUntil,
+				"updatedAt":          time.Now(),
+			}); err != nil {
+			logrus.Error("UpdateOneCustomer: ", err)
+		}
+	}
+}
+
 func (cj *cronjob) _sendEmailExpSubscription(config model.Config, customerSubscription *model.CustomerSubscription, company *model.Company) (err error) {
 	//get package name
 	var packageName string
@@ -0,0 +1,8 @@
+package domain
+
+type APIKeyRequest struct {
+	Name       string   `json:"name"`
+	CustomerID string   `json:"customerID"`
+	Scopes     []string `json:"scopes"`
+	RateLimit  int64    `json:"rateLimit"`
+}
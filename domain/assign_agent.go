@@ -2,4 +2,6 @@ package domain
 
 type AssignAgentRequest struct {
 	AgentIds []string `json:"agentIds"`
+	// assign even when the agent reached the company capacity
+	Force bool `json:"force"`
 }
@@ -4,6 +4,8 @@ type LoginRequest struct {
 	Email     string `json:"email"`
 	Password  string `json:"password"`
 	AccessKey string `json:"accessKey"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 type RegisterRequest struct {
@@ -33,8 +35,14 @@ type SuperuserLoginRequest struct {
 }
 
 type SuperadminLoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token"`
 }
 
 type AccountRequest struct {
@@ -44,3 +52,26 @@ type AccountRequest struct {
 	CompanyProductID string `json:"companyProductId"`
 	Category         string `json:"category"`
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type ImpersonateRequest struct {
+	Reason    string `json:"reason"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type MagicLinkRequest struct {
+	Email     string `json:"email"`
+	AccessKey string `json:"accessKey"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type MagicLinkLoginRequest struct {
+	Token     string `json:"token"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
@@ -23,3 +23,12 @@ type CompanyDomain struct {
 	Subdomain string `json:"subdomain"`
 	FullUrl   string `json:"fullUrl"`
 }
+
+type UpdateCompanyWalletRequest struct {
+	IsPooled bool `json:"isPooled"`
+}
+
+type UpdateWalletCapRequest struct {
+	Limit     int64 `json:"limit"`
+	ResetUsed bool  `json:"resetUsed"`
+}
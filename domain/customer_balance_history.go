@@ -0,0 +1,6 @@
+package domain
+
+type BalanceStatementResponse struct {
+	FileName string `json:"fileName"`
+	File     string `json:"file"`
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+type ExchangeRateRequest struct {
+	Currency    string     `json:"currency"`
+	Rate        float64    `json:"rate"`
+	Note        string     `json:"note"`
+	EffectiveAt *time.Time `json:"effectiveAt"`
+}
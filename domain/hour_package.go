@@ -1,17 +1,29 @@
 package domain
 
 type HourPackageRequest struct {
-	Name          string   `json:"name"`
-	Benefit       []string `json:"benefit"`
-	Price         float64  `json:"price"`
-	DurationHours int64    `json:"durationHours"`
+	Name          string             `json:"name"`
+	Benefit       []string           `json:"benefit"`
+	Price         float64            `json:"price"`
+	Prices        map[string]float64 `json:"prices"`
+	DurationHours int64              `json:"durationHours"`
+	Type          string             `json:"type"`
+	TrialDays     int64              `json:"trialDays"`
+	AutoGrant     bool               `json:"autoGrant"`
 }
 
 type HourPackageUpdate struct {
-	Name          string   `json:"name"`
-	Benefit       []string `json:"benefit"`
-	Price         float64  `json:"price"`
-	DurationHours int64    `json:"durationHours"`
+	Name          string             `json:"name"`
+	Benefit       []string           `json:"benefit"`
+	Price         float64            `json:"price"`
+	Prices        map[string]float64 `json:"prices"`
+	DurationHours int64              `json:"durationHours"`
+	TrialDays     int64              `json:"trialDays"`
+	AutoGrant     bool               `json:"autoGrant"`
+}
+
+type GrantTrialRequest struct {
+	CustomerID string `json:"customerId"`
+	PackageID  string `json:"packageId"`
 }
 
 type HourPackageStatusUpdate struct {
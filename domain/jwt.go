@@ -11,9 +11,12 @@ type JWTClaimUser struct {
 	CompanyID        string              `json:"companyID"`
 	CompanyProductID string              `json:"companyProductID"`
 	Role             string              `json:"role"`
+	SessionID        string              `json:"sid"`
 	Company          model.CompanyNested `json:"-"`
 	//CompanyProduct   model.CompanyProductNested `json:"-"`
 	User model.UserNested `json:"-"`
+	// set on the short lived tokens a superadmin gets when impersonating
+	Impersonator *model.UserNested `json:"imp,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -21,13 +24,17 @@ type JWTClaimAgent struct {
 	UserID    string              `json:"userID"`
 	CompanyID string              `json:"companyID"`
 	Role      string              `json:"role"`
+	SessionID string              `json:"sid"`
 	Company   model.CompanyNested `json:"-"`
 	User      model.UserNested    `json:"-"`
+	// set on the short lived tokens a superadmin gets when impersonating
+	Impersonator *model.UserNested `json:"imp,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type JWTClaimSuperadmin struct {
-	UserID string           `json:"userID"`
-	User   model.UserNested `json:"-"`
+	UserID    string           `json:"userID"`
+	SessionID string           `json:"sid"`
+	User      model.UserNested `json:"-"`
 	jwt.RegisteredClaims
 }
@@ -17,15 +17,38 @@ type Agent struct {
 	Bio                  string             `bson:"bio" json:"bio"`
 	Contact              string             `bson:"contact" json:"contact"`
 	Role                 UserRole           `bson:"role" json:"role"`
-	Category             TicketCategoryFK   `bson:"category" json:"category"`
+	Category             TicketCategoryFK   `bson:"category" json:"category"` // primary category, the first of categories
+	Categories           []AgentCategory    `bson:"categories" json:"categories"`
+	Languages            []string           `bson:"languages" json:"languages"`
+	Load                 *AgentLoad         `bson:"-" json:"load,omitempty"`
 	TotalTicketCompleted int64              `bson:"totalTicketCompleted" json:"totalTicketCompleted"`
 	LastActivityAt       *time.Time         `bson:"lastActivityAt" json:"lastActivityAt"`
 	PasswordResetToken   string             `bson:"passwordResetToken" json:"-"`
+	PasswordChangedAt    *time.Time         `bson:"passwordChangedAt" json:"passwordChangedAt"`
+	PasswordHistory      []string           `bson:"passwordHistory" json:"-"`
+	MustChangePassword   bool               `bson:"mustChangePassword" json:"mustChangePassword"`
+	TwoFactor            TwoFactor          `bson:"twoFactor" json:"twoFactor"`
+	SSO                  *SSOIdentity       `bson:"sso" json:"sso"`
+	Lockout              *AccountLockout    `bson:"lockout" json:"lockout"`
 	CreatedAt            time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt            time.Time          `bson:"updatedAt" json:"updatedAt"`
 	DeletedAt            *time.Time         `bson:"deletedAt" json:"-"`
 }
 
+// AgentCategory category membership of an agent with the skill level, from 1 (basic) to 5 (expert)
+type AgentCategory struct {
+	ID          string `bson:"id" json:"id"`
+	Name        string `bson:"name" json:"name"`
+	Proficiency int    `bson:"proficiency" json:"proficiency"`
+}
+
+// AgentLoad active tickets of the agent against the company capacity, 0 capacity is unlimited
+type AgentLoad struct {
+	Active    int64 `json:"active"`
+	Capacity  int64 `json:"capacity"`
+	Available bool  `json:"available"`
+}
+
 type AgentNested struct {
 	ID    string `bson:"id" json:"id,omitempty"`
 	Name  string `bson:"name" json:"name,omitempty"`
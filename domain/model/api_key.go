@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type APIKeyScope string
+
+const (
+	APIKeyScopeTicketsRead  APIKeyScope = "tickets:read"
+	APIKeyScopeTicketsWrite APIKeyScope = "tickets:write"
+)
+
+// APIKey server to server access for a company, requests act as the linked customer
+type APIKey struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Company    CompanyNested      `bson:"company" json:"company"`
+	Customer   UserNested         `bson:"customer" json:"customer"`
+	Name       string             `bson:"name" json:"name"`
+	Prefix     string             `bson:"prefix" json:"prefix"`
+	KeyHash    string             `bson:"keyHash" json:"-"`
+	Scopes     []APIKeyScope      `bson:"scopes" json:"scopes"`
+	RateLimit  int64              `bson:"rateLimit" json:"rateLimit"` // requests per minute
+	LastUsedAt *time.Time         `bson:"lastUsedAt" json:"lastUsedAt"`
+	LastUsedIP string             `bson:"lastUsedIP" json:"lastUsedIP"`
+	CreatedBy  UserNested         `bson:"createdBy" json:"createdBy"`
+	RotatedAt  *time.Time         `bson:"rotatedAt" json:"rotatedAt"`
+	RevokedAt  *time.Time         `bson:"revokedAt" json:"revokedAt"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time          `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt  *time.Time         `bson:"deletedAt" json:"-"`
+}
+
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuthSessionUserType string
+
+const (
+	SessionCustomer   AuthSessionUserType = "customer"
+	SessionAgent      AuthSessionUserType = "agent"
+	SessionSuperadmin AuthSessionUserType = "superadmin"
+)
+
+type SessionRevokeReason string
+
+const (
+	RevokeLogout             SessionRevokeReason = "logout"
+	RevokeLogoutAll          SessionRevokeReason = "logout_all"
+	RevokePasswordChanged    SessionRevokeReason = "password_changed"
+	RevokeUserDeleted        SessionRevokeReason = "user_deleted"
+	RevokeRefreshTokenReused SessionRevokeReason = "refresh_token_reused"
+	RevokeTwoFactorRequired  SessionRevokeReason = "two_factor_required"
+	RevokeTwoFactorReset     SessionRevokeReason = "two_factor_reset"
+	RevokeImpersonationEnded SessionRevokeReason = "impersonation_ended"
+)
+
+// AuthSession server side login, access tokens carry its id and the refresh token rotates on every use
+type AuthSession struct {
+	ID                primitive.ObjectID  `bson:"_id" json:"id"`
+	UserType          AuthSessionUserType `bson:"userType" json:"userType"`
+	User              UserNested          `bson:"user" json:"user"`
+	CompanyID         string              `bson:"companyId" json:"companyId"`
+	Impersonator      *UserNested         `bson:"impersonator" json:"impersonator"`
+	RefreshTokenHash  string              `bson:"refreshTokenHash" json:"-"`
+	PreviousTokenHash string              `bson:"previousTokenHash" json:"-"`
+	ExpiredAt         time.Time           `bson:"expiredAt" json:"expiredAt"`
+	RefreshedAt       *time.Time          `bson:"refreshedAt" json:"refreshedAt"`
+	RevokedAt         *time.Time          `bson:"revokedAt" json:"revokedAt"`
+	RevokedReason     SessionRevokeReason `bson:"revokedReason" json:"revokedReason"`
+	CreatedAt         time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt         time.Time           `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt         *time.Time          `bson:"deletedAt" json:"-"`
+}
+
+// IsActive check the session is neither revoked nor expired
+func (s *AuthSession) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiredAt)
+}
@@ -17,19 +17,46 @@ type Company struct {
 	TicketTotal   int64              `bson:"ticketTotal" json:"ticketTotal"`
 	Logo          MediaFK            `bson:"logo" json:"logo"`
 	Settings      CompanySeting      `bson:"settings" json:"settings"`
+	Wallet        *CompanyWallet     `bson:"wallet" json:"wallet"`
+	Trial         *TrialGrant        `bson:"trial" json:"trial"`
+	SSO           *CompanySSO        `bson:"sso" json:"sso"`
 	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt     time.Time          `bson:"updatedAt" json:"updatedAt"`
 	DeletedAt     *time.Time         `bson:"deletedAt" json:"-"`
 }
 
+// CompanyWallet pooled hour balance shared by the members of a company
+type CompanyWallet struct {
+	IsPooled bool        `bson:"isPooled" json:"isPooled"`
+	Time     TimeBalance `bson:"time" json:"time"`
+}
+
 type CompanySeting struct {
 	Code      string        `bson:"code" json:"code"`
 	Email     string        `bson:"email" json:"email"`
 	ColorMode ColorMode     `bson:"colorMode" json:"colorMode"`
 	Domain    CompanyDomain `bson:"domain" json:"domain"`
 	SMTP      SMTP          `bson:"smtp" json:"smtp"`
+	Overage   OveragePolicy `bson:"overage" json:"overage"`
+	// agents must enroll two factor authentication before they can login
+	RequireTwoFactor bool `bson:"requireTwoFactor" json:"requireTwoFactor"`
+	// customers can sign in with an emailed link instead of the password
+	PasswordlessLogin bool `bson:"passwordlessLogin" json:"passwordlessLogin"`
+	// new tickets are assigned to the best matching agent by category and language skills
+	AutoAssignTicket bool `bson:"autoAssignTicket" json:"autoAssignTicket"`
+	// maximum open and in progress tickets assigned to an agent, 0 is unlimited
+	AgentCapacity int64 `bson:"agentCapacity" json:"agentCapacity"`
 }
 
+// OveragePolicy what happens when the customer runs out of time balance
+type OveragePolicy string
+
+const (
+	OverageBlock         OveragePolicy = "block"
+	OverageBillNextOrder OveragePolicy = "bill_next_order"
+	OveragePauseTimer    OveragePolicy = "pause_timer"
+)
+
 type SMTP struct {
 	FromAddress string `bson:"fromAddress" json:"fromAddress"`
 	FromName    string `bson:"fromName" json:"fromName"`
@@ -15,6 +15,10 @@ type ConfigPublic struct {
 	Email              ConfigEmail        `bson:"email" json:"-"`
 	Registration       ConfigRegistration `bson:"registration" json:"-"`
 	ResetPasswordLink  string             `bson:"resetPasswordLink" json:"-"`
+	UnlockAccountLink  string             `bson:"unlockAccountLink" json:"-"`
+	TicketTrackingLink string             `bson:"ticketTrackingLink" json:"-"`
+	MagicLoginLink     string             `bson:"magicLoginLink" json:"-"`
+	InvitationLink     string             `bson:"invitationLink" json:"-"`
 	LoginLink          string             `bson:"loginLink" json:"-"`
 	MainDomain         string             `bson:"mainDomain" json:"mainDomain"`
 	CS                 string             `bson:"cs" json:"cs"`
@@ -22,7 +26,12 @@ type ConfigPublic struct {
 	DollarInIdr        float64            `bson:"dollarInIdr" json:"dollarInIdr"`
 	DefaultColor       ColorMode          `bson:"defaultColor" json:"defaultColor"`
 	ManualPayment      ManualPayment      `bson:"manualPayment" json:"manualPayment"`
+	PaymentReminder    PaymentReminder    `bson:"paymentReminder" json:"-"`
+	LowBalance         LowBalance         `bson:"lowBalance" json:"-"`
+	AutoRenewal        AutoRenewal        `bson:"autoRenewal" json:"-"`
+	ServerRetention    ServerRetention    `bson:"serverRetention" json:"-"`
 	BlacklistSubdomain []string           `bson:"blacklistSubdomain" json:"-"`
+	PasswordPolicy     PasswordPolicy     `bson:"passwordPolicy" json:"passwordPolicy"`
 	CreatedAt          time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt          time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
@@ -36,6 +45,10 @@ type Config struct {
 	Email              ConfigEmail        `bson:"email" json:"email"`
 	Registration       ConfigRegistration `bson:"registration" json:"registration"`
 	ResetPasswordLink  string             `bson:"resetPasswordLink" json:"resetPasswordLink"`
+	UnlockAccountLink  string             `bson:"unlockAccountLink" json:"unlockAccountLink"`
+	TicketTrackingLink string             `bson:"ticketTrackingLink" json:"ticketTrackingLink"`
+	MagicLoginLink     string             `bson:"magicLoginLink" json:"magicLoginLink"`
+	InvitationLink     string             `bson:"invitationLink" json:"invitationLink"`
 	LoginLink          string             `bson:"loginLink" json:"loginLink"`
 	MainDomain         string             `bson:"mainDomain" json:"mainDomain"`
 	CS                 string             `bson:"cs" json:"cs"`
@@ -43,7 +56,12 @@ type Config struct {
 	DollarInIdr        float64            `bson:"dollarInIdr" json:"dollarInIdr"`
 	DefaultColor       ColorMode          `bson:"defaultColor" json:"defaultColor"`
 	ManualPayment      ManualPayment      `bson:"manualPayment" json:"manualPayment"`
+	PaymentReminder    PaymentReminder    `bson:"paymentReminder" json:"paymentReminder"`
+	LowBalance         LowBalance         `bson:"lowBalance" json:"lowBalance"`
+	AutoRenewal        AutoRenewal        `bson:"autoRenewal" json:"autoRenewal"`
+	ServerRetention    ServerRetention    `bson:"serverRetention" json:"serverRetention"`
 	BlacklistSubdomain []string           `bson:"blacklistSubdomain" json:"blacklistSubdomain"`
+	PasswordPolicy     PasswordPolicy     `bson:"passwordPolicy" json:"passwordPolicy"`
 	CreatedAt          time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt          time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
@@ -81,6 +99,17 @@ type EmailTemplate struct {
 	ReopenTicket       TemplateEmailConfig `bson:"reopenTicket" json:"reopenTicket"`
 	PackageActivated   TemplateEmailConfig `bson:"packageActivated" json:"packageActivated"`
 	PackageExpired     TemplateEmailConfig `bson:"packageExpired" json:"packageExpired"`
+	PaymentReminder    TemplateEmailConfig `bson:"paymentReminder" json:"paymentReminder"`
+	OrderExpired       TemplateEmailConfig `bson:"orderExpired" json:"orderExpired"`
+	WaitingApproval    TemplateEmailConfig `bson:"waitingApproval" json:"waitingApproval"`
+	LowBalance         TemplateEmailConfig `bson:"lowBalance" json:"lowBalance"`
+	BalanceDepleted    TemplateEmailConfig `bson:"balanceDepleted" json:"balanceDepleted"`
+	RenewalInvoice     TemplateEmailConfig `bson:"renewalInvoice" json:"renewalInvoice"`
+	ServerSuspended    TemplateEmailConfig `bson:"serverSuspended" json:"serverSuspended"`
+	AccountLocked      TemplateEmailConfig `bson:"accountLocked" json:"accountLocked"`
+	TicketTracking     TemplateEmailConfig `bson:"ticketTracking" json:"ticketTracking"`
+	MagicLogin         TemplateEmailConfig `bson:"magicLogin" json:"magicLogin"`
+	CustomerInvitation TemplateEmailConfig `bson:"customerInvitation" json:"customerInvitation"`
 }
 
 type TemplateEmailConfig struct {
@@ -96,3 +125,25 @@ type ManualPayment struct {
 	BankName         string `bson:"bankName" json:"bankName"`
 	SwiftCode        string `bson:"swiftCode" json:"swiftCode"`
 }
+
+type PaymentReminder struct {
+	BeforeExpiredInSecond int64 `bson:"beforeExpiredInSecond" json:"beforeExpiredInSecond"`
+}
+
+// LowBalance remaining time thresholds that trigger an alert, depleted balance is always alerted
+type LowBalance struct {
+	ThresholdsInSecond []int64 `bson:"thresholdsInSecond" json:"thresholdsInSecond"`
+}
+
+// AutoRenewal renewal order lead time, grace period after expiry and how much unused time is carried over
+type AutoRenewal struct {
+	BeforeExpiredInSecond int64 `bson:"beforeExpiredInSecond" json:"beforeExpiredInSecond"`
+	GracePeriodInSecond   int64 `bson:"gracePeriodInSecond" json:"gracePeriodInSecond"`
+	CarryOverPercent      int64 `bson:"carryOverPercent" json:"carryOverPercent"`
+	MaxCarryOverInSecond  int64 `bson:"maxCarryOverInSecond" json:"maxCarryOverInSecond"`
+}
+
+// ServerRetention how long a suspended server is kept before it is terminated
+type ServerRetention struct {
+	TerminateAfterInSecond int64 `bson:"terminateAfterInSecond" json:"terminateAfterInSecond"`
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CreditNote struct {
+	ID               primitive.ObjectID `bson:"_id" json:"id"`
+	CreditNoteNumber string             `bson:"creditNoteNumber" json:"creditNoteNumber"`
+	Order            OrderFK            `bson:"order" json:"order"`
+	Customer         CustomerFK         `bson:"customer" json:"customer"`
+	Amount           float64            `bson:"amount" json:"amount"`
+	Reason           string             `bson:"reason" json:"reason"`
+	Method           RefundMethod       `bson:"method" json:"method"`
+	Status           RefundStatus       `bson:"status" json:"status"`
+	State            RefundState        `bson:"state" json:"state"`
+	Gateway          interface{}        `bson:"gateway" json:"-"`
+	ClawbackSeconds  int64              `bson:"clawbackSeconds" json:"clawbackSeconds"`
+	Document         *MediaFK           `bson:"document" json:"document"`
+	CreatedBy        UserNested         `bson:"createdBy" json:"createdBy"`
+	CompletedAt      *time.Time         `bson:"completedAt" json:"completedAt"`
+	CreatedAt        time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt        time.Time          `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt        *time.Time         `bson:"deletedAt" json:"-"`
+}
+
+type RefundMethod string
+
+const (
+	RefundGateway RefundMethod = "gateway"
+	RefundManual  RefundMethod = "manual"
+)
+
+type RefundStatus string
+
+const (
+	RefundPartial RefundStatus = "partial"
+	RefundFull    RefundStatus = "full"
+)
+
+// RefundState progress of the refund, it is recorded as pending before the payment gateway is called
+// and completed once the order and balances are updated. Credit notes without a state are completed
+type RefundState string
+
+const (
+	RefundStatePending   RefundState = "pending"
+	RefundStateCompleted RefundState = "completed"
+	RefundStateFailed    RefundState = "failed"
+)
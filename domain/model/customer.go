@@ -10,24 +10,35 @@ type Customer struct {
 	ID      primitive.ObjectID `bson:"_id" json:"id"`
 	Company CompanyNested      `bson:"company" json:"company"`
 	// CompanyProduct     CompanyProductNested `bson:"companyProduct" json:"companyProduct"`
-	Name               string        `bson:"name" json:"name"`
-	Email              string        `bson:"email" json:"email"`
-	Password           string        `bson:"password" json:"-"`
-	IsNeedBalance      bool          `bson:"isNeedBalance" json:"isNeedBalance"`
-	Subscription       *Subscription `bson:"subscription" json:"subscription"`
-	ProfilePicture     MediaFK       `bson:"profilePicture" json:"profilePicture"`
-	JobTitle           string        `bson:"jobTitle" json:"jobTitle"`
-	Bio                string        `bson:"bio" json:"bio"`
-	Role               UserRole      `bson:"role" json:"role"`
-	TickeTotal         int64         `bson:"ticketTotal" json:"ticketTotal"`
-	Token              string        `bson:"token" json:"-"`
-	PasswordResetToken string        `bson:"passwordResetToken" json:"-"`
-	IsVerified         bool          `bson:"isVerified" json:"isVerified"`
-	VerifiedAt         *time.Time    `bson:"verifiedAt" json:"-"`
-	LastActivityAt     *time.Time    `bson:"lastActivityAt" json:"lastActivityAt"`
-	CreatedAt          time.Time     `bson:"createdAt" json:"createdAt"`
-	UpdatedAt          time.Time     `bson:"updatedAt" json:"updatedAt"`
-	DeletedAt          *time.Time    `bson:"deletedAt" json:"-"`
+	Name               string          `bson:"name" json:"name"`
+	Email              string          `bson:"email" json:"email"`
+	Password           string          `bson:"password" json:"-"`
+	IsNeedBalance      bool            `bson:"isNeedBalance" json:"isNeedBalance"`
+	Subscription       *Subscription   `bson:"subscription" json:"subscription"`
+	WalletCap          WalletCap       `bson:"walletCap" json:"walletCap"`
+	BalanceAlert       BalanceAlert    `bson:"balanceAlert" json:"-"`
+	Trial              *TrialGrant     `bson:"trial" json:"trial"`
+	Currency           string          `bson:"currency" json:"currency"`
+	ProfilePicture     MediaFK         `bson:"profilePicture" json:"profilePicture"`
+	JobTitle           string          `bson:"jobTitle" json:"jobTitle"`
+	Bio                string          `bson:"bio" json:"bio"`
+	Role               UserRole        `bson:"role" json:"role"`
+	TickeTotal         int64           `bson:"ticketTotal" json:"ticketTotal"`
+	Token              string          `bson:"token" json:"-"`
+	PasswordResetToken string          `bson:"passwordResetToken" json:"-"`
+	PasswordChangedAt  *time.Time      `bson:"passwordChangedAt" json:"passwordChangedAt"`
+	PasswordHistory    []string        `bson:"passwordHistory" json:"-"`
+	MustChangePassword bool            `bson:"mustChangePassword" json:"mustChangePassword"`
+	IsGuest            bool            `bson:"isGuest" json:"isGuest"` // created by a public ticket submission, no password until registered
+	IsVerified         bool            `bson:"isVerified" json:"isVerified"`
+	SSO                *SSOIdentity    `bson:"sso" json:"sso"`
+	Lockout            *AccountLockout `bson:"lockout" json:"lockout"`
+	MagicLink          *MagicLink      `bson:"magicLink" json:"-"`
+	VerifiedAt         *time.Time      `bson:"verifiedAt" json:"-"`
+	LastActivityAt     *time.Time      `bson:"lastActivityAt" json:"lastActivityAt"`
+	CreatedAt          time.Time       `bson:"createdAt" json:"createdAt"`
+	UpdatedAt          time.Time       `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt          *time.Time      `bson:"deletedAt" json:"-"`
 }
 
 type CustomerFK struct {
@@ -44,6 +55,18 @@ type Subscription struct {
 	EndAt       time.Time          `bson:"endAt" json:"endAt"`
 }
 
+// WalletCap limit of time a member may draw from the company wallet, zero limit means unlimited
+type WalletCap struct {
+	Limit int64 `bson:"limit" json:"limit"`
+	Used  int64 `bson:"used" json:"used"`
+}
+
+// BalanceAlert lowest threshold already alerted, reset when the balance is topped up
+type BalanceAlert struct {
+	Threshold *int64     `bson:"threshold"`
+	AlertedAt *time.Time `bson:"alertedAt"`
+}
+
 type SubscriptionStatus string
 
 const (
@@ -7,24 +7,51 @@ import (
 )
 
 type CustomerBalanceHistory struct {
-	ID        primitive.ObjectID `bson:"_id" json:"id"`
-	Customer  CustomerFK         `bson:"customer" json:"customer"`
-	In        int64              `bson:"in" json:"in"`
-	Out       int64              `bson:"out" json:"out"`
-	Reference Reference          `bson:"reference" json:"reference"`
-	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
-	DeletedAt *time.Time         `bson:"deletedAt" json:"-"`
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Customer    CustomerFK         `bson:"customer" json:"customer"`
+	CompanyID   string             `bson:"companyID" json:"companyID"`
+	Wallet      WalletType         `bson:"wallet" json:"wallet"`
+	In          int64              `bson:"in" json:"in"`
+	Out         int64              `bson:"out" json:"out"`
+	Overage     int64              `bson:"overage" json:"overage"`
+	Balance     int64              `bson:"balance" json:"balance"`
+	Description string             `bson:"description" json:"description"`
+	Reference   Reference          `bson:"reference" json:"reference"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt   *time.Time         `bson:"deletedAt" json:"-"`
 }
 
 type Reference struct {
 	UniqueID string        `bson:"unique_id" json:"unique_id"`
+	Number   string        `bson:"number" json:"number"`
 	Type     ReferenceType `bson:"type" json:"type"`
 }
 
+type WalletType string
+
+const (
+	WalletCustomer WalletType = "customer"
+	WalletCompany  WalletType = "company"
+)
+
 type ReferenceType string
 
 const (
-	OrderReference  ReferenceType = "order"
-	TicketReference ReferenceType = "ticket"
+	OrderReference   ReferenceType = "order"
+	TicketReference  ReferenceType = "ticket"
+	ExpiredReference ReferenceType = "expired"
 )
+
+type BalanceReconciliation struct {
+	Customer          CustomerFK `json:"customer"`
+	LedgerIn          int64      `json:"ledgerIn"`
+	LedgerOut         int64      `json:"ledgerOut"`
+	LedgerBalance     int64      `json:"ledgerBalance"`
+	LedgerPeriodIn    int64      `json:"ledgerPeriodIn"`
+	LedgerPeriodUsed  int64      `json:"ledgerPeriodUsed"`
+	SubscriptionTotal int64      `json:"subscriptionTotal"`
+	SubscriptionUsed  int64      `json:"subscriptionUsed"`
+	Remaining         int64      `json:"remaining"`
+	IsMatched         bool       `json:"isMatched"`
+}
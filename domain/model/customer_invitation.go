@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationRevoked  InvitationStatus = "revoked"
+	InvitationExpired  InvitationStatus = "expired" // pending past its expiry, never stored
+)
+
+// CustomerInvitation a company admin invites a user, the invitee sets the password on accept
+type CustomerInvitation struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Company    CompanyNested      `bson:"company" json:"company"`
+	Name       string             `bson:"name" json:"name"`
+	Email      string             `bson:"email" json:"email"`
+	JobTitle   string             `bson:"jobTitle" json:"jobTitle"`
+	Role       UserRole           `bson:"role" json:"role"`
+	TokenHash  string             `bson:"tokenHash" json:"-"`
+	Status     InvitationStatus   `bson:"status" json:"status"`
+	InvitedBy  UserNested         `bson:"invitedBy" json:"invitedBy"`
+	Customer   *UserNested        `bson:"customer" json:"customer"`
+	SentCount  int64              `bson:"sentCount" json:"sentCount"`
+	LastSentAt time.Time          `bson:"lastSentAt" json:"lastSentAt"`
+	ExpiredAt  time.Time          `bson:"expiredAt" json:"expiredAt"`
+	AcceptedAt *time.Time         `bson:"acceptedAt" json:"acceptedAt"`
+	RevokedAt  *time.Time         `bson:"revokedAt" json:"revokedAt"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time          `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt  *time.Time         `bson:"deletedAt" json:"-"`
+}
+
+func (i *CustomerInvitation) IsExpired() bool {
+	return i.Status == InvitationPending && time.Now().After(i.ExpiredAt)
+}
+
+// Format show the pending invitations past their expiry as expired
+func (i *CustomerInvitation) Format() *CustomerInvitation {
+	if i.IsExpired() {
+		i.Status = InvitationExpired
+	}
+	return i
+}
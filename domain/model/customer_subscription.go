@@ -7,14 +7,34 @@ import (
 )
 
 type CustomerSubscription struct {
-	ID            primitive.ObjectID `bson:"_id" json:"id"`
-	Customer      CustomerFK         `bson:"customer" json:"customer"`
-	HourPackage   *HourPackageFK     `bson:"hourPackage" json:"hourPackage"`
-	ServerPackage *ServerPackageFK   `bson:"serverPackage" json:"serverPackage"`
-	Order         OrderFK            `bson:"order" json:"order"`
-	Status        SubscriptionStatus `bson:"status" json:"status"`
-	ExpiredAt     time.Time          `bson:"expiredAt" json:"expiredAt"`
-	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt     time.Time          `bson:"updatedAt" json:"updatedAt"`
-	DeletedAt     *time.Time         `bson:"deletedAt" json:"-"`
+	ID              primitive.ObjectID   `bson:"_id" json:"id"`
+	Customer        CustomerFK           `bson:"customer" json:"customer"`
+	HourPackage     *HourPackageFK       `bson:"hourPackage" json:"hourPackage"`
+	ServerPackage   *ServerPackageFK     `bson:"serverPackage" json:"serverPackage"`
+	Order           OrderFK              `bson:"order" json:"order"`
+	ExtendedBy      []OrderFK            `bson:"extendedBy" json:"extendedBy"`
+	Status          SubscriptionStatus   `bson:"status" json:"status"`
+	IsCompanyWallet bool                 `bson:"isCompanyWallet" json:"isCompanyWallet"`
+	IsTrial         bool                 `bson:"isTrial" json:"isTrial"`
+	AutoRenew       bool                 `bson:"autoRenew" json:"autoRenew"`
+	Renewal         *SubscriptionRenewal `bson:"renewal" json:"renewal"`
+	GraceUntil      *time.Time           `bson:"graceUntil" json:"graceUntil"`
+	ExpiredAt       time.Time            `bson:"expiredAt" json:"expiredAt"`
+	CreatedAt       time.Time            `bson:"createdAt" json:"createdAt"`
+	UpdatedAt       time.Time            `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt       *time.Time           `bson:"deletedAt" json:"-"`
+}
+
+// SubscriptionRenewal renewal order waiting for payment
+type SubscriptionRenewal struct {
+	Order     OrderFK   `bson:"order" json:"order"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// EndsAt the subscription ends after the grace period while the renewal is waiting for payment
+func (s *CustomerSubscription) EndsAt() time.Time {
+	if s.GraceUntil != nil {
+		return *s.GraceUntil
+	}
+	return s.ExpiredAt
 }
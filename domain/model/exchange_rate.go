@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// BaseCurrency currency of the package list price
+	BaseCurrency = "USD"
+	CurrencyIDR  = "IDR"
+)
+
+// ExchangeRate rate from the base currency, the latest effective entry is used for new orders
+type ExchangeRate struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Currency    string             `bson:"currency" json:"currency"`
+	Rate        float64            `bson:"rate" json:"rate"`
+	Note        string             `bson:"note" json:"note"`
+	CreatedBy   UserNested         `bson:"createdBy" json:"createdBy"`
+	EffectiveAt time.Time          `bson:"effectiveAt" json:"effectiveAt"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt   *time.Time         `bson:"deletedAt" json:"-"`
+}
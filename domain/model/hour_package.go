@@ -12,8 +12,11 @@ type HourPackage struct {
 	Description string                `bson:"description" json:"description"`
 	Benefit     []string              `bson:"benefit" json:"benefit"`
 	Price       float64               `bson:"price" json:"price"`
+	Prices      map[string]float64    `bson:"prices" json:"prices"`
 	Duration    HourPackageDuration   `bson:"duration" json:"duration"`
 	Additional  HourPackageAdditional `bson:"additional" json:"additional"`
+	Type        HourPackageType       `bson:"type" json:"type"`
+	Trial       *HourPackageTrial     `bson:"trial" json:"trial"`
 	Status      HourPackageStatus     `bson:"status" json:"status"`
 	CreatedAt   time.Time             `bson:"createdAt" json:"createdAt"`
 	UpdatedAt   time.Time             `bson:"updatedAt" json:"updatedAt"`
@@ -37,6 +40,33 @@ type HourPackageAdditional struct {
 	TicketLimit *int64 `bson:"ticketLimit" json:"ticketLimit"`
 }
 
+type HourPackageType string
+
+const (
+	HourPackageTypeRegular HourPackageType = "regular"
+	HourPackageTypeTrial   HourPackageType = "trial"
+)
+
+// HourPackageTrial how long the trial lasts and whether it is granted at registration
+type HourPackageTrial struct {
+	Days      int64 `bson:"days" json:"days"`
+	AutoGrant bool  `bson:"autoGrant" json:"autoGrant"`
+}
+
+// TrialGrant the trial taken by a customer, or by a B2B company on behalf of its members
+type TrialGrant struct {
+	HourPackage HourPackageFK `bson:"hourPackage" json:"hourPackage"`
+	Order       OrderFK       `bson:"order" json:"order"`
+	GrantedAt   time.Time     `bson:"grantedAt" json:"grantedAt"`
+	ExpiredAt   time.Time     `bson:"expiredAt" json:"expiredAt"`
+	ConvertedAt *time.Time    `bson:"convertedAt" json:"convertedAt"`
+}
+
+// IsTrial check the package is a trial, packages created before the type default to regular
+func (p *HourPackage) IsTrial() bool {
+	return p.Type == HourPackageTypeTrial && p.Trial != nil
+}
+
 type HourPackageStatus string
 
 const (
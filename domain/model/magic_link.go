@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// MagicLink single use passwordless login of a customer, only the token hash is kept
+type MagicLink struct {
+	CompanyID string    `bson:"companyID" json:"companyID"`
+	TokenHash string    `bson:"tokenHash" json:"-"`
+	ExpiredAt time.Time `bson:"expiredAt" json:"expiredAt"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+func (l *MagicLink) IsExpired() bool {
+	return l == nil || time.Now().After(l.ExpiredAt)
+}
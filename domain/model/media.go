@@ -42,4 +42,5 @@ const (
 	BrandLogo   MediaCategory = "BRAND_LOGO"
 	Avatar      MediaCategory = "AVATAR"
 	Other       MediaCategory = "OTHER"
+	OrderFile   MediaCategory = "ORDER_FILE"
 )
@@ -24,7 +24,9 @@ type Notification struct {
 type NotificationType string
 
 const (
-	TicketCreated NotificationType = "ticketCreated"
-	TicketUpdated NotificationType = "ticketUpdated"
-	TicketClosed  NotificationType = "ticketClosed"
+	TicketCreated   NotificationType = "ticketCreated"
+	TicketUpdated   NotificationType = "ticketUpdated"
+	TicketClosed    NotificationType = "ticketClosed"
+	BalanceLow      NotificationType = "balanceLow"
+	BalanceDepleted NotificationType = "balanceDepleted"
 )
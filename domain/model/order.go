@@ -7,35 +7,78 @@ import (
 )
 
 type Order struct {
-	ID              primitive.ObjectID `bson:"_id" json:"id"`
-	HourPackage     *HourPackageFK     `bson:"hourPackage" json:"hourPackage"`
-	ServerPackage   *ServerPackageFK   `bson:"serverPackage" json:"serverPackage"`
-	Customer        CustomerFK         `bson:"customer" json:"customer"`
-	Invoice         InvoiceNested      `bson:"invoice" json:"invoice"`
-	OrderNumber     string             `bson:"orderNumber" json:"orderNumber"`
-	Status          OrderStatus        `bson:"status" json:"status"`
-	Type            OrderType          `bson:"type" json:"type"`
-	Amount          int64              `bson:"amount" json:"amount"`
-	Tax             float64            `bson:"tax" json:"tax"`
-	AdminFee        float64            `bson:"adminFee" json:"adminFee"`
-	Discount        float64            `bson:"discount" json:"discount"`
-	SubTotal        float64            `bson:"subTotal" json:"subTotal"`
-	GrandTotal      float64            `bson:"grandTotal" json:"grandTotal"`
-	GrandTotalinIdr float64            `bson:"-" json:"grandTotalInIdr"`
-	Note            string             `bson:"note" json:"note"`
-	Payment         Payment            `bson:"payment" json:"payment"`
-	PaidAt          *time.Time         `bson:"paidAt" json:"paidAt"`
-	ExpiredAt       time.Time          `bson:"expiredAt" json:"expiredAt"`
-	CreatedAt       time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt       time.Time          `bson:"updatedAt" json:"updatedAt"`
-	DeletedAt       *time.Time         `bson:"deletedAt" json:"-"`
+	ID              primitive.ObjectID  `bson:"_id" json:"id"`
+	HourPackage     *HourPackageFK      `bson:"hourPackage" json:"hourPackage"`
+	ServerPackage   *ServerPackageFK    `bson:"serverPackage" json:"serverPackage"`
+	Customer        CustomerFK          `bson:"customer" json:"customer"`
+	Invoice         InvoiceNested       `bson:"invoice" json:"invoice"`
+	OrderNumber     string              `bson:"orderNumber" json:"orderNumber"`
+	Status          OrderStatus         `bson:"status" json:"status"`
+	Type            OrderType           `bson:"type" json:"type"`
+	IsCompanyWallet bool                `bson:"isCompanyWallet" json:"isCompanyWallet"`
+	Amount          int64               `bson:"amount" json:"amount"`
+	Tax             float64             `bson:"tax" json:"tax"`
+	AdminFee        float64             `bson:"adminFee" json:"adminFee"`
+	Discount        float64             `bson:"discount" json:"discount"`
+	SubTotal        float64             `bson:"subTotal" json:"subTotal"`
+	GrandTotal      float64             `bson:"grandTotal" json:"grandTotal"`
+	GrandTotalinIdr float64             `bson:"-" json:"grandTotalInIdr"`
+	Currency        string              `bson:"currency" json:"currency"`
+	ExchangeRate    float64             `bson:"exchangeRate" json:"exchangeRate"`
+	DollarInIdr     float64             `bson:"dollarInIdr" json:"dollarInIdr"`
+	UnitPrice       float64             `bson:"unitPrice" json:"unitPrice"`
+	Note            string              `bson:"note" json:"note"`
+	Payment         Payment             `bson:"payment" json:"payment"`
+	Document        OrderDocument       `bson:"document" json:"document"`
+	Refund          *OrderRefund        `bson:"refund" json:"refund"`
+	Overage         *OrderOverage       `bson:"overage" json:"overage"`
+	Renewal         *OrderRenewal       `bson:"renewal" json:"renewal"`
+	Change          *OrderPackageChange `bson:"change" json:"change"`
+	Trial           *OrderTrial         `bson:"trial" json:"trial"`
+	PaidAt          *time.Time          `bson:"paidAt" json:"paidAt"`
+	Reminder        OrderReminder       `bson:"reminder" json:"-"`
+	ExpiredAt       time.Time           `bson:"expiredAt" json:"expiredAt"`
+	CreatedAt       time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt       time.Time           `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt       *time.Time          `bson:"deletedAt" json:"-"`
 }
 
 func (o *Order) Format(c *Config) *Order {
-	o.GrandTotalinIdr = o.GrandTotal * c.DollarInIdr
+	switch {
+	case o.Currency == CurrencyIDR:
+		o.GrandTotalinIdr = o.GrandTotal
+	case o.ExchangeRate > 0 && o.DollarInIdr > 0:
+		o.GrandTotalinIdr = o.GrandTotal / o.ExchangeRate * o.DollarInIdr
+	default:
+		// orders created before the rate was frozen on the order
+		o.GrandTotalinIdr = o.GrandTotal * c.DollarInIdr
+	}
 	return o
 }
 
+// OrderCurrency currency the order is charged in, orders created before multi currency are in the base currency
+func (o *Order) OrderCurrency() string {
+	if o.Currency == "" {
+		return BaseCurrency
+	}
+	return o.Currency
+}
+
+// OverageSeconds time used beyond the balance that is credited back when the order is paid
+func (o *Order) OverageSeconds() int64 {
+	if o.Overage == nil {
+		return 0
+	}
+	return o.Overage.Seconds
+}
+
+// OrderTrial free trial granted without payment
+type OrderTrial struct {
+	Days        int64      `bson:"days" json:"days"`
+	GrantedBy   UserNested `bson:"grantedBy" json:"grantedBy"`
+	ConvertedAt *time.Time `bson:"convertedAt" json:"convertedAt"`
+}
+
 type OrderFK struct {
 	ID          string    `bson:"id" json:"id"`
 	OrderNumber string    `bson:"orderNumber" json:"orderNumber"`
@@ -54,6 +97,58 @@ type InvoiceNested struct {
 	SwiftCode          string `bson:"swiftCode" json:"swiftCode"`
 }
 
+type OrderDocument struct {
+	InvoiceNumber string     `bson:"invoiceNumber" json:"invoiceNumber"`
+	ReceiptNumber string     `bson:"receiptNumber" json:"receiptNumber"`
+	Invoice       *MediaFK   `bson:"invoice" json:"invoice"`
+	Receipt       *MediaFK   `bson:"receipt" json:"receipt"`
+	GeneratedAt   *time.Time `bson:"generatedAt" json:"generatedAt"`
+}
+
+type OrderReminder struct {
+	PaymentSentAt         *time.Time `bson:"paymentSentAt" json:"paymentSentAt"`
+	WaitingApprovalSentAt *time.Time `bson:"waitingApprovalSentAt" json:"waitingApprovalSentAt"`
+}
+
+type PackageChangeType string
+
+const (
+	PackageUpgrade   PackageChangeType = "upgrade"
+	PackageDowngrade PackageChangeType = "downgrade"
+)
+
+// OrderPackageChange subscription replaced by the ordered package, the unused value is credited as discount
+type OrderPackageChange struct {
+	Type           PackageChangeType `bson:"type" json:"type"`
+	SubscriptionID string            `bson:"subscriptionId" json:"subscriptionId"`
+	From           HourPackageFK     `bson:"from" json:"from"`
+	UnusedSeconds  int64             `bson:"unusedSeconds" json:"unusedSeconds"`
+	CarrySeconds   int64             `bson:"carrySeconds" json:"carrySeconds"` // credit above the order total, added as time of the new package
+	Lines          []ProrationLine   `bson:"lines" json:"lines"`
+}
+
+type ProrationLine struct {
+	Description string  `bson:"description" json:"description"`
+	Amount      float64 `bson:"amount" json:"amount"`
+}
+
+// OrderRenewal subscription extended when the order is paid
+type OrderRenewal struct {
+	SubscriptionID string `bson:"subscriptionId" json:"subscriptionId"`
+}
+
+// OrderOverage time used beyond the balance, billed on this order
+type OrderOverage struct {
+	Seconds int64   `bson:"seconds" json:"seconds"`
+	Amount  float64 `bson:"amount" json:"amount"`
+}
+
+type OrderRefund struct {
+	Status     RefundStatus `bson:"status" json:"status"`
+	Amount     float64      `bson:"amount" json:"amount"`
+	RefundedAt time.Time    `bson:"refundedAt" json:"refundedAt"`
+}
+
 type OrderStatus string
 
 const (
@@ -62,6 +157,7 @@ const (
 	STATUS_REJECT           OrderStatus = "reject"
 	STATUS_PAID             OrderStatus = "paid"
 	STATUS_EXPIRED          OrderStatus = "expired"
+	STATUS_REFUNDED         OrderStatus = "refunded"
 )
 
 type OrderType string
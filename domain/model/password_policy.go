@@ -0,0 +1,16 @@
+package model
+
+// PasswordPolicy rules for every password set by a user, an empty policy
+// (minLength 0) falls back to the defaults in helpers.GetPasswordPolicy
+type PasswordPolicy struct {
+	MinLength        int  `bson:"minLength" json:"minLength"`
+	RequireUppercase bool `bson:"requireUppercase" json:"requireUppercase"`
+	RequireLowercase bool `bson:"requireLowercase" json:"requireLowercase"`
+	RequireNumber    bool `bson:"requireNumber" json:"requireNumber"`
+	RequireSymbol    bool `bson:"requireSymbol" json:"requireSymbol"`
+	CheckBreached    bool `bson:"checkBreached" json:"checkBreached"`
+	// number of previous passwords that can not be reused
+	HistoryCount int `bson:"historyCount" json:"historyCount"`
+	// days before an agent has to change the password, 0 never expires
+	AgentExpiryDays int `bson:"agentExpiryDays" json:"agentExpiryDays"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Permission string
+
+const (
+	PermissionTicketAssign   Permission = "ticket.assign"
+	PermissionTicketClose    Permission = "ticket.close"
+	PermissionTicketViewAll  Permission = "ticket.view_all"
+	PermissionCustomerManage Permission = "customer.manage"
+	PermissionBillingView    Permission = "billing.view"
+	PermissionCategoryManage Permission = "category.manage"
+	PermissionUserManage     Permission = "user.manage"
+	PermissionSettingManage  Permission = "setting.manage"
+	PermissionRoleManage     Permission = "role.manage"
+	PermissionServerManage   Permission = "server.manage"
+	PermissionSecurityView   Permission = "security.view"
+	PermissionAPIKeyManage   Permission = "api_key.manage"
+	// leads can take tickets over the agent capacity
+	PermissionTicketOverCapacity Permission = "ticket.over_capacity"
+)
+
+// RoleScope portal the role is given in
+type RoleScope string
+
+const (
+	RoleScopeAgent    RoleScope = "agent"
+	RoleScopeCustomer RoleScope = "customer"
+)
+
+// Role set of permissions referenced by its code from Agent.Role and Customer.Role,
+// default roles are defined in code and custom roles are stored per company
+type Role struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Company     CompanyNested      `bson:"company" json:"company"`
+	Scope       RoleScope          `bson:"scope" json:"scope"`
+	Code        string             `bson:"code" json:"code"`
+	Name        string             `bson:"name" json:"name"`
+	Permissions []Permission       `bson:"permissions" json:"permissions"`
+	IsDefault   bool               `bson:"-" json:"isDefault"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt   *time.Time         `bson:"deletedAt" json:"-"`
+}
+
+// HasPermission check the role grants every given permission
+func (r *Role) HasPermission(permissions ...Permission) bool {
+	for _, permission := range permissions {
+		granted := false
+		for _, p := range r.Permissions {
+			if p == permission {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SecurityEventType string
+
+const (
+	SecurityLoginSuccess    SecurityEventType = "login_success"
+	SecurityLoginFailed     SecurityEventType = "login_failed"
+	SecurityLoginThrottled  SecurityEventType = "login_throttled"
+	SecurityAccountLocked   SecurityEventType = "account_locked"
+	SecurityAccountUnlocked SecurityEventType = "account_unlocked"
+	SecurityMagicLinkSent   SecurityEventType = "magic_link_sent"
+
+	SecurityImpersonationStarted SecurityEventType = "impersonation_started"
+	SecurityImpersonationAction  SecurityEventType = "impersonation_action"
+	SecurityImpersonationEnded   SecurityEventType = "impersonation_ended"
+)
+
+// SecurityEvent audit trail of the logins and impersonations, superadmin events have no company
+type SecurityEvent struct {
+	ID           primitive.ObjectID    `bson:"_id" json:"id"`
+	CompanyID    string                `bson:"companyID" json:"companyID"`
+	UserType     AuthSessionUserType   `bson:"userType" json:"userType"`
+	User         UserNested            `bson:"user" json:"user"`
+	Type         SecurityEventType     `bson:"type" json:"type"`
+	Reason       string                `bson:"reason" json:"reason"`
+	IPAddress    string                `bson:"ipAddress" json:"ipAddress"`
+	UserAgent    string                `bson:"userAgent" json:"userAgent"`
+	Impersonator *UserNested           `bson:"impersonator" json:"impersonator"`
+	Request      *SecurityEventRequest `bson:"request" json:"request"`
+	CreatedAt    time.Time             `bson:"createdAt" json:"createdAt"`
+}
+
+// SecurityEventRequest request made while impersonating, secrets in the body are redacted
+type SecurityEventRequest struct {
+	SessionID string `bson:"sessionID" json:"sessionID"`
+	Method    string `bson:"method" json:"method"`
+	Path      string `bson:"path" json:"path"`
+	Status    int    `bson:"status" json:"status"`
+	Body      string `bson:"body" json:"body"`
+}
+
+// AccountLockout set after too many failed logins, lifted by the emailed link,
+// by a superadmin or once it expires
+type AccountLockout struct {
+	LockedAt  time.Time `bson:"lockedAt" json:"lockedAt"`
+	ExpiredAt time.Time `bson:"expiredAt" json:"expiredAt"`
+	Reason    string    `bson:"reason" json:"reason"`
+	TokenHash string    `bson:"tokenHash" json:"-"`
+}
+
+func (l *AccountLockout) IsActive() bool {
+	return l != nil && time.Now().Before(l.ExpiredAt)
+}
@@ -0,0 +1,7 @@
+package model
+
+// Sequence running number of a document kind, increased atomically so concurrent writers never share a number
+type Sequence struct {
+	ID    string `bson:"_id" json:"id"`
+	Value int64  `bson:"value" json:"value"`
+}
@@ -12,6 +12,7 @@ type ServerPackage struct {
 	Description  string              `bson:"description" json:"description"`
 	Benefit      []string            `bson:"benefit" json:"benefit"`
 	Price        float64             `bson:"price" json:"price"`
+	Prices       map[string]float64  `bson:"prices" json:"prices"`
 	Customizable bool                `bson:"customizable" json:"customizable"`
 	Validity     int64               `bson:"validity" json:"validity"`
 	Status       ServerPackageStatus `bson:"status" json:"status"`
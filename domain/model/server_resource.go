@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ServerResource the server provisioned for a server package subscription
+type ServerResource struct {
+	ID             primitive.ObjectID      `bson:"_id" json:"id"`
+	Company        CompanyNested           `bson:"company" json:"company"`
+	Customer       CustomerFK              `bson:"customer" json:"customer"`
+	SubscriptionID string                  `bson:"subscriptionId" json:"subscriptionId"`
+	ServerPackage  ServerPackageFK         `bson:"serverPackage" json:"serverPackage"`
+	Status         ServerResourceStatus    `bson:"status" json:"status"`
+	Provider       string                  `bson:"provider" json:"provider"`
+	ExternalID     string                  `bson:"externalId" json:"externalId"`
+	Hostname       string                  `bson:"hostname" json:"hostname"`
+	IPAddress      string                  `bson:"ipAddress" json:"ipAddress"`
+	Note           string                  `bson:"note" json:"note"`
+	Task           *ProvisioningTask       `bson:"task" json:"task"`
+	History        []ServerResourceHistory `bson:"history" json:"history"`
+	ExpiredAt      time.Time               `bson:"expiredAt" json:"expiredAt"`
+	SuspendedAt    *time.Time              `bson:"suspendedAt" json:"suspendedAt"`
+	TerminatedAt   *time.Time              `bson:"terminatedAt" json:"terminatedAt"`
+	CreatedAt      time.Time               `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time               `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt      *time.Time              `bson:"deletedAt" json:"-"`
+}
+
+type ServerResourceStatus string
+
+const (
+	ServerRequested    ServerResourceStatus = "requested"
+	ServerProvisioning ServerResourceStatus = "provisioning"
+	ServerActive       ServerResourceStatus = "active"
+	ServerSuspended    ServerResourceStatus = "suspended"
+	ServerTerminated   ServerResourceStatus = "terminated"
+)
+
+// ProvisioningTask the work an agent does to bring the server up
+type ProvisioningTask struct {
+	Status      ProvisioningTaskStatus `bson:"status" json:"status"`
+	Agent       *AgentNested           `bson:"agent" json:"agent"`
+	Note        string                 `bson:"note" json:"note"`
+	CreatedAt   time.Time              `bson:"createdAt" json:"createdAt"`
+	StartedAt   *time.Time             `bson:"startedAt" json:"startedAt"`
+	CompletedAt *time.Time             `bson:"completedAt" json:"completedAt"`
+}
+
+type ProvisioningTaskStatus string
+
+const (
+	TaskOpen       ProvisioningTaskStatus = "open"
+	TaskInProgress ProvisioningTaskStatus = "in_progress"
+	TaskDone       ProvisioningTaskStatus = "done"
+)
+
+type ServerResourceHistory struct {
+	Status    ServerResourceStatus `bson:"status" json:"status"`
+	Note      string               `bson:"note" json:"note"`
+	CreatedBy UserNested           `bson:"createdBy" json:"createdBy"`
+	CreatedAt time.Time            `bson:"createdAt" json:"createdAt"`
+}
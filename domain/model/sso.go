@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// CompanySSO OpenID Connect identity provider of a company
+type CompanySSO struct {
+	Enabled      bool            `bson:"enabled" json:"enabled"`
+	Issuer       string          `bson:"issuer" json:"issuer"`
+	ClientID     string          `bson:"clientId" json:"clientId"`
+	ClientSecret string          `bson:"clientSecret" json:"-"`
+	Scopes       []string        `bson:"scopes" json:"scopes"`
+	RedirectURIs []string        `bson:"redirectUris" json:"redirectUris"`
+	ClaimMapping SSOClaimMapping `bson:"claimMapping" json:"claimMapping"`
+	// email domains allowed to be provisioned, empty allows every domain
+	AllowedDomains []string `bson:"allowedDomains" json:"allowedDomains"`
+	// portals the provider signs into
+	Agent    bool `bson:"agent" json:"agent"`
+	Customer bool `bson:"customer" json:"customer"`
+	// category given to agents provisioned on their first login
+	DefaultCategory TicketCategoryFK `bson:"defaultCategory" json:"defaultCategory"`
+	UpdatedAt       *time.Time       `bson:"updatedAt" json:"updatedAt"`
+}
+
+// SSOClaimMapping id token claims holding the user profile
+type SSOClaimMapping struct {
+	Email    string `bson:"email" json:"email"`
+	Name     string `bson:"name" json:"name"`
+	JobTitle string `bson:"jobTitle" json:"jobTitle"`
+}
+
+// SSOIdentity links a user to the subject of the identity provider
+type SSOIdentity struct {
+	Issuer      string     `bson:"issuer" json:"issuer"`
+	Subject     string     `bson:"subject" json:"subject"`
+	LastLoginAt *time.Time `bson:"lastLoginAt" json:"lastLoginAt"`
+}
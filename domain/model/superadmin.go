@@ -11,6 +11,8 @@ type Superadmin struct {
 	Name      string             `bson:"name" json:"name"`
 	Email     string             `bson:"email" json:"email"`
 	Password  string             `bson:"password" json:"-"`
+	TwoFactor TwoFactor          `bson:"twoFactor" json:"twoFactor"`
+	Lockout   *AccountLockout    `bson:"lockout" json:"lockout"`
 	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
 	DeletedAt *time.Time         `bson:"deletedAt" json:"-"`
@@ -15,6 +15,8 @@ type Ticket struct {
 	Customer     CustomerFK        `bson:"customer" json:"customer"`
 	Agent        []AgentNested     `bson:"agents" json:"agents"`
 	AssignedToMe *bool             `bson:"-" json:"assignedToMe,omitempty"`
+	Presence     *TicketPresence   `bson:"-" json:"presence,omitempty"`
+	CommentCount *int64            `bson:"-" json:"commentCount,omitempty"`
 	Subject      string            `bson:"subject" json:"subject"`
 	Content      string            `bson:"content" json:"content"`
 	Code         string            `bson:"code" json:"code"`
@@ -22,9 +24,11 @@ type Ticket struct {
 	Attachments  []AttachmentFK    `bson:"attachments" json:"attachments"`
 	LogTime      LogTime           `bson:"logTime" json:"logTime"`
 	Priority     TicketPriority    `bson:"priority" json:"priority"`
+	Language     string            `bson:"language" json:"language"`
 	Status       TicketStatus      `bson:"status" json:"status"`
 	ReminderSent bool              `bson:"reminderSent" json:"reminderSent"`
 	Token        string            `bson:"token" json:"-"`
+	TrackToken   string            `bson:"trackToken" json:"-"` // hash of the magic link token of a public submission
 	DetailTime   DetailTime        `bson:"detailTime" json:"detailTime"`
 	Parent       *TicketNested     `bson:"parent" json:"parent"`
 	CompletedBy  *AgentNested      `bson:"completedBy" json:"completedBy"`
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// PresenceState what an agent is doing on a ticket, left clears the agent from the ticket
+type PresenceState string
+
+const (
+	PresenceViewing PresenceState = "viewing"
+	PresenceTyping  PresenceState = "typing"
+	PresenceLeft    PresenceState = "left"
+)
+
+// TicketPresence agents currently on the ticket, it only lives in redis with short TTLs
+type TicketPresence struct {
+	TicketID  string        `json:"ticketId"`
+	Viewing   []AgentNested `json:"viewing"`
+	Typing    []AgentNested `json:"typing"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
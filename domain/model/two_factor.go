@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// TwoFactor time based one time password of an agent or superadmin, recovery codes are stored hashed
+type TwoFactor struct {
+	Enabled       bool       `bson:"enabled" json:"enabled"`
+	Secret        string     `bson:"secret" json:"-"`
+	PendingSecret string     `bson:"pendingSecret" json:"-"`
+	RecoveryCodes []string   `bson:"recoveryCodes" json:"-"`
+	LastUsedStep  int64      `bson:"lastUsedStep" json:"-"`
+	EnabledAt     *time.Time `bson:"enabledAt" json:"enabledAt"`
+}
@@ -22,3 +22,14 @@ type UpdateManualPaymentRequest struct {
 	AccountNumber string `json:"accountNumber"`
 	BankName      string `json:"BankName"`
 }
+
+type RefundOrderRequest struct {
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+	Method string  `json:"method"`
+}
+
+type ChangePackageOrderRequest struct {
+	SubscriptionID string `json:"subscriptionId"`
+	PackageID      string `json:"packageId"`
+}
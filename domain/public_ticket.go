@@ -0,0 +1,21 @@
+package domain
+
+// PublicTicketRequest ticket sent by a visitor without an account, the company is found by domain or accessKey
+type PublicTicketRequest struct {
+	Domain       string `json:"domain"`
+	AccessKey    string `json:"accessKey"`
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Subject      string `json:"subject"`
+	Content      string `json:"content"`
+	Priority     string `json:"priority"`
+	CategoryId   string `json:"categoryId"`
+	Language     string `json:"language"`
+	CaptchaToken string `json:"captchaToken"`
+	IPAddress    string `json:"-"`
+}
+
+type PublicTicketCommentRequest struct {
+	Token   string `json:"token"`
+	Content string `json:"content"`
+}
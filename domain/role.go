@@ -0,0 +1,8 @@
+package domain
+
+type RoleRequest struct {
+	Scope       string   `json:"scope"`
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
@@ -1,19 +1,21 @@
 package domain
 
 type ServerPackageRequest struct {
-	Name         string   `json:"name"`
-	Benefit      []string `json:"benefit"`
-	Price        float64  `json:"price"`
-	Validity     int64    `json:"validity"`
-	Customizable bool     `bson:"customizable" json:"customizable"`
+	Name         string             `json:"name"`
+	Benefit      []string           `json:"benefit"`
+	Price        float64            `json:"price"`
+	Prices       map[string]float64 `json:"prices"`
+	Validity     int64              `json:"validity"`
+	Customizable bool               `bson:"customizable" json:"customizable"`
 }
 
 type ServerPackageUpdate struct {
-	Name         string   `json:"name"`
-	Benefit      []string `json:"benefit"`
-	Price        float64  `json:"price"`
-	Validity     int64    `json:"validity"`
-	Customizable bool     `bson:"customizable" json:"customizable"`
+	Name         string             `json:"name"`
+	Benefit      []string           `json:"benefit"`
+	Price        float64            `json:"price"`
+	Prices       map[string]float64 `json:"prices"`
+	Validity     int64              `json:"validity"`
+	Customizable bool               `bson:"customizable" json:"customizable"`
 }
 
 type ServerPackageStatusUpdate struct {
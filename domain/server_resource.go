@@ -0,0 +1,16 @@
+package domain
+
+// ProvisionResult what the provider knows about the server once asked to provision it, ready when no agent work is left
+type ProvisionResult struct {
+	ExternalID string
+	Hostname   string
+	IPAddress  string
+	Ready      bool
+}
+
+type CompleteProvisioningTaskRequest struct {
+	ExternalID string `json:"externalId"`
+	Hostname   string `json:"hostname"`
+	IPAddress  string `json:"ipAddress"`
+	Note       string `json:"note"`
+}
@@ -22,3 +22,23 @@ type ChangeColorMode struct {
 	Light Color `json:"light"`
 	Dark  Color `json:"dark"`
 }
+
+type ChangeCurrencyRequest struct {
+	Currency string `json:"currency"`
+}
+
+type ChangeOveragePolicyRequest struct {
+	Policy string `json:"policy"`
+}
+
+type ChangePasswordlessLoginRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+type ChangeAutoAssignTicketRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+type ChangeAgentCapacityRequest struct {
+	Capacity *int64 `json:"capacity"`
+}
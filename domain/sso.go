@@ -0,0 +1,30 @@
+package domain
+
+import "app/domain/model"
+
+type SSOAuthorizeRequest struct {
+	AccessKey   string `json:"accessKey"`
+	RedirectURI string `json:"redirectUri"`
+	Binding     string `json:"-"`
+}
+
+type SSOCallbackRequest struct {
+	State   string `json:"state"`
+	Code    string `json:"code"`
+	Binding string `json:"-"`
+}
+
+type UpdateCompanySSORequest struct {
+	Enabled      bool                  `json:"enabled"`
+	Issuer       string                `json:"issuer"`
+	ClientID     string                `json:"clientId"`
+	ClientSecret *string               `json:"clientSecret"`
+	Scopes       []string              `json:"scopes"`
+	RedirectURIs []string              `json:"redirectUris"`
+	ClaimMapping model.SSOClaimMapping `json:"claimMapping"`
+	// AllowedDomains email domains allowed to login, empty allows every domain
+	AllowedDomains    []string `json:"allowedDomains"`
+	Agent             bool     `json:"agent"`
+	Customer          bool     `json:"customer"`
+	DefaultCategoryID string   `json:"defaultCategoryId"`
+}
@@ -11,6 +11,7 @@ type TicketRequest struct {
 	Name       string   `form:"name"`
 	ProjectId  string   `form:"projectId"`
 	CategoryId string   `form:"categoryId"`
+	Language   string   `form:"language"`
 }
 
 type TicketCommentRequest struct {
@@ -18,6 +19,13 @@ type TicketCommentRequest struct {
 	Content   string             `json:"content"`
 	AttachIds []string           `json:"attachIds"`
 	Status    model.TicketStatus `json:"status"`
+	// comment count of the ticket when the agent loaded it, a newer reply is a conflict unless forced
+	CommentCount *int64 `json:"commentCount"`
+	Force        bool   `json:"force"`
+}
+
+type TicketPresenceRequest struct {
+	State model.PresenceState `json:"state"`
 }
 
 type SuperadminTicketCommentRequest struct {
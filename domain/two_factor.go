@@ -0,0 +1,25 @@
+package domain
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+	IPAddress      string `json:"-"`
+	UserAgent      string `json:"-"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type CompanyTwoFactorRequest struct {
+	Required *bool `json:"required"`
+}
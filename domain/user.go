@@ -7,4 +7,26 @@ type CreateUserRequest struct {
 	CompanyProductId string `json:"companyProductId"`
 	Role             string `json:"role"`
 	CategoryId       string `json:"categoryId"`
+	// agent skills, categoryId is kept for clients without multi category
+	Categories []AgentCategoryRequest `json:"categories"`
+	// Languages nil keeps the languages of the agent on update
+	Languages *[]string `json:"languages"`
+}
+
+type AgentCategoryRequest struct {
+	CategoryId  string `json:"categoryId"`
+	Proficiency int    `json:"proficiency"`
+}
+
+// BulkAgentCategoryRequest action is add, remove or set
+type BulkAgentCategoryRequest struct {
+	AgentIds   []string               `json:"agentIds"`
+	Action     string                 `json:"action"`
+	Categories []AgentCategoryRequest `json:"categories"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
 }
@@ -0,0 +1,95 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+)
+
+// AgentLoadStatuses ticket statuses counted against the agent capacity
+var AgentLoadStatuses = []string{string(model.Open), string(model.InProgress)}
+
+// AgentLoadCounter the part of the repository the workload is counted with
+type AgentLoadCounter interface {
+	CountTicketByAgent(ctx context.Context, options map[string]interface{}, agentIDs []string) (map[string]int64, error)
+}
+
+// GetAgentCapacity maximum active tickets per agent of the company, 0 is unlimited.
+// It is a soft limit, two assignments checked at the same time can both pass and go over it by one
+func GetAgentCapacity(company *model.Company) int64 {
+	if company == nil || company.Settings.AgentCapacity < 0 {
+		return 0
+	}
+	return company.Settings.AgentCapacity
+}
+
+func IsAgentAtCapacity(load model.AgentLoad) bool {
+	return load.Capacity > 0 && load.Active >= load.Capacity
+}
+
+func GetAgentLoad(ctx context.Context, counter AgentLoadCounter, company *model.Company, agentID string) (model.AgentLoad, error) {
+	loads, err := GetAgentLoads(ctx, counter, company, []string{agentID})
+	if err != nil {
+		return model.AgentLoad{}, err
+	}
+	return loads[agentID], nil
+}
+
+// GetAgentLoads workload of every given agent counted in one query
+func GetAgentLoads(ctx context.Context, counter AgentLoadCounter, company *model.Company, agentIDs []string) (map[string]model.AgentLoad, error) {
+	totals, err := counter.CountTicketByAgent(ctx, map[string]interface{}{
+		"companyID": company.ID.Hex(),
+		"status":    AgentLoadStatuses,
+	}, agentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	loads := make(map[string]model.AgentLoad, len(agentIDs))
+	for _, agentID := range agentIDs {
+		load := model.AgentLoad{
+			Active:   totals[agentID],
+			Capacity: GetAgentCapacity(company),
+		}
+		load.Available = !IsAgentAtCapacity(load)
+		loads[agentID] = load
+	}
+	return loads, nil
+}
+
+// PickAgentByLoad the best skill match with room left, ties go to the least loaded agent
+func PickAgentByLoad(ctx context.Context, counter AgentLoadCounter, company *model.Company, ranked []model.Agent, ticket *model.Ticket) (*model.Agent, error) {
+	if len(ranked) == 0 {
+		return nil, nil
+	}
+
+	agentIDs := make([]string, len(ranked))
+	for i := range ranked {
+		agentIDs[i] = ranked[i].ID.Hex()
+	}
+	loads, err := GetAgentLoads(ctx, counter, company, agentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var picked *model.Agent
+	var pickedScore int
+	var pickedLoad model.AgentLoad
+
+	for i := range ranked {
+		score := AgentSkillScore(ranked[i], ticket)
+		if picked != nil && score < pickedScore {
+			break
+		}
+
+		load := loads[agentIDs[i]]
+		if !load.Available {
+			continue
+		}
+
+		if picked == nil || load.Active < pickedLoad.Active {
+			picked, pickedScore, pickedLoad = &ranked[i], score, load
+		}
+	}
+
+	return picked, nil
+}
@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryLoadCounter active tickets per agent, counted in one call like the aggregation
+type memoryLoadCounter struct {
+	totals map[string]int64
+	err    error
+	calls  int
+}
+
+func (c *memoryLoadCounter) CountTicketByAgent(ctx context.Context, options map[string]interface{}, agentIDs []string) (map[string]int64, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	totals := map[string]int64{}
+	for _, agentID := range agentIDs {
+		if total, ok := c.totals[agentID]; ok {
+			totals[agentID] = total
+		}
+	}
+	return totals, nil
+}
+
+func TestPickAgentByLoad(t *testing.T) {
+	ctx := context.Background()
+	ticket := &model.Ticket{Category: &model.TicketCategoryFK{ID: "billing"}}
+
+	agent := func(proficiency int) model.Agent {
+		return model.Agent{
+			ID:         primitive.NewObjectID(),
+			Categories: []model.AgentCategory{{ID: "billing", Proficiency: proficiency}},
+		}
+	}
+	expert, expertPeer, basic := agent(5), agent(5), agent(1)
+	ranked := RankAgentsBySkill([]model.Agent{basic, expert, expertPeer}, ticket)
+
+	tests := []struct {
+		name     string
+		capacity int64
+		totals   map[string]int64
+		want     *model.Agent
+	}{
+		{
+			name:     "least loaded of the best match",
+			capacity: 5,
+			totals:   map[string]int64{expert.ID.Hex(): 3, expertPeer.ID.Hex(): 1},
+			want:     &expertPeer,
+		},
+		{
+			name:     "best match full falls back to the next skill level",
+			capacity: 2,
+			totals:   map[string]int64{expert.ID.Hex(): 2, expertPeer.ID.Hex(): 2, basic.ID.Hex(): 1},
+			want:     &basic,
+		},
+		{
+			name:     "everyone full",
+			capacity: 1,
+			totals:   map[string]int64{expert.ID.Hex(): 1, expertPeer.ID.Hex(): 1, basic.ID.Hex(): 1},
+		},
+		{
+			name:   "unlimited capacity",
+			totals: map[string]int64{expert.ID.Hex(): 100, expertPeer.ID.Hex(): 200},
+			want:   &expert,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			company := &model.Company{ID: primitive.NewObjectID()}
+			company.Settings.AgentCapacity = tt.capacity
+			counter := &memoryLoadCounter{totals: tt.totals}
+
+			picked, err := PickAgentByLoad(ctx, counter, company, ranked, ticket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if counter.calls != 1 {
+				t.Fatalf("counted %d times, want 1", counter.calls)
+			}
+			if (picked == nil) != (tt.want == nil) || (picked != nil && picked.ID != tt.want.ID) {
+				t.Fatalf("picked %v, want %v", picked, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAgentLoadCountFailed(t *testing.T) {
+	ctx := context.Background()
+	company := &model.Company{ID: primitive.NewObjectID()}
+	company.Settings.AgentCapacity = 5
+	counter := &memoryLoadCounter{err: errors.New("connection refused")}
+
+	if _, err := GetAgentLoad(ctx, counter, company, "agent"); err == nil {
+		t.Fatal("load returned without an error when counting failed")
+	}
+	ranked := []model.Agent{{ID: primitive.NewObjectID()}}
+	if picked, err := PickAgentByLoad(ctx, counter, company, ranked, &model.Ticket{}); err == nil || picked != nil {
+		t.Fatalf("picked %v, %v, want no agent and an error", picked, err)
+	}
+}
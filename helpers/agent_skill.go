@@ -0,0 +1,160 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"sort"
+	"strings"
+)
+
+const (
+	AgentProficiencyMin     = 1
+	AgentProficiencyMax     = 5
+	AgentProficiencyDefault = 3
+)
+
+// bulk category actions on agents
+const (
+	AgentCategoryAdd    = "add"
+	AgentCategoryRemove = "remove"
+	AgentCategorySet    = "set"
+)
+
+func IsValidAgentProficiency(proficiency int) bool {
+	return proficiency >= AgentProficiencyMin && proficiency <= AgentProficiencyMax
+}
+
+// NormalizeLanguages lowercase language codes without duplicates, ex: ["EN", "id", "en"] => ["en", "id"]
+func NormalizeLanguages(languages []string) []string {
+	result := make([]string, 0)
+	seen := map[string]bool{}
+	for _, language := range languages {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if language == "" || seen[language] {
+			continue
+		}
+		seen[language] = true
+		result = append(result, language)
+	}
+	return result
+}
+
+// GetAgentCategories agents created before multi category only have the primary category
+func GetAgentCategories(agent model.Agent) []model.AgentCategory {
+	if len(agent.Categories) > 0 {
+		return agent.Categories
+	}
+	if agent.Category.ID == "" {
+		return []model.AgentCategory{}
+	}
+	return []model.AgentCategory{{
+		ID:          agent.Category.ID,
+		Name:        agent.Category.Name,
+		Proficiency: AgentProficiencyDefault,
+	}}
+}
+
+func GetAgentCategoryIDs(agent model.Agent) []string {
+	ids := make([]string, 0)
+	for _, category := range GetAgentCategories(agent) {
+		ids = append(ids, category.ID)
+	}
+	return ids
+}
+
+// SetAgentCategories keeps the primary category in sync with the list
+func SetAgentCategories(agent *model.Agent, categories []model.AgentCategory) {
+	agent.Categories = categories
+	agent.Category = model.TicketCategoryFK{}
+	if len(categories) > 0 {
+		agent.Category = model.TicketCategoryFK{ID: categories[0].ID, Name: categories[0].Name}
+	}
+}
+
+// MergeAgentCategories applies a bulk action, an existing category takes the new proficiency on add
+func MergeAgentCategories(current []model.AgentCategory, action string, categories []model.AgentCategory) []model.AgentCategory {
+	if action == AgentCategorySet {
+		return categories
+	}
+
+	changes := map[string]model.AgentCategory{}
+	for _, category := range categories {
+		changes[category.ID] = category
+	}
+
+	result := make([]model.AgentCategory, 0)
+	for _, category := range current {
+		change, ok := changes[category.ID]
+		if !ok {
+			result = append(result, category)
+			continue
+		}
+		if action == AgentCategoryAdd {
+			result = append(result, change)
+		}
+		delete(changes, category.ID)
+	}
+
+	if action == AgentCategoryAdd {
+		for _, category := range categories {
+			if _, ok := changes[category.ID]; ok {
+				result = append(result, category)
+			}
+		}
+	}
+
+	return result
+}
+
+// AgentSkillScore how well the agent fits the ticket, -1 when the agent does not handle its category
+func AgentSkillScore(agent model.Agent, ticket *model.Ticket) int {
+	score := 0
+	if ticket.Category != nil && ticket.Category.ID != "" {
+		score = -1
+		for _, category := range GetAgentCategories(agent) {
+			if category.ID == ticket.Category.ID {
+				score = category.Proficiency * 10
+				break
+			}
+		}
+		if score < 0 {
+			return score
+		}
+	}
+
+	if ticket.Language != "" {
+		for _, language := range agent.Languages {
+			if language == ticket.Language {
+				score += 100
+				break
+			}
+		}
+	}
+
+	return score
+}
+
+// RankAgentsBySkill agents able to handle the ticket, best match first.
+// Speaking the ticket language weighs more than the proficiency in its category
+func RankAgentsBySkill(agents []model.Agent, ticket *model.Ticket) []model.Agent {
+	type rankedAgent struct {
+		agent model.Agent
+		score int
+	}
+
+	ranked := make([]rankedAgent, 0)
+	for _, agent := range agents {
+		if score := AgentSkillScore(agent, ticket); score >= 0 {
+			ranked = append(ranked, rankedAgent{agent: agent, score: score})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	result := make([]model.Agent, 0)
+	for _, row := range ranked {
+		result = append(result, row.agent)
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNormalizeLanguages(t *testing.T) {
+	tests := []struct {
+		languages []string
+		want      []string
+	}{
+		{nil, []string{}},
+		{[]string{"EN", "id", "en"}, []string{"en", "id"}},
+		{[]string{" fr ", "", "FR"}, []string{"fr"}},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeLanguages(tt.languages); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NormalizeLanguages(%v) = %v, want %v", tt.languages, got, tt.want)
+		}
+	}
+}
+
+func TestMergeAgentCategories(t *testing.T) {
+	current := []model.AgentCategory{
+		{ID: "billing", Proficiency: 2},
+		{ID: "network", Proficiency: 4},
+	}
+
+	tests := []struct {
+		name       string
+		action     string
+		categories []model.AgentCategory
+		want       []model.AgentCategory
+	}{
+		{
+			name:       "add new and update existing",
+			action:     AgentCategoryAdd,
+			categories: []model.AgentCategory{{ID: "billing", Proficiency: 5}, {ID: "hardware", Proficiency: 1}},
+			want:       []model.AgentCategory{{ID: "billing", Proficiency: 5}, {ID: "network", Proficiency: 4}, {ID: "hardware", Proficiency: 1}},
+		},
+		{
+			name:       "remove",
+			action:     AgentCategoryRemove,
+			categories: []model.AgentCategory{{ID: "billing"}, {ID: "unknown"}},
+			want:       []model.AgentCategory{{ID: "network", Proficiency: 4}},
+		},
+		{
+			name:       "set",
+			action:     AgentCategorySet,
+			categories: []model.AgentCategory{{ID: "hardware", Proficiency: 3}},
+			want:       []model.AgentCategory{{ID: "hardware", Proficiency: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeAgentCategories(current, tt.action, tt.categories); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("MergeAgentCategories = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankAgentsBySkill(t *testing.T) {
+	agent := func(name string, proficiency int, languages ...string) model.Agent {
+		return model.Agent{
+			ID:         primitive.NewObjectID(),
+			Name:       name,
+			Categories: []model.AgentCategory{{ID: "billing", Proficiency: proficiency}},
+			Languages:  languages,
+		}
+	}
+	expert := agent("expert", 5, "en")
+	speaker := agent("speaker", 1, "id")
+	basic := agent("basic", 2, "en")
+	legacy := model.Agent{ID: primitive.NewObjectID(), Name: "legacy", Category: model.TicketCategoryFK{ID: "billing"}}
+	other := model.Agent{ID: primitive.NewObjectID(), Name: "other", Categories: []model.AgentCategory{{ID: "network", Proficiency: 5}}}
+	agents := []model.Agent{basic, other, legacy, expert, speaker}
+
+	tests := []struct {
+		name   string
+		ticket *model.Ticket
+		want   []string
+	}{
+		{
+			name:   "by proficiency, primary category counts as default",
+			ticket: &model.Ticket{Category: &model.TicketCategoryFK{ID: "billing"}},
+			want:   []string{"expert", "legacy", "basic", "speaker"},
+		},
+		{
+			name:   "language weighs more than proficiency",
+			ticket: &model.Ticket{Category: &model.TicketCategoryFK{ID: "billing"}, Language: "id"},
+			want:   []string{"speaker", "expert", "legacy", "basic"},
+		},
+		{
+			name:   "no category keeps everyone",
+			ticket: &model.Ticket{Language: "en"},
+			want:   []string{"basic", "expert", "other", "legacy", "speaker"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, row := range RankAgentsBySkill(agents, tt.ticket) {
+				got = append(got, row.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("RankAgentsBySkill = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
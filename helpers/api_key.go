@@ -0,0 +1,106 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// api keys look like hdk_<prefix>_<secret>, the prefix finds the key and is safe to show
+const apiKeyPrefix = "hdk_"
+
+var apiKeyScopes = []model.APIKeyScope{
+	model.APIKeyScopeTicketsRead,
+	model.APIKeyScopeTicketsWrite,
+}
+
+func APIKeyScopes() []model.APIKeyScope {
+	return apiKeyScopes
+}
+
+func IsValidAPIKeyScope(scope string) bool {
+	for _, s := range apiKeyScopes {
+		if string(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAPIKeyRateLimit default requests per minute of a key
+func GetAPIKeyRateLimit() int64 {
+	limit, err := strconv.ParseInt(os.Getenv("API_KEY_RATE_LIMIT"), 10, 64)
+	if err != nil || limit <= 0 {
+		return 60
+	}
+	return limit
+}
+
+// NewAPIKey generate the key shown once to the user, only the hash is kept
+func NewAPIKey() (key, prefix, hash string) {
+	b := make([]byte, 4)
+	rand.Read(b)
+	prefix = hex.EncodeToString(b)
+
+	secret := make([]byte, 24)
+	rand.Read(secret)
+	key = apiKeyPrefix + prefix + "_" + hex.EncodeToString(secret)
+
+	return key, prefix, HashAPIKey(key)
+}
+
+// ParseAPIKeyPrefix prefix used to find the key
+func ParseAPIKeyPrefix(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, apiKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CompareAPIKey constant time check of the key against the kept hash
+func CompareAPIKey(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(hash)) == 1
+}
+
+// RateLimiter fixed window counters, shared through redis when it is enabled
+type RateLimiter struct {
+	store AttemptStore
+}
+
+func NewRateLimiter(store AttemptStore) *RateLimiter {
+	if store == nil || !store.Enabled() {
+		store = memoryAttempts
+	}
+	return &RateLimiter{store: store}
+}
+
+// Allow count the request, it returns the remaining requests and when the window resets
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit int64, window time.Duration) (bool, int64, time.Duration) {
+	now := time.Now()
+	start := now.Truncate(window)
+	reset := start.Add(window).Sub(now)
+
+	count, err := l.store.Incr(ctx, "ratelimit:"+key+":"+strconv.FormatInt(start.Unix(), 10), window)
+	if err != nil {
+		// the limiter never blocks on a store failure
+		return true, limit, reset
+	}
+	return count <= limit, max(limit-count, 0), reset
+}
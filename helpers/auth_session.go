@@ -0,0 +1,187 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// refresh rejections, the message is safe to return to the client
+var (
+	ErrRefreshTokenInvalid  = errors.New("refresh token is invalid")
+	ErrRefreshTokenReused   = errors.New("refresh token was already used, please login again")
+	ErrSessionEnded         = errors.New("session has ended, please login again")
+	ErrImpersonationRefresh = errors.New("impersonation sessions can not be refreshed")
+)
+
+// AuthSessionStore sessions shared by the member, agent and superadmin auth
+type AuthSessionStore interface {
+	FetchAuthSessionList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error)
+	FetchOneAuthSession(ctx context.Context, options map[string]interface{}) (*model.AuthSession, error)
+	UpdateOneAuthSession(ctx context.Context, row *model.AuthSession) (err error)
+	RotateAuthSessionToken(ctx context.Context, id primitive.ObjectID, presentedHash, refreshTokenHash string, now time.Time) (matched bool, err error)
+}
+
+// AuthSessionCache cached session state read by the auth middleware
+type AuthSessionCache interface {
+	Enabled() bool
+	Del(ctx context.Context, keys ...string) (err error)
+}
+
+// AuthSessionCacheKey redis key of the session state checked by the auth middleware
+func AuthSessionCacheKey(sessionID string) string {
+	return "auth-session:" + sessionID
+}
+
+// NewAuthSession start a session and return the refresh token, only its hash is stored
+func NewAuthSession(userType model.AuthSessionUserType, user model.UserNested, companyID string) (*model.AuthSession, string) {
+	now := time.Now()
+	session := &model.AuthSession{
+		ID:        primitive.NewObjectID(),
+		UserType:  userType,
+		User:      user,
+		CompanyID: companyID,
+		ExpiredAt: now.Add(time.Duration(GetJWTRefreshTTL()) * time.Minute),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	secret := newRefreshSecret()
+	session.RefreshTokenHash = HashRefreshToken(secret)
+
+	return session, session.ID.Hex() + "." + secret
+}
+
+// RevokeAuthSession end the session, access tokens bound to it are rejected by the middleware
+func RevokeAuthSession(session *model.AuthSession, reason model.SessionRevokeReason) {
+	now := time.Now()
+	session.RevokedAt = &now
+	session.RevokedReason = reason
+	session.UpdatedAt = now
+}
+
+// IsRefreshRejected the error is a refresh rejection rather than a storage failure
+func IsRefreshRejected(err error) bool {
+	return err == ErrRefreshTokenInvalid || err == ErrRefreshTokenReused || err == ErrSessionEnded || err == ErrImpersonationRefresh
+}
+
+// RefreshAuthSession rotate the refresh token of the session with a single conditional update,
+// a token that no longer matches was already rotated and using it again ends the session
+func RefreshAuthSession(ctx context.Context, store AuthSessionStore, cache AuthSessionCache, userType model.AuthSessionUserType, refreshToken string) (*model.AuthSession, string, error) {
+	sessionID, secret, ok := ParseRefreshToken(refreshToken)
+	if !ok {
+		return nil, "", ErrRefreshTokenInvalid
+	}
+
+	session, err := store.FetchOneAuthSession(ctx, map[string]interface{}{
+		"id":       sessionID,
+		"userType": string(userType),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if session == nil {
+		return nil, "", ErrRefreshTokenInvalid
+	}
+	if !session.IsActive() {
+		return nil, "", ErrSessionEnded
+	}
+	if session.Impersonator != nil {
+		return nil, "", ErrImpersonationRefresh
+	}
+
+	hash := HashRefreshToken(secret)
+	if hash != session.RefreshTokenHash && hash != session.PreviousTokenHash {
+		return nil, "", ErrRefreshTokenInvalid
+	}
+
+	now := time.Now()
+	newSecret := newRefreshSecret()
+	matched, err := store.RotateAuthSessionToken(ctx, session.ID, hash, HashRefreshToken(newSecret), now)
+	if err != nil {
+		return nil, "", err
+	}
+	if !matched {
+		// the token was already rotated, by an earlier refresh or a concurrent one holding the same token
+		if err := RevokeStoredAuthSession(ctx, store, cache, session, model.RevokeRefreshTokenReused); err != nil {
+			return nil, "", err
+		}
+		return nil, "", ErrRefreshTokenReused
+	}
+
+	session.PreviousTokenHash = hash
+	session.RefreshTokenHash = HashRefreshToken(newSecret)
+	session.RefreshedAt = &now
+	session.UpdatedAt = now
+
+	return session, session.ID.Hex() + "." + newSecret, nil
+}
+
+// RevokeStoredAuthSession end the session and drop its cached state so the middleware sees it right away
+func RevokeStoredAuthSession(ctx context.Context, store AuthSessionStore, cache AuthSessionCache, session *model.AuthSession, reason model.SessionRevokeReason) error {
+	RevokeAuthSession(session, reason)
+	if err := store.UpdateOneAuthSession(ctx, session); err != nil {
+		return err
+	}
+
+	if cache.Enabled() {
+		cache.Del(ctx, AuthSessionCacheKey(session.ID.Hex()))
+	}
+
+	return nil
+}
+
+// RevokeUserAuthSessions end every active session of the user, e.g. after the password changed
+func RevokeUserAuthSessions(ctx context.Context, store AuthSessionStore, cache AuthSessionCache, userType model.AuthSessionUserType, userID string, reason model.SessionRevokeReason) (int, error) {
+	cur, err := store.FetchAuthSessionList(ctx, map[string]interface{}{
+		"userType": string(userType),
+		"userID":   userID,
+		"active":   true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	total := 0
+	for cur.Next(ctx) {
+		row := model.AuthSession{}
+		if err := cur.Decode(&row); err != nil {
+			return total, err
+		}
+		if err := RevokeStoredAuthSession(ctx, store, cache, &row, reason); err != nil {
+			return total, err
+		}
+		total++
+	}
+
+	return total, nil
+}
+
+// ParseRefreshToken split the refresh token into the session id and the secret
+func ParseRefreshToken(token string) (sessionID string, secret string, ok bool) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || parts[1] == "" || !primitive.IsValidObjectID(parts[0]) {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func HashRefreshToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func newRefreshSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
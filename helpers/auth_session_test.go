@@ -0,0 +1,167 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memorySessionStore keeps one session and applies the rotation filter like the repository
+type memorySessionStore struct {
+	sync.Mutex
+	session model.AuthSession
+}
+
+func (s *memorySessionStore) FetchAuthSessionList(ctx context.Context, options map[string]interface{}) (*mongo.Cursor, error) {
+	return nil, nil
+}
+
+func (s *memorySessionStore) FetchOneAuthSession(ctx context.Context, options map[string]interface{}) (*model.AuthSession, error) {
+	s.Lock()
+	defer s.Unlock()
+	if options["id"] != s.session.ID.Hex() || options["userType"] != string(s.session.UserType) {
+		return nil, nil
+	}
+	row := s.session
+	return &row, nil
+}
+
+func (s *memorySessionStore) UpdateOneAuthSession(ctx context.Context, row *model.AuthSession) error {
+	s.Lock()
+	defer s.Unlock()
+	s.session = *row
+	return nil
+}
+
+func (s *memorySessionStore) RotateAuthSessionToken(ctx context.Context, id primitive.ObjectID, presentedHash, refreshTokenHash string, now time.Time) (bool, error) {
+	s.Lock()
+	defer s.Unlock()
+	if id != s.session.ID || s.session.RefreshTokenHash != presentedHash || s.session.RevokedAt != nil {
+		return false, nil
+	}
+	s.session.PreviousTokenHash = presentedHash
+	s.session.RefreshTokenHash = refreshTokenHash
+	s.session.RefreshedAt = &now
+	return true, nil
+}
+
+type disabledSessionCache struct{}
+
+func (disabledSessionCache) Enabled() bool                                 { return false }
+func (disabledSessionCache) Del(ctx context.Context, keys ...string) error { return nil }
+
+func TestRefreshAuthSession(t *testing.T) {
+	ctx := context.Background()
+
+	newStore := func() (*memorySessionStore, string) {
+		session, token := NewAuthSession(model.SessionCustomer, model.UserNested{ID: "user"}, "company")
+		return &memorySessionStore{session: *session}, token
+	}
+
+	tests := []struct {
+		name    string
+		prepare func(store *memorySessionStore, token string) string
+		wantErr error
+		revoked bool
+	}{
+		{
+			name:    "current token rotates",
+			prepare: func(store *memorySessionStore, token string) string { return token },
+		},
+		{
+			name:    "malformed token",
+			prepare: func(store *memorySessionStore, token string) string { return "not-a-token" },
+			wantErr: ErrRefreshTokenInvalid,
+		},
+		{
+			name: "unknown secret",
+			prepare: func(store *memorySessionStore, token string) string {
+				return store.session.ID.Hex() + ".unknown"
+			},
+			wantErr: ErrRefreshTokenInvalid,
+		},
+		{
+			name: "rotated token reused",
+			prepare: func(store *memorySessionStore, token string) string {
+				if _, _, err := RefreshAuthSession(ctx, store, disabledSessionCache{}, model.SessionCustomer, token); err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr: ErrRefreshTokenReused,
+			revoked: true,
+		},
+		{
+			name: "ended session",
+			prepare: func(store *memorySessionStore, token string) string {
+				RevokeAuthSession(&store.session, model.RevokeLogout)
+				return token
+			},
+			wantErr: ErrSessionEnded,
+			revoked: true,
+		},
+		{
+			name: "impersonation session",
+			prepare: func(store *memorySessionStore, token string) string {
+				store.session.Impersonator = &model.UserNested{ID: "admin"}
+				return token
+			},
+			wantErr: ErrImpersonationRefresh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, token := newStore()
+			token = tt.prepare(store, token)
+
+			session, refreshToken, err := RefreshAuthSession(ctx, store, disabledSessionCache{}, model.SessionCustomer, token)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if (store.session.RevokedAt != nil) != tt.revoked {
+				t.Fatalf("revoked = %v, want %v", store.session.RevokedAt != nil, tt.revoked)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if refreshToken == token || session.RefreshTokenHash != store.session.RefreshTokenHash {
+				t.Fatal("refresh token was not rotated")
+			}
+		})
+	}
+}
+
+func TestRefreshAuthSessionConcurrent(t *testing.T) {
+	ctx := context.Background()
+	session, token := NewAuthSession(model.SessionCustomer, model.UserNested{ID: "user"}, "company")
+	store := &memorySessionStore{session: *session}
+
+	var wg sync.WaitGroup
+	results := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := RefreshAuthSession(ctx, store, disabledSessionCache{}, model.SessionCustomer, token)
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+		}
+	}
+	if succeeded > 1 {
+		t.Fatalf("%d refreshes succeeded with the same token, want at most 1", succeeded)
+	}
+}
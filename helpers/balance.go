@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"app/helpers/export"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewCustomerBalanceHistory build a ledger entry, the running balance is taken from the customer balance after the change
+func NewCustomerBalanceHistory(customer *model.Customer, in, out int64, reference model.Reference, description string) *model.CustomerBalanceHistory {
+	balance := int64(0)
+	if customer.Subscription != nil && customer.Subscription.Balance != nil {
+		balance = customer.Subscription.Balance.Time.Total - customer.Subscription.Balance.Time.Used
+	}
+
+	now := time.Now()
+	return &model.CustomerBalanceHistory{
+		ID: primitive.NewObjectID(),
+		Customer: model.CustomerFK{
+			ID:    customer.ID.Hex(),
+			Name:  customer.Name,
+			Email: customer.Email,
+		},
+		CompanyID:   customer.Company.ID,
+		Wallet:      model.WalletCustomer,
+		In:          in,
+		Out:         out,
+		Balance:     balance,
+		Description: description,
+		Reference:   reference,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// GenerateBalanceStatement render monthly ledger statement into xlsx (base64), running balance start from opening balance
+func GenerateBalanceStatement(openingBalance int64, rows []model.CustomerBalanceHistory) (string, error) {
+	exporter := export.NewExporter(
+		export.WithHeaders([]export.FieldConfig{
+			{Key: "createdAt", Label: "Date", As: "date", DateFormat: "DATETIME"},
+			{Key: "description", Label: "Description"},
+			{Key: "referenceType", Label: "Reference Type"},
+			{Key: "referenceNumber", Label: "Reference"},
+			{Key: "in", Label: "Credit (Hours)"},
+			{Key: "out", Label: "Debit (Hours)"},
+			{Key: "balance", Label: "Balance (Hours)"},
+		}),
+		export.WithDefaultSheetName("Statement"),
+		export.WithStyle(true),
+		export.WithFilter(true),
+	)
+
+	balance := openingBalance
+	if err := exporter.AddRow(map[string]any{
+		"description": "Opening balance",
+		"balance":     secondsToHours(balance),
+	}); err != nil {
+		return "", err
+	}
+
+	for _, row := range rows {
+		balance += row.In - row.Out
+		if err := exporter.AddRow(map[string]any{
+			"createdAt":       row.CreatedAt,
+			"description":     row.Description,
+			"referenceType":   string(row.Reference.Type),
+			"referenceNumber": row.Reference.Number,
+			"in":              secondsToHours(row.In),
+			"out":             secondsToHours(row.Out),
+			"balance":         secondsToHours(balance),
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	if err := exporter.AddRow(map[string]any{
+		"description": "Closing balance",
+		"balance":     secondsToHours(balance),
+	}); err != nil {
+		return "", err
+	}
+
+	return exporter.ToBase64()
+}
+
+func secondsToHours(seconds int64) float64 {
+	return math.Round(float64(seconds)/36) / 100
+}
@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewCaptchaVerifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		json.NewEncoder(w).Encode(map[string]bool{
+			"success": r.PostForm.Get("secret") == "secret" && r.PostForm.Get("response") == "valid",
+		})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		provider string
+		token    string
+		want     bool
+		wantErr  bool
+	}{
+		{name: "no provider fails closed", provider: "", token: "local-captcha", wantErr: true},
+		{name: "unknown provider fails closed", provider: "other", token: "local-captcha", wantErr: true},
+		{name: "local stub token", provider: "local", token: "local-captcha", want: true},
+		{name: "local stub wrong token", provider: "local", token: "other"},
+		{name: "siteverify valid", provider: "siteverify", token: "valid", want: true},
+		{name: "siteverify invalid", provider: "siteverify", token: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CAPTCHA_PROVIDER", tt.provider)
+			t.Setenv("CAPTCHA_LOCAL_TOKEN", "")
+			t.Setenv("CAPTCHA_VERIFY_URL", server.URL)
+			t.Setenv("CAPTCHA_SECRET_KEY", "secret")
+
+			valid, err := NewCaptchaVerifier().Verify(context.Background(), tt.token, "")
+			if (err != nil) != tt.wantErr || valid != tt.want {
+				t.Fatalf("Verify = %v, %v, want %v, wantErr %v", valid, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// DefaultCurrency currency charged to customers without their own preference, IDR by default
+func DefaultCurrency() string {
+	currency := strings.ToUpper(os.Getenv("DEFAULT_CURRENCY"))
+	if currency == "" {
+		return model.CurrencyIDR
+	}
+	return currency
+}
+
+// CustomerCurrency return the currency the customer is charged in
+func CustomerCurrency(customer *model.Customer) string {
+	if customer != nil && customer.Currency != "" {
+		return customer.Currency
+	}
+	return DefaultCurrency()
+}
+
+// IsCurrencyCode check the value is an ISO 4217 style code, e.g. USD
+func IsCurrencyCode(currency string) bool {
+	return currencyCodeRegex.MatchString(currency)
+}
+
+// RoundCurrency round the amount to the smallest unit charged in the currency
+func RoundCurrency(amount float64, currency string) float64 {
+	if currency == model.CurrencyIDR {
+		return math.Round(amount)
+	}
+	return math.Round(amount*100) / 100
+}
+
+// ConvertCurrency convert an amount in the base currency with the given rate
+func ConvertCurrency(amount float64, currency string, rate float64) float64 {
+	return RoundCurrency(amount*rate, currency)
+}
+
+// PackagePrice return the price set for the currency, converted from the base price otherwise
+func PackagePrice(price float64, prices map[string]float64, currency string, rate float64) float64 {
+	if currency == model.BaseCurrency {
+		return price
+	}
+	if value, ok := prices[currency]; ok && value > 0 {
+		return value
+	}
+	return ConvertCurrency(price, currency, rate)
+}
+
+// ExchangeRateStore the part of the repository the rates are read from
+type ExchangeRateStore interface {
+	FetchLatestExchangeRate(ctx context.Context, currency string) (*model.ExchangeRate, error)
+}
+
+// ExchangeRate rate from the base currency in effect now, 0 when no rate is available.
+// IDR falls back to the config rate until a rate history is kept
+func ExchangeRate(ctx context.Context, store ExchangeRateStore, config model.Config, currency string) (float64, error) {
+	if currency == model.BaseCurrency {
+		return 1, nil
+	}
+
+	exchangeRate, err := store.FetchLatestExchangeRate(ctx, currency)
+	if err != nil {
+		return 0, err
+	}
+	if exchangeRate != nil {
+		return exchangeRate.Rate, nil
+	}
+	if currency == model.CurrencyIDR {
+		return config.DollarInIdr, nil
+	}
+
+	return 0, nil
+}
+
+// OrderUnitPrice package price in the order currency, the list price for orders created before multi currency
+func OrderUnitPrice(order *model.Order, price float64) float64 {
+	if order.UnitPrice > 0 {
+		return order.UnitPrice
+	}
+	return price
+}
+
+// FormatOrderPrice grand total in the order currency, with the IDR equivalent at the frozen rate
+func FormatOrderPrice(order *model.Order, config *model.Config) string {
+	currency := order.OrderCurrency()
+	price := currency + " " + FormatFloat("#,###.##", order.GrandTotal)
+	if currency == model.CurrencyIDR || config == nil {
+		return price
+	}
+	return price + " (IDR " + FormatFloat("#,###.##", order.Format(config).GrandTotalinIdr) + ")"
+}
+
+// ValidateCurrencyPrices return the message of the first invalid currency price, empty when valid
+func ValidateCurrencyPrices(prices map[string]float64) string {
+	for currency, price := range prices {
+		if !IsCurrencyCode(currency) {
+			return currency + " is not a valid currency code"
+		}
+		if currency == model.BaseCurrency {
+			return model.BaseCurrency + " price is set by the price field"
+		}
+		if price <= 0 {
+			return currency + " price must be greater than 0"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// request bodies kept in the audit log are cut to this size
+const impersonationBodyLimit = 4096
+
+// GetImpersonationTTL lifetime of an impersonation token in minutes, it can not be refreshed
+func GetImpersonationTTL() time.Duration {
+	ttl, err := strconv.Atoi(os.Getenv("IMPERSONATION_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = 15
+	}
+	return time.Duration(ttl) * time.Minute
+}
+
+// NewImpersonationSession session marked with the superadmin, no refresh token is handed out
+func NewImpersonationSession(userType model.AuthSessionUserType, user model.UserNested, companyID string, impersonator model.UserNested) *model.AuthSession {
+	session, _ := NewAuthSession(userType, user, companyID)
+	session.Impersonator = &impersonator
+	session.ExpiredAt = session.CreatedAt.Add(GetImpersonationTTL())
+	return session
+}
+
+// NewImpersonationClaims access token claims ending with the impersonation session
+func NewImpersonationClaims(issuer string, session *model.AuthSession) jwt.RegisteredClaims {
+	claims := NewAccessTokenClaims(issuer)
+	claims.ExpiresAt = jwt.NewNumericDate(session.ExpiredAt)
+	return claims
+}
+
+// RedactRequestBody json body for the audit log with the secrets hidden
+func RedactRequestBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		// not json, only the size is kept
+		return "[" + strconv.Itoa(len(body)) + " bytes]"
+	}
+	redacted, _ := json.Marshal(_redactValue(payload))
+	if len(redacted) > impersonationBodyLimit {
+		return string(redacted[:impersonationBodyLimit]) + "..."
+	}
+	return string(redacted)
+}
+
+func _redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			lower := strings.ToLower(key)
+			if strings.Contains(lower, "password") || strings.Contains(lower, "token") || strings.Contains(lower, "secret") || lower == "code" {
+				v[key] = "[redacted]"
+				continue
+			}
+			v[key] = _redactValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = _redactValue(item)
+		}
+		return v
+	}
+	return value
+}
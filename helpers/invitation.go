@@ -0,0 +1,77 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// rows read from a bulk invitation csv
+const InvitationBulkLimit = 500
+
+// GetInvitationTTL lifetime of an invitation in days
+func GetInvitationTTL() time.Duration {
+	ttl, err := strconv.Atoi(os.Getenv("INVITATION_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = 7
+	}
+	return time.Duration(ttl) * 24 * time.Hour
+}
+
+// NewInvitationToken the token is sent in the accept link and only its hash is kept
+func NewInvitationToken() (token, hash string) {
+	b := make([]byte, 32)
+	rand.Read(b)
+	token = hex.EncodeToString(b)
+	return token, HashInvitationToken(token)
+}
+
+func HashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SendCustomerInvitationEmail accept link of an invitation
+func SendCustomerInvitationEmail(config model.Config, company *model.Company, invitation *model.CustomerInvitation, token string) {
+	link := config.InvitationLink
+	if link == "" {
+		link = "{{base_url_frontend}}/invitation/accept?token={{invitationToken}}"
+	}
+	link = StringReplacer(link, map[string]string{
+		"base_url_frontend": company.Settings.Domain.FullUrl,
+		"invitationToken":   token,
+	})
+
+	template := config.Email.Template.CustomerInvitation
+	if template.Title == "" || template.Body == "" {
+		template = model.TemplateEmailConfig{
+			Title: "{{inviter_name}} invited you to {{company_name}}",
+			Body:  "Dear {{name}},<br><br>{{inviter_name}} invited you to join {{company_name}} on the helpdesk.<br><br><a href=\"{{accept_link}}\">Accept the invitation</a> and choose your password. The invitation expires at {{expired_at}}.",
+		}
+	}
+	replacer := map[string]string{
+		"title":        template.Title,
+		"name":         invitation.Name,
+		"email":        invitation.Email,
+		"inviter_name": invitation.InvitedBy.Name,
+		"company_name": company.Name,
+		"accept_link":  link,
+		"expired_at":   invitation.ExpiredAt.Format("02 January 2006 15:04 MST"),
+	}
+
+	// send email
+	mail := NewSMTPMailer(company)
+	mail.To([]string{invitation.Email})
+	mail.Subject(StringReplacer(template.Title, replacer))
+	mail.Body(StringReplacer(template.Body, replacer))
+
+	if err := mail.Send(); err != nil {
+		logrus.Errorf("Send Email invitation to %s error %v", invitation.Email, err)
+	}
+}
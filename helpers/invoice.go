@@ -0,0 +1,387 @@
+package helpers
+
+import (
+	s3repo "app/app/repository/s3"
+	"app/domain/model"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/sirupsen/logrus"
+)
+
+type OrderDocumentType string
+
+const (
+	InvoiceDocument OrderDocumentType = "INVOICE"
+	ReceiptDocument OrderDocumentType = "RECEIPT"
+)
+
+// GenerateOrderDocument render invoice / receipt of an order into pdf bytes
+func GenerateOrderDocument(docType OrderDocumentType, number string, order *model.Order, company *model.Company, config *model.Config) (*bytes.Buffer, error) {
+	pdf := newDocumentPDF(string(docType), number, company, order.Customer)
+
+	pdf.CellFormat(40, 5, "Order Number", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, ": "+order.OrderNumber, "", 1, "L", false, 0, "")
+	pdf.CellFormat(40, 5, "Order Date", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, ": "+order.CreatedAt.Format("2006-01-02"), "", 1, "L", false, 0, "")
+	if order.PaidAt != nil {
+		pdf.CellFormat(40, 5, "Paid At", "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, ": "+order.PaidAt.Format("2006-01-02 15:04"), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	// line items
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(235, 235, 235)
+	pdf.CellFormat(90, 7, "Item", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(20, 7, "Qty", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(35, 7, "Price", "1", 0, "R", true, 0, "")
+	pdf.CellFormat(35, 7, "Total", "1", 1, "R", true, 0, "")
+
+	currency := order.OrderCurrency()
+	pdf.SetFont("Helvetica", "", 10)
+	for _, item := range orderLineItems(order) {
+		pdf.CellFormat(90, 7, item.name, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(20, 7, fmt.Sprintf("%d", item.qty), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(35, 7, formatAmount(currency, item.price), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 7, formatAmount(currency, item.price*float64(item.qty)), "1", 1, "R", false, 0, "")
+	}
+
+	// summary
+	summary := [][2]string{
+		{"Sub Total", formatAmount(currency, order.SubTotal)},
+		{"Tax", formatAmount(currency, order.Tax)},
+		{"Admin Fee", formatAmount(currency, order.AdminFee)},
+		{"Discount", "- " + formatAmount(currency, order.Discount)},
+	}
+	for _, row := range summary {
+		pdf.CellFormat(145, 6, row[0], "", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 6, row[1], "", 1, "R", false, 0, "")
+	}
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(145, 7, "Grand Total", "", 0, "R", false, 0, "")
+	pdf.CellFormat(35, 7, formatAmount(currency, order.GrandTotal), "", 1, "R", false, 0, "")
+	if config != nil && currency != model.CurrencyIDR {
+		// format a copy, the stored order keeps its own total
+		formatted := *order
+		if grandTotalinIdr := formatted.Format(config).GrandTotalinIdr; grandTotalinIdr > 0 {
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.CellFormat(145, 5, "Grand Total (IDR)", "", 0, "R", false, 0, "")
+			pdf.CellFormat(35, 5, formatAmount(model.CurrencyIDR, grandTotalinIdr), "", 1, "R", false, 0, "")
+		}
+	}
+	pdf.Ln(8)
+
+	// payment details
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 6, "Payment Details", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, row := range orderPaymentDetails(order) {
+		pdf.CellFormat(40, 5, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, ": "+row[1], "", 1, "L", false, 0, "")
+	}
+
+	if docType == ReceiptDocument {
+		pdf.Ln(8)
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.SetTextColor(0, 128, 0)
+		pdf.CellFormat(0, 8, "PAID", "", 1, "C", false, 0, "")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := pdf.Output(buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+func newDocumentPDF(title, number string, company *model.Company, customer model.CustomerFK) *fpdf.Fpdf {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+
+	// company branding
+	if company.Logo.URL != "" {
+		if err := registerRemoteImage(pdf, "logo", company.Logo.URL); err == nil {
+			pdf.ImageOptions("logo", 15, 12, 0, 18, false, fpdf.ImageOptions{}, 0, "")
+		}
+	}
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, title, "", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 5, number, "", 1, "R", false, 0, "")
+	pdf.Ln(12)
+
+	// header
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(90, 6, company.Name, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Bill To", "", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(90, 5, company.Settings.Email, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, customer.Name, "", 1, "R", false, 0, "")
+	pdf.CellFormat(90, 5, company.Settings.Domain.FullUrl, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, customer.Email, "", 1, "R", false, 0, "")
+	pdf.Ln(6)
+
+	return pdf
+}
+
+type orderLineItem struct {
+	name  string
+	qty   int64
+	price float64
+}
+
+func orderLineItems(order *model.Order) []orderLineItem {
+	items := []orderLineItem{}
+	if order.HourPackage != nil {
+		items = append(items, orderLineItem{
+			name:  fmt.Sprintf("%s (%d hours)", order.HourPackage.Name, order.HourPackage.Hours),
+			qty:   order.Amount,
+			price: OrderUnitPrice(order, order.HourPackage.Price),
+		})
+	}
+	if order.ServerPackage != nil {
+		items = append(items, orderLineItem{
+			name:  fmt.Sprintf("%s (%d days)", order.ServerPackage.Name, order.ServerPackage.Validity),
+			qty:   order.Amount,
+			price: OrderUnitPrice(order, order.ServerPackage.Price),
+		})
+	}
+	if order.Overage != nil {
+		hours, minutes, _ := ConvertRemainingSeconds(order.Overage.Seconds)
+		items = append(items, orderLineItem{
+			name:  fmt.Sprintf("Overage (%dh %dm)", hours, minutes),
+			qty:   1,
+			price: order.Overage.Amount,
+		})
+	}
+	return items
+}
+
+func orderPaymentDetails(order *model.Order) [][2]string {
+	rows := [][2]string{}
+	add := func(label, value string) {
+		if value != "" {
+			rows = append(rows, [2]string{label, value})
+		}
+	}
+
+	add("Payment Method", order.Invoice.PaymentMethod)
+	add("Merchant", order.Invoice.MerchantName)
+	add("Bank", order.Invoice.BankCode)
+	add("Channel", order.Invoice.PaymentChannel)
+	add("Destination", order.Invoice.PaymentDestination)
+	add("Reference", order.Invoice.InvoiceExternalId)
+	if manual := order.Payment.ManualPaid; manual != nil {
+		add("Bank Name", manual.BankName)
+		add("Account Name", manual.AccountName)
+		add("Account Number", manual.AccountNumber)
+	}
+
+	return rows
+}
+
+func formatAmount(currency string, n float64) string {
+	return currency + " " + FormatFloat("#,###.##", n)
+}
+
+// the logo is fetched while a payment webhook is handled, a slow or huge image must not hold it
+const remoteImageMaxSize = 2 << 20
+
+var remoteImageClient = &http.Client{Timeout: 5 * time.Second}
+
+func registerRemoteImage(pdf *fpdf.Fpdf, name, url string) error {
+	resp, err := remoteImageClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("logo not found")
+	}
+
+	imageType := ""
+	contentType := resp.Header.Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, "png"):
+		imageType = "PNG"
+	case strings.Contains(contentType, "jpeg"), strings.Contains(contentType, "jpg"):
+		imageType = "JPG"
+	case strings.Contains(contentType, "gif"):
+		imageType = "GIF"
+	default:
+		return fmt.Errorf("logo type %s not supported", contentType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, remoteImageMaxSize+1))
+	if err != nil {
+		return err
+	}
+	if len(body) > remoteImageMaxSize {
+		return fmt.Errorf("logo is larger than %d bytes", remoteImageMaxSize)
+	}
+
+	pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(body))
+	if err := pdf.Error(); err != nil {
+		// logo is optional, keep rendering the document without it
+		pdf.ClearError()
+		return err
+	}
+
+	return nil
+}
+
+type OrderDocumentFile struct {
+	Name    string
+	Content []byte
+}
+
+// orderDocumentSequence sequence the invoice and receipt numbers are taken from
+const orderDocumentSequence = "orderDocument"
+
+// OrderCounter the part of the repository the document numbers are taken with
+type OrderCounter interface {
+	CountOrder(ctx context.Context, options map[string]interface{}) int64
+	NextSequence(ctx context.Context, name string, start int64) (value int64, err error)
+}
+
+// GenerateOrderDocuments number, render and store the invoice and receipt of a paid order,
+// the returned files are attached to the payment email. Nothing is done when they already exist
+func GenerateOrderDocuments(ctx context.Context, orders OrderCounter, s3 s3repo.S3Repo, order *model.Order, company *model.Company, config *model.Config) (documents []OrderDocumentFile) {
+	// already generated
+	if order.Document.Invoice != nil && order.Document.Receipt != nil {
+		return
+	}
+
+	// generate document number, the sequence continues from the orders numbered before it existed
+	number, err := orders.NextSequence(ctx, orderDocumentSequence, orders.CountOrder(ctx, map[string]interface{}{
+		"hasDocument": true,
+	}))
+	if err != nil {
+		logrus.Error("generate document number error:", err)
+		return
+	}
+	randomChar := RandomChar(3)
+	invoiceNumber := GenerateFormattedCode("INV", number, randomChar)
+	receiptNumber := GenerateFormattedCode("RCP", number, randomChar)
+
+	invoice, invoiceFile, err := UploadOrderDocument(s3, InvoiceDocument, invoiceNumber, order, company, config)
+	if err != nil {
+		logrus.Error("generate invoice document error:", err)
+		return
+	}
+
+	receipt, receiptFile, err := UploadOrderDocument(s3, ReceiptDocument, receiptNumber, order, company, config)
+	if err != nil {
+		logrus.Error("generate receipt document error:", err)
+		return
+	}
+
+	now := time.Now()
+	order.Document = model.OrderDocument{
+		InvoiceNumber: invoiceNumber,
+		ReceiptNumber: receiptNumber,
+		Invoice:       invoice,
+		Receipt:       receipt,
+		GeneratedAt:   &now,
+	}
+
+	return []OrderDocumentFile{*invoiceFile, *receiptFile}
+}
+
+// UploadOrderDocument render order document and store it privately to s3
+func UploadOrderDocument(s3 s3repo.S3Repo, docType OrderDocumentType, number string, order *model.Order, company *model.Company, config *model.Config) (*model.MediaFK, *OrderDocumentFile, error) {
+	buf, err := GenerateOrderDocument(docType, number, order, company, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return uploadDocumentPDF(s3, number, buf)
+}
+
+// GenerateCreditNoteDocument render credit note of a refunded order into pdf bytes
+func GenerateCreditNoteDocument(creditNote *model.CreditNote, order *model.Order, company *model.Company) (*bytes.Buffer, error) {
+	pdf := newDocumentPDF("CREDIT NOTE", creditNote.CreditNoteNumber, company, order.Customer)
+
+	pdf.CellFormat(40, 5, "Order Number", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, ": "+order.OrderNumber, "", 1, "L", false, 0, "")
+	if order.Document.InvoiceNumber != "" {
+		pdf.CellFormat(40, 5, "Invoice Number", "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, ": "+order.Document.InvoiceNumber, "", 1, "L", false, 0, "")
+	}
+	pdf.CellFormat(40, 5, "Issued At", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, ": "+creditNote.CreatedAt.Format("2006-01-02 15:04"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(40, 5, "Refund Method", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, ": "+string(creditNote.Method), "", 1, "L", false, 0, "")
+	pdf.CellFormat(40, 5, "Reason", "", 0, "L", false, 0, "")
+	pdf.MultiCell(0, 5, ": "+creditNote.Reason, "", "L", false)
+	pdf.Ln(6)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(235, 235, 235)
+	pdf.CellFormat(145, 7, "Description", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(35, 7, "Amount", "1", 1, "R", true, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	names := []string{}
+	for _, item := range orderLineItems(order) {
+		names = append(names, item.name)
+	}
+	pdf.CellFormat(145, 7, "Refund of "+strings.Join(names, ", "), "1", 0, "L", false, 0, "")
+	pdf.CellFormat(35, 7, "- "+formatAmount(order.OrderCurrency(), creditNote.Amount), "1", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(145, 7, "Original Total", "", 0, "R", false, 0, "")
+	pdf.CellFormat(35, 7, formatAmount(order.OrderCurrency(), order.GrandTotal), "", 1, "R", false, 0, "")
+	pdf.CellFormat(145, 7, "Total Credited", "", 0, "R", false, 0, "")
+	pdf.CellFormat(35, 7, formatAmount(order.OrderCurrency(), creditNote.Amount), "", 1, "R", false, 0, "")
+
+	buf := new(bytes.Buffer)
+	if err := pdf.Output(buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// UploadCreditNoteDocument render credit note and store it privately to s3
+func UploadCreditNoteDocument(s3 s3repo.S3Repo, creditNote *model.CreditNote, order *model.Order, company *model.Company) (*model.MediaFK, *OrderDocumentFile, error) {
+	buf, err := GenerateCreditNoteDocument(creditNote, order, company)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return uploadDocumentPDF(s3, creditNote.CreditNoteNumber, buf)
+}
+
+func uploadDocumentPDF(s3 s3repo.S3Repo, number string, buf *bytes.Buffer) (*model.MediaFK, *OrderDocumentFile, error) {
+	file := &OrderDocumentFile{
+		Name:    strings.ReplaceAll(number, "/", "-") + ".pdf",
+		Content: buf.Bytes(),
+	}
+
+	year, month, _ := time.Now().Date()
+	objName := "orders/documents/" + strconv.Itoa(year) + "/" + strconv.Itoa(int(month)) + "/" + file.Name
+	uploadData, err := s3.UploadFilePrivate(objName, bytes.NewReader(file.Content), "application/pdf", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &model.MediaFK{
+		Name:        file.Name,
+		Size:        int64(len(file.Content)),
+		URL:         uploadData.URL,
+		Type:        "document",
+		Category:    model.OrderFile,
+		IsPrivate:   true,
+		ProviderKey: objName,
+	}, file, nil
+}
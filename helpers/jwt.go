@@ -3,8 +3,10 @@ package helpers
 import (
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 func GetJWTSecretKeyCustomer() string {
@@ -75,3 +77,23 @@ func GenerateJWTTokenSuperadmin(claims jwt.Claims) (string, error) {
 
 	return tokenString, nil
 }
+
+// GetJWTRefreshTTL lifetime of a login session in minutes, 30 days by default
+func GetJWTRefreshTTL() int {
+	ttl, _ := strconv.Atoi(os.Getenv("JWT_REFRESH_TTL"))
+	if ttl <= 0 {
+		return 30 * 24 * 60
+	}
+	return ttl
+}
+
+// NewAccessTokenClaims short lived registered claims of an access token
+func NewAccessTokenClaims(issuer string) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Issuer:    issuer,
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		NotBefore: jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(GetJWTTTL()) * time.Minute)),
+	}
+}
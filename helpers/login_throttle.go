@@ -0,0 +1,368 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AttemptStore counters with expiry shared by the login throttling
+type AttemptStore interface {
+	Enabled() bool
+	Get(ctx context.Context, key string) (value []byte, err error)
+	Set(ctx context.Context, key string, value []byte, expiration *time.Duration) (err error)
+	Del(ctx context.Context, keys ...string) (err error)
+	Incr(ctx context.Context, key string, expiration time.Duration) (value int64, err error)
+}
+
+// memoryAttempts fallback when redis is disabled, the counters are per process
+var memoryAttempts = &memoryAttemptStore{items: map[string]memoryAttempt{}}
+
+type memoryAttempt struct {
+	value     []byte
+	expiredAt time.Time
+}
+
+type memoryAttemptStore struct {
+	sync.Mutex
+	items map[string]memoryAttempt
+}
+
+func (s *memoryAttemptStore) Enabled() bool {
+	return true
+}
+
+func (s *memoryAttemptStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	item, ok := s.items[key]
+	if !ok || time.Now().After(item.expiredAt) {
+		delete(s.items, key)
+		return nil, errors.New("key not found")
+	}
+	return item.value, nil
+}
+
+func (s *memoryAttemptStore) Set(ctx context.Context, key string, value []byte, expiration *time.Duration) error {
+	s.Lock()
+	defer s.Unlock()
+
+	ttl := time.Minute
+	if expiration != nil {
+		ttl = *expiration
+	}
+
+	s._purge()
+	s.items[key] = memoryAttempt{value: value, expiredAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryAttemptStore) Del(ctx context.Context, keys ...string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, key := range keys {
+		delete(s.items, key)
+	}
+	return nil
+}
+
+func (s *memoryAttemptStore) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	item, ok := s.items[key]
+	if !ok || time.Now().After(item.expiredAt) {
+		s._purge()
+		item = memoryAttempt{expiredAt: time.Now().Add(expiration)}
+	}
+
+	value, _ := strconv.ParseInt(string(item.value), 10, 64)
+	value++
+	item.value = []byte(strconv.FormatInt(value, 10))
+	s.items[key] = item
+
+	return value, nil
+}
+
+// _purge drop the expired counters once the map grows
+func (s *memoryAttemptStore) _purge() {
+	if len(s.items) < 10000 {
+		return
+	}
+	now := time.Now()
+	for key, item := range s.items {
+		if now.After(item.expiredAt) {
+			delete(s.items, key)
+		}
+	}
+}
+
+func getLoginEnvInt(key string, fallback int64) int64 {
+	value, _ := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// GetLoginMaxAttempts failed logins before the account is locked
+func GetLoginMaxAttempts() int64 {
+	return getLoginEnvInt("LOGIN_MAX_ATTEMPTS", 5)
+}
+
+// GetLoginMaxIPAttempts failed logins from one address before it is blocked for the window
+func GetLoginMaxIPAttempts() int64 {
+	return getLoginEnvInt("LOGIN_MAX_IP_ATTEMPTS", 50)
+}
+
+// GetLoginAttemptWindow period the failed logins are counted in
+func GetLoginAttemptWindow() time.Duration {
+	return time.Duration(getLoginEnvInt("LOGIN_ATTEMPT_WINDOW", 15)) * time.Minute
+}
+
+// GetLoginLockoutDuration period a locked account stays locked unless unlocked earlier
+func GetLoginLockoutDuration() time.Duration {
+	return time.Duration(getLoginEnvInt("LOGIN_LOCKOUT_DURATION", 30)) * time.Minute
+}
+
+// GetTwoFactorMaxAttempts wrong codes accepted on one challenge before it is invalidated
+func GetTwoFactorMaxAttempts() int64 {
+	return getLoginEnvInt("TWO_FACTOR_MAX_ATTEMPTS", 3)
+}
+
+// LoginDelay progressive wait after the free failures, doubling up to a minute
+func LoginDelay(failures, free int64) time.Duration {
+	if failures <= free {
+		return 0
+	}
+	shift := failures - free - 1
+	if shift > 6 {
+		return time.Minute
+	}
+	return min(time.Duration(1<<shift)*time.Second, time.Minute)
+}
+
+// LoginThrottle failed login tracking per account and per ip address
+type LoginThrottle struct {
+	store  AttemptStore
+	window time.Duration
+}
+
+func NewLoginThrottle(store AttemptStore) *LoginThrottle {
+	if store == nil || !store.Enabled() {
+		store = memoryAttempts
+	}
+	return &LoginThrottle{store: store, window: GetLoginAttemptWindow()}
+}
+
+func (t *LoginThrottle) _accountKey(userType model.AuthSessionUserType, email string) string {
+	return "login:" + string(userType) + ":" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func (t *LoginThrottle) _ipKey(ipAddress string) string {
+	return "login:ip:" + ipAddress
+}
+
+// Wait remaining delay before the account or the address may try again
+func (t *LoginThrottle) Wait(ctx context.Context, userType model.AuthSessionUserType, email, ipAddress string) time.Duration {
+	keys := []string{t._accountKey(userType, email) + ":wait"}
+	if ipAddress != "" {
+		keys = append(keys, t._ipKey(ipAddress)+":wait")
+	}
+
+	wait := time.Duration(0)
+	for _, key := range keys {
+		value, err := t.store.Get(ctx, key)
+		if err != nil {
+			continue
+		}
+		allowedAt, _ := strconv.ParseInt(string(value), 10, 64)
+		if remaining := time.Until(time.Unix(0, allowedAt)); remaining > wait {
+			wait = remaining
+		}
+	}
+	return wait
+}
+
+// Fail count a failed login and return the failures of the account in the window
+func (t *LoginThrottle) Fail(ctx context.Context, userType model.AuthSessionUserType, email, ipAddress string) int64 {
+	accountKey := t._accountKey(userType, email)
+	failures, _ := t.store.Incr(ctx, accountKey, t.window)
+	t._delay(ctx, accountKey, LoginDelay(failures, 2))
+
+	if ipAddress != "" {
+		ipKey := t._ipKey(ipAddress)
+		ipFailures, _ := t.store.Incr(ctx, ipKey, t.window)
+		if ipFailures >= GetLoginMaxIPAttempts() {
+			t._delay(ctx, ipKey, t.window)
+		} else {
+			t._delay(ctx, ipKey, LoginDelay(ipFailures, GetLoginMaxAttempts()))
+		}
+	}
+
+	return failures
+}
+
+// Reset clear the account counters after a successful login or an unlock
+func (t *LoginThrottle) Reset(ctx context.Context, userType model.AuthSessionUserType, email string) {
+	accountKey := t._accountKey(userType, email)
+	t.store.Del(ctx, accountKey, accountKey+":wait")
+}
+
+func (t *LoginThrottle) _challengeKey(challengeID string) string {
+	return "two-factor:challenge:" + challengeID
+}
+
+// TwoFactorChallengeEnded the challenge was already exchanged for a session or got too many wrong codes
+func (t *LoginThrottle) TwoFactorChallengeEnded(ctx context.Context, challengeID string) bool {
+	value, err := t.store.Get(ctx, t._challengeKey(challengeID))
+	if err != nil {
+		return false
+	}
+	failures, _ := strconv.ParseInt(string(value), 10, 64)
+	return failures >= GetTwoFactorMaxAttempts()
+}
+
+// FailTwoFactor count a wrong code on the challenge and on the account,
+// the account failures are shared with the password step so they lead to the same lockout
+func (t *LoginThrottle) FailTwoFactor(ctx context.Context, userType model.AuthSessionUserType, email, ipAddress, challengeID string) (challengeFailures int64, accountFailures int64) {
+	challengeFailures, _ = t.store.Incr(ctx, t._challengeKey(challengeID), twoFactorChallengeTTL)
+	accountFailures = t.Fail(ctx, userType, email, ipAddress)
+	return
+}
+
+// EndTwoFactorChallenge a challenge is exchanged for one session only
+func (t *LoginThrottle) EndTwoFactorChallenge(ctx context.Context, challengeID string) {
+	ttl := twoFactorChallengeTTL
+	t.store.Set(ctx, t._challengeKey(challengeID), []byte(strconv.FormatInt(GetTwoFactorMaxAttempts(), 10)), &ttl)
+}
+
+func (t *LoginThrottle) _delay(ctx context.Context, key string, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+	allowedAt := strconv.FormatInt(time.Now().Add(delay).UnixNano(), 10)
+	t.store.Set(ctx, key+":wait", []byte(allowedAt), &delay)
+}
+
+// NewAccountLockout lock the account, the token is sent in the unlock link and only its hash is kept
+func NewAccountLockout(reason string) (*model.AccountLockout, string) {
+	b := make([]byte, 32)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	now := time.Now()
+	return &model.AccountLockout{
+		LockedAt:  now,
+		ExpiredAt: now.Add(GetLoginLockoutDuration()),
+		Reason:    reason,
+		TokenHash: HashUnlockToken(token),
+	}, token
+}
+
+func HashUnlockToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewSecurityEvent login audit entry
+func NewSecurityEvent(eventType model.SecurityEventType, userType model.AuthSessionUserType, companyID string, user model.UserNested, ipAddress, userAgent, reason string) *model.SecurityEvent {
+	return &model.SecurityEvent{
+		ID:        primitive.NewObjectID(),
+		CompanyID: companyID,
+		UserType:  userType,
+		User:      user,
+		Type:      eventType,
+		Reason:    reason,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		CreatedAt: time.Now(),
+	}
+}
+
+// SendAccountLockedEmail tell the user the account is locked with the link to unlock it
+func SendAccountLockedEmail(config model.Config, company *model.Company, name, email, baseURL, token string) {
+	link := config.UnlockAccountLink
+	if link == "" {
+		link = "{{base_url_frontend}}/unlock-account?token={{unlockToken}}"
+	}
+	link = StringReplacer(link, map[string]string{
+		"base_url_frontend": baseURL,
+		"unlockToken":       token,
+	})
+
+	template := config.Email.Template.AccountLocked
+	if template.Title == "" || template.Body == "" {
+		template = model.TemplateEmailConfig{
+			Title: "Your account has been locked",
+			Body:  "Dear {{name}},<br><br>Your account was locked after too many failed login attempts. It unlocks automatically at {{expired_at}}.<br><br>If this was you, <a href=\"{{unlock_link}}\">unlock your account now</a>. If it was not, we recommend changing your password after unlocking.",
+		}
+	}
+	replacer := map[string]string{
+		"title":       template.Title,
+		"name":        name,
+		"unlock_link": link,
+		"expired_at":  time.Now().Add(GetLoginLockoutDuration()).Format("02 January 2006 15:04 MST"),
+	}
+
+	// send email
+	mail := NewSMTPMailer(company)
+	mail.To([]string{email})
+	mail.Subject(StringReplacer(template.Title, replacer))
+	mail.Body(StringReplacer(template.Body, replacer))
+
+	if err := mail.Send(); err != nil {
+		logrus.Errorf("Send Email account locked to %s error %v", email, err)
+	}
+}
+
+// LoginWaitMessage error shown while the login is throttled
+func LoginWaitMessage(wait time.Duration) string {
+	seconds := int64(wait.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	return "too many login attempts, try again in " + strconv.FormatInt(seconds, 10) + " seconds"
+}
+
+// SecurityEventFilter read the security event list filters from the query string
+func SecurityEventFilter(query url.Values) (map[string]interface{}, map[string]string) {
+	options := map[string]interface{}{}
+	errValidation := map[string]string{}
+	for _, key := range []string{"type", "userType", "userID", "ipAddress", "impersonatorID", "sessionID", "q"} {
+		if value := query.Get(key); value != "" {
+			options[key] = value
+		}
+	}
+	for _, key := range []string{"createdFrom", "createdBefore"} {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			errValidation[key] = "invalid date format, use YYYY-MM-DD"
+			continue
+		}
+		if key == "createdBefore" {
+			// the whole day is included
+			date = date.AddDate(0, 0, 1)
+		}
+		options[key] = date
+	}
+	return options, errValidation
+}
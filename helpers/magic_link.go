@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// links a customer can request per hour, the rest are dropped silently
+const magicLinkRequestLimit = 5
+
+func GetMagicLinkRequestLimit() int64 {
+	return magicLinkRequestLimit
+}
+
+// GetMagicLinkTTL lifetime of a login link in minutes
+func GetMagicLinkTTL() time.Duration {
+	ttl, err := strconv.Atoi(os.Getenv("MAGIC_LINK_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = 15
+	}
+	return time.Duration(ttl) * time.Minute
+}
+
+// IsPasswordlessEnabled the company lets its customers sign in with a magic link
+func IsPasswordlessEnabled(company *model.Company) bool {
+	return company != nil && company.Settings.PasswordlessLogin
+}
+
+// NewMagicLink the token is sent in the login link and only its hash is kept
+func NewMagicLink(companyID string) (*model.MagicLink, string) {
+	b := make([]byte, 32)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	now := time.Now()
+	return &model.MagicLink{
+		CompanyID: companyID,
+		TokenHash: HashMagicLinkToken(token),
+		ExpiredAt: now.Add(GetMagicLinkTTL()),
+		CreatedAt: now,
+	}, token
+}
+
+func HashMagicLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SendMagicLinkEmail login link of the member portal
+func SendMagicLinkEmail(config model.Config, company *model.Company, customer *model.Customer, token string) {
+	link := config.MagicLoginLink
+	if link == "" {
+		link = "{{base_url_frontend}}/login/magic-link?token={{magicToken}}"
+	}
+	link = StringReplacer(link, map[string]string{
+		"base_url_frontend": company.Settings.Domain.FullUrl,
+		"magicToken":        token,
+	})
+
+	template := config.Email.Template.MagicLogin
+	if template.Title == "" || template.Body == "" {
+		template = model.TemplateEmailConfig{
+			Title: "Your sign in link for {{company_name}}",
+			Body:  "Dear {{name}},<br><br><a href=\"{{login_link}}\">Sign in to {{company_name}}</a>. The link works once and expires at {{expired_at}}.<br><br>If you did not ask for it you can ignore this email.",
+		}
+	}
+	replacer := map[string]string{
+		"title":        template.Title,
+		"name":         customer.Name,
+		"company_name": company.Name,
+		"login_link":   link,
+		"expired_at":   customer.MagicLink.ExpiredAt.Format("02 January 2006 15:04 MST"),
+	}
+
+	// send email
+	mail := NewSMTPMailer(company)
+	mail.To([]string{customer.Email})
+	mail.Subject(StringReplacer(template.Title, replacer))
+	mail.Body(StringReplacer(template.Body, replacer))
+
+	if err := mail.Send(); err != nil {
+		logrus.Errorf("Send Email magic link to %s error %v", customer.Email, err)
+	}
+}
@@ -227,6 +227,13 @@ func CustomCommonFilter(options map[string]any) (query map[string]any) {
 	// category
 	if categoryID, ok := options["categoryID"].(string); ok {
 		query["category.id"] = categoryID
+	} else if categoryIDs, ok := options["categoryIDs"].([]string); ok {
+		query["category.id"] = bson.M{"$in": categoryIDs}
+	}
+
+	// language
+	if language, ok := options["language"].(string); ok {
+		query["language"] = language
 	}
 
 	// last month ticket
@@ -0,0 +1,487 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	OIDCStateTTL = 10 * time.Minute
+	oidcCacheTTL = 1 * time.Hour
+
+	// OIDCBindingCookie http only cookie tying the state to the browser that started the login
+	OIDCBindingCookie = "sso_binding"
+)
+
+var oidcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// discovery documents and signing keys of the identity providers
+var oidcCache = struct {
+	sync.Mutex
+	discovery map[string]oidcCachedDiscovery
+	keys      map[string]oidcCachedKeys
+}{
+	discovery: map[string]oidcCachedDiscovery{},
+	keys:      map[string]oidcCachedKeys{},
+}
+
+type oidcCachedDiscovery struct {
+	doc       *OIDCDiscovery
+	expiredAt time.Time
+}
+
+type oidcCachedKeys struct {
+	keys      map[string]crypto.PublicKey
+	expiredAt time.Time
+}
+
+// OIDCDiscovery endpoints published by the identity provider
+type OIDCDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// OIDCState carried through the identity provider between authorize and callback
+type OIDCState struct {
+	CompanyID   string                    `json:"cid"`
+	UserType    model.AuthSessionUserType `json:"typ"`
+	RedirectURI string                    `json:"redirectUri"`
+	Nonce       string                    `json:"nonce"`
+	BindingHash string                    `json:"bnd"`
+	jwt.RegisteredClaims
+}
+
+// OIDCIdentity the user asserted by the identity provider
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	JobTitle      string
+}
+
+// IsSSOEnabled check the company signs the given portal in with its identity provider
+func IsSSOEnabled(company *model.Company, userType model.AuthSessionUserType) bool {
+	if company == nil || company.SSO == nil || !company.SSO.Enabled {
+		return false
+	}
+
+	switch userType {
+	case model.SessionAgent:
+		return company.SSO.Agent
+	case model.SessionCustomer:
+		return company.SSO.Customer
+	}
+	return false
+}
+
+// ValidateOIDCIssuer issuer must be https, plain http is only accepted on loopback for a local mock provider
+func ValidateOIDCIssuer(issuer string) error {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return errors.New("issuer must be an absolute url without query")
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := u.Hostname()
+		if host == "localhost" {
+			return nil
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+			return nil
+		}
+	}
+
+	return errors.New("issuer must use https")
+}
+
+// OIDCScopes requested scopes, openid is always included
+func OIDCScopes(sso *model.CompanySSO) []string {
+	scopes := []string{"openid"}
+	for _, scope := range sso.Scopes {
+		if scope != "" && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 1 {
+		scopes = append(scopes, "email", "profile")
+	}
+	return scopes
+}
+
+// FetchOIDCDiscovery load the openid configuration of the issuer
+func FetchOIDCDiscovery(ctx context.Context, issuer string) (*OIDCDiscovery, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	oidcCache.Lock()
+	cached, ok := oidcCache.discovery[issuer]
+	oidcCache.Unlock()
+	if ok && time.Now().Before(cached.expiredAt) {
+		return cached.doc, nil
+	}
+
+	doc := &OIDCDiscovery{}
+	if err := oidcGetJSON(ctx, issuer+"/.well-known/openid-configuration", doc); err != nil {
+		return nil, fmt.Errorf("identity provider discovery failed: %w", err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, errors.New("identity provider discovery returned another issuer")
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JwksURI == "" {
+		return nil, errors.New("identity provider discovery is incomplete")
+	}
+
+	oidcCache.Lock()
+	oidcCache.discovery[issuer] = oidcCachedDiscovery{doc: doc, expiredAt: time.Now().Add(oidcCacheTTL)}
+	oidcCache.Unlock()
+
+	return doc, nil
+}
+
+// NewOIDCBinding random value kept in the browser cookie, only its hash travels in the state
+func NewOIDCBinding() string {
+	return newRefreshSecret()
+}
+
+func hashOIDCBinding(binding string) string {
+	sum := sha256.Sum256([]byte(binding))
+	return hex.EncodeToString(sum[:])
+}
+
+// OIDCAuthorizationURL start the authorization code flow with PKCE,
+// the code verifier is derived from the nonce so nothing is stored until the callback.
+// The state is bound to the browser so a callback link sent to someone else does not log them in
+func OIDCAuthorizationURL(ctx context.Context, secretKey string, company *model.Company, userType model.AuthSessionUserType, redirectURI, binding string) (string, error) {
+	if binding == "" {
+		return "", errors.New("browser binding is required")
+	}
+	sso := company.SSO
+	if !slices.Contains(sso.RedirectURIs, redirectURI) {
+		return "", errors.New("redirectUri is not allowed")
+	}
+
+	discovery, err := FetchOIDCDiscovery(ctx, sso.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	nonceBytes := make([]byte, 24)
+	rand.Read(nonceBytes)
+	nonce := base64.RawURLEncoding.EncodeToString(nonceBytes)
+
+	state, err := jwt.NewWithClaims(jwt.SigningMethodHS256, OIDCState{
+		CompanyID:   company.ID.Hex(),
+		UserType:    userType,
+		RedirectURI: redirectURI,
+		Nonce:       nonce,
+		BindingHash: hashOIDCBinding(binding),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    string(userType) + ":sso",
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(OIDCStateTTL)),
+		},
+	}).SignedString([]byte(secretKey + ":sso"))
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(oidcCodeVerifier(secretKey, nonce)))
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", sso.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(OIDCScopes(sso), " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// ParseOIDCState verify the state returned by the identity provider and that the login started in this browser
+func ParseOIDCState(secretKey string, userType model.AuthSessionUserType, tokenString, binding string) (*OIDCState, error) {
+	state := &OIDCState{}
+	token, err := jwt.ParseWithClaims(tokenString, state, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secretKey + ":sso"), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(string(userType)+":sso"))
+	if err != nil || !token.Valid || state.UserType != userType || state.CompanyID == "" || state.Nonce == "" {
+		return nil, errors.New("state is invalid or expired, please login again")
+	}
+	if binding == "" || subtle.ConstantTimeCompare([]byte(state.BindingHash), []byte(hashOIDCBinding(binding))) != 1 {
+		return nil, errors.New("login was started in another browser, please login again")
+	}
+
+	return state, nil
+}
+
+// CompleteOIDCLogin exchange the authorization code and verify the id token
+func CompleteOIDCLogin(ctx context.Context, secretKey string, company *model.Company, state *OIDCState, code string) (*OIDCIdentity, error) {
+	sso := company.SSO
+
+	discovery, err := FetchOIDCDiscovery(ctx, sso.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	// exchange the code
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", state.RedirectURI)
+	form.Set("client_id", sso.ClientID)
+	form.Set("code_verifier", oidcCodeVerifier(secretKey, state.Nonce))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if sso.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(sso.ClientID), url.QueryEscape(sso.ClientSecret))
+	}
+
+	res, err := oidcHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("identity provider token request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	tokenResponse := struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&tokenResponse); err != nil {
+		return nil, fmt.Errorf("identity provider token response is invalid: %w", err)
+	}
+	if res.StatusCode != http.StatusOK || tokenResponse.IDToken == "" {
+		if tokenResponse.Error == "" {
+			tokenResponse.Error = res.Status
+		}
+		return nil, fmt.Errorf("identity provider rejected the code: %s %s", tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+
+	// verify the id token
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(tokenResponse.IDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return oidcSigningKey(ctx, discovery.JwksURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(sso.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id token is invalid: %w", err)
+	}
+	if nonce, _ := claims["nonce"].(string); nonce != state.Nonce {
+		return nil, errors.New("id token nonce does not match")
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != sso.ClientID {
+		return nil, errors.New("id token was issued to another client")
+	}
+
+	// map the profile
+	mapping := sso.ClaimMapping
+	if mapping.Email == "" {
+		mapping.Email = "email"
+	}
+	if mapping.Name == "" {
+		mapping.Name = "name"
+	}
+
+	identity := &OIDCIdentity{Issuer: discovery.Issuer}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims[mapping.Email].(string)
+	identity.Email = strings.ToLower(strings.TrimSpace(identity.Email))
+	identity.Name, _ = claims[mapping.Name].(string)
+	if mapping.JobTitle != "" {
+		identity.JobTitle, _ = claims[mapping.JobTitle].(string)
+	}
+
+	if identity.Subject == "" {
+		return nil, errors.New("id token has no subject")
+	}
+	if identity.Email == "" {
+		return nil, errors.New("id token has no email")
+	}
+	// some providers send the flag as a string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+	if !identity.EmailVerified {
+		return nil, errors.New("email is not verified by the identity provider")
+	}
+	if len(sso.AllowedDomains) > 0 {
+		domain := identity.Email[strings.LastIndex(identity.Email, "@")+1:]
+		if !slices.ContainsFunc(sso.AllowedDomains, func(allowed string) bool {
+			return strings.EqualFold(allowed, domain)
+		}) {
+			return nil, errors.New("email domain is not allowed")
+		}
+	}
+	if identity.Name == "" {
+		identity.Name = identity.Email
+	}
+
+	return identity, nil
+}
+
+// oidcCodeVerifier PKCE verifier, never leaves the server
+func oidcCodeVerifier(secretKey, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(secretKey+":sso-verifier"))
+	mac.Write([]byte(nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// oidcSigningKey key of the id token, the key set is reloaded once for an unknown key id
+func oidcSigningKey(ctx context.Context, jwksURI, kid string) (crypto.PublicKey, error) {
+	for _, reload := range []bool{false, true} {
+		oidcCache.Lock()
+		cached, ok := oidcCache.keys[jwksURI]
+		oidcCache.Unlock()
+
+		fetched := false
+		if reload || !ok || time.Now().After(cached.expiredAt) {
+			fetched = true
+			keys, err := oidcFetchKeys(ctx, jwksURI)
+			if err != nil {
+				return nil, err
+			}
+			cached = oidcCachedKeys{keys: keys, expiredAt: time.Now().Add(oidcCacheTTL)}
+
+			oidcCache.Lock()
+			oidcCache.keys[jwksURI] = cached
+			oidcCache.Unlock()
+		}
+
+		if key, ok := cached.keys[kid]; ok {
+			return key, nil
+		}
+		if kid == "" && len(cached.keys) == 1 {
+			for _, key := range cached.keys {
+				return key, nil
+			}
+		}
+		if fetched {
+			break
+		}
+	}
+
+	return nil, errors.New("signing key not found")
+}
+
+func oidcFetchKeys(ctx context.Context, jwksURI string) (map[string]crypto.PublicKey, error) {
+	jwks := struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}{}
+	if err := oidcGetJSON(ctx, jwksURI, &jwks); err != nil {
+		return nil, fmt.Errorf("identity provider keys failed: %w", err)
+	}
+
+	decode := func(s string) *big.Int {
+		b, _ := base64.RawURLEncoding.DecodeString(s)
+		return new(big.Int).SetBytes(b)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, key := range jwks.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		switch key.Kty {
+		case "RSA":
+			if key.N == "" || key.E == "" {
+				continue
+			}
+			keys[key.Kid] = &rsa.PublicKey{N: decode(key.N), E: int(decode(key.E).Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch key.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			keys[key.Kid] = &ecdsa.PublicKey{Curve: curve, X: decode(key.X), Y: decode(key.Y)}
+		}
+	}
+
+	return keys, nil
+}
+
+func oidcGetJSON(ctx context.Context, endpoint string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := oidcHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.New(res.Status)
+	}
+
+	return json.NewDecoder(res.Body).Decode(target)
+}
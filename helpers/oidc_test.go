@@ -0,0 +1,263 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockOIDCProvider identity provider serving discovery, the token endpoint and its keys
+type mockOIDCProvider struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	sync.Mutex
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &mockOIDCProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OIDCDiscovery{
+			Issuer:                p.URL,
+			AuthorizationEndpoint: p.URL + "/authorize",
+			TokenEndpoint:         p.URL + "/token",
+			JwksURI:               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+
+		p.Lock()
+		defer p.Unlock()
+		if r.PostForm.Get("code") != "code" || base64.RawURLEncoding.EncodeToString(verifier[:]) != p.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, p.claims)
+		token.Header["kid"] = "test"
+		idToken, _ := token.SignedString(p.key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+// authorize start the login and keep the PKCE challenge like the provider would
+func (p *mockOIDCProvider) authorize(t *testing.T, company *model.Company, binding string) *OIDCState {
+	authorizationURL, err := OIDCAuthorizationURL(context.Background(), "secret", company, model.SessionAgent, "https://app.example.com/callback", binding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authorizationURL)
+	if !strings.HasPrefix(authorizationURL, p.URL+"/authorize?") || u.Query().Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected authorization url %s", authorizationURL)
+	}
+
+	p.Lock()
+	p.challenge = u.Query().Get("code_challenge")
+	p.Unlock()
+
+	state, err := ParseOIDCState("secret", model.SessionAgent, u.Query().Get("state"), binding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func TestValidateOIDCIssuer(t *testing.T) {
+	tests := []struct {
+		issuer  string
+		wantErr bool
+	}{
+		{"https://idp.example.com", false},
+		{"http://localhost:8080", false},
+		{"http://127.0.0.1:8080", false},
+		{"http://idp.example.com", true},
+		{"https://idp.example.com?tenant=1", true},
+		{"idp.example.com", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateOIDCIssuer(tt.issuer); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateOIDCIssuer(%s) = %v, wantErr %v", tt.issuer, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseOIDCState(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	company := &model.Company{ID: primitive.NewObjectID(), SSO: &model.CompanySSO{
+		Issuer:       provider.URL,
+		ClientID:     "client",
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}}
+
+	if _, err := OIDCAuthorizationURL(context.Background(), "secret", company, model.SessionAgent, "https://evil.example.com", "binding"); err == nil {
+		t.Fatal("redirect uri outside the allowed list was accepted")
+	}
+	if _, err := OIDCAuthorizationURL(context.Background(), "secret", company, model.SessionAgent, "https://app.example.com/callback", ""); err == nil {
+		t.Fatal("authorization without a browser binding was accepted")
+	}
+
+	authorizationURL, err := OIDCAuthorizationURL(context.Background(), "secret", company, model.SessionAgent, "https://app.example.com/callback", "binding")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authorizationURL)
+	state := u.Query().Get("state")
+
+	tests := []struct {
+		name     string
+		secret   string
+		userType model.AuthSessionUserType
+		binding  string
+		wantErr  bool
+	}{
+		{"same browser", "secret", model.SessionAgent, "binding", false},
+		{"other browser", "secret", model.SessionAgent, "other", true},
+		{"no cookie", "secret", model.SessionAgent, "", true},
+		{"other portal", "secret", model.SessionCustomer, "binding", true},
+		{"other secret", "other", model.SessionAgent, "binding", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseOIDCState(tt.secret, tt.userType, state, tt.binding); (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOIDCState = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompleteOIDCLogin(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	company := &model.Company{ID: primitive.NewObjectID(), SSO: &model.CompanySSO{
+		Issuer:         provider.URL,
+		ClientID:       "client",
+		RedirectURIs:   []string{"https://app.example.com/callback"},
+		AllowedDomains: []string{"example.com"},
+	}}
+
+	validClaims := func(state *OIDCState) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            provider.URL,
+			"aud":            "client",
+			"sub":            "subject",
+			"email":          "Agent@Example.com",
+			"email_verified": true,
+			"name":           "Agent",
+			"nonce":          state.Nonce,
+			"exp":            time.Now().Add(time.Minute).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		code    string
+		modify  func(claims jwt.MapClaims)
+		wantErr bool
+	}{
+		{name: "valid", code: "code", modify: func(claims jwt.MapClaims) {}},
+		{name: "email verified as string", code: "code", modify: func(claims jwt.MapClaims) { claims["email_verified"] = "true" }},
+		{name: "rejected code", code: "other", modify: func(claims jwt.MapClaims) {}, wantErr: true},
+		{name: "other issuer", code: "code", modify: func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }, wantErr: true},
+		{name: "other audience", code: "code", modify: func(claims jwt.MapClaims) { claims["aud"] = "other" }, wantErr: true},
+		{name: "other authorized party", code: "code", modify: func(claims jwt.MapClaims) { claims["azp"] = "other" }, wantErr: true},
+		{name: "expired", code: "code", modify: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }, wantErr: true},
+		{name: "other nonce", code: "code", modify: func(claims jwt.MapClaims) { claims["nonce"] = "other" }, wantErr: true},
+		{name: "email not verified", code: "code", modify: func(claims jwt.MapClaims) { claims["email_verified"] = false }, wantErr: true},
+		{name: "email verified missing", code: "code", modify: func(claims jwt.MapClaims) { delete(claims, "email_verified") }, wantErr: true},
+		{name: "domain not allowed", code: "code", modify: func(claims jwt.MapClaims) { claims["email"] = "agent@evil.com" }, wantErr: true},
+		{name: "no subject", code: "code", modify: func(claims jwt.MapClaims) { delete(claims, "sub") }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := provider.authorize(t, company, "binding")
+			claims := validClaims(state)
+			tt.modify(claims)
+			provider.Lock()
+			provider.claims = claims
+			provider.Unlock()
+
+			identity, err := CompleteOIDCLogin(context.Background(), "secret", company, state, tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CompleteOIDCLogin = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if identity.Issuer != provider.URL || identity.Subject != "subject" || identity.Email != "agent@example.com" || !identity.EmailVerified {
+				t.Fatalf("unexpected identity %+v", identity)
+			}
+		})
+	}
+}
+
+func TestCompleteOIDCLoginUnknownKey(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	company := &model.Company{ID: primitive.NewObjectID(), SSO: &model.CompanySSO{
+		Issuer:       provider.URL,
+		ClientID:     "client",
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}}
+	state := provider.authorize(t, company, "binding")
+
+	// signed by a key the provider does not publish
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	provider.Lock()
+	provider.key = otherKey
+	provider.claims = jwt.MapClaims{
+		"iss":            provider.URL,
+		"aud":            "client",
+		"sub":            "subject",
+		"email":          "agent@example.com",
+		"email_verified": true,
+		"nonce":          state.Nonce,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	}
+	provider.Unlock()
+
+	if _, err := CompleteOIDCLogin(context.Background(), "secret", company, state, "code"); err == nil {
+		t.Fatal("id token signed by an unknown key was accepted")
+	}
+}
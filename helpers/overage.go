@@ -0,0 +1,262 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetOveragePolicy return the company overage policy, block by default
+func GetOveragePolicy(company *model.Company) model.OveragePolicy {
+	if company == nil || company.Settings.Overage == "" {
+		return model.OverageBlock
+	}
+	return company.Settings.Overage
+}
+
+// IsBalanceLimited check the customer ticket time is taken from a balance
+func IsBalanceLimited(company *model.Company, customer *model.Customer) bool {
+	return IsPooledWallet(company) || customer.IsNeedBalance
+}
+
+// TimeRemaining return the time the customer may still use, from the pooled wallet or the own subscription
+func TimeRemaining(company *model.Company, customer *model.Customer) int64 {
+	if IsPooledWallet(company) {
+		return PooledTimeRemaining(company, customer)
+	}
+	if customer.Subscription == nil || customer.Subscription.Balance == nil {
+		return 0
+	}
+	return customer.Subscription.Balance.Time.Total - customer.Subscription.Balance.Time.Used
+}
+
+// LowBalanceThreshold return the lowest threshold the remaining time has reached, nil when above all of them
+func LowBalanceThreshold(config model.Config, remaining int64) *int64 {
+	var threshold *int64
+	if remaining <= 0 {
+		zero := int64(0)
+		return &zero
+	}
+
+	for _, value := range config.LowBalance.ThresholdsInSecond {
+		if remaining > value {
+			continue
+		}
+		if threshold == nil || value < *threshold {
+			v := value
+			threshold = &v
+		}
+	}
+
+	return threshold
+}
+
+// ShouldAlertLowBalance check the threshold is lower than the one already alerted
+func ShouldAlertLowBalance(customer *model.Customer, threshold *int64) bool {
+	if threshold == nil {
+		return false
+	}
+	return customer.BalanceAlert.Threshold == nil || *threshold < *customer.BalanceAlert.Threshold
+}
+
+// BalanceAlertStore the part of the repository the low balance alert is saved with
+type BalanceAlertStore interface {
+	UpdateOneCustomer(ctx context.Context, query, payload map[string]interface{}) (err error)
+	CreateNotification(ctx context.Context, row *model.Notification) (err error)
+}
+
+// CheckLowBalance alert the customer and the assigned agents once per threshold reached,
+// remaining is the time left including the timer still running
+func CheckLowBalance(ctx context.Context, store BalanceAlertStore, config model.Config, ticket *model.Ticket, customer *model.Customer, company *model.Company, remaining int64) {
+	if company == nil || !IsBalanceLimited(company, customer) {
+		return
+	}
+
+	threshold := LowBalanceThreshold(config, remaining)
+
+	// balance topped up, alert again on the next threshold
+	if threshold == nil {
+		if customer.BalanceAlert.Threshold != nil {
+			if err := store.UpdateOneCustomer(ctx, map[string]interface{}{
+				"id": customer.ID,
+			}, map[string]interface{}{
+				"balanceAlert": model.BalanceAlert{},
+				"updatedAt":    time.Now(),
+			}); err != nil {
+				logrus.Error("UpdateOneCustomer: ", err)
+			}
+		}
+		return
+	}
+
+	if !ShouldAlertLowBalance(customer, threshold) {
+		return
+	}
+
+	now := time.Now()
+	customer.BalanceAlert = model.BalanceAlert{Threshold: threshold, AlertedAt: &now}
+	if err := store.UpdateOneCustomer(ctx, map[string]interface{}{
+		"id": customer.ID,
+	}, map[string]interface{}{
+		"balanceAlert": customer.BalanceAlert,
+		"updatedAt":    now,
+	}); err != nil {
+		logrus.Error("UpdateOneCustomer: ", err)
+		return
+	}
+
+	for _, notification := range NewBalanceNotifications(config, ticket, customer, remaining) {
+		if err := store.CreateNotification(ctx, notification); err != nil {
+			logrus.Error("CreateNotification: ", err)
+		}
+	}
+
+	go SendLowBalanceEmail(config, company, ticket, customer, remaining)
+}
+
+func balanceTemplate(config model.Config, remaining int64) (model.TemplateEmailConfig, model.NotificationType) {
+	if remaining <= 0 {
+		template := config.Email.Template.BalanceDepleted
+		if template.Title == "" || template.Body == "" {
+			template = model.TemplateEmailConfig{
+				Title: "Your time balance has run out",
+				Body:  "Dear {{customer_name}},<br><br>Your time balance has run out while working on ticket <b>{{ticket_subject}}</b>.<br><br>Please purchase a new package to keep receiving support.",
+			}
+		}
+		return template, model.BalanceDepleted
+	}
+
+	template := config.Email.Template.LowBalance
+	if template.Title == "" || template.Body == "" {
+		template = model.TemplateEmailConfig{
+			Title: "Your time balance is running low",
+			Body:  "Dear {{customer_name}},<br><br>Your remaining time balance is <b>{{remaining}}</b> while working on ticket <b>{{ticket_subject}}</b>.<br><br>Please purchase a new package to avoid interruption.",
+		}
+	}
+	return template, model.BalanceLow
+}
+
+func balanceReplacer(ticket *model.Ticket, customer *model.Customer, remaining int64) map[string]string {
+	if remaining < 0 {
+		remaining = 0
+	}
+	hours, minutes, _ := ConvertRemainingSeconds(remaining)
+
+	return map[string]string{
+		"customer_name":  customer.Name,
+		"ticket_subject": ticket.Subject,
+		"ticket_code":    ticket.Code,
+		"remaining":      strconv.FormatInt(hours, 10) + "h " + strconv.FormatInt(minutes, 10) + "m",
+	}
+}
+
+// SendLowBalanceEmail send the low or depleted balance alert to the customer and the assigned agents
+func SendLowBalanceEmail(config model.Config, company *model.Company, ticket *model.Ticket, customer *model.Customer, remaining int64) {
+	template, _ := balanceTemplate(config, remaining)
+	replacer := balanceReplacer(ticket, customer, remaining)
+
+	receivers := []string{customer.Email}
+	for _, agent := range ticket.Agent {
+		if agent.Email != "" {
+			receivers = append(receivers, agent.Email)
+		}
+	}
+
+	mail := NewSMTPMailer(company)
+	mail.To(receivers)
+	mail.Subject(StringReplacer(template.Title, replacer))
+	mail.Body(StringReplacer(template.Body, replacer))
+
+	if err := mail.Send(); err != nil {
+		logrus.Errorf("Send Email low balance to %s error %v", customer.Email, err)
+	}
+}
+
+// NewBalanceNotifications build the in-app low or depleted balance alert for the customer and the assigned agents
+func NewBalanceNotifications(config model.Config, ticket *model.Ticket, customer *model.Customer, remaining int64) []*model.Notification {
+	template, notificationType := balanceTemplate(config, remaining)
+	title := StringReplacer(template.Title, balanceReplacer(ticket, customer, remaining))
+	content := "Ticket " + ticket.Subject
+
+	category := model.TicketCategoryFK{}
+	if ticket.Category != nil {
+		category = *ticket.Category
+	}
+
+	now := time.Now()
+	newNotification := func(role model.UserRole, user model.UserNested) *model.Notification {
+		return &model.Notification{
+			ID:       primitive.NewObjectID(),
+			Company:  model.CompanyNested{ID: ticket.Company.ID, Name: ticket.Company.Name},
+			Title:    title,
+			Content:  content,
+			UserRole: role,
+			User:     user,
+			Type:     notificationType,
+			Ticket: model.TicketNested{
+				ID:      ticket.ID.Hex(),
+				Subject: ticket.Subject,
+			},
+			Category:  category,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+
+	// the role is the sender, agent for the customer inbox and customer for the agent inbox
+	notifications := []*model.Notification{
+		newNotification(model.AgentRole, model.UserNested{ID: customer.ID.Hex(), Name: customer.Name, Email: customer.Email}),
+	}
+	for _, agent := range ticket.Agent {
+		notifications = append(notifications, newNotification(model.CustomerRole, model.UserNested(agent)))
+	}
+
+	return notifications
+}
+
+// CanOpenTicket check the remaining time allows a new ticket under the overage policy
+func CanOpenTicket(policy model.OveragePolicy, remaining, minimumCredit int64) bool {
+	switch policy {
+	case model.OverageBillNextOrder:
+		return true
+	case model.OveragePauseTimer:
+		return remaining > 0
+	default:
+		return remaining >= minimumCredit
+	}
+}
+
+// CanRunTimer check the remaining time allows the agent timer to run under the overage policy
+func CanRunTimer(policy model.OveragePolicy, remaining int64) bool {
+	if policy == model.OverageBillNextOrder {
+		return true
+	}
+	return remaining > 0
+}
+
+// NewOrderOverage bill the time used beyond the balance on the next hour order, priced per second of the package
+func NewOrderOverage(company *model.Company, customer *model.Customer, pkg *model.HourPackage, price float64, currency string) *model.OrderOverage {
+	if GetOveragePolicy(company) != model.OverageBillNextOrder || pkg.Duration.TotalinSeconds <= 0 {
+		return nil
+	}
+
+	seconds := int64(0)
+	if IsPooledWallet(company) {
+		seconds = company.Wallet.Time.Used - company.Wallet.Time.Total
+	} else if customer.Subscription != nil && customer.Subscription.Balance != nil {
+		seconds = customer.Subscription.Balance.Time.Used - customer.Subscription.Balance.Time.Total
+	}
+	if seconds <= 0 {
+		return nil
+	}
+
+	amount := RoundCurrency(price/float64(pkg.Duration.TotalinSeconds)*float64(seconds), currency)
+	return &model.OrderOverage{
+		Seconds: seconds,
+		Amount:  amount,
+	}
+}
@@ -0,0 +1,200 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"bufio"
+	"crypto/rand"
+	"embed"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+//go:embed data/breached_passwords.txt
+var breachedPasswordFS embed.FS
+
+var (
+	breachedPasswords     map[string]bool
+	breachedPasswordsOnce sync.Once
+)
+
+const passwordSymbols = "!@#$%^&*()-_=+[]{}<>?"
+
+// GetPasswordPolicy policy from the config, defaults when it was never set
+func GetPasswordPolicy(config model.Config) model.PasswordPolicy {
+	policy := config.PasswordPolicy
+	if policy.MinLength > 0 {
+		return policy
+	}
+
+	return model.PasswordPolicy{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireNumber:    true,
+		CheckBreached:    true,
+		HistoryCount:     5,
+		AgentExpiryDays:  90,
+	}
+}
+
+// ValidatePassword return the first rule the password breaks, empty when it is valid
+func ValidatePassword(policy model.PasswordPolicy, password, email string) string {
+	if len(password) < policy.MinLength {
+		return "password must be at least " + strconv.Itoa(policy.MinLength) + " characters"
+	}
+
+	var upper, lower, number, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			number = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	if policy.RequireUppercase && !upper {
+		return "password must contain an uppercase letter"
+	}
+	if policy.RequireLowercase && !lower {
+		return "password must contain a lowercase letter"
+	}
+	if policy.RequireNumber && !number {
+		return "password must contain a number"
+	}
+	if policy.RequireSymbol && !symbol {
+		return "password must contain a symbol"
+	}
+
+	if localPart, _, ok := strings.Cut(strings.ToLower(email), "@"); ok && len(localPart) >= 3 && strings.Contains(strings.ToLower(password), localPart) {
+		return "password must not contain your email"
+	}
+
+	if policy.CheckBreached && IsBreachedPassword(password) {
+		return "password is too common and appears in known data breaches"
+	}
+
+	return ""
+}
+
+// IsBreachedPassword check the password against the local breached list
+func IsBreachedPassword(password string) bool {
+	breachedPasswordsOnce.Do(_loadBreachedPasswords)
+	return breachedPasswords[strings.ToLower(password)]
+}
+
+func _loadBreachedPasswords() {
+	breachedPasswords = map[string]bool{}
+
+	file, err := breachedPasswordFS.Open("data/breached_passwords.txt")
+	if err != nil {
+		logrus.Error("breached password list:", err)
+	} else {
+		_readBreachedPasswords(bufio.NewScanner(file))
+		file.Close()
+	}
+
+	// operators can extend the list without a rebuild
+	if path := os.Getenv("PASSWORD_BREACHED_LIST"); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			logrus.Error("breached password list:", err)
+			return
+		}
+		defer file.Close()
+		_readBreachedPasswords(bufio.NewScanner(file))
+	}
+}
+
+func _readBreachedPasswords(scanner *bufio.Scanner) {
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		breachedPasswords[strings.ToLower(line)] = true
+	}
+}
+
+// IsPasswordReused check the new password against the current hash and the kept history
+func IsPasswordReused(policy model.PasswordPolicy, password, current string, history []string) bool {
+	if current != "" && bcrypt.CompareHashAndPassword([]byte(current), []byte(password)) == nil {
+		return true
+	}
+	for i, hash := range history {
+		if i >= policy.HistoryCount {
+			break
+		}
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// NextPasswordHistory keep the replaced hash first, trimmed to the policy size
+func NextPasswordHistory(policy model.PasswordPolicy, current string, history []string) []string {
+	if current == "" || policy.HistoryCount <= 0 {
+		return history
+	}
+	history = append([]string{current}, history...)
+	if len(history) > policy.HistoryCount {
+		history = history[:policy.HistoryCount]
+	}
+	return history
+}
+
+// IsPasswordExpired agent passwords expire after the policy days
+func IsPasswordExpired(policy model.PasswordPolicy, changedAt *time.Time) bool {
+	if policy.AgentExpiryDays <= 0 || changedAt == nil {
+		return false
+	}
+	return time.Since(*changedAt) > time.Duration(policy.AgentExpiryDays)*24*time.Hour
+}
+
+// GeneratePassword random one time password that satisfies the policy
+func GeneratePassword(policy model.PasswordPolicy) string {
+	const (
+		upper  = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+		lower  = "abcdefghijkmnopqrstuvwxyz"
+		number = "23456789"
+	)
+	length := policy.MinLength
+	if length < 12 {
+		length = 12
+	}
+
+	// one of each class first so every rule is met, then shuffle
+	password := []byte{
+		_randomChar(upper),
+		_randomChar(lower),
+		_randomChar(number),
+		_randomChar(passwordSymbols),
+	}
+	all := upper + lower + number + passwordSymbols
+	for len(password) < length {
+		password = append(password, _randomChar(all))
+	}
+	for i := len(password) - 1; i > 0; i-- {
+		j, _ := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+
+	return string(password)
+}
+
+func _randomChar(chars string) byte {
+	n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+	return chars[n.Int64()]
+}
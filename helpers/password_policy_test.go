@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestValidatePassword(t *testing.T) {
+	policy := GetPasswordPolicy(model.Config{})
+
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"valid", "Kettle-Harbor7", true},
+		{"too short", "Ab1", false},
+		{"no uppercase", "kettle-harbor7", false},
+		{"no lowercase", "KETTLE-HARBOR7", false},
+		{"no number", "Kettle-Harbor", false},
+		{"contains the email", "Jane.doe2024X", false},
+		{"breached", "Password1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := ValidatePassword(policy, tt.password, "jane.doe@example.com")
+			if (message == "") != tt.valid {
+				t.Fatalf("ValidatePassword = %q, want valid %v", message, tt.valid)
+			}
+		})
+	}
+
+	symbolPolicy := policy
+	symbolPolicy.RequireSymbol = true
+	if message := ValidatePassword(symbolPolicy, "KettleHarbor7", ""); message == "" {
+		t.Fatal("password without a symbol was accepted")
+	}
+}
+
+func TestPasswordHistory(t *testing.T) {
+	policy := model.PasswordPolicy{HistoryCount: 2}
+	hash := func(password string) string {
+		hashed, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		return string(hashed)
+	}
+
+	history := []string{}
+	current := hash("first")
+	for _, password := range []string{"second", "third"} {
+		history = NextPasswordHistory(policy, current, history)
+		current = hash(password)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history has %d hashes, want 2", len(history))
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"third", true},
+		{"second", true},
+		{"first", true},
+		{"fourth", false},
+	}
+	for _, tt := range tests {
+		if got := IsPasswordReused(policy, tt.password, current, history); got != tt.want {
+			t.Errorf("IsPasswordReused(%s) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+
+	history = NextPasswordHistory(policy, current, history)
+	if IsPasswordReused(policy, "first", hash("fourth"), history) {
+		t.Fatal("password older than the history count is still rejected")
+	}
+}
+
+func TestIsPasswordExpired(t *testing.T) {
+	policy := model.PasswordPolicy{AgentExpiryDays: 90}
+	recent := time.Now().Add(-24 * time.Hour)
+	old := time.Now().Add(-91 * 24 * time.Hour)
+
+	if IsPasswordExpired(policy, &recent) {
+		t.Fatal("recent password expired")
+	}
+	if !IsPasswordExpired(policy, &old) {
+		t.Fatal("old password did not expire")
+	}
+	if IsPasswordExpired(policy, nil) || IsPasswordExpired(model.PasswordPolicy{}, &old) {
+		t.Fatal("password expired without a change date or an expiry")
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	policy := GetPasswordPolicy(model.Config{})
+	policy.RequireSymbol = true
+	policy.CheckBreached = false
+
+	for i := 0; i < 20; i++ {
+		password := GeneratePassword(policy)
+		if message := ValidatePassword(policy, password, ""); message != "" {
+			t.Fatalf("generated password %q is invalid: %s", password, message)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"fmt"
+)
+
+// NewPackageChange prorate the unused time of the current package against the new package price,
+// both lines are in the order currency. The unused time is credited at what was paid for it on paidOrder,
+// the list price when the order is not known
+func NewPackageChange(subscription *model.CustomerSubscription, paidOrder *model.Order, company *model.Company, customer *model.Customer, pkg *model.HourPackage, price float64, currency string, rate float64) *model.OrderPackageChange {
+	current := subscription.HourPackage
+	currentSeconds := current.Hours * 60 * 60
+	unused := PackageChangeUnused(company, customer, current)
+
+	credit := 0.0
+	if currentSeconds > 0 {
+		credit = PaidPrice(paidOrder, current.Price, currency, rate) / float64(currentSeconds) * float64(unused)
+		credit = RoundCurrency(credit, currency)
+	}
+
+	changeType := model.PackageUpgrade
+	if pkg.Price < current.Price {
+		changeType = model.PackageDowngrade
+	}
+
+	hours, minutes, _ := ConvertRemainingSeconds(unused)
+	return &model.OrderPackageChange{
+		Type:           changeType,
+		SubscriptionID: subscription.ID.Hex(),
+		From:           *current,
+		UnusedSeconds:  unused,
+		Lines: []model.ProrationLine{
+			{
+				Description: fmt.Sprintf("%s (%d hours)", pkg.Name, pkg.Duration.Hours),
+				Amount:      price,
+			},
+			{
+				Description: fmt.Sprintf("Unused %dh %dm of %s", hours, minutes, current.Name),
+				Amount:      -credit,
+			},
+		},
+	}
+}
+
+// PaidPrice package price paid on the order in the given currency, converted through the base currency at the
+// rate frozen on the order when it was paid in another currency
+func PaidPrice(order *model.Order, listPrice float64, currency string, rate float64) float64 {
+	if order == nil {
+		return ConvertCurrency(listPrice, currency, rate)
+	}
+
+	// nothing was paid for a trial
+	if order.Trial != nil {
+		return 0
+	}
+
+	paid := OrderUnitPrice(order, listPrice)
+	if order.OrderCurrency() == currency {
+		return paid
+	}
+
+	orderRate := order.ExchangeRate
+	if orderRate <= 0 {
+		orderRate = 1
+	}
+	return paid / orderRate * rate
+}
+
+// PackageChangeUnused unused time of the current package, only the time bought with it is credited
+func PackageChangeUnused(company *model.Company, customer *model.Customer, current *model.HourPackageFK) int64 {
+	unused := TimeRemaining(company, customer)
+	if currentSeconds := current.Hours * 60 * 60; unused > currentSeconds {
+		unused = currentSeconds
+	}
+	if unused < 0 {
+		unused = 0
+	}
+	return unused
+}
+
+// PackageChangeCarrySeconds credit left above the order total, as time of the new package at its price
+func PackageChangeCarrySeconds(credit, total, price float64, pkg *model.HourPackage) int64 {
+	if credit <= total || price <= 0 {
+		return 0
+	}
+	return int64((credit - total) / price * float64(pkg.Duration.TotalinSeconds))
+}
+
+// PackageChangeCredit return the total credited by the proration lines
+func PackageChangeCredit(change *model.OrderPackageChange) float64 {
+	credit := 0.0
+	for _, line := range change.Lines {
+		if line.Amount < 0 {
+			credit -= line.Amount
+		}
+	}
+	return credit
+}
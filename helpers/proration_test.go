@@ -0,0 +1,123 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPackagePrice(t *testing.T) {
+	prices := map[string]float64{"EUR": 95}
+
+	tests := []struct {
+		name     string
+		currency string
+		rate     float64
+		want     float64
+	}{
+		{name: "base currency", currency: model.BaseCurrency, rate: 1, want: 100},
+		{name: "price set for the currency", currency: "EUR", rate: 0.9, want: 95},
+		{name: "converted from the base price", currency: model.CurrencyIDR, rate: 15000.4, want: 1500040},
+		{name: "converted and rounded to cents", currency: "SGD", rate: 1.33333, want: 133.33},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PackagePrice(100, prices, tt.currency, tt.rate); got != tt.want {
+				t.Fatalf("PackagePrice = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaidPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    *model.Order
+		currency string
+		rate     float64
+		want     float64
+	}{
+		{name: "unknown order at list price", currency: model.BaseCurrency, rate: 1, want: 100},
+		{name: "unknown order converted", currency: model.CurrencyIDR, rate: 15000, want: 1500000},
+		{name: "trial", order: &model.Order{Trial: &model.OrderTrial{}}, currency: model.BaseCurrency, rate: 1, want: 0},
+		{name: "discounted in the same currency", order: &model.Order{Currency: model.BaseCurrency, UnitPrice: 80}, currency: model.BaseCurrency, rate: 1, want: 80},
+		{name: "order before multi currency", order: &model.Order{}, currency: model.BaseCurrency, rate: 1, want: 100},
+		{name: "paid in another currency at the frozen rate", order: &model.Order{Currency: model.CurrencyIDR, ExchangeRate: 15000, UnitPrice: 1200000}, currency: model.BaseCurrency, rate: 1, want: 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PaidPrice(tt.order, 100, tt.currency, tt.rate); got != tt.want {
+				t.Fatalf("PaidPrice = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPackageChange(t *testing.T) {
+	current := &model.HourPackageFK{ID: "current", Name: "Basic", Hours: 10, Price: 100}
+	subscription := &model.CustomerSubscription{ID: primitive.NewObjectID(), HourPackage: current}
+	paidOrder := &model.Order{Currency: model.BaseCurrency, UnitPrice: 80}
+
+	customer := func(remaining int64) *model.Customer {
+		return &model.Customer{Subscription: &model.Subscription{
+			HourPackage: current,
+			Balance:     &model.Balance{Time: model.TimeBalance{Total: remaining}},
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		remaining  int64
+		pkgPrice   float64
+		wantType   model.PackageChangeType
+		wantUnused int64
+		wantCredit float64
+	}{
+		{name: "upgrade with half left", remaining: 5 * 3600, pkgPrice: 200, wantType: model.PackageUpgrade, wantUnused: 5 * 3600, wantCredit: 40},
+		{name: "downgrade", remaining: 5 * 3600, pkgPrice: 50, wantType: model.PackageDowngrade, wantUnused: 5 * 3600, wantCredit: 40},
+		{name: "carried over time is not credited", remaining: 20 * 3600, pkgPrice: 200, wantType: model.PackageUpgrade, wantUnused: 10 * 3600, wantCredit: 80},
+		{name: "overdrawn balance", remaining: -3600, pkgPrice: 200, wantType: model.PackageUpgrade},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := &model.HourPackage{Name: "New", Price: tt.pkgPrice}
+			change := NewPackageChange(subscription, paidOrder, &model.Company{}, customer(tt.remaining), pkg, tt.pkgPrice, model.BaseCurrency, 1)
+
+			if change.Type != tt.wantType || change.UnusedSeconds != tt.wantUnused {
+				t.Fatalf("got %s with %d unused, want %s with %d", change.Type, change.UnusedSeconds, tt.wantType, tt.wantUnused)
+			}
+			if credit := PackageChangeCredit(change); credit != tt.wantCredit {
+				t.Fatalf("credit = %v, want %v", credit, tt.wantCredit)
+			}
+			if change.Lines[0].Amount != tt.pkgPrice {
+				t.Fatalf("package line = %v, want %v", change.Lines[0].Amount, tt.pkgPrice)
+			}
+		})
+	}
+}
+
+func TestPackageChangeCarrySeconds(t *testing.T) {
+	pkg := &model.HourPackage{Duration: model.HourPackageDuration{TotalinSeconds: 10 * 3600}}
+
+	tests := []struct {
+		name                 string
+		credit, total, price float64
+		want                 int64
+	}{
+		{name: "credit below the total", credit: 40, total: 100, price: 100},
+		{name: "credit above the total", credit: 80, total: 50, price: 100, want: 3 * 3600},
+		{name: "free package", credit: 80, total: 0, price: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PackageChangeCarrySeconds(tt.credit, tt.total, tt.price, pkg); got != tt.want {
+				t.Fatalf("PackageChangeCarrySeconds = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GetPublicTicketRateLimit submissions per hour allowed for an ip address and for an email
+func GetPublicTicketRateLimit() int64 {
+	limit, err := strconv.ParseInt(os.Getenv("PUBLIC_TICKET_RATE_LIMIT"), 10, 64)
+	if err != nil || limit <= 0 {
+		return 5
+	}
+	return limit
+}
+
+// NewTicketTrackToken the token is sent in the tracking link and only its hash is kept
+func NewTicketTrackToken() (token, hash string) {
+	b := make([]byte, 32)
+	rand.Read(b)
+	token = hex.EncodeToString(b)
+	return token, HashTicketTrackToken(token)
+}
+
+func HashTicketTrackToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SendTicketTrackingEmail magic link to follow a ticket submitted without an account
+func SendTicketTrackingEmail(config model.Config, company *model.Company, ticket *model.Ticket, token string) {
+	link := config.TicketTrackingLink
+	if link == "" {
+		link = "{{base_url_frontend}}/ticket/track?token={{trackToken}}"
+	}
+	link = StringReplacer(link, map[string]string{
+		"base_url_frontend": company.Settings.Domain.FullUrl,
+		"trackToken":        token,
+	})
+
+	template := config.Email.Template.TicketTracking
+	if template.Title == "" || template.Body == "" {
+		template = model.TemplateEmailConfig{
+			Title: "We received your ticket {{ticket_code}}",
+			Body:  "Dear {{name}},<br><br>Thank you for contacting {{company_name}}. Your ticket <b>{{ticket_subject}}</b> was received with the code {{ticket_code}}.<br><br><a href=\"{{tracking_link}}\">Follow your ticket</a> to see its progress and reply to our team. Keep this link private, anyone with it can see the ticket.",
+		}
+	}
+	replacer := map[string]string{
+		"title":          template.Title,
+		"name":           ticket.Customer.Name,
+		"company_name":   company.Name,
+		"ticket_code":    ticket.Code,
+		"ticket_subject": ticket.Subject,
+		"tracking_link":  link,
+	}
+
+	// send email
+	mail := NewSMTPMailer(company)
+	mail.To([]string{ticket.Customer.Email})
+	mail.Subject(StringReplacer(template.Title, replacer))
+	mail.Body(StringReplacer(template.Body, replacer))
+
+	if err := mail.Send(); err != nil {
+		logrus.Errorf("Send Email ticket tracking to %s error %v", ticket.Customer.Email, err)
+	}
+}
@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"regexp"
+	"slices"
+)
+
+// scopePermissions permissions that can be granted in each portal
+var scopePermissions = map[model.RoleScope][]model.Permission{
+	model.RoleScopeAgent: {
+		model.PermissionTicketAssign,
+		model.PermissionTicketClose,
+		model.PermissionTicketViewAll,
+		model.PermissionCustomerManage,
+		model.PermissionBillingView,
+		model.PermissionCategoryManage,
+		model.PermissionUserManage,
+		model.PermissionSettingManage,
+		model.PermissionRoleManage,
+		model.PermissionServerManage,
+		model.PermissionSecurityView,
+		model.PermissionAPIKeyManage,
+		model.PermissionTicketOverCapacity,
+	},
+	model.RoleScopeCustomer: {
+		model.PermissionTicketClose,
+		model.PermissionCustomerManage,
+		model.PermissionBillingView,
+	},
+}
+
+// defaultRoles the former fixed admin, agent and customer roles
+var defaultRoles = []model.Role{
+	{
+		Scope:       model.RoleScopeAgent,
+		Code:        string(model.AdminRole),
+		Name:        "Admin",
+		Permissions: scopePermissions[model.RoleScopeAgent],
+	},
+	{
+		Scope: model.RoleScopeAgent,
+		Code:  string(model.AgentRole),
+		Name:  "Agent",
+		Permissions: []model.Permission{
+			model.PermissionTicketAssign,
+			model.PermissionTicketClose,
+			model.PermissionBillingView,
+		},
+	},
+	{
+		Scope:       model.RoleScopeCustomer,
+		Code:        string(model.AdminRole),
+		Name:        "Admin",
+		Permissions: scopePermissions[model.RoleScopeCustomer],
+	},
+	{
+		Scope: model.RoleScopeCustomer,
+		Code:  string(model.CustomerRole),
+		Name:  "Customer",
+		Permissions: []model.Permission{
+			model.PermissionTicketClose,
+		},
+	},
+}
+
+// ScopePermissions permissions that can be granted in the portal
+func ScopePermissions(scope model.RoleScope) []model.Permission {
+	return scopePermissions[scope]
+}
+
+// IsRoleScope check the scope is known
+func IsRoleScope(scope string) bool {
+	_, ok := scopePermissions[model.RoleScope(scope)]
+	return ok
+}
+
+// DefaultRoles built-in roles of the portal
+func DefaultRoles(scope model.RoleScope) []model.Role {
+	roles := []model.Role{}
+	for _, role := range defaultRoles {
+		if role.Scope == scope {
+			role.IsDefault = true
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// DefaultRole built-in role of the portal by its code, nil for custom roles
+func DefaultRole(scope model.RoleScope, code string) *model.Role {
+	for _, role := range DefaultRoles(scope) {
+		if role.Code == code {
+			return &role
+		}
+	}
+	return nil
+}
+
+// ValidatePermissions return the first permission that cannot be granted in the portal
+func ValidatePermissions(scope model.RoleScope, permissions []model.Permission) (model.Permission, bool) {
+	for _, permission := range permissions {
+		if !slices.Contains(scopePermissions[scope], permission) {
+			return permission, false
+		}
+	}
+	return "", true
+}
+
+// IsValidRoleCode lowercase code stored on the users, e.g. team_lead
+func IsValidRoleCode(code string) bool {
+	match, _ := regexp.MatchString(`^[a-z][a-z0-9_-]{1,31}$`, code)
+	return match
+}
@@ -0,0 +1,64 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewServerResource request the server of a new server subscription, an agent pick up the provisioning task
+func NewServerResource(subscription *model.CustomerSubscription, customer *model.Customer) *model.ServerResource {
+	now := time.Now()
+	resource := &model.ServerResource{
+		ID:             primitive.NewObjectID(),
+		Company:        model.CompanyNested{ID: customer.Company.ID, Name: customer.Company.Name},
+		Customer:       subscription.Customer,
+		SubscriptionID: subscription.ID.Hex(),
+		Task: &model.ProvisioningTask{
+			Status:    model.TaskOpen,
+			CreatedAt: now,
+		},
+		ExpiredAt: subscription.ExpiredAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if subscription.ServerPackage != nil {
+		resource.ServerPackage = *subscription.ServerPackage
+	}
+	SetServerResourceStatus(resource, model.ServerRequested, "Order "+subscription.Order.OrderNumber+" paid", model.UserNested{})
+
+	return resource
+}
+
+// SetServerResourceStatus move the server to the status and keep the change on its history
+func SetServerResourceStatus(resource *model.ServerResource, status model.ServerResourceStatus, note string, by model.UserNested) {
+	now := time.Now()
+	resource.Status = status
+	resource.History = append(resource.History, model.ServerResourceHistory{
+		Status:    status,
+		Note:      note,
+		CreatedBy: by,
+		CreatedAt: now,
+	})
+	resource.UpdatedAt = now
+}
+
+// ServerTerminateAfter how long a suspended server is kept, 30 days by default
+func ServerTerminateAfter(config model.Config) time.Duration {
+	after := config.ServerRetention.TerminateAfterInSecond
+	if after <= 0 {
+		after = 30 * 24 * 60 * 60
+	}
+	return time.Duration(after) * time.Second
+}
+
+// LiveServerStatus the server status that still hold infrastructure
+func LiveServerStatus() []string {
+	return []string{
+		string(model.ServerRequested),
+		string(model.ServerProvisioning),
+		string(model.ServerActive),
+		string(model.ServerSuspended),
+	}
+}
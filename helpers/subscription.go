@@ -4,6 +4,7 @@ import (
 	"app/domain/model"
 	"os"
 	"strconv"
+	"time"
 )
 
 func GetSubscriptionDuration() int {
@@ -37,3 +38,27 @@ func ConvertRemainingSeconds(seconds int64) (int64, int64, int64) {
 
 	return hours, minutes, remainingSeconds
 }
+
+// RenewalCarryOver return the unused time carried over to the renewed subscription
+func RenewalCarryOver(config model.Config, remaining int64) int64 {
+	if remaining <= 0 {
+		return 0
+	}
+
+	carryOver := remaining * config.AutoRenewal.CarryOverPercent / 100
+	if max := config.AutoRenewal.MaxCarryOverInSecond; max > 0 && carryOver > max {
+		carryOver = max
+	}
+
+	return carryOver
+}
+
+// RenewalGraceUntil return the end of the grace period when the renewal is waiting for payment
+func RenewalGraceUntil(config model.Config, subscription *model.CustomerSubscription) *time.Time {
+	if !subscription.AutoRenew || subscription.Renewal == nil || config.AutoRenewal.GracePeriodInSecond <= 0 {
+		return nil
+	}
+
+	graceUntil := subscription.ExpiredAt.Add(time.Duration(config.AutoRenewal.GracePeriodInSecond) * time.Second)
+	return &graceUntil
+}
@@ -0,0 +1,300 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// an agent drops from the ticket when the client stops refreshing its state
+	presenceViewingTTL = 30 * time.Second
+	presenceTypingTTL  = 8 * time.Second
+	// the realtime channel resends the presence to catch the expired agents
+	presenceHeartbeat = 10 * time.Second
+)
+
+func GetPresenceHeartbeat() time.Duration {
+	return presenceHeartbeat
+}
+
+// PresenceStore sorted sets and pub/sub the presence is kept in, shared through redis when it is enabled
+type PresenceStore interface {
+	Enabled() bool
+	ZAdd(ctx context.Context, key string, member string, score float64, expiration time.Duration) (err error)
+	ZRem(ctx context.Context, key string, members ...string) (err error)
+	ZRangeByScore(ctx context.Context, key string, min, max string) (members []string, err error)
+	ZRemRangeByScore(ctx context.Context, key string, min, max string) (err error)
+	Publish(ctx context.Context, channel string, message []byte) (err error)
+	Subscribe(ctx context.Context, channel string) (messages <-chan []byte, unsubscribe func() error)
+}
+
+// PresenceTracker who is viewing and typing on a ticket, every agent is a member scored by its expiry
+type PresenceTracker struct {
+	store PresenceStore
+}
+
+func NewPresenceTracker(store PresenceStore) *PresenceTracker {
+	if store == nil || !store.Enabled() {
+		store = memoryPresence
+	}
+	return &PresenceTracker{store: store}
+}
+
+func presenceKey(ticketID string, state model.PresenceState) string {
+	return "ticket_presence:" + ticketID + ":" + string(state)
+}
+
+func presenceChannel(ticketID string) string {
+	return "ticket_presence:" + ticketID
+}
+
+func presenceScore(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
+
+// Update set the agent state and publish the presence, typing agents are viewing as well
+func (t *PresenceTracker) Update(ctx context.Context, ticketID string, agent model.AgentNested, state model.PresenceState) (*model.TicketPresence, error) {
+	if err := t.remove(ctx, ticketID, agent.ID); err != nil {
+		return nil, err
+	}
+
+	member, _ := json.Marshal(agent)
+	now := time.Now()
+	switch state {
+	case model.PresenceTyping:
+		if err := t.store.ZAdd(ctx, presenceKey(ticketID, model.PresenceTyping), string(member), presenceScore(now.Add(presenceTypingTTL)), presenceTypingTTL); err != nil {
+			return nil, err
+		}
+		fallthrough
+	case model.PresenceViewing:
+		if err := t.store.ZAdd(ctx, presenceKey(ticketID, model.PresenceViewing), string(member), presenceScore(now.Add(presenceViewingTTL)), presenceViewingTTL); err != nil {
+			return nil, err
+		}
+	}
+
+	presence, err := t.Get(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message, err := json.Marshal(presence); err == nil {
+		t.store.Publish(ctx, presenceChannel(ticketID), message)
+	}
+
+	return presence, nil
+}
+
+// Get the agents on the ticket, expired members are dropped on the way
+func (t *PresenceTracker) Get(ctx context.Context, ticketID string) (*model.TicketPresence, error) {
+	now := time.Now()
+	presence := &model.TicketPresence{
+		TicketID:  ticketID,
+		Viewing:   []model.AgentNested{},
+		Typing:    []model.AgentNested{},
+		UpdatedAt: now,
+	}
+
+	for _, state := range []model.PresenceState{model.PresenceViewing, model.PresenceTyping} {
+		key := presenceKey(ticketID, state)
+		minScore := strconv.FormatFloat(presenceScore(now), 'f', 0, 64)
+		if err := t.store.ZRemRangeByScore(ctx, key, "-inf", "("+minScore); err != nil {
+			return nil, err
+		}
+
+		members, err := t.store.ZRangeByScore(ctx, key, minScore, "+inf")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			agent := model.AgentNested{}
+			if err := json.Unmarshal([]byte(member), &agent); err != nil {
+				continue
+			}
+			if state == model.PresenceTyping {
+				presence.Typing = append(presence.Typing, agent)
+			} else {
+				presence.Viewing = append(presence.Viewing, agent)
+			}
+		}
+	}
+
+	return presence, nil
+}
+
+// Subscribe presence published for the ticket, as json of model.TicketPresence
+func (t *PresenceTracker) Subscribe(ctx context.Context, ticketID string) (<-chan []byte, func() error) {
+	return t.store.Subscribe(ctx, presenceChannel(ticketID))
+}
+
+func (t *PresenceTracker) remove(ctx context.Context, ticketID string, agentID string) error {
+	for _, state := range []model.PresenceState{model.PresenceViewing, model.PresenceTyping} {
+		key := presenceKey(ticketID, state)
+		members, err := t.store.ZRangeByScore(ctx, key, "-inf", "+inf")
+		if err != nil {
+			return err
+		}
+
+		for _, member := range members {
+			agent := model.AgentNested{}
+			if err := json.Unmarshal([]byte(member), &agent); err == nil && agent.ID == agentID {
+				if err := t.store.ZRem(ctx, key, member); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// CountRepliesByOthers comments not written by the agent, customer replies always count
+func CountRepliesByOthers(comments []model.TicketComment, agentID string) int64 {
+	var total int64
+	for _, comment := range comments {
+		if comment.Sender == model.AgentSender && comment.Agent.ID == agentID {
+			continue
+		}
+		total++
+	}
+	return total
+}
+
+// memoryPresence fallback when redis is disabled, the presence is per process
+var memoryPresence = &memoryPresenceStore{
+	sets:        map[string]map[string]float64{},
+	subscribers: map[string]map[chan []byte]bool{},
+}
+
+type memoryPresenceStore struct {
+	sync.Mutex
+	sets        map[string]map[string]float64
+	subscribers map[string]map[chan []byte]bool
+}
+
+func (s *memoryPresenceStore) Enabled() bool {
+	return true
+}
+
+func (s *memoryPresenceStore) ZAdd(ctx context.Context, key string, member string, score float64, expiration time.Duration) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.sets[key] == nil {
+		s.sets[key] = map[string]float64{}
+	}
+	s.sets[key][member] = score
+	return nil
+}
+
+func (s *memoryPresenceStore) ZRem(ctx context.Context, key string, members ...string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, member := range members {
+		delete(s.sets[key], member)
+	}
+	return nil
+}
+
+func (s *memoryPresenceStore) ZRangeByScore(ctx context.Context, key string, min, max string) ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	members := make([]string, 0)
+	for member, score := range s.sets[key] {
+		if inScoreRange(score, min, max) {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+func (s *memoryPresenceStore) ZRemRangeByScore(ctx context.Context, key string, min, max string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for member, score := range s.sets[key] {
+		if inScoreRange(score, min, max) {
+			delete(s.sets[key], member)
+		}
+	}
+	if len(s.sets[key]) == 0 {
+		delete(s.sets, key)
+	}
+	return nil
+}
+
+func (s *memoryPresenceStore) Publish(ctx context.Context, channel string, message []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for subscriber := range s.subscribers[channel] {
+		// slow subscribers miss the message, the heartbeat catches them up
+		select {
+		case subscriber <- message:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *memoryPresenceStore) Subscribe(ctx context.Context, channel string) (<-chan []byte, func() error) {
+	s.Lock()
+	defer s.Unlock()
+
+	subscriber := make(chan []byte, 8)
+	if s.subscribers[channel] == nil {
+		s.subscribers[channel] = map[chan []byte]bool{}
+	}
+	s.subscribers[channel][subscriber] = true
+
+	done := make(chan struct{})
+	var once sync.Once
+	unsubscribe := func() error {
+		once.Do(func() { close(done) })
+		return nil
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		delete(s.subscribers[channel], subscriber)
+		if len(s.subscribers[channel]) == 0 {
+			delete(s.subscribers, channel)
+		}
+		close(subscriber)
+	}()
+
+	return subscriber, unsubscribe
+}
+
+// inScoreRange redis style bounds, "(" makes the bound exclusive
+func inScoreRange(score float64, min, max string) bool {
+	parse := func(bound string) (float64, bool) {
+		exclusive := len(bound) > 0 && bound[0] == '('
+		if exclusive {
+			bound = bound[1:]
+		}
+		value, _ := strconv.ParseFloat(bound, 64)
+		return value, exclusive
+	}
+
+	low, lowExclusive := parse(min)
+	high, highExclusive := parse(max)
+	if score < low || (lowExclusive && score == low) {
+		return false
+	}
+	if score > high || (highExclusive && score == high) {
+		return false
+	}
+	return true
+}
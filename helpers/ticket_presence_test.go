@@ -0,0 +1,106 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInScoreRange(t *testing.T) {
+	tests := []struct {
+		score    float64
+		min, max string
+		want     bool
+	}{
+		{5, "-inf", "+inf", true},
+		{5, "5", "10", true},
+		{5, "(5", "10", false},
+		{10, "5", "10", true},
+		{10, "5", "(10", false},
+		{4, "5", "+inf", false},
+		{11, "-inf", "10", false},
+	}
+
+	for _, tt := range tests {
+		if got := inScoreRange(tt.score, tt.min, tt.max); got != tt.want {
+			t.Errorf("inScoreRange(%v, %s, %s) = %v, want %v", tt.score, tt.min, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestPresenceTracker(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewPresenceTracker(nil)
+	ticketID := "ticket-presence-test"
+	viewer := model.AgentNested{ID: "viewer", Name: "Viewer"}
+	typist := model.AgentNested{ID: "typist", Name: "Typist"}
+
+	messages, unsubscribe := tracker.Subscribe(ctx, ticketID)
+
+	if _, err := tracker.Update(ctx, ticketID, viewer, model.PresenceViewing); err != nil {
+		t.Fatal(err)
+	}
+	presence, err := tracker.Update(ctx, ticketID, typist, model.PresenceTyping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presence.Viewing) != 2 || len(presence.Typing) != 1 || presence.Typing[0].ID != typist.ID {
+		t.Fatalf("unexpected presence %+v", presence)
+	}
+
+	presence, err = tracker.Update(ctx, ticketID, typist, model.PresenceLeft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presence.Viewing) != 1 || presence.Viewing[0].ID != viewer.ID || len(presence.Typing) != 0 {
+		t.Fatalf("unexpected presence after leaving %+v", presence)
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-messages:
+		case <-time.After(time.Second):
+			t.Fatalf("update %d was not published", i+1)
+		}
+	}
+
+	// unsubscribing twice is safe and closes the channel
+	unsubscribe()
+	unsubscribe()
+	select {
+	case _, ok := <-messages:
+		if ok {
+			t.Fatal("message after unsubscribe")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("messages channel still open after unsubscribe")
+	}
+}
+
+func TestCountRepliesByOthers(t *testing.T) {
+	comments := []model.TicketComment{
+		{Sender: model.AgentSender, Agent: model.AgentNested{ID: "me"}},
+		{Sender: model.AgentSender, Agent: model.AgentNested{ID: "other"}},
+		{Sender: model.CustomerSender},
+		{Sender: model.AgentSender, Agent: model.AgentNested{ID: "me"}},
+	}
+
+	tests := []struct {
+		name     string
+		comments []model.TicketComment
+		want     int64
+	}{
+		{"no new comments", nil, 0},
+		{"only my replies", []model.TicketComment{comments[0], comments[3]}, 0},
+		{"other agent and customer", comments, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CountRepliesByOthers(tt.comments, "me"); got != tt.want {
+				t.Fatalf("CountRepliesByOthers = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IsCompanyTrial B2B company take the trial once for all its members
+func IsCompanyTrial(company *model.Company) bool {
+	return company.Type != "B2C"
+}
+
+// TrialGrantOf return the trial already taken by the customer or its company
+func TrialGrantOf(company *model.Company, customer *model.Customer) *model.TrialGrant {
+	if IsCompanyTrial(company) {
+		return company.Trial
+	}
+	return customer.Trial
+}
+
+// CanGrantTrial check the trial was never taken, once per customer for B2C and once per company for B2B
+func CanGrantTrial(company *model.Company, customer *model.Customer) bool {
+	return TrialGrantOf(company, customer) == nil
+}
+
+// NewTrialOrder paid order of the trial package, it goes through the same subscription and ledger as a purchase
+func NewTrialOrder(customer *model.Customer, company *model.Company, pkg *model.HourPackage, orderNumber string, by model.UserNested) *model.Order {
+	now := time.Now()
+	return &model.Order{
+		ID: primitive.NewObjectID(),
+		Customer: model.CustomerFK{
+			ID:    customer.ID.Hex(),
+			Name:  customer.Name,
+			Email: customer.Email,
+		},
+		HourPackage: &model.HourPackageFK{
+			ID:      pkg.ID.Hex(),
+			Name:    pkg.Name,
+			Hours:   pkg.Duration.Hours,
+			Benefit: pkg.Benefit,
+			Price:   pkg.Price,
+		},
+		OrderNumber:     orderNumber,
+		Amount:          1,
+		Type:            model.HOUR_TYPE,
+		IsCompanyWallet: IsPooledWallet(company),
+		Currency:        model.BaseCurrency,
+		ExchangeRate:    1,
+		Status:          model.STATUS_PAID,
+		Payment: model.Payment{
+			Status: string(model.STATUS_PAID),
+			PaidAt: &now,
+		},
+		Invoice: model.InvoiceNested{
+			PaymentMethod: "TRIAL",
+		},
+		Trial: &model.OrderTrial{
+			Days:      pkg.Trial.Days,
+			GrantedBy: by,
+		},
+		PaidAt:    &now,
+		ExpiredAt: now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewTrialGrant record the trial on the customer or the company so it is not granted twice
+func NewTrialGrant(order *model.Order, expiredAt time.Time) *model.TrialGrant {
+	return &model.TrialGrant{
+		HourPackage: *order.HourPackage,
+		Order: model.OrderFK{
+			ID:          order.ID.Hex(),
+			OrderNumber: order.OrderNumber,
+			Type:        order.Type,
+		},
+		GrantedAt: order.CreatedAt,
+		ExpiredAt: expiredAt,
+	}
+}
+
+// HourSubscriptionExpiredAt trial last its days, a purchase the subscription duration
+func HourSubscriptionExpiredAt(order *model.Order, startAt time.Time) time.Time {
+	if order.Trial != nil {
+		return startAt.AddDate(0, 0, int(order.Trial.Days))
+	}
+	return startAt.AddDate(0, GetSubscriptionDuration(), 0)
+}
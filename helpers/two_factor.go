@@ -0,0 +1,197 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	totpDigits            = 6
+	totpPeriod            = 30
+	totpSkew              = 1
+	recoveryCodeTotal     = 10
+	twoFactorChallengeTTL = 5 * time.Minute
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GetTwoFactorIssuer name shown in the authenticator app
+func GetTwoFactorIssuer() string {
+	if issuer := os.Getenv("TWO_FACTOR_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Helpdesk"
+}
+
+// IsTwoFactorRequired check the company enforces two factor authentication for its agents
+func IsTwoFactorRequired(company *model.Company) bool {
+	return company != nil && company.Settings.RequireTwoFactor
+}
+
+// GenerateTOTPSecret random base32 secret of 160 bits
+func GenerateTOTPSecret() string {
+	b := make([]byte, 20)
+	rand.Read(b)
+	return totpEncoding.EncodeToString(b)
+}
+
+// TOTPProvisioningURI otpauth uri rendered as QR code by the frontend
+func TOTPProvisioningURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprintf("%d", totpDigits))
+	query.Set("period", fmt.Sprintf("%d", totpPeriod))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// VerifyTOTP check the code against the current time step and one step around it,
+// steps already used are rejected so a code cannot be replayed
+func VerifyTOTP(secret, code string, lastUsedStep int64) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := time.Now().Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+func totpCode(key []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// GenerateRecoveryCodes return the codes shown once to the user and their hashes to store
+func GenerateRecoveryCodes() (codes []string, hashes []string) {
+	for i := 0; i < recoveryCodeTotal; i++ {
+		b := make([]byte, 5)
+		rand.Read(b)
+		code := hex.EncodeToString(b)
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hash, _ := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		hashes = append(hashes, string(hash))
+	}
+	return
+}
+
+// normalizeRecoveryCode accept the code with or without the dash, empty when it can not be a recovery code
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if _, err := hex.DecodeString(code); err != nil || len(code) != 10 {
+		return ""
+	}
+	return code
+}
+
+// EnableTwoFactor confirm the pending secret with a code from the app, return the recovery codes
+func EnableTwoFactor(twoFactor *model.TwoFactor, code string) ([]string, bool) {
+	if twoFactor.PendingSecret == "" {
+		return nil, false
+	}
+	step, ok := VerifyTOTP(twoFactor.PendingSecret, code, 0)
+	if !ok {
+		return nil, false
+	}
+
+	now := time.Now()
+	codes, hashes := GenerateRecoveryCodes()
+	twoFactor.Enabled = true
+	twoFactor.Secret = twoFactor.PendingSecret
+	twoFactor.PendingSecret = ""
+	twoFactor.RecoveryCodes = hashes
+	twoFactor.LastUsedStep = step
+	twoFactor.EnabledAt = &now
+
+	return codes, true
+}
+
+// VerifyTwoFactorCode accept a code from the app or an unused recovery code, which is then consumed
+func VerifyTwoFactorCode(twoFactor *model.TwoFactor, code string) bool {
+	if !twoFactor.Enabled {
+		return false
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := VerifyTOTP(twoFactor.Secret, code, twoFactor.LastUsedStep); ok {
+		twoFactor.LastUsedStep = step
+		return true
+	}
+
+	// recovery codes are hashed with bcrypt, only codes of the right shape are compared
+	code = normalizeRecoveryCode(code)
+	if code == "" {
+		return false
+	}
+	for i, recoveryCode := range twoFactor.RecoveryCodes {
+		if bcrypt.CompareHashAndPassword([]byte(recoveryCode), []byte(code)) == nil {
+			twoFactor.RecoveryCodes = append(twoFactor.RecoveryCodes[:i], twoFactor.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// GenerateTwoFactorChallenge short lived token proving the password step passed,
+// it is signed with a derived key so it is never accepted as an access token.
+// The id counts the wrong codes of the challenge
+func GenerateTwoFactorChallenge(secretKey, issuer, userID string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ID:        newRefreshSecret(),
+		Subject:   userID,
+		Issuer:    issuer + ":two-factor",
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(twoFactorChallengeTTL)),
+	})
+
+	return token.SignedString([]byte(secretKey + ":two-factor"))
+}
+
+// ParseTwoFactorChallenge return the user id and the challenge id of a valid challenge token
+func ParseTwoFactorChallenge(secretKey, issuer, tokenString string) (string, string, bool) {
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secretKey + ":two-factor"), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(issuer+":two-factor"))
+	if err != nil || !token.Valid || claims.Subject == "" || claims.ID == "" {
+		return "", "", false
+	}
+
+	return claims.Subject, claims.ID, true
+}
@@ -0,0 +1,147 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, the SHA1 seed truncated to 6 digits
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		if got := totpCode(key, tt.unix/totpPeriod); got != tt.want {
+			t.Errorf("totpCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret := GenerateTOTPSecret()
+	key, _ := totpEncoding.DecodeString(secret)
+	current := time.Now().Unix() / totpPeriod
+
+	tests := []struct {
+		name     string
+		code     string
+		lastUsed int64
+		want     bool
+	}{
+		{"current step", totpCode(key, current), 0, true},
+		{"previous step", totpCode(key, current-1), 0, true},
+		{"next step", totpCode(key, current+1), 0, true},
+		{"too old", totpCode(key, current-3), 0, false},
+		{"replayed step", totpCode(key, current), current, false},
+		{"wrong length", "12345", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := VerifyTOTP(secret, tt.code, tt.lastUsed); ok != tt.want {
+				t.Fatalf("VerifyTOTP = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyTwoFactorCodeRecovery(t *testing.T) {
+	codes, hashes := GenerateRecoveryCodes()
+	if len(codes) != recoveryCodeTotal || len(hashes) != recoveryCodeTotal {
+		t.Fatalf("got %d codes and %d hashes", len(codes), len(hashes))
+	}
+	for i := range codes {
+		if strings.Contains(hashes[i], strings.ReplaceAll(codes[i], "-", "")) {
+			t.Fatal("recovery code is stored in plain text")
+		}
+	}
+
+	twoFactor := &model.TwoFactor{Enabled: true, Secret: GenerateTOTPSecret(), RecoveryCodes: hashes}
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"with dash", codes[0], true},
+		{"without dash and upper case", strings.ToUpper(strings.ReplaceAll(codes[1], "-", "")), true},
+		{"already used", codes[0], false},
+		{"unknown", "00000-00000", false},
+		{"not a recovery code", "not-a-code", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyTwoFactorCode(twoFactor, tt.code); got != tt.want {
+				t.Fatalf("VerifyTwoFactorCode = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if len(twoFactor.RecoveryCodes) != recoveryCodeTotal-2 {
+		t.Fatalf("%d recovery codes left, want %d", len(twoFactor.RecoveryCodes), recoveryCodeTotal-2)
+	}
+}
+
+func TestTwoFactorChallenge(t *testing.T) {
+	token, err := GenerateTwoFactorChallenge("secret", "agent", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		issuer string
+		want   bool
+	}{
+		{"valid", "secret", "agent", true},
+		{"other secret", "other", "agent", false},
+		{"other issuer", "secret", "superadmin", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID, challengeID, ok := ParseTwoFactorChallenge(tt.secret, tt.issuer, token)
+			if ok != tt.want {
+				t.Fatalf("ParseTwoFactorChallenge = %v, want %v", ok, tt.want)
+			}
+			if ok && (userID != "user" || challengeID == "") {
+				t.Fatalf("got user %q challenge %q", userID, challengeID)
+			}
+		})
+	}
+}
+
+func TestTwoFactorChallengeAttempts(t *testing.T) {
+	ctx := context.Background()
+	throttle := &LoginThrottle{store: &memoryAttemptStore{items: map[string]memoryAttempt{}}, window: time.Minute}
+
+	for i := int64(1); i <= GetTwoFactorMaxAttempts(); i++ {
+		if throttle.TwoFactorChallengeEnded(ctx, "challenge") {
+			t.Fatalf("challenge ended after %d failures", i-1)
+		}
+		challengeFailures, accountFailures := throttle.FailTwoFactor(ctx, model.SessionAgent, "agent@example.com", "", "challenge")
+		if challengeFailures != i || accountFailures != i {
+			t.Fatalf("failures = %d/%d, want %d", challengeFailures, accountFailures, i)
+		}
+	}
+	if !throttle.TwoFactorChallengeEnded(ctx, "challenge") {
+		t.Fatal("challenge still open after the max attempts")
+	}
+
+	throttle.EndTwoFactorChallenge(ctx, "exchanged")
+	if !throttle.TwoFactorChallengeEnded(ctx, "exchanged") {
+		t.Fatal("exchanged challenge still open")
+	}
+}
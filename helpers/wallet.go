@@ -0,0 +1,121 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IsPooledWallet check the company members share the company hour balance
+func IsPooledWallet(company *model.Company) bool {
+	return company != nil && company.Wallet != nil && company.Wallet.IsPooled
+}
+
+func CompanyWalletFormat(c *model.Company) *model.Company {
+	if c.Wallet != nil {
+		remainingSeconds := c.Wallet.Time.Total - c.Wallet.Time.Used
+		hours, minutes, seconds := ConvertRemainingSeconds(remainingSeconds)
+		c.Wallet.Time.Remaining = model.RemainingNested{
+			Total:  remainingSeconds,
+			Hour:   hours,
+			Minute: minutes,
+			Second: seconds,
+		}
+	}
+	return c
+}
+
+// PooledTimeRemaining return the time the customer may still draw from the company wallet
+func PooledTimeRemaining(company *model.Company, customer *model.Customer) int64 {
+	if !IsPooledWallet(company) {
+		return 0
+	}
+
+	remaining := company.Wallet.Time.Total - company.Wallet.Time.Used
+	if customer.WalletCap.Limit > 0 {
+		if capRemaining := customer.WalletCap.Limit - customer.WalletCap.Used; capRemaining < remaining {
+			remaining = capRemaining
+		}
+	}
+
+	return remaining
+}
+
+// NewCompanyBalanceHistory build a company wallet ledger entry, the running balance is taken from the wallet after the change
+func NewCompanyBalanceHistory(company *model.Company, customer *model.Customer, in, out int64, reference model.Reference, description string) *model.CustomerBalanceHistory {
+	balance := int64(0)
+	if company.Wallet != nil {
+		balance = company.Wallet.Time.Total - company.Wallet.Time.Used
+	}
+
+	now := time.Now()
+	return &model.CustomerBalanceHistory{
+		ID: primitive.NewObjectID(),
+		Customer: model.CustomerFK{
+			ID:    customer.ID.Hex(),
+			Name:  customer.Name,
+			Email: customer.Email,
+		},
+		CompanyID:   company.ID.Hex(),
+		Wallet:      model.WalletCompany,
+		In:          in,
+		Out:         out,
+		Balance:     balance,
+		Description: description,
+		Reference:   reference,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// WalletCapOverage return the part of the drawn duration that went past the member wallet cap, walletCap is the cap after the draw
+func WalletCapOverage(walletCap model.WalletCap, duration int64) int64 {
+	if walletCap.Limit <= 0 || walletCap.Used <= walletCap.Limit {
+		return 0
+	}
+	if overage := walletCap.Used - walletCap.Limit; overage < duration {
+		return overage
+	}
+	return duration
+}
+
+// WalletStore the part of the repository the company wallet is drawn with
+type WalletStore interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error)
+	IncrementCustomerWalletCap(ctx context.Context, id string, duration int64) (walletCap model.WalletCap, err error)
+	IncrementOneCompany(ctx context.Context, id string, payload map[string]int64) (err error)
+	CreateCustomerBalanceHistory(ctx context.Context, row *model.CustomerBalanceHistory) (err error)
+}
+
+// DrawCompanyWallet deduct the ticket time from the pooled wallet, the cap is enforced when the timer starts or resumes
+// so the worked time is always charged and the part past the member cap is recorded as overage
+func DrawCompanyWallet(ctx context.Context, store WalletStore, ticket *model.Ticket, customer *model.Customer, company *model.Company, duration int64) error {
+	// set from the stored value on every attempt, the transaction may be retried
+	walletUsed := company.Wallet.Time.Used
+	return store.WithTransaction(ctx, func(ctx context.Context) error {
+		walletCap, err := store.IncrementCustomerWalletCap(ctx, customer.ID.Hex(), duration)
+		if err != nil {
+			return err
+		}
+
+		if err := store.IncrementOneCompany(ctx, company.ID.Hex(), map[string]int64{
+			"wallet.time.used": duration,
+		}); err != nil {
+			return err
+		}
+
+		customer.WalletCap = walletCap
+		company.Wallet.Time.Used = walletUsed + duration
+
+		history := NewCompanyBalanceHistory(company, customer, 0, duration, model.Reference{
+			UniqueID: ticket.ID.Hex(),
+			Number:   ticket.Code,
+			Type:     model.TicketReference,
+		}, "Ticket "+ticket.Subject)
+		history.Overage = WalletCapOverage(walletCap, duration)
+
+		return store.CreateCustomerBalanceHistory(ctx, history)
+	})
+}
@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"app/domain/model"
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryWalletStore keeps one member cap and company wallet and applies the increments like the repository
+type memoryWalletStore struct {
+	walletCap  model.WalletCap
+	walletUsed int64
+	histories  []*model.CustomerBalanceHistory
+}
+
+func (s *memoryWalletStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (s *memoryWalletStore) IncrementCustomerWalletCap(ctx context.Context, id string, duration int64) (model.WalletCap, error) {
+	s.walletCap.Used += duration
+	return s.walletCap, nil
+}
+
+func (s *memoryWalletStore) IncrementOneCompany(ctx context.Context, id string, payload map[string]int64) error {
+	s.walletUsed += payload["wallet.time.used"]
+	return nil
+}
+
+func (s *memoryWalletStore) CreateCustomerBalanceHistory(ctx context.Context, row *model.CustomerBalanceHistory) error {
+	s.histories = append(s.histories, row)
+	return nil
+}
+
+func TestWalletCapOverage(t *testing.T) {
+	tests := []struct {
+		name      string
+		walletCap model.WalletCap
+		duration  int64
+		want      int64
+	}{
+		{"unlimited", model.WalletCap{Limit: 0, Used: 5000}, 900, 0},
+		{"within cap", model.WalletCap{Limit: 3600, Used: 3600}, 600, 0},
+		{"crossing cap", model.WalletCap{Limit: 3600, Used: 3900}, 900, 300},
+		{"already over cap", model.WalletCap{Limit: 3600, Used: 5000}, 900, 900},
+	}
+
+	for _, tt := range tests {
+		if got := WalletCapOverage(tt.walletCap, tt.duration); got != tt.want {
+			t.Errorf("%s: WalletCapOverage(%+v, %d) = %d, want %d", tt.name, tt.walletCap, tt.duration, got, tt.want)
+		}
+	}
+}
+
+// the cron pauses a capped member once the running segment reached the cap, the whole segment is still charged
+func TestDrawCompanyWalletPauseAtDepletion(t *testing.T) {
+	ctx := context.Background()
+	company := &model.Company{
+		ID: primitive.NewObjectID(),
+		Wallet: &model.CompanyWallet{
+			IsPooled: true,
+			Time:     model.TimeBalance{Total: 36000, Used: 10000},
+		},
+	}
+	customer := &model.Customer{
+		ID:        primitive.NewObjectID(),
+		WalletCap: model.WalletCap{Limit: 3600, Used: 3000},
+	}
+	ticket := &model.Ticket{ID: primitive.NewObjectID(), Code: "TCK-1", Subject: "Server down"}
+	store := &memoryWalletStore{walletCap: customer.WalletCap, walletUsed: company.Wallet.Time.Used}
+
+	// the cron runs every minute so the segment is past the cap when it is paused
+	segment := int64(900)
+	if remaining := PooledTimeRemaining(company, customer) - segment; remaining > 0 {
+		t.Fatalf("remaining %d, the cron would not pause", remaining)
+	}
+
+	if err := DrawCompanyWallet(ctx, store, ticket, customer, company, segment); err != nil {
+		t.Fatalf("DrawCompanyWallet() error = %v", err)
+	}
+
+	if customer.WalletCap.Used != 3900 || store.walletCap.Used != 3900 {
+		t.Errorf("wallet cap used = %d (stored %d), want 3900", customer.WalletCap.Used, store.walletCap.Used)
+	}
+	if company.Wallet.Time.Used != 10900 || store.walletUsed != 10900 {
+		t.Errorf("company wallet used = %d (stored %d), want 10900", company.Wallet.Time.Used, store.walletUsed)
+	}
+	if len(store.histories) != 1 {
+		t.Fatalf("histories = %d, want 1", len(store.histories))
+	}
+	if history := store.histories[0]; history.Out != 900 || history.Overage != 300 || history.Balance != 25100 {
+		t.Errorf("history out %d overage %d balance %d, want 900 300 25100", history.Out, history.Overage, history.Balance)
+	}
+	if remaining := PooledTimeRemaining(company, customer); remaining > 0 {
+		t.Errorf("remaining after pause = %d, the timer could be resumed", remaining)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	http_superadmin "app/app/delivery/http/superadmin"
 	http_webhook "app/app/delivery/http/webhook"
 	mongorepo "app/app/repository/mongo"
+	provisionerrepo "app/app/repository/provisioner"
 	redisrepo "app/app/repository/redis"
 	s3Repo "app/app/repository/s3"
 	xenditrepo "app/app/repository/xendit"
@@ -105,6 +106,9 @@ func main() {
 	// redis repo
 	redisrepo := redisrepo.NewRedisRepo(redisClient)
 
+	// server provisioner repo
+	provisionerRepo := provisionerrepo.NewProvisionerRepo()
+
 	runType := os.Getenv("APP_RUNTYPE")
 	if !helpers.InArrayString(runType, []string{"both", "cron", "api"}) {
 		runType = "both"
@@ -127,6 +131,8 @@ func main() {
 		cj := cronjob.NewCronjob(cronjob.RepoInjection{
 			MongoDBRepo: mongorepo,
 			Redis:       redisrepo,
+			XenditRepo:  xenditRepo,
+			Provisioner: provisionerRepo,
 			Ctx:         context.TODO(),
 			Cron:        c,
 		})
@@ -147,6 +153,7 @@ func main() {
 			MongoDBRepo: mongorepo,
 			Redis:       redisrepo,
 			S3Repo:      s3Repo,
+			Provisioner: provisionerRepo,
 		}, timeoutContext)
 
 		// init usecase superadmin
@@ -154,16 +161,18 @@ func main() {
 			MongoDBRepo: mongorepo,
 			Redis:       redisrepo,
 			S3Repo:      s3Repo,
+			XenditRepo:  xenditRepo,
 		}, timeoutContext)
 
 		// init usecase webhook
 		ucWebhook := usecase_webhook.NewAppWebhookUsecase(usecase_webhook.RepoInjection{
 			MongoDBRepo: mongorepo,
 			Redis:       redisrepo,
+			S3Repo:      s3Repo,
 		}, timeoutContext)
 
 		// init middleware
-		mdl := middleware.NewMiddleware(redisClient, mongorepo)
+		mdl := middleware.NewMiddleware(redisClient, mongorepo, redisrepo)
 
 		// gin mode realease when go env is production
 		if os.Getenv("GO_ENV") == "production" || os.Getenv("GO_ENV") == "prod" {